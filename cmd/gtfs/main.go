@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/config"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/gtfs"
+	"github.com/Rodrigoberes/TransportBookingBackend/pkg/database"
+)
+
+const usage = `Usage:
+  gtfs import -file feed.zip -company ID [-agency ID] [-vehicle ID] [-dry-run]
+  gtfs export -company ID -agency-url URL [-currency EUR] [-out feed.zip]`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "import":
+		runImport(os.Args[2:])
+	case "export":
+		runExport(os.Args[2:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "GTFS zip to import")
+	companyID := flags.Int("company", 0, "company that operates the feed")
+	agencyID := flags.String("agency", "", "agency of the feed, required when it has several")
+	vehicleID := flags.Int("vehicle", 0, "vehicle of the schedules, defaults to the company's first active vehicle")
+	dryRun := flags.Bool("dry-run", false, "report changes without applying them")
+	flags.Parse(args)
+
+	if *file == "" || *companyID == 0 {
+		log.Fatal(usage)
+	}
+
+	archive, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer archive.Close()
+	info, err := archive.Stat()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	feed, err := gtfs.ReadFeed(archive, info.Size())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db := database.Connect(config.Load().DatabaseURL)
+	defer db.Close()
+
+	report, err := gtfs.Import(db, feed, gtfs.ImportOptions{
+		CompanyID: *companyID,
+		AgencyID:  *agencyID,
+		VehicleID: *vehicleID,
+		DryRun:    *dryRun,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+}
+
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	companyID := flags.Int("company", 0, "company to export")
+	agencyURL := flags.String("agency-url", "", "agency website, required by GTFS")
+	currency := flags.String("currency", "", "ISO 4217 currency of route prices; fares are left out without one")
+	out := flags.String("out", "gtfs.zip", "zip file to write")
+	flags.Parse(args)
+
+	if *companyID == 0 {
+		log.Fatal(usage)
+	}
+
+	db := database.Connect(config.Load().DatabaseURL)
+	defer db.Close()
+
+	feed, warnings, err := gtfs.Export(db, *companyID, gtfs.ExportOptions{AgencyURL: *agencyURL, Currency: *currency})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, warning := range warnings {
+		log.Println("warning:", warning)
+	}
+
+	archive, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer archive.Close()
+
+	if err := gtfs.WriteFeed(archive, feed); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %d routes and %d trips to %s", len(feed.Routes), len(feed.Trips), *out)
+}
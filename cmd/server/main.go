@@ -1,12 +1,15 @@
 package main
 
 import (
+	"database/sql"
 	"log"
 	"os"
+	"time"
 
 	_ "github.com/Rodrigoberes/TransportBookingBackend/docs"
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/api/routes"
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/config"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
 	"github.com/Rodrigoberes/TransportBookingBackend/pkg/database"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -40,6 +43,21 @@ func main() {
 	// Swagger endpoint
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Close departed bookings in the background
+	if cfg.NoShowJobIntervalMinutes > 0 {
+		go runNoShowJob(db, time.Duration(cfg.NoShowJobIntervalMinutes)*time.Minute, time.Duration(cfg.NoShowGraceMinutes)*time.Minute)
+	}
+
+	// Offer seats freed for waitlisted passengers in the background
+	if cfg.WaitlistJobIntervalMinutes > 0 {
+		go runWaitlistJob(db, time.Duration(cfg.WaitlistJobIntervalMinutes)*time.Minute, time.Duration(cfg.WaitlistClaimMinutes)*time.Minute)
+	}
+
+	// Enforce group booking deadlines in the background
+	if cfg.GroupBookingJobIntervalMinutes > 0 {
+		go runGroupBookingJob(db, time.Duration(cfg.GroupBookingJobIntervalMinutes)*time.Minute, time.Duration(cfg.WaitlistClaimMinutes)*time.Minute)
+	}
+
 	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -50,4 +68,52 @@ func main() {
 	if err := router.Run(":" + port); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
-}
\ No newline at end of file
+}
+
+// runNoShowJob marks departed bookings as completed or no-show at every interval
+func runNoShowJob(db *sql.DB, interval, grace time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		run, err := services.ProcessNoShows(db, grace, time.Now())
+		if err != nil {
+			log.Printf("No-show processing failed: %v", err)
+		}
+		if run != nil && run.Completed+run.NoShows > 0 {
+			log.Printf("No-show processing: %d completed, %d no-shows, %.2f in fees", run.Completed, run.NoShows, run.Fees)
+		}
+	}
+}
+
+// runWaitlistJob expires lapsed waitlist offers and offers freed seats at every interval
+func runWaitlistJob(db *sql.DB, interval, claimPeriod time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		run, err := services.ProcessWaitlists(db, claimPeriod, time.Now())
+		if err != nil {
+			log.Printf("Waitlist processing failed: %v", err)
+		}
+		if run != nil && run.Expired+int64(run.Offered) > 0 {
+			log.Printf("Waitlist processing: %d offers made, %d entries expired", run.Offered, run.Expired)
+		}
+	}
+}
+
+// runGroupBookingJob expires lapsed group quotes and unpaid groups and releases unclaimed group seats at every interval
+func runGroupBookingJob(db *sql.DB, interval, claimPeriod time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		run, err := services.ProcessGroupBookings(db, claimPeriod, time.Now())
+		if err != nil {
+			log.Printf("Group booking processing failed: %v", err)
+		}
+		if run != nil && int64(run.Expired)+run.SeatsReleased > 0 {
+			log.Printf("Group booking processing: %d groups expired, %d seats released", run.Expired, run.SeatsReleased)
+		}
+	}
+}
@@ -15,36 +15,35 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/travels/search": {
+        "/admin/gtfs/export": {
             "get": {
-                "description": "Search for available bus travels by origin, destination, and date",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Export the active routes and schedules of a company as a GTFS zip. Every stop needs coordinates",
                 "produces": [
-                    "application/json"
+                    "application/zip"
                 ],
                 "tags": [
-                    "travels"
+                    "gtfs"
                 ],
-                "summary": "Search available bus travels",
+                "summary": "Export a GTFS feed",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Origin city",
-                        "name": "origin",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Company ID",
+                        "name": "company_id",
+                        "in": "query",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Destination city",
-                        "name": "destination",
-                        "in": "query"
+                        "description": "Agency website, required by GTFS",
+                        "name": "agency_url",
+                        "in": "query",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Travel date (YYYY-MM-DD)",
-                        "name": "date",
+                        "description": "ISO 4217 currency of route prices; fares are left out without one",
+                        "name": "currency",
                         "in": "query"
                     }
                 ],
@@ -52,45 +51,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/login": {
-            "post": {
-                "description": "Authenticate user and return JWT token",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Login user",
-                "parameters": [
-                    {
-                        "description": "Login credentials",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "type": "file"
                         }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -98,8 +63,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -110,35 +75,58 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/register": {
+        "/admin/gtfs/import": {
             "post": {
-                "description": "Register a new user with email and password",
+                "description": "Import the routes, trips and services of one agency of a GTFS zip into a company. Entities are matched by GTFS id, so feeds can be imported again; dry_run reports the changes without applying them",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "gtfs"
                 ],
-                "summary": "Register a new user",
+                "summary": "Import a GTFS feed",
                 "parameters": [
                     {
-                        "description": "User data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.User"
-                        }
+                        "type": "file",
+                        "description": "GTFS zip",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Company ID",
+                        "name": "company_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Agency of the feed, required when it has several",
+                        "name": "agency_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vehicle of the schedules, defaults to the company's first active vehicle",
+                        "name": "vehicle_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Report changes without applying them",
+                        "name": "dry_run",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/gtfs.ImportReport"
                         }
                     },
                     "400": {
@@ -149,37 +137,69 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/bookings": {
+        "/ancillaries": {
             "get": {
-                "description": "Get list of bookings for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List the ancillary catalogue of the operator's company, or of company_id for admins, including inactive ones",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "ancillaries"
+                ],
+                "summary": "List ancillaries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Company ID (admins)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get bookings for user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Booking"
+                                "$ref": "#/definitions/models.Ancillary"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new booking with seat selection and simulated payment",
+                "description": "Add an extra to a company's catalogue, such as an extra bag, oversize luggage, a bicycle, a pet or travel insurance. Its price per unit and leg is the fixed price plus fare_percent of the seat fare. Operators create ancillaries for their own company; admins must give company_id",
                 "consumes": [
                     "application/json"
                 ],
@@ -187,17 +207,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "ancillaries"
                 ],
-                "summary": "Create a new booking with seat selection",
+                "summary": "Create an ancillary",
                 "parameters": [
                     {
-                        "description": "Booking data with seat selection",
-                        "name": "booking",
+                        "description": "Ancillary data",
+                        "name": "ancillary",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.CreateBookingRequest"
+                            "$ref": "#/definitions/handlers.AncillaryRequest"
                         }
                     }
                 ],
@@ -205,7 +225,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Booking"
+                            "$ref": "#/definitions/models.Ancillary"
                         }
                     },
                     "400": {
@@ -217,8 +237,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -229,9 +249,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/bookings/{id}": {
-            "get": {
-                "description": "Get booking information by ID",
+        "/ancillaries/{id}": {
+            "put": {
+                "description": "Update an ancillary of the operator's company. New prices apply to later sales; set is_active to false to stop selling it",
                 "consumes": [
                     "application/json"
                 ],
@@ -239,23 +259,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "ancillaries"
                 ],
-                "summary": "Get booking by ID",
+                "summary": "Update an ancillary",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Booking ID",
+                        "description": "Ancillary ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Ancillary data",
+                        "name": "ancillary",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AncillaryRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Booking"
+                            "$ref": "#/definitions/models.Ancillary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "404": {
@@ -268,9 +315,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update booking information",
+            }
+        },
+        "/api/v1/travels/itineraries": {
+            "get": {
+                "description": "Combine departures into itineraries from origin to destination, changing at a shared city or terminal. Results are ranked by total duration, then price",
                 "consumes": [
                     "application/json"
                 ],
@@ -278,32 +327,64 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "travels"
                 ],
-                "summary": "Update booking",
+                "summary": "Search direct and connecting itineraries",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Booking ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Origin city",
+                        "name": "origin",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "description": "Booking data",
-                        "name": "booking",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Booking"
-                        }
+                        "type": "string",
+                        "description": "Destination city",
+                        "name": "destination",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Travel date (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of transfers (default 1, at most 2)",
+                        "name": "max_transfers",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum connection time in minutes",
+                        "name": "min_connection_minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum connection time in minutes",
+                        "name": "max_connection_minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of passengers (default 1)",
+                        "name": "passengers",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Booking"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Itinerary"
+                            }
                         }
                     },
                     "400": {
@@ -316,9 +397,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a booking by ID",
+            }
+        },
+        "/api/v1/travels/search": {
+            "get": {
+                "description": "Search for available bus travels by origin, destination, and date. Origin and destination match any pair of stops of a route in travel order. With a date, prices follow the route's yield rules for the seats sold and the days left. With a return date, outbound and return travels are listed together",
                 "consumes": [
                     "application/json"
                 ],
@@ -326,87 +409,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "travels"
                 ],
-                "summary": "Delete booking",
+                "summary": "Search available bus travels",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Booking ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
+                        "type": "string",
+                        "description": "Origin city",
+                        "name": "origin",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                    {
+                        "type": "string",
+                        "description": "Destination city",
+                        "name": "destination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Travel date (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return date (YYYY-MM-DD); requires origin and destination",
+                        "name": "return_date",
+                        "in": "query"
                     }
-                }
-            }
-        },
-        "/companies": {
-            "get": {
-                "description": "Get list of all companies",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "companies"
                 ],
-                "summary": "Get all companies",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Company"
-                            }
-                        }
-                    }
-                }
-            },
-            "post": {
-                "description": "Create a new company",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "companies"
-                ],
-                "summary": "Create a new company",
-                "parameters": [
-                    {
-                        "description": "Company data",
-                        "name": "company",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Company"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/models.Company"
+                            "$ref": "#/definitions/handlers.RoundTripSearchResult"
                         }
                     },
                     "400": {
@@ -421,9 +457,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/companies/{id}": {
-            "get": {
-                "description": "Get company information by ID",
+        "/auth/login": {
+            "post": {
+                "description": "Authenticate user and return JWT token",
                 "consumes": [
                     "application/json"
                 ],
@@ -431,27 +467,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "companies"
+                    "auth"
                 ],
-                "summary": "Get company by ID",
+                "summary": "Login user",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Company ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Login credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Company"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -460,9 +504,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update company information",
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Register a new user with email and password",
                 "consumes": [
                     "application/json"
                 ],
@@ -470,32 +516,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "companies"
+                    "auth"
                 ],
-                "summary": "Update company",
+                "summary": "Register a new user",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Company ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Company data",
-                        "name": "company",
+                        "description": "User data",
+                        "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Company"
+                            "$ref": "#/definitions/models.User"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Company"
+                            "$ref": "#/definitions/models.User"
                         }
                     },
                     "400": {
@@ -508,47 +547,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a company by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "companies"
-                ],
-                "summary": "Delete company",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Company ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
             }
         },
-        "/routes": {
+        "/bookings": {
             "get": {
-                "description": "Get list of all routes",
+                "description": "Get list of bookings for the authenticated user",
                 "consumes": [
                     "application/json"
                 ],
@@ -556,23 +559,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "routes"
+                    "bookings"
                 ],
-                "summary": "Get all routes",
+                "summary": "Get bookings for user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Route"
+                                "$ref": "#/definitions/models.Booking"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new route",
+                "description": "Create a new booking with seat selection and simulated payment. When several legs, or a return leg, are given they are booked atomically as one journey with a single payment. Ancillaries such as luggage, bicycles, pets or insurance can be bought with each leg, within the capacity left on the departure. A fare family of the route sets the seat price, change and cancellation rules and included ancillaries. The route's yield rules adjust the seat price to the departure's load factor and the days left, and the price is locked into the booking.",
                 "consumes": [
                     "application/json"
                 ],
@@ -580,17 +583,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "routes"
+                    "bookings"
                 ],
-                "summary": "Create a new route",
+                "summary": "Create a new booking with seat selection",
                 "parameters": [
                     {
-                        "description": "Route data",
-                        "name": "route",
+                        "description": "Booking data with seat selection",
+                        "name": "booking",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Route"
+                            "$ref": "#/definitions/handlers.CreateBookingRequest"
                         }
                     }
                 ],
@@ -598,7 +601,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Route"
+                            "$ref": "#/definitions/models.Journey"
                         }
                     },
                     "400": {
@@ -609,13 +612,22 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/routes/{id}": {
+        "/bookings/{id}": {
             "get": {
-                "description": "Get route information by ID",
+                "description": "Get a booking of the authenticated user by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -623,13 +635,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "routes"
+                    "bookings"
                 ],
-                "summary": "Get route by ID",
+                "summary": "Get booking by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Route ID",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -639,7 +651,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Route"
+                            "$ref": "#/definitions/models.Booking"
                         }
                     },
                     "404": {
@@ -654,7 +666,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Update route information",
+                "description": "Update the passenger name, phone and notes of a booking of the authenticated user. Use an exchange to change the departure or seats",
                 "consumes": [
                     "application/json"
                 ],
@@ -662,24 +674,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "routes"
+                    "bookings"
                 ],
-                "summary": "Update route",
+                "summary": "Update booking",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Route ID",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Route data",
-                        "name": "route",
+                        "description": "Booking details",
+                        "name": "booking",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Route"
+                            "$ref": "#/definitions/handlers.UpdateBookingRequest"
                         }
                     }
                 ],
@@ -687,7 +699,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Route"
+                            "$ref": "#/definitions/models.Booking"
                         }
                     },
                     "400": {
@@ -698,11 +710,29 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a route by ID",
+                "description": "Delete a booking of the authenticated user by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -710,13 +740,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "routes"
+                    "bookings"
                 ],
-                "summary": "Delete route",
+                "summary": "Delete booking",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Route ID",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -726,6 +756,15 @@ const docTemplate = `{
                     "204": {
                         "description": "No Content"
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -738,32 +777,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/travels/seats": {
+        "/bookings/{id}/ancillaries": {
             "get": {
-                "description": "Get list of available seats for booking",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List the ancillaries of a booking of the authenticated user with the price they were sold for",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "travels"
+                    "bookings"
                 ],
-                "summary": "Get available seats for a specific schedule and date",
+                "summary": "Booking ancillaries",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "schedule_id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Travel date (YYYY-MM-DD)",
-                        "name": "travel_date",
-                        "in": "query",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -773,40 +802,23 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Seat"
+                                "$ref": "#/definitions/models.BookingAncillary"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/users": {
-            "get": {
-                "description": "Get list of all users",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "users"
-                ],
-                "summary": "Get all users",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new user account",
+                "description": "Buy ancillaries for a confirmed, paid booking of the authenticated user before departure. They are charged with the booking's payment method, added to its total and refunded with it",
                 "consumes": [
                     "application/json"
                 ],
@@ -814,17 +826,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "bookings"
                 ],
-                "summary": "Create a new user",
+                "summary": "Add ancillaries to a booking",
                 "parameters": [
                     {
-                        "description": "User data",
-                        "name": "user",
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ancillaries to add",
+                        "name": "ancillaries",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/handlers.AddBookingAncillariesRequest"
                         }
                     }
                 ],
@@ -832,7 +851,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/services.AncillaryPurchase"
                         }
                     },
                     "400": {
@@ -843,13 +862,31 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/users/search": {
-            "get": {
-                "description": "Search users by name or email",
+        "/bookings/{id}/cancel": {
+            "post": {
+                "description": "Cancel a booking of the authenticated user and refund it. Freed seats are offered to the departure's waitlist. Connecting journeys are cancelled as a whole; cancelling the outbound leg of a round trip cancels the return too, while cancelling only the return refunds it minus the round-trip discount. Fare families keep their cancellation fee, or the whole fare when not refundable. Bookings issued for a group follow the group's terms and cannot be cancelled on their own",
                 "consumes": [
                     "application/json"
                 ],
@@ -857,47 +894,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "bookings"
                 ],
-                "summary": "Search users",
+                "summary": "Cancel booking",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Search query",
-                        "name": "q",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/services.BookingCancellation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/users/{id}": {
+        "/bookings/{id}/check-in": {
             "get": {
-                "description": "Get user information by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get when online check-in opens and closes for a booking of the authenticated user, and whether it is checked in",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "bookings"
                 ],
-                "summary": "Get user by ID",
+                "summary": "Check-in status",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -907,7 +957,16 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/services.CheckInStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "404": {
@@ -918,11 +977,20 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             },
-            "put": {
-                "description": "Update user information",
+            "post": {
+                "description": "Check in a confirmed, paid booking of the authenticated user within the check-in window, completing the passenger document and nationality, and get the boarding passes",
                 "consumes": [
                     "application/json"
                 ],
@@ -930,24 +998,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "bookings"
                 ],
-                "summary": "Update user",
+                "summary": "Check in",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "User data",
-                        "name": "user",
+                        "description": "Passenger document and nationality",
+                        "name": "check_in",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/handlers.CheckInRequest"
                         }
                     }
                 ],
@@ -955,7 +1023,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/services.CheckInResult"
                         }
                     },
                     "400": {
@@ -966,11 +1034,31 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a user by ID",
+            }
+        },
+        "/bookings/{id}/exchange": {
+            "post": {
+                "description": "Move a booking of the authenticated user to another departure, segment or seats. Fare families that do not allow changes can only change seats or segment. The new seats are reserved, the amount due is charged or refunded when negative, and the original booking is kept in the exchange history. Check-in has to be done again. Released seats are offered to the waitlist",
                 "consumes": [
                     "application/json"
                 ],
@@ -978,24 +1066,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "bookings"
                 ],
-                "summary": "Delete user",
+                "summary": "Exchange a booking",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "New departure and seats",
+                        "name": "exchange",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ExchangeBookingRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.BookingExchangeResult"
+                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1005,41 +1123,8651 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.CreateBookingRequest": {
-            "type": "object",
-            "required": [
-                "passenger_document",
-                "passenger_name",
-                "passenger_phone",
-                "schedule_id",
-                "seat_ids",
-                "travel_date"
-            ],
-            "properties": {
-                "notes": {
-                    "type": "string"
-                },
-                "passenger_document": {
-                    "type": "string"
-                },
-                "passenger_name": {
-                    "type": "string"
-                },
-                "passenger_phone": {
-                    "type": "string"
-                },
-                "payment_method": {
-                    "type": "string"
-                },
-                "schedule_id": {
-                    "type": "integer"
-                },
-                "seat_ids": {
-                    "type": "array",
-                    "minItems": 1,
+        },
+        "/bookings/{id}/exchange/quote": {
+            "post": {
+                "description": "Price moving a booking of the authenticated user to another departure, segment or seats: the fare difference plus the change fee of the booking's fare family, or the company's, waived when only the seats change. Seats changed within the same departure and segment keep the yield price locked at booking time. Nothing is reserved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Quote a booking exchange",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New departure and seats",
+                        "name": "exchange",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ExchangeBookingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.BookingExchangeResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/exchanges": {
+            "get": {
+                "description": "List the exchanges of a booking of the authenticated user, oldest first, each with the booking as it was before",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Booking exchange history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BookingExchange"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/tickets": {
+            "get": {
+                "description": "Issue a signed ticket for each seat of a confirmed, paid booking of the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Booking tickets",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Ticket"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/tickets/pdf": {
+            "get": {
+                "description": "Download the tickets of a booking as a PDF, one page per seat with its QR code",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Printable tickets",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/tickets/{booking_seat_id}/qr": {
+            "get": {
+                "description": "Download the QR code of a ticket as a PNG image",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Ticket QR code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Booking seat ID",
+                        "name": "booking_seat_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/tracking": {
+            "get": {
+                "description": "Live position of the bus running the authenticated user's booking, with estimated times at its stops and at the booking's destination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Track a booking's bus",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.BookingTracking"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/calendars": {
+            "get": {
+                "description": "List the calendars of the operator's company, or of company_id for admins",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendars"
+                ],
+                "summary": "List service calendars",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Company ID (admins)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ServiceCalendar"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a reusable operating calendar for a company. Operators create calendars for their own company; admins must give company_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendars"
+                ],
+                "summary": "Create a service calendar",
+                "parameters": [
+                    {
+                        "description": "Calendar data",
+                        "name": "calendar",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceCalendar"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceCalendar"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/calendars/{id}": {
+            "get": {
+                "description": "Get a calendar with its exception dates",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendars"
+                ],
+                "summary": "Get service calendar",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Calendar ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceCalendar"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Rename a calendar",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendars"
+                ],
+                "summary": "Update service calendar",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Calendar ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Calendar data",
+                        "name": "calendar",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceCalendar"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceCalendar"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a calendar; schedules following it keep running on their days of week",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendars"
+                ],
+                "summary": "Delete service calendar",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Calendar ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/calendars/{id}/exceptions": {
+            "post": {
+                "description": "Add or remove service on a date (e.g. no service on 25 December, extra departures on Ferragosto). An existing exception on the same date is replaced",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendars"
+                ],
+                "summary": "Add a calendar exception",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Calendar ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Exception date and type",
+                        "name": "exception",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CalendarExceptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CalendarException"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/calendars/{id}/exceptions/{exception_id}": {
+            "delete": {
+                "description": "Remove an exception date from a calendar",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendars"
+                ],
+                "summary": "Delete a calendar exception",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Calendar ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Exception ID",
+                        "name": "exception_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies": {
+            "get": {
+                "description": "Get list of all companies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Get all companies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Company"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new company",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Create a new company",
+                "parameters": [
+                    {
+                        "description": "Company data",
+                        "name": "company",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Company"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Company"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}": {
+            "get": {
+                "description": "Get company information by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Get company by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Company"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update company information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Update company",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Company data",
+                        "name": "company",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Company"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Company"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a company by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Delete company",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/crew": {
+            "get": {
+                "description": "List the crew of the operator's company, or of company_id for admins",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "crew"
+                ],
+                "summary": "List crew members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Company ID (admins)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CrewMember"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a driver or attendant to a company. Drivers need a licence number and expiry date. Operators create crew for their own company; admins must give company_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "crew"
+                ],
+                "summary": "Create a crew member",
+                "parameters": [
+                    {
+                        "description": "Crew member data",
+                        "name": "crew",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CrewMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CrewMember"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/crew/me/duties": {
+            "get": {
+                "description": "List the duties of the authenticated crew member. Defaults to the next 7 days",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "crew"
+                ],
+                "summary": "My duties",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CrewDuty"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/crew/{id}": {
+            "get": {
+                "description": "Get a crew member of the operator's company",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "crew"
+                ],
+                "summary": "Get crew member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Crew member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CrewMember"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a crew member, e.g. renew a licence or deactivate someone who left. Existing duties are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "crew"
+                ],
+                "summary": "Update crew member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Crew member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Crew member data",
+                        "name": "crew",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CrewMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CrewMember"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/crew/{id}/duties": {
+            "get": {
+                "description": "List the duties of a crew member. Defaults to the next 7 days",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "crew"
+                ],
+                "summary": "Crew member duties",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Crew member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CrewDuty"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/devices/boardings/sync": {
+            "post": {
+                "description": "Upload tickets scanned by an on-board device, authenticated with \"Authorization: Device \u003ctoken\u003e\", for a trip run by its vehicle. Scans are resolved as in /trips/{id}/boardings/sync",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "boarding"
+                ],
+                "summary": "Upload device scans",
+                "parameters": [
+                    {
+                        "description": "Trip and scans",
+                        "name": "scans",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DeviceBoardingSyncRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BoardingScan"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/devices/positions": {
+            "post": {
+                "description": "Positions reported by an on-board device, authenticated with \"Authorization: Device \u003ctoken\u003e\". Each position is linked to the trip the vehicle was running at the time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicles"
+                ],
+                "summary": "Report vehicle positions",
+                "parameters": [
+                    {
+                        "description": "Positions, oldest first",
+                        "name": "positions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DevicePositionsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.VehiclePosition"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/fare-families": {
+            "get": {
+                "description": "List the fare families of a route of the operator's company in display order, including inactive ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fare-families"
+                ],
+                "summary": "List fare families of a route",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Route ID",
+                        "name": "route_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FareFamily"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a fare family, such as Basic, Flex or Premium, to a route of the operator's company. Seats cost price_percent of the segment fare plus the surcharge; change and cancellation rules apply to bookings sold under it, and included ancillaries come with each seat",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fare-families"
+                ],
+                "summary": "Create a fare family",
+                "parameters": [
+                    {
+                        "description": "Fare family data",
+                        "name": "family",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FareFamilyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.FareFamily"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/fare-families/{id}": {
+            "put": {
+                "description": "Update a fare family of the operator's company, replacing its included ancillaries. Bookings already sold keep their price and the change and cancellation rules they were sold with. Set is_active to false to stop selling it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fare-families"
+                ],
+                "summary": "Update a fare family",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Fare family ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fare family data",
+                        "name": "family",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FareFamilyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FareFamily"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/group-bookings": {
+            "get": {
+                "description": "List the group bookings of the authenticated user, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group-bookings"
+                ],
+                "summary": "List my group bookings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GroupBooking"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Ask the operator for a quote on a block of seats for a group of at least 10 passengers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group-bookings"
+                ],
+                "summary": "Request a group booking",
+                "parameters": [
+                    {
+                        "description": "Departure and group",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateGroupBookingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupBooking"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/group-bookings/{id}": {
+            "get": {
+                "description": "Get a group booking of the authenticated user with its seat block and passengers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group-bookings"
+                ],
+                "summary": "Get a group booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupBooking"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/group-bookings/{id}/balance": {
+            "post": {
+                "description": "Pay the rest of a confirmed group's total before the balance deadline. Every seat needs a passenger; a booking is issued for each",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group-bookings"
+                ],
+                "summary": "Pay a group's balance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GroupPaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupBooking"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/group-bookings/{id}/cancel": {
+            "post": {
+                "description": "Withdraw a group booking that is not completed, releasing its seat block. A deposit already paid is not refunded",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group-bookings"
+                ],
+                "summary": "Cancel a group booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupBooking"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/group-bookings/{id}/confirm": {
+            "post": {
+                "description": "Accept the operator's quote by paying the deposit, before the quote expires",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group-bookings"
+                ],
+                "summary": "Confirm a group quote",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GroupPaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupBooking"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/group-bookings/{id}/passengers": {
+            "put": {
+                "description": "Set the passenger of seats of a confirmed group's block, before the passenger deadline. Seats still without a passenger at the deadline are released",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group-bookings"
+                ],
+                "summary": "Supply a group's passengers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Passengers by seat",
+                        "name": "passengers",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GroupPassengersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupBooking"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/group-requests": {
+            "get": {
+                "description": "List the group bookings on the departures of the operator's company, or of company_id for admins, by departure",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group-bookings"
+                ],
+                "summary": "List group requests",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Company ID (admins)",
+                        "name": "company_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status (requested, quoted, confirmed, completed, declined, expired, cancelled)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GroupBooking"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/group-requests/{id}": {
+            "get": {
+                "description": "Get a group booking on a departure of the operator's company, with its seat block and passengers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group-bookings"
+                ],
+                "summary": "Get a group request",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupBooking"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/group-requests/{id}/decline": {
+            "post": {
+                "description": "Turn down a group request that has not been confirmed, releasing any quoted block",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group-bookings"
+                ],
+                "summary": "Decline a group request",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupBooking"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/group-requests/{id}/quote": {
+            "post": {
+                "description": "Price a group request with a custom price per seat and a deposit, and hold a block of seats, the given ones or the first free ones, until the quote expires. Quoting again replaces the previous quote",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group-bookings"
+                ],
+                "summary": "Quote a group request",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Quote",
+                        "name": "quote",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.QuoteGroupBookingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupBooking"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/gtfs-rt/trip-updates": {
+            "get": {
+                "description": "Delays and cancellations of trips that have not arrived yet, as a GTFS-Realtime protobuf feed. format=json returns the same feed as JSON for debugging",
+                "produces": [
+                    "application/x-protobuf",
+                    "application/json"
+                ],
+                "tags": [
+                    "gtfs"
+                ],
+                "summary": "GTFS-Realtime trip updates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json for the debug variant",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gtfs.FeedMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/gtfs-rt/vehicle-positions": {
+            "get": {
+                "description": "Latest reported position of each running trip, as a GTFS-Realtime protobuf feed. format=json returns the same feed as JSON for debugging",
+                "produces": [
+                    "application/x-protobuf",
+                    "application/json"
+                ],
+                "tags": [
+                    "gtfs"
+                ],
+                "summary": "GTFS-Realtime vehicle positions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json for the debug variant",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gtfs.FeedMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/journeys/{id}": {
+            "get": {
+                "description": "Get a journey of the authenticated user with the bookings of all its legs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Get journey by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Journey ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Journey"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "description": "Get the notifications of the authenticated user (trip cancellations, delays, ...)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get notifications for user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Notification"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/read": {
+            "put": {
+                "description": "Mark a notification of the authenticated user as read",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark notification as read",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/attendance": {
+            "get": {
+                "description": "Count the bookings that travelled and the no-shows of a company's departures, with the no-show fees kept. Operators see their own company; admins pass company_id. Defaults to the last 7 days",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Attendance report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Company ID (admins)",
+                        "name": "company_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First travel date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last travel date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.AttendanceReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/routes": {
+            "get": {
+                "description": "Get list of all routes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routes"
+                ],
+                "summary": "Get all routes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Route"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new route. Stops are optional; without them the route goes straight from origin to destination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routes"
+                ],
+                "summary": "Create a new route",
+                "parameters": [
+                    {
+                        "description": "Route data",
+                        "name": "route",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Route"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Route"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/routes/{id}": {
+            "get": {
+                "description": "Get route information by ID, including its stops and stop-pair fares",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routes"
+                ],
+                "summary": "Get route by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Route ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Route"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update route information. Omitted stops and fares are kept; stops can only be changed while the route has no upcoming bookings, except for their coordinates and GTFS ids",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routes"
+                ],
+                "summary": "Update route",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Route ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Route data",
+                        "name": "route",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Route"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Route"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a route by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routes"
+                ],
+                "summary": "Delete route",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Route ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/routes/{id}/fare-families": {
+            "get": {
+                "description": "List the fare families sold on a route in display order, with their rules and included ancillaries",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fare-families"
+                ],
+                "summary": "Fare families of a route",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Route ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FareFamily"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules": {
+            "post": {
+                "description": "Create a recurring departure for a route of the operator's company, optionally following a service calendar. The vehicle must belong to the route's company and be free for the departures",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Create a schedule",
+                "parameters": [
+                    {
+                        "description": "Schedule data",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}": {
+            "get": {
+                "description": "Get schedule information by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Get schedule by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schedule"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a schedule of the operator's company, including its service calendar",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Update schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule data",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/ancillaries": {
+            "get": {
+                "description": "List the ancillaries sold on a departure of a schedule, priced for the segment, with the units left on it when their capacity is limited",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ancillaries"
+                ],
+                "summary": "Ancillaries on a departure",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Travel date (YYYY-MM-DD)",
+                        "name": "travel_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Boarding stop sequence (defaults to the first stop)",
+                        "name": "origin_stop_sequence",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Alighting stop sequence (defaults to the last stop)",
+                        "name": "destination_stop_sequence",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AncillaryOffer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/seat-blocks": {
+            "get": {
+                "description": "List the seat blocks of a schedule, those of every trip first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Schedule seat blocks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SeatBlock"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Keep specific seats, or a number of seats, off sale on every trip of a schedule, optionally until some minutes before departure",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Block schedule seats",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Seats to block",
+                        "name": "block",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SeatBlockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SeatBlock"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/seat-blocks/{block_id}": {
+            "delete": {
+                "description": "Put the seats of a block of the schedule, or of one of its trips, back on sale",
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Release schedule seat block",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seat block ID",
+                        "name": "block_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tickets/public-key": {
+            "get": {
+                "description": "Get the Ed25519 public key (base64) that signs ticket QR codes. A ticket payload is \"\u003ckey_id\u003e.\u003cclaims\u003e.\u003csignature\u003e\", with the JSON claims and their signature in unpadded base64url",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Ticket verification key",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TicketPublicKey"
+                        }
+                    }
+                }
+            }
+        },
+        "/travels/seats": {
+            "get": {
+                "description": "Get list of available seats for booking",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "travels"
+                ],
+                "summary": "Get available seats for a specific schedule and date",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "schedule_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Travel date (YYYY-MM-DD)",
+                        "name": "travel_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Boarding stop sequence (defaults to the first stop)",
+                        "name": "origin_stop_sequence",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Alighting stop sequence (defaults to the last stop)",
+                        "name": "destination_stop_sequence",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Seat"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips": {
+            "post": {
+                "description": "Get or create the trip of a schedule on a travel date so it can be managed by operators, optionally with another vehicle than the schedule's. A new trip is refused when its vehicle is in maintenance or already assigned to an overlapping departure",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Materialise a trip",
+                "parameters": [
+                    {
+                        "description": "Schedule and travel date",
+                        "name": "trip",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateTripRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Trip"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}": {
+            "get": {
+                "description": "Get trip information, including its status and delay",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Get trip by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Trip"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/alternative-vehicles": {
+            "get": {
+                "description": "List the active vehicles of the trip's company with compatible seats that are free and out of maintenance during the trip",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Alternative vehicles for a trip",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Vehicle"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/boardings": {
+            "get": {
+                "description": "List the tickets scanned for a trip with their outcome, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "boarding"
+                ],
+                "summary": "Trip boarding scans",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BoardingScan"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Validate a ticket against a trip and board its seat. Boarded scans return 201, already boarded seats 409 and rejected tickets 422, with the reason (invalid_ticket, ticket_not_yet_valid, ticket_expired, trip_cancelled, wrong_trip, ticket_not_found, booking_cancelled, already_boarded)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "boarding"
+                ],
+                "summary": "Scan a ticket",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Scanned ticket",
+                        "name": "scan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BoardingScanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.BoardingScan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.BoardingScan"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.BoardingScan"
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/boardings/sync": {
+            "post": {
+                "description": "Upload tickets scanned offline for a trip. Scans are processed in the order they were made: a passenger boards at their first scan and later scans of the seat are duplicates. Scans already uploaded with the same scan_id are returned unchanged, so uploads can be retried",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "boarding"
+                ],
+                "summary": "Upload offline scans",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Scans",
+                        "name": "scans",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BoardingSyncRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BoardingScan"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/cancel": {
+            "post": {
+                "description": "Cancel a trip, refund and cancel its bookings, offer rebooking and notify the passengers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Cancel a trip",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cancellation reason",
+                        "name": "cancellation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CancelTripRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TripDisruption"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/crew": {
+            "get": {
+                "description": "List the crew assigned to a trip",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Trip crew",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TripCrew"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Assign a driver or attendant of the operator's company to a trip. Refused when the driver's licence expires before the trip, when the crew member has an overlapping duty, or when a driver would exceed the maximum driving time within 24 hours",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Assign crew to a trip",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Crew member and duty",
+                        "name": "crew",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TripCrewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.TripCrew"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/crew/{crew_member_id}": {
+            "delete": {
+                "description": "Remove a crew member's duty on a trip",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Remove crew from a trip",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Crew member ID",
+                        "name": "crew_member_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/delay": {
+            "post": {
+                "description": "Record a delay on a trip and notify the passengers. Long delays entitle the bookings to a full refund on cancellation or a free exchange",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Delay a trip",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Delay in minutes and reason",
+                        "name": "delay",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DelayTripRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TripDisruption"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/manifest": {
+            "get": {
+                "description": "List the passengers of a trip in seat order with their document, boarding and alighting stops and status. Cancelled bookings are left out. Available as JSON, CSV or printable PDF",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Trip passenger manifest",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default), csv or pdf",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Manifest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/positions": {
+            "get": {
+                "description": "List the positions reported for a trip, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Trip position history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only positions recorded from this time (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.VehiclePosition"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Record where the vehicle running a trip is, for the GTFS-Realtime vehicle positions feed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Report a trip position",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Position",
+                        "name": "position",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TripPositionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.VehiclePosition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/seat-blocks": {
+            "get": {
+                "description": "List the seat blocks applying to a trip, including those of every trip of its schedule",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Trip seat blocks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SeatBlock"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Keep specific seats, or a number of seats, of a trip off sale, optionally until some minutes before departure",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Block trip seats",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Seats to block",
+                        "name": "block",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SeatBlockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SeatBlock"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/seat-blocks/{block_id}": {
+            "delete": {
+                "description": "Put the seats of a block of the trip back on sale",
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Release trip seat block",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seat block ID",
+                        "name": "block_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/vehicle": {
+            "put": {
+                "description": "Move a trip to another vehicle of the operator's company, e.g. after a breakdown. Booked seats move to the seat with the same number, else one of the same type, else any free seat, and passengers whose seat changed are notified. Seats held for groups, seat blocks and waitlist offers move the same way and are listed under holds. Refused when the vehicle is in maintenance or already assigned to an overlapping departure, when a group would lose a seat, and, unless allow_unmatched is set, when some passengers or holds would get no seat; the 409 response then lists them under swap.unmatched and swap.unmatched_holds. With allow_unmatched, blocks of the trip that find no seat are lifted and waitlist offers withdrawn",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Swap a trip's vehicle",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Vehicle",
+                        "name": "vehicle",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReassignTripRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.VehicleSwap"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{id}/waitlist": {
+            "get": {
+                "description": "List the waitlist of a trip of the operator's company in the order passengers joined",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Trip waitlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trip ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WaitlistEntry"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Get list of all users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get all users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new passenger account. Roles are granted by administrators",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "User data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/search": {
+            "get": {
+                "description": "Search users by name or email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Search users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Get user information by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update user information. The role and company are kept; administrators change them through the role endpoint",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a user by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/role": {
+            "put": {
+                "description": "Make a user a passenger, an operator of a company or an administrator. Administrators only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set a user's role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role and company",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UserRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/conflicts": {
+            "get": {
+                "description": "Report the vehicles of the operator's company, or of company_id for admins, assigned to overlapping departures, and the schedules using a vehicle of another company. Defaults to the next 7 days",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicles"
+                ],
+                "summary": "Vehicle assignment conflicts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Company ID (admins)",
+                        "name": "company_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.AssignmentConflict"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}/devices": {
+            "get": {
+                "description": "List the devices registered for a vehicle",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicles"
+                ],
+                "summary": "List vehicle devices",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.VehicleDevice"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register an on-board device that reports the vehicle's positions. The returned token authenticates the device and is only shown once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicles"
+                ],
+                "summary": "Register a vehicle device",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Device name",
+                        "name": "device",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.VehicleDevice"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.VehicleDeviceRegistration"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}/devices/{device_id}": {
+            "delete": {
+                "description": "Deactivate a device so its token is no longer accepted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicles"
+                ],
+                "summary": "Revoke a vehicle device",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}/maintenance": {
+            "get": {
+                "description": "List the maintenance windows of a vehicle. Defaults to the next 7 days",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicles"
+                ],
+                "summary": "List vehicle maintenance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MaintenanceWindow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Block a vehicle for maintenance. Trips can no longer be assigned to it during the window; the departures already planned in it are returned as warnings with alternative vehicles of the same company and compatible seats",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicles"
+                ],
+                "summary": "Schedule vehicle maintenance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Start, end (RFC 3339) and reason",
+                        "name": "window",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceWindow"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MaintenanceSchedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}/maintenance/warnings": {
+            "get": {
+                "description": "List the departures of a vehicle that fall inside its maintenance windows, with alternative vehicles for each. Defaults to the next 7 days",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicles"
+                ],
+                "summary": "Departures in maintenance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.MaintenanceWarning"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}/maintenance/{window_id}": {
+            "delete": {
+                "description": "Remove a maintenance window so the vehicle can be assigned again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicles"
+                ],
+                "summary": "Cancel vehicle maintenance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maintenance window ID",
+                        "name": "window_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}/timeline": {
+            "get": {
+                "description": "List the departures a vehicle is assigned to: its trips and the upcoming departures of its schedules. Defaults to the next 7 days",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicles"
+                ],
+                "summary": "Vehicle timeline",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Assignment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/waitlist": {
+            "get": {
+                "description": "List the waitlist entries of the authenticated user, newest first, with the number of passengers waiting ahead",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waitlist"
+                ],
+                "summary": "List my waitlist entries",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.WaitlistPosition"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Wait for a seat, optionally of a given type, on a departure with none free. Seats freed by cancellations or lapsed offers are offered in the order passengers joined, through a notification with a time-limited claim token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waitlist"
+                ],
+                "summary": "Join the waitlist of a departure",
+                "parameters": [
+                    {
+                        "description": "Departure and passenger",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.JoinWaitlistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.WaitlistPosition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/waitlist/claim": {
+            "post": {
+                "description": "Book the seat offered to the authenticated user, while the offer lasts, by posting the claim token of the waitlist notification",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waitlist"
+                ],
+                "summary": "Claim a waitlist seat",
+                "parameters": [
+                    {
+                        "description": "Claim token and payment",
+                        "name": "claim",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ClaimWaitlistOfferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Booking"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/waitlist/{id}": {
+            "delete": {
+                "description": "Take an entry of the authenticated user off the waitlist. A seat offered to it goes to the next passenger",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waitlist"
+                ],
+                "summary": "Leave a waitlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Waitlist entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WaitlistEntry"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/yield-rules": {
+            "get": {
+                "description": "List the yield rules of a route of the operator's company in evaluation order, including inactive ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "yield-rules"
+                ],
+                "summary": "List yield rules of a route",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Route ID",
+                        "name": "route_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.YieldRule"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a price band to a route of the operator's company. Seats sold while the segment's load factor and the days left before departure fall within the band cost price_percent of the segment fare. Rules are evaluated by priority and the first matching one applies, within the route's min_yield_percent and max_yield_percent caps. The price is locked into each booking when it is made",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "yield-rules"
+                ],
+                "summary": "Create a yield rule",
+                "parameters": [
+                    {
+                        "description": "Yield rule data",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.YieldRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.YieldRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/yield-rules/simulate": {
+            "post": {
+                "description": "Preview the price of a whole-route seat of a route of the operator's company at each combination of load factor and days before departure, under the route's active rules and caps or under the rules and caps given. Nothing is stored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "yield-rules"
+                ],
+                "summary": "Simulate yield rules",
+                "parameters": [
+                    {
+                        "description": "Rules and scenarios to preview",
+                        "name": "simulation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.YieldSimulationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.YieldSimulationResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/yield-rules/{id}": {
+            "put": {
+                "description": "Update a yield rule of the operator's company. Bookings already made keep their price",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "yield-rules"
+                ],
+                "summary": "Update a yield rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Yield rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Yield rule data",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.YieldRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.YieldRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a yield rule of the operator's company. Bookings already made keep their price",
+                "tags": [
+                    "yield-rules"
+                ],
+                "summary": "Delete a yield rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Yield rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "gtfs.Change": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "gtfs_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gtfs.FeedEntity": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "trip_update": {
+                    "$ref": "#/definitions/gtfs.TripUpdate"
+                },
+                "vehicle": {
+                    "$ref": "#/definitions/gtfs.VehiclePosition"
+                }
+            }
+        },
+        "gtfs.FeedHeader": {
+            "type": "object",
+            "properties": {
+                "gtfs_realtime_version": {
+                    "type": "string"
+                },
+                "incrementality": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gtfs.FeedMessage": {
+            "type": "object",
+            "properties": {
+                "entity": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gtfs.FeedEntity"
+                    }
+                },
+                "header": {
+                    "$ref": "#/definitions/gtfs.FeedHeader"
+                }
+            }
+        },
+        "gtfs.ImportReport": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gtfs.Change"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "summary": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "gtfs.Position": {
+            "type": "object",
+            "properties": {
+                "bearing": {
+                    "type": "number"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "speed": {
+                    "description": "metres per second",
+                    "type": "number"
+                }
+            }
+        },
+        "gtfs.StopTimeEvent": {
+            "type": "object",
+            "properties": {
+                "delay": {
+                    "description": "seconds",
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gtfs.StopTimeUpdate": {
+            "type": "object",
+            "properties": {
+                "arrival": {
+                    "$ref": "#/definitions/gtfs.StopTimeEvent"
+                },
+                "departure": {
+                    "$ref": "#/definitions/gtfs.StopTimeEvent"
+                },
+                "stop_id": {
+                    "type": "string"
+                },
+                "stop_sequence": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gtfs.TripDescriptor": {
+            "type": "object",
+            "properties": {
+                "route_id": {
+                    "type": "string"
+                },
+                "schedule_relationship": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "trip_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "gtfs.TripUpdate": {
+            "type": "object",
+            "properties": {
+                "delay": {
+                    "description": "seconds",
+                    "type": "integer"
+                },
+                "stop_time_update": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gtfs.StopTimeUpdate"
+                    }
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "trip": {
+                    "$ref": "#/definitions/gtfs.TripDescriptor"
+                },
+                "vehicle": {
+                    "$ref": "#/definitions/gtfs.VehicleDescriptor"
+                }
+            }
+        },
+        "gtfs.VehicleDescriptor": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "license_plate": {
+                    "type": "string"
+                }
+            }
+        },
+        "gtfs.VehiclePosition": {
+            "type": "object",
+            "properties": {
+                "position": {
+                    "$ref": "#/definitions/gtfs.Position"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "trip": {
+                    "$ref": "#/definitions/gtfs.TripDescriptor"
+                },
+                "vehicle": {
+                    "$ref": "#/definitions/gtfs.VehicleDescriptor"
+                }
+            }
+        },
+        "handlers.AddBookingAncillariesRequest": {
+            "type": "object",
+            "required": [
+                "ancillaries"
+            ],
+            "properties": {
+                "ancillaries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AncillaryRequest"
+                    }
+                }
+            }
+        },
+        "handlers.AncillaryRequest": {
+            "type": "object",
+            "required": [
+                "category",
+                "name"
+            ],
+            "properties": {
+                "capacity_per_trip": {
+                    "description": "Units sold per trip at most; unlimited when omitted",
+                    "type": "integer"
+                },
+                "category": {
+                    "description": "extra_bag, oversize_luggage, bicycle, pet or insurance",
+                    "type": "string"
+                },
+                "company_id": {
+                    "description": "admins only",
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "fare_percent": {
+                    "type": "number"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.BoardingScanRequest": {
+            "type": "object",
+            "required": [
+                "payload"
+            ],
+            "properties": {
+                "payload": {
+                    "type": "string"
+                },
+                "scan_id": {
+                    "description": "Generated by the scanner; a scan uploaded twice with the same id is only processed once",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "scanned_at": {
+                    "description": "RFC 3339, defaults to the time of the request",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.BoardingSyncRequest": {
+            "type": "object",
+            "required": [
+                "scans"
+            ],
+            "properties": {
+                "scans": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/handlers.BoardingScanRequest"
+                    }
+                }
+            }
+        },
+        "handlers.CalendarExceptionRequest": {
+            "type": "object",
+            "required": [
+                "exception_date",
+                "exception_type"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "exception_date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "exception_type": {
+                    "description": "added or removed",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CancelTripRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CheckInRequest": {
+            "type": "object",
+            "required": [
+                "document_expiry",
+                "document_type",
+                "nationality"
+            ],
+            "properties": {
+                "document_expiry": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "document_number": {
+                    "description": "Defaults to the document given at booking",
+                    "type": "string"
+                },
+                "document_type": {
+                    "type": "string"
+                },
+                "nationality": {
+                    "description": "ISO 3166-1 alpha-2",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ClaimWaitlistOfferRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "payment_method": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateBookingRequest": {
+            "type": "object",
+            "required": [
+                "passenger_document",
+                "passenger_name",
+                "passenger_phone"
+            ],
+            "properties": {
+                "ancillaries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AncillaryRequest"
+                    }
+                },
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "fare_family_id": {
+                    "type": "integer"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.BookingLeg"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "origin_stop_sequence": {
+                    "description": "Optional boarding and alighting stops; the whole route is booked when omitted",
+                    "type": "integer"
+                },
+                "passenger_document": {
+                    "type": "string"
+                },
+                "passenger_name": {
+                    "type": "string"
+                },
+                "passenger_phone": {
+                    "type": "string"
+                },
+                "payment_method": {
+                    "type": "string"
+                },
+                "return": {
+                    "$ref": "#/definitions/services.BookingLeg"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "seat_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "travel_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateGroupBookingRequest": {
+            "type": "object",
+            "required": [
+                "contact_name",
+                "contact_phone",
+                "group_name",
+                "schedule_id",
+                "seat_count",
+                "travel_date"
+            ],
+            "properties": {
+                "contact_email": {
+                    "type": "string"
+                },
+                "contact_name": {
+                    "type": "string"
+                },
+                "contact_phone": {
+                    "type": "string"
+                },
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "origin_stop_sequence": {
+                    "description": "Optional boarding and alighting stops; the whole route when omitted",
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "seat_count": {
+                    "type": "integer"
+                },
+                "travel_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateTripRequest": {
+            "type": "object",
+            "required": [
+                "schedule_id",
+                "travel_date"
+            ],
+            "properties": {
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "travel_date": {
+                    "type": "string"
+                },
+                "vehicle_id": {
+                    "description": "defaults to the schedule's vehicle",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.CrewMemberRequest": {
+            "type": "object",
+            "required": [
+                "first_name",
+                "last_name",
+                "role"
+            ],
+            "properties": {
+                "company_id": {
+                    "description": "admins only",
+                    "type": "integer"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "licence_expiry": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "licence_number": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "driver or attendant",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "account the crew member uses to see their duties",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.DelayTripRequest": {
+            "type": "object",
+            "required": [
+                "delay_minutes",
+                "reason"
+            ],
+            "properties": {
+                "delay_minutes": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.DeviceBoardingSyncRequest": {
+            "type": "object",
+            "required": [
+                "scans",
+                "trip_id"
+            ],
+            "properties": {
+                "scans": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/handlers.BoardingScanRequest"
+                    }
+                },
+                "trip_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.DevicePosition": {
+            "type": "object",
+            "required": [
+                "latitude",
+                "longitude",
+                "recorded_at"
+            ],
+            "properties": {
+                "bearing": {
+                    "type": "number",
+                    "maximum": 360,
+                    "minimum": 0
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "recorded_at": {
+                    "description": "RFC 3339",
+                    "type": "string"
+                },
+                "speed_kmh": {
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "handlers.DevicePositionsRequest": {
+            "type": "object",
+            "required": [
+                "positions"
+            ],
+            "properties": {
+                "positions": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/handlers.DevicePosition"
+                    }
+                }
+            }
+        },
+        "handlers.ExchangeBookingRequest": {
+            "type": "object",
+            "properties": {
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "fare_family_id": {
+                    "description": "Fare family on the new departure; the booking's family is kept on the same route",
+                    "type": "integer"
+                },
+                "origin_stop_sequence": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "seat_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "travel_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.FareFamilyRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "allow_changes": {
+                    "type": "boolean"
+                },
+                "allow_refunds": {
+                    "type": "boolean"
+                },
+                "cancellation_fee_percent": {
+                    "type": "number"
+                },
+                "change_fee": {
+                    "description": "Fee to move to another departure; the company's change fee when omitted",
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "included_ancillaries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FareFamilyAncillary"
+                    }
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price_percent": {
+                    "description": "Seats cost price_percent of the segment fare plus the surcharge; 100 when omitted",
+                    "type": "number"
+                },
+                "route_id": {
+                    "description": "on creation only",
+                    "type": "integer"
+                },
+                "sort_order": {
+                    "type": "integer"
+                },
+                "surcharge": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.GroupPassengersRequest": {
+            "type": "object",
+            "required": [
+                "passengers"
+            ],
+            "properties": {
+                "passengers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.GroupPassenger"
+                    }
+                }
+            }
+        },
+        "handlers.GroupPaymentRequest": {
+            "type": "object",
+            "required": [
+                "payment_method"
+            ],
+            "properties": {
+                "payment_method": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.JoinWaitlistRequest": {
+            "type": "object",
+            "required": [
+                "passenger_document",
+                "passenger_name",
+                "passenger_phone",
+                "schedule_id",
+                "travel_date"
+            ],
+            "properties": {
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "origin_stop_sequence": {
+                    "description": "Optional boarding and alighting stops; the whole route when omitted",
+                    "type": "integer"
+                },
+                "passenger_document": {
+                    "type": "string"
+                },
+                "passenger_name": {
+                    "type": "string"
+                },
+                "passenger_phone": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "seat_type": {
+                    "description": "Optional seat type; any seat is offered when omitted",
+                    "type": "string"
+                },
+                "travel_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.MaintenanceSchedule": {
+            "type": "object",
+            "properties": {
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.MaintenanceWarning"
+                    }
+                },
+                "window": {
+                    "$ref": "#/definitions/models.MaintenanceWindow"
+                }
+            }
+        },
+        "handlers.QuoteGroupBookingRequest": {
+            "type": "object",
+            "required": [
+                "balance_deadline",
+                "passenger_deadline",
+                "price_per_seat",
+                "quote_expires_at"
+            ],
+            "properties": {
+                "balance_deadline": {
+                    "type": "string"
+                },
+                "deposit_percent": {
+                    "type": "number"
+                },
+                "passenger_deadline": {
+                    "type": "string"
+                },
+                "price_per_seat": {
+                    "type": "number"
+                },
+                "quote_expires_at": {
+                    "type": "string"
+                },
+                "seat_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "handlers.ReassignTripRequest": {
+            "type": "object",
+            "required": [
+                "vehicle_id"
+            ],
+            "properties": {
+                "allow_unmatched": {
+                    "description": "swap even if some passengers get no seat",
+                    "type": "boolean"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.RoundTripSearchResult": {
+            "type": "object",
+            "properties": {
+                "outbound": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.TravelResult"
+                    }
+                },
+                "return": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.TravelResult"
+                    }
+                }
+            }
+        },
+        "handlers.ScheduleRequest": {
+            "type": "object",
+            "required": [
+                "arrival_time",
+                "departure_time",
+                "route_id",
+                "valid_from",
+                "vehicle_id"
+            ],
+            "properties": {
+                "arrival_time": {
+                    "description": "HH:MM:SS",
+                    "type": "string"
+                },
+                "calendar_id": {
+                    "type": "integer"
+                },
+                "days_of_week": {
+                    "description": "ISO days, 1 = Monday",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "departure_time": {
+                    "description": "HH:MM:SS",
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "route_id": {
+                    "type": "integer"
+                },
+                "valid_from": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "valid_until": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.SeatBlockRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "release_minutes_before": {
+                    "type": "integer"
+                },
+                "seat_count": {
+                    "type": "integer"
+                },
+                "seat_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "handlers.TicketPublicKey": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "type": "string"
+                },
+                "key_id": {
+                    "type": "string"
+                },
+                "public_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.TravelResult": {
+            "type": "object",
+            "properties": {
+                "amenities": {
+                    "type": "string"
+                },
+                "arrival_time": {
+                    "description": "Local time at the destination stop",
+                    "type": "string"
+                },
+                "base_price": {
+                    "type": "number"
+                },
+                "brand": {
+                    "type": "string"
+                },
+                "company_email": {
+                    "type": "string"
+                },
+                "company_id": {
+                    "description": "Links route to company",
+                    "type": "integer"
+                },
+                "company_id_full": {
+                    "description": "Company Information",
+                    "type": "integer"
+                },
+                "company_name": {
+                    "type": "string"
+                },
+                "days_of_week": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "departure_time": {
+                    "description": "Local time at the origin stop",
+                    "type": "string"
+                },
+                "destination_city": {
+                    "type": "string"
+                },
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "destination_terminal": {
+                    "type": "string"
+                },
+                "destination_time_zone": {
+                    "type": "string"
+                },
+                "distance_km": {
+                    "type": "integer"
+                },
+                "estimated_duration_minutes": {
+                    "type": "integer"
+                },
+                "fare_families": {
+                    "description": "Fare families sold on the route, priced for the searched stops",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FareOption"
+                    }
+                },
+                "license_plate": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "origin_city": {
+                    "type": "string"
+                },
+                "origin_stop_sequence": {
+                    "type": "integer"
+                },
+                "origin_terminal": {
+                    "type": "string"
+                },
+                "origin_time_zone": {
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Fare between the searched stops",
+                    "type": "number"
+                },
+                "round_trip_discount_percent": {
+                    "description": "Discount on both legs when the return is booked with the same company",
+                    "type": "number"
+                },
+                "route_id": {
+                    "description": "Route Information (origin and destination are the searched stops)",
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "description": "Schedule Information (links to route and vehicle)",
+                    "type": "integer"
+                },
+                "total_seats": {
+                    "type": "integer"
+                },
+                "vehicle_id": {
+                    "description": "Links schedule to vehicle",
+                    "type": "integer"
+                },
+                "vehicle_id_full": {
+                    "description": "Vehicle Information (belongs to company, used by schedule)",
+                    "type": "integer"
+                },
+                "vehicle_type": {
+                    "type": "string"
+                },
+                "yield": {
+                    "description": "Price band applied by the route's yield rules on the travel date",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.YieldQuote"
+                        }
+                    ]
+                }
+            }
+        },
+        "handlers.TripCrewRequest": {
+            "type": "object",
+            "required": [
+                "crew_member_id",
+                "duty"
+            ],
+            "properties": {
+                "crew_member_id": {
+                    "type": "integer"
+                },
+                "duty": {
+                    "description": "driver or attendant",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.TripPositionRequest": {
+            "type": "object",
+            "required": [
+                "latitude",
+                "longitude"
+            ],
+            "properties": {
+                "bearing": {
+                    "type": "number",
+                    "maximum": 360,
+                    "minimum": 0
+                },
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                },
+                "recorded_at": {
+                    "description": "RFC 3339, defaults to now",
+                    "type": "string"
+                },
+                "speed_kmh": {
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "handlers.UpdateBookingRequest": {
+            "type": "object",
+            "required": [
+                "passenger_name",
+                "passenger_phone"
+            ],
+            "properties": {
+                "notes": {
+                    "type": "string"
+                },
+                "passenger_name": {
+                    "type": "string"
+                },
+                "passenger_phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.UserRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "company_id": {
+                    "description": "required for operators",
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "passenger",
+                        "operator",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "handlers.VehicleDeviceRegistration": {
+            "type": "object",
+            "properties": {
+                "device": {
+                    "$ref": "#/definitions/models.VehicleDevice"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.YieldRuleRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "price_percent"
+            ],
+            "properties": {
+                "is_active": {
+                    "type": "boolean"
+                },
+                "max_days_before": {
+                    "type": "integer"
+                },
+                "max_load_percent": {
+                    "type": "number"
+                },
+                "min_days_before": {
+                    "description": "Band of whole days left before departure; unbounded when omitted",
+                    "type": "integer"
+                },
+                "min_load_percent": {
+                    "description": "Band of the share of seats sold on the segment; 0 to 100 when omitted",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price_percent": {
+                    "description": "Share of the segment fare charged",
+                    "type": "number"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "route_id": {
+                    "description": "on creation only",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.YieldSimulationRequest": {
+            "type": "object",
+            "required": [
+                "route_id"
+            ],
+            "properties": {
+                "days_before": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "load_percents": {
+                    "description": "Load factors and days before departure to price; a default grid when omitted",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "max_yield_percent": {
+                    "type": "number"
+                },
+                "min_yield_percent": {
+                    "description": "Caps to preview in place of the route's",
+                    "type": "number"
+                },
+                "route_id": {
+                    "type": "integer"
+                },
+                "rules": {
+                    "description": "Rules to preview in place of the route's active ones",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.YieldRuleRequest"
+                    }
+                }
+            }
+        },
+        "handlers.YieldSimulationResult": {
+            "type": "object",
+            "properties": {
+                "fare": {
+                    "description": "Static whole-route fare",
+                    "type": "number"
+                },
+                "max_yield_percent": {
+                    "type": "number"
+                },
+                "min_yield_percent": {
+                    "type": "number"
+                },
+                "quotes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.YieldQuote"
+                    }
+                },
+                "route_id": {
+                    "type": "integer"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.YieldRule"
+                    }
+                }
+            }
+        },
+        "models.Ancillary": {
+            "type": "object",
+            "properties": {
+                "capacity_per_trip": {
+                    "description": "Units that can be sold on a trip at any point of the route; unlimited when nil",
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "company_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "fare_percent": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Price per unit and leg, to which a share of the seat fare is added",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AncillaryOffer": {
+            "type": "object",
+            "properties": {
+                "capacity_per_trip": {
+                    "description": "Units that can be sold on a trip at any point of the route; unlimited when nil",
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "company_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "fare_percent": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Price per unit and leg, to which a share of the seat fare is added",
+                    "type": "number"
+                },
+                "remaining": {
+                    "description": "Units left on the segment; unlimited when nil",
+                    "type": "integer"
+                },
+                "unit_price": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BoardingScan": {
+            "type": "object",
+            "properties": {
+                "booking_code": {
+                    "type": "string"
+                },
+                "booking_seat_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "passenger_name": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "scan_id": {
+                    "type": "string"
+                },
+                "scanned_at": {
+                    "type": "string"
+                },
+                "scanned_by": {
+                    "type": "integer"
+                },
+                "seat_number": {
+                    "type": "string"
+                },
+                "trip_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BookedSeat": {
+            "type": "object",
+            "properties": {
+                "booking_code": {
+                    "type": "string"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
+                "booking_seat_id": {
+                    "type": "integer"
+                },
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "origin_stop_sequence": {
+                    "type": "integer"
+                },
+                "passenger_name": {
+                    "type": "string"
+                },
+                "seat_id": {
+                    "type": "integer"
+                },
+                "seat_number": {
+                    "type": "string"
+                },
+                "seat_type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Booking": {
+            "type": "object",
+            "properties": {
+                "ancillaries": {
+                    "description": "Ancillaries bought with the booking, only filled in when it is created",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BookingAncillary"
+                    }
+                },
+                "ancillary_amount": {
+                    "type": "number"
+                },
+                "booking_code": {
+                    "type": "string"
+                },
+                "booking_status": {
+                    "type": "string"
+                },
+                "checked_in_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delay_compensation": {
+                    "type": "boolean"
+                },
+                "departure_datetime": {
+                    "type": "string"
+                },
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "discount_amount": {
+                    "type": "number"
+                },
+                "fare_family_id": {
+                    "type": "integer"
+                },
+                "fare_rules": {
+                    "$ref": "#/definitions/models.FareRules"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "journey_id": {
+                    "type": "integer"
+                },
+                "leg_sequence": {
+                    "type": "integer"
+                },
+                "no_show_fee": {
+                    "type": "number"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "origin_stop_sequence": {
+                    "type": "integer"
+                },
+                "passenger_document": {
+                    "type": "string"
+                },
+                "passenger_document_expiry": {
+                    "type": "string"
+                },
+                "passenger_document_type": {
+                    "type": "string"
+                },
+                "passenger_name": {
+                    "type": "string"
+                },
+                "passenger_nationality": {
+                    "type": "string"
+                },
+                "passenger_phone": {
+                    "type": "string"
+                },
+                "payment_method": {
+                    "type": "string"
+                },
+                "payment_status": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "total_amount": {
+                    "type": "number"
+                },
+                "travel_date": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "yield_percent": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.BookingAncillary": {
+            "type": "object",
+            "properties": {
+                "ancillary_id": {
+                    "type": "integer"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "total_price": {
+                    "type": "number"
+                },
+                "unit_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.BookingExchange": {
+            "type": "object",
+            "properties": {
+                "amount_due": {
+                    "description": "Charged when positive, refunded when negative",
+                    "type": "number"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
+                "change_fee": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "new_fare": {
+                    "type": "number"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "previous_booking": {
+                    "$ref": "#/definitions/models.PreviousBooking"
+                },
+                "previous_fare": {
+                    "type": "number"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "travel_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CalendarException": {
+            "type": "object",
+            "properties": {
+                "calendar_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "exception_date": {
+                    "type": "string"
+                },
+                "exception_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Company": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "change_fee": {
+                    "description": "Fee charged to move a booking to another departure",
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "cuit": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "no_show_fee_percent": {
+                    "description": "Share of the fare kept when a passenger does not show up; the rest is refunded",
+                    "type": "number"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "round_trip_discount_percent": {
+                    "description": "Discount applied to both legs of a round trip operated by the company",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CrewDuty": {
+            "type": "object",
+            "properties": {
+                "arrival_datetime": {
+                    "type": "string"
+                },
+                "delay_minutes": {
+                    "type": "integer"
+                },
+                "departure_datetime": {
+                    "type": "string"
+                },
+                "destination_city": {
+                    "type": "string"
+                },
+                "duty": {
+                    "type": "string"
+                },
+                "license_plate": {
+                    "type": "string"
+                },
+                "origin_city": {
+                    "type": "string"
+                },
+                "route_id": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "travel_date": {
+                    "type": "string"
+                },
+                "trip_id": {
+                    "type": "integer"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CrewMember": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "licence_expiry": {
+                    "type": "string"
+                },
+                "licence_number": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DepartureAttendance": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "destination_city": {
+                    "type": "string"
+                },
+                "no_show_fees": {
+                    "type": "number"
+                },
+                "no_shows": {
+                    "type": "integer"
+                },
+                "origin_city": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "travel_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FareFamily": {
+            "type": "object",
+            "properties": {
+                "allow_changes": {
+                    "type": "boolean"
+                },
+                "allow_refunds": {
+                    "type": "boolean"
+                },
+                "cancellation_fee_percent": {
+                    "description": "Share of the fare kept when the passenger cancels",
+                    "type": "number"
+                },
+                "change_fee": {
+                    "description": "Fee to move to another departure; the company's change fee applies when nil",
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "included_ancillaries": {
+                    "description": "Ancillaries included with each seat",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FareFamilyAncillary"
+                    }
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price_percent": {
+                    "description": "Seats cost this share of the segment fare plus the surcharge",
+                    "type": "number"
+                },
+                "route_id": {
+                    "type": "integer"
+                },
+                "sort_order": {
+                    "type": "integer"
+                },
+                "surcharge": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FareFamilyAncillary": {
+            "type": "object",
+            "properties": {
+                "ancillary_id": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FareOption": {
+            "type": "object",
+            "properties": {
+                "allow_changes": {
+                    "type": "boolean"
+                },
+                "allow_refunds": {
+                    "type": "boolean"
+                },
+                "cancellation_fee_percent": {
+                    "description": "Share of the fare kept when the passenger cancels",
+                    "type": "number"
+                },
+                "change_fee": {
+                    "description": "Fee to move to another departure; the company's change fee applies when nil",
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "included_ancillaries": {
+                    "description": "Ancillaries included with each seat",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FareFamilyAncillary"
+                    }
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "price_percent": {
+                    "description": "Seats cost this share of the segment fare plus the surcharge",
+                    "type": "number"
+                },
+                "route_id": {
+                    "type": "integer"
+                },
+                "sort_order": {
+                    "type": "integer"
+                },
+                "surcharge": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FareRules": {
+            "type": "object",
+            "properties": {
+                "allow_changes": {
+                    "type": "boolean"
+                },
+                "allow_refunds": {
+                    "type": "boolean"
+                },
+                "cancellation_fee_percent": {
+                    "description": "Share of the fare kept when the passenger cancels",
+                    "type": "number"
+                },
+                "change_fee": {
+                    "description": "Fee to move to another departure; the company's change fee applies when nil",
+                    "type": "number"
+                }
+            }
+        },
+        "models.GroupBooking": {
+            "type": "object",
+            "properties": {
+                "amount_paid": {
+                    "type": "number"
+                },
+                "balance_deadline": {
+                    "type": "string"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "confirmed_at": {
+                    "type": "string"
+                },
+                "contact_email": {
+                    "type": "string"
+                },
+                "contact_name": {
+                    "type": "string"
+                },
+                "contact_phone": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "departure_datetime": {
+                    "type": "string"
+                },
+                "deposit_amount": {
+                    "type": "number"
+                },
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "origin_stop_sequence": {
+                    "type": "integer"
+                },
+                "passenger_deadline": {
+                    "type": "string"
+                },
+                "payment_method": {
+                    "type": "string"
+                },
+                "price_per_seat": {
+                    "description": "Set by the operator's quote",
+                    "type": "number"
+                },
+                "quote_expires_at": {
+                    "type": "string"
+                },
+                "quoted_by": {
+                    "type": "integer"
+                },
+                "seat_count": {
+                    "type": "integer"
+                },
+                "seats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GroupBookingSeat"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_amount": {
+                    "type": "number"
+                },
+                "trip_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GroupBookingSeat": {
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "group_booking_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "passenger_document": {
+                    "type": "string"
+                },
+                "passenger_name": {
+                    "type": "string"
+                },
+                "passenger_phone": {
+                    "type": "string"
+                },
+                "seat_id": {
+                    "type": "integer"
+                },
+                "seat_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Itinerary": {
+            "type": "object",
+            "properties": {
+                "arrival_datetime": {
+                    "type": "string"
+                },
+                "departure_datetime": {
+                    "type": "string"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryLeg"
+                    }
+                },
+                "total_duration_minutes": {
+                    "type": "integer"
+                },
+                "total_price": {
+                    "type": "number"
+                },
+                "transfers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ItineraryLeg": {
+            "type": "object",
+            "properties": {
+                "arrival_datetime": {
+                    "type": "string"
+                },
+                "available_seats": {
+                    "type": "integer"
+                },
+                "company_id": {
+                    "type": "integer"
+                },
+                "company_name": {
+                    "type": "string"
+                },
+                "departure_datetime": {
+                    "type": "string"
+                },
+                "destination_city": {
+                    "type": "string"
+                },
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "destination_terminal": {
+                    "type": "string"
+                },
+                "origin_city": {
+                    "type": "string"
+                },
+                "origin_stop_sequence": {
+                    "type": "integer"
+                },
+                "origin_terminal": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "route_id": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "travel_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Journey": {
+            "type": "object",
+            "properties": {
+                "bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Booking"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "journey_code": {
+                    "type": "string"
+                },
+                "journey_type": {
+                    "type": "string"
+                },
+                "total_amount": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MaintenanceWindow": {
+            "type": "object",
+            "required": [
+                "ends_at",
+                "reason",
+                "starts_at"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ManifestEntry": {
+            "type": "object",
+            "properties": {
+                "alighting_stop": {
+                    "type": "string"
+                },
+                "alighting_stop_sequence": {
+                    "type": "integer"
+                },
+                "ancillaries": {
+                    "type": "string"
+                },
+                "boarded_at": {
+                    "type": "string"
+                },
+                "boarding_stop": {
+                    "type": "string"
+                },
+                "boarding_stop_sequence": {
+                    "type": "integer"
+                },
+                "booking_code": {
+                    "type": "string"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
+                "booking_status": {
+                    "type": "string"
+                },
+                "checked_in_at": {
+                    "type": "string"
+                },
+                "passenger_document": {
+                    "type": "string"
+                },
+                "passenger_name": {
+                    "type": "string"
+                },
+                "passenger_phone": {
+                    "type": "string"
+                },
+                "payment_status": {
+                    "type": "string"
+                },
+                "seat_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Notification": {
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {},
+                "id": {
+                    "type": "integer"
+                },
+                "is_read": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "notification_type": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "group_booking_id": {
+                    "description": "Group deposits and balances are paid before the group's bookings exist, without a booking",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "journey_id": {
+                    "type": "integer"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "payment_gateway": {
+                    "type": "string"
+                },
+                "payment_method": {
+                    "type": "string"
+                },
+                "payment_status": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PreviousBooking": {
+            "type": "object",
+            "properties": {
+                "booking": {
+                    "$ref": "#/definitions/models.Booking"
+                },
+                "seats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BookedSeat"
+                    }
+                }
+            }
+        },
+        "models.Route": {
+            "type": "object",
+            "properties": {
+                "base_price": {
+                    "type": "number"
+                },
+                "company_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "destination_city": {
+                    "type": "string"
+                },
+                "destination_terminal": {
+                    "type": "string"
+                },
+                "distance_km": {
+                    "type": "integer"
+                },
+                "estimated_duration_minutes": {
+                    "type": "integer"
+                },
+                "fares": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RouteFare"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "max_yield_percent": {
+                    "type": "number"
+                },
+                "min_yield_percent": {
+                    "type": "number"
+                },
+                "origin_city": {
+                    "type": "string"
+                },
+                "origin_terminal": {
+                    "type": "string"
+                },
+                "stops": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RouteStop"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RouteFare": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "origin_stop_sequence": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "route_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RouteStop": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "distance_km": {
+                    "type": "integer"
+                },
+                "gtfs_stop_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "offset_minutes": {
+                    "type": "integer"
+                },
+                "route_id": {
+                    "type": "integer"
+                },
+                "stop_sequence": {
+                    "type": "integer"
+                },
+                "terminal": {
+                    "type": "string"
+                },
+                "time_zone": {
+                    "description": "IANA zone, e.g. Europe/Rome",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Schedule": {
+            "type": "object",
+            "properties": {
+                "arrival_time": {
+                    "type": "string"
+                },
+                "calendar_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "days_of_week": {
+                    "description": "ISO days, 1 = Monday",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "departure_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "route_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_until": {
+                    "type": "string"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Seat": {
+            "type": "object",
+            "properties": {
+                "column_position": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_available": {
+                    "type": "boolean"
+                },
+                "price_modifier": {
+                    "type": "number"
+                },
+                "row_number": {
+                    "type": "integer"
+                },
+                "seat_number": {
+                    "type": "string"
+                },
+                "seat_type": {
+                    "type": "string"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SeatBlock": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "release_minutes_before": {
+                    "description": "Minutes before departure when the seats go back on sale; blocked until departure when nil",
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "seat_count": {
+                    "type": "integer"
+                },
+                "seat_id": {
+                    "type": "integer"
+                },
+                "seat_number": {
+                    "type": "string"
+                },
+                "travel_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ServiceCalendar": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "company_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "exceptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CalendarException"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Trip": {
+            "type": "object",
+            "properties": {
+                "arrival_datetime": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delay_minutes": {
+                    "type": "integer"
+                },
+                "departure_datetime": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "status_changed_at": {
+                    "type": "string"
+                },
+                "status_reason": {
+                    "type": "string"
+                },
+                "travel_date": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TripCrew": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "crew_member": {
+                    "$ref": "#/definitions/models.CrewMember"
+                },
+                "crew_member_id": {
+                    "type": "integer"
+                },
+                "duty": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "trip_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "document_number": {
+                    "type": "string"
+                },
+                "document_type": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Vehicle": {
+            "type": "object",
+            "properties": {
+                "amenities": {},
+                "brand": {
+                    "type": "string"
+                },
+                "company_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "license_plate": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "seat_layout": {},
+                "total_seats": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "vehicle_type": {
+                    "type": "string"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.VehicleDevice": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.VehiclePosition": {
+            "type": "object",
+            "properties": {
+                "bearing": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "recorded_at": {
+                    "type": "string"
+                },
+                "speed_kmh": {
+                    "type": "number"
+                },
+                "trip_id": {
+                    "type": "integer"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WaitlistEntry": {
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "offer_expires_at": {
+                    "type": "string"
+                },
+                "offered_at": {
+                    "type": "string"
+                },
+                "offered_seat_id": {
+                    "type": "integer"
+                },
+                "origin_stop_sequence": {
+                    "description": "Segment the passenger wants to travel",
+                    "type": "integer"
+                },
+                "passenger_document": {
+                    "type": "string"
+                },
+                "passenger_name": {
+                    "type": "string"
+                },
+                "passenger_phone": {
+                    "type": "string"
+                },
+                "seat_type": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "trip_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.YieldQuote": {
+            "type": "object",
+            "properties": {
+                "days_before": {
+                    "type": "integer"
+                },
+                "load_percent": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "price_percent": {
+                    "type": "number"
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "rule_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.YieldRule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "max_days_before": {
+                    "type": "integer"
+                },
+                "max_load_percent": {
+                    "type": "number"
+                },
+                "min_days_before": {
+                    "description": "Band of whole days left before departure; nil for no bound",
+                    "type": "integer"
+                },
+                "min_load_percent": {
+                    "description": "Band of the share of seats already sold on the segment",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price_percent": {
+                    "description": "Share of the segment fare charged",
+                    "type": "number"
+                },
+                "priority": {
+                    "description": "Rules are evaluated lowest priority first; the first matching one applies",
+                    "type": "integer"
+                },
+                "route_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.AncillaryPurchase": {
+            "type": "object",
+            "properties": {
+                "ancillaries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BookingAncillary"
+                    }
+                },
+                "booking": {
+                    "$ref": "#/definitions/models.Booking"
+                },
+                "payment": {
+                    "$ref": "#/definitions/models.Payment"
+                }
+            }
+        },
+        "services.AncillaryRequest": {
+            "type": "object",
+            "properties": {
+                "ancillary_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.Assignment": {
+            "type": "object",
+            "properties": {
+                "arrival_datetime": {
+                    "description": "including any delay",
+                    "type": "string"
+                },
+                "departure_datetime": {
+                    "type": "string"
+                },
+                "route_id": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "travel_date": {
+                    "type": "string"
+                },
+                "trip_id": {
+                    "type": "integer"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.AssignmentConflict": {
+            "type": "object",
+            "properties": {
+                "assignment": {
+                    "$ref": "#/definitions/services.Assignment"
+                },
+                "conflicts_with": {
+                    "$ref": "#/definitions/services.Assignment"
+                },
+                "maintenance_window": {
+                    "$ref": "#/definitions/models.MaintenanceWindow"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.AttendanceReport": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "integer"
+                },
+                "completed": {
+                    "type": "integer"
+                },
+                "departures": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DepartureAttendance"
+                    }
+                },
+                "no_show_fees": {
+                    "type": "number"
+                },
+                "no_show_rate": {
+                    "description": "Share of departed bookings that did not show up, in percent",
+                    "type": "number"
+                },
+                "no_shows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.BookingCancellation": {
+            "type": "object",
+            "properties": {
+                "cancellation_fee": {
+                    "description": "Part of the fares kept under the bookings' fare family rules",
+                    "type": "number"
+                },
+                "cancelled_bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Booking"
+                    }
+                },
+                "refund_amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "services.BookingExchangeResult": {
+            "type": "object",
+            "properties": {
+                "booking": {
+                    "$ref": "#/definitions/models.Booking"
+                },
+                "exchange": {
+                    "$ref": "#/definitions/models.BookingExchange"
+                },
+                "payment": {
+                    "$ref": "#/definitions/models.Payment"
+                }
+            }
+        },
+        "services.BookingImpact": {
+            "type": "object",
+            "properties": {
+                "booking_code": {
+                    "type": "string"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
+                "booking_status": {
+                    "type": "string"
+                },
+                "delay_compensation": {
+                    "description": "The passenger may cancel for a full refund or exchange free of charge",
+                    "type": "boolean"
+                },
+                "notification_id": {
+                    "type": "integer"
+                },
+                "payment_status": {
+                    "type": "string"
+                },
+                "rebook_offer": {
+                    "$ref": "#/definitions/services.RebookOffer"
+                },
+                "refund_amount": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.BookingLeg": {
+            "type": "object",
+            "properties": {
+                "ancillaries": {
+                    "description": "Ancillaries bought for the leg, such as luggage or insurance",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AncillaryRequest"
+                    }
+                },
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "fare_family_id": {
+                    "description": "Fare family the seats are sold under; the route's plain fare when omitted",
+                    "type": "integer"
+                },
+                "origin_stop_sequence": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "seat_ids": {
+                    "type": "array",
                     "items": {
                         "type": "integer"
                     }
@@ -1049,27 +9777,74 @@ const docTemplate = `{
                 }
             }
         },
-        "models.Booking": {
+        "services.BookingTracking": {
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "destination": {
+                    "$ref": "#/definitions/services.StopEstimate"
+                },
+                "origin": {
+                    "$ref": "#/definitions/services.StopEstimate"
+                },
+                "position": {
+                    "$ref": "#/definitions/models.VehiclePosition"
+                },
+                "progress": {
+                    "$ref": "#/definitions/services.TripProgress"
+                },
+                "trip_id": {
+                    "type": "integer"
+                },
+                "trip_status": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.CheckInResult": {
+            "type": "object",
+            "properties": {
+                "booking": {
+                    "$ref": "#/definitions/models.Booking"
+                },
+                "tickets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Ticket"
+                    }
+                }
+            }
+        },
+        "services.CheckInStatus": {
             "type": "object",
             "properties": {
-                "booking_code": {
-                    "type": "string"
-                },
-                "booking_status": {
-                    "type": "string"
+                "booking_id": {
+                    "type": "integer"
                 },
-                "created_at": {
+                "checked_in_at": {
                     "type": "string"
                 },
-                "departure_datetime": {
+                "closes_at": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "is_open": {
+                    "type": "boolean"
                 },
-                "notes": {
+                "opens_at": {
                     "type": "string"
-                },
+                }
+            }
+        },
+        "services.GroupPassenger": {
+            "type": "object",
+            "required": [
+                "passenger_document",
+                "passenger_name",
+                "seat_id"
+            ],
+            "properties": {
                 "passenger_document": {
                     "type": "string"
                 },
@@ -1079,169 +9854,373 @@ const docTemplate = `{
                 "passenger_phone": {
                     "type": "string"
                 },
-                "payment_method": {
+                "seat_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.HeldSeatChange": {
+            "type": "object",
+            "properties": {
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "every_trip": {
+                    "description": "Seat blocks applying to every trip of the schedule",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "ID of the group booking seat, seat block or waitlist entry holding the seat",
+                    "type": "integer"
+                },
+                "kind": {
                     "type": "string"
                 },
-                "payment_status": {
+                "match": {
                     "type": "string"
                 },
-                "schedule_id": {
+                "origin_stop_sequence": {
                     "type": "integer"
                 },
-                "total_amount": {
-                    "type": "number"
+                "seat_id": {
+                    "type": "integer"
                 },
-                "travel_date": {
+                "seat_number": {
                     "type": "string"
                 },
-                "updated_at": {
+                "seat_type": {
                     "type": "string"
                 },
-                "user_id": {
+                "to_seat_id": {
                     "type": "integer"
+                },
+                "to_seat_number": {
+                    "type": "string"
                 }
             }
         },
-        "models.Company": {
+        "services.MaintenanceWarning": {
             "type": "object",
             "properties": {
-                "address": {
-                    "type": "string"
+                "alternatives": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Vehicle"
+                    }
                 },
-                "created_at": {
+                "assignment": {
+                    "$ref": "#/definitions/services.Assignment"
+                },
+                "window": {
+                    "$ref": "#/definitions/models.MaintenanceWindow"
+                }
+            }
+        },
+        "services.Manifest": {
+            "type": "object",
+            "properties": {
+                "destination_city": {
                     "type": "string"
                 },
-                "cuit": {
+                "generated_at": {
                     "type": "string"
                 },
-                "email": {
+                "license_plate": {
                     "type": "string"
                 },
-                "icon": {
+                "origin_city": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "passengers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ManifestEntry"
+                    }
                 },
-                "is_active": {
-                    "type": "boolean"
+                "trip": {
+                    "$ref": "#/definitions/models.Trip"
+                }
+            }
+        },
+        "services.RebookOffer": {
+            "type": "object",
+            "properties": {
+                "available_seats": {
+                    "type": "integer"
                 },
-                "name": {
+                "departure_datetime": {
                     "type": "string"
                 },
-                "phone": {
-                    "type": "string"
+                "schedule_id": {
+                    "type": "integer"
                 },
-                "updated_at": {
+                "travel_date": {
                     "type": "string"
                 }
             }
         },
-        "models.Route": {
+        "services.SeatChange": {
             "type": "object",
             "properties": {
-                "base_price": {
-                    "type": "number"
+                "booking_code": {
+                    "type": "string"
                 },
-                "company_id": {
+                "booking_id": {
                     "type": "integer"
                 },
-                "created_at": {
-                    "type": "string"
+                "booking_seat_id": {
+                    "type": "integer"
                 },
-                "destination_city": {
+                "destination_stop_sequence": {
+                    "type": "integer"
+                },
+                "match": {
                     "type": "string"
                 },
-                "destination_terminal": {
+                "notification_id": {
+                    "type": "integer"
+                },
+                "origin_stop_sequence": {
+                    "type": "integer"
+                },
+                "passenger_name": {
                     "type": "string"
                 },
-                "distance_km": {
+                "seat_id": {
                     "type": "integer"
                 },
-                "estimated_duration_minutes": {
+                "seat_number": {
+                    "type": "string"
+                },
+                "seat_type": {
+                    "type": "string"
+                },
+                "to_seat_id": {
                     "type": "integer"
                 },
-                "id": {
+                "to_seat_number": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "integer"
+                }
+            }
+        },
+        "services.StopEstimate": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
                 },
-                "is_active": {
+                "estimated_at": {
+                    "type": "string"
+                },
+                "passed": {
                     "type": "boolean"
                 },
-                "origin_city": {
+                "scheduled_at": {
                     "type": "string"
                 },
-                "origin_terminal": {
-                    "type": "string"
+                "stop_sequence": {
+                    "type": "integer"
                 },
-                "updated_at": {
+                "terminal": {
                     "type": "string"
                 }
             }
         },
-        "models.Seat": {
+        "services.Ticket": {
             "type": "object",
             "properties": {
-                "column_position": {
+                "ancillaries": {
+                    "description": "Ancillaries of the booking, printed on each of its tickets",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BookingAncillary"
+                    }
+                },
+                "arrival_datetime": {
                     "type": "string"
                 },
-                "created_at": {
+                "claims": {
+                    "$ref": "#/definitions/services.TicketClaims"
+                },
+                "departure_datetime": {
                     "type": "string"
                 },
-                "id": {
+                "destination_stop": {
+                    "type": "string"
+                },
+                "origin_stop": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.TicketClaims": {
+            "type": "object",
+            "properties": {
+                "booking_code": {
+                    "type": "string"
+                },
+                "booking_seat_id": {
                     "type": "integer"
                 },
-                "is_available": {
-                    "type": "boolean"
+                "destination_stop_sequence": {
+                    "type": "integer"
                 },
-                "price_modifier": {
-                    "type": "number"
+                "origin_stop_sequence": {
+                    "type": "integer"
                 },
-                "row_number": {
+                "passenger_name": {
+                    "type": "string"
+                },
+                "schedule_id": {
                     "type": "integer"
                 },
                 "seat_number": {
                     "type": "string"
                 },
-                "seat_type": {
+                "travel_date": {
                     "type": "string"
                 },
-                "vehicle_id": {
+                "valid_from": {
+                    "type": "integer"
+                },
+                "valid_until": {
                     "type": "integer"
                 }
             }
         },
-        "models.User": {
+        "services.TripDisruption": {
+            "type": "object",
+            "properties": {
+                "affected_bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.BookingImpact"
+                    }
+                },
+                "trip": {
+                    "$ref": "#/definitions/models.Trip"
+                }
+            }
+        },
+        "services.TripProgress": {
+            "type": "object",
+            "properties": {
+                "delay_minutes": {
+                    "type": "integer"
+                },
+                "distance_to_next_stop_km": {
+                    "type": "number"
+                },
+                "next_stop_sequence": {
+                    "type": "integer"
+                },
+                "stops": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.StopEstimate"
+                    }
+                }
+            }
+        },
+        "services.VehicleSwap": {
+            "type": "object",
+            "properties": {
+                "from_vehicle_id": {
+                    "type": "integer"
+                },
+                "holds": {
+                    "description": "Seats held without a booking, for groups, seat blocks and waitlist offers",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.HeldSeatChange"
+                    }
+                },
+                "seats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.SeatChange"
+                    }
+                },
+                "to_vehicle_id": {
+                    "type": "integer"
+                },
+                "trip": {
+                    "$ref": "#/definitions/models.Trip"
+                },
+                "unmatched": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.SeatChange"
+                    }
+                },
+                "unmatched_holds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.HeldSeatChange"
+                    }
+                }
+            }
+        },
+        "services.WaitlistPosition": {
             "type": "object",
             "properties": {
+                "ahead": {
+                    "type": "integer"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
-                "document_number": {
-                    "type": "string"
+                "destination_stop_sequence": {
+                    "type": "integer"
                 },
-                "document_type": {
-                    "type": "string"
+                "id": {
+                    "type": "integer"
                 },
-                "email": {
+                "offer_expires_at": {
                     "type": "string"
                 },
-                "first_name": {
+                "offered_at": {
                     "type": "string"
                 },
-                "id": {
+                "offered_seat_id": {
                     "type": "integer"
                 },
-                "is_active": {
-                    "type": "boolean"
+                "origin_stop_sequence": {
+                    "description": "Segment the passenger wants to travel",
+                    "type": "integer"
                 },
-                "last_name": {
+                "passenger_document": {
                     "type": "string"
                 },
-                "phone": {
+                "passenger_name": {
+                    "type": "string"
+                },
+                "passenger_phone": {
+                    "type": "string"
+                },
+                "seat_type": {
+                    "type": "string"
+                },
+                "status": {
                     "type": "string"
                 },
+                "trip_id": {
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         }
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// canAccessCompany reports whether the operator in the context may act on the given company.
+// Admins may act on every company. It writes a 403 response when access is denied.
+func canAccessCompany(c *gin.Context, companyID int) bool {
+	if c.GetString("role") == models.RoleAdmin {
+		return true
+	}
+
+	operatorCompanyID, exists := c.Get("company_id")
+	if !exists || operatorCompanyID.(int) != companyID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to manage this company"})
+		return false
+	}
+
+	return true
+}
+
+// resolveCompany returns the company an operator acts for: operators always act for their own company,
+// admins for the requested one. It writes an error response and returns false when none applies.
+func resolveCompany(c *gin.Context, requestedCompanyID int) (int, bool) {
+	if c.GetString("role") == models.RoleAdmin {
+		if requestedCompanyID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "company_id is required"})
+			return 0, false
+		}
+		return requestedCompanyID, true
+	}
+
+	if requestedCompanyID == 0 {
+		requestedCompanyID = c.GetInt("company_id")
+	}
+	if !canAccessCompany(c, requestedCompanyID) {
+		return 0, false
+	}
+	return requestedCompanyID, true
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AncillaryRequest represents the ancillary creation and update request
+type AncillaryRequest struct {
+	CompanyID   int     `json:"company_id"`                  // admins only
+	Category    string  `json:"category" binding:"required"` // extra_bag, oversize_luggage, bicycle, pet or insurance
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	FarePercent float64 `json:"fare_percent"`
+	// Units sold per trip at most; unlimited when omitted
+	CapacityPerTrip *int  `json:"capacity_per_trip"`
+	IsActive        *bool `json:"is_active"`
+}
+
+// AddBookingAncillariesRequest lists the ancillaries to add to a booking
+type AddBookingAncillariesRequest struct {
+	Ancillaries []services.AncillaryRequest `json:"ancillaries" binding:"required"`
+}
+
+// apply copies the request onto an ancillary, writing a 400 response on invalid data
+func (req AncillaryRequest) apply(c *gin.Context, ancillary *models.Ancillary) bool {
+	ancillary.Category = req.Category
+	ancillary.Name = req.Name
+	ancillary.Description = req.Description
+	ancillary.Price = req.Price
+	ancillary.FarePercent = req.FarePercent
+	ancillary.CapacityPerTrip = req.CapacityPerTrip
+	ancillary.IsActive = req.IsActive == nil || *req.IsActive
+
+	if err := services.ValidateAncillary(ancillary); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// CreateAncillary godoc
+// @Summary Create an ancillary
+// @Description Add an extra to a company's catalogue, such as an extra bag, oversize luggage, a bicycle, a pet or travel insurance. Its price per unit and leg is the fixed price plus fare_percent of the seat fare. Operators create ancillaries for their own company; admins must give company_id
+// @Tags ancillaries
+// @Accept json
+// @Produce json
+// @Param ancillary body AncillaryRequest true "Ancillary data"
+// @Success 201 {object} models.Ancillary
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /ancillaries [post]
+func CreateAncillary(c *gin.Context, db *sql.DB) {
+	var req AncillaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	companyID, ok := resolveCompany(c, req.CompanyID)
+	if !ok {
+		return
+	}
+	ancillary := models.Ancillary{CompanyID: companyID}
+	if !req.apply(c, &ancillary) {
+		return
+	}
+
+	if err := repository.CreateAncillary(db, &ancillary); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ancillary)
+}
+
+// GetAncillaries godoc
+// @Summary List ancillaries
+// @Description List the ancillary catalogue of the operator's company, or of company_id for admins, including inactive ones
+// @Tags ancillaries
+// @Produce json
+// @Param company_id query int false "Company ID (admins)"
+// @Success 200 {array} models.Ancillary
+// @Failure 403 {object} map[string]string
+// @Router /ancillaries [get]
+func GetAncillaries(c *gin.Context, db *sql.DB) {
+	requestedCompanyID, _ := strconv.Atoi(c.Query("company_id"))
+	companyID, ok := resolveCompany(c, requestedCompanyID)
+	if !ok {
+		return
+	}
+
+	ancillaries, err := repository.GetAncillariesByCompanyID(db, companyID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ancillaries)
+}
+
+// UpdateAncillary godoc
+// @Summary Update an ancillary
+// @Description Update an ancillary of the operator's company. New prices apply to later sales; set is_active to false to stop selling it
+// @Tags ancillaries
+// @Accept json
+// @Produce json
+// @Param id path int true "Ancillary ID"
+// @Param ancillary body AncillaryRequest true "Ancillary data"
+// @Success 200 {object} models.Ancillary
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /ancillaries/{id} [put]
+func UpdateAncillary(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ancillary ID"})
+		return
+	}
+
+	var req AncillaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ancillary, err := repository.GetAncillaryByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ancillary not found"})
+		return
+	}
+	if !canAccessCompany(c, ancillary.CompanyID) {
+		return
+	}
+	if !req.apply(c, ancillary) {
+		return
+	}
+
+	if err := repository.UpdateAncillary(db, ancillary); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ancillary)
+}
+
+// GetDepartureAncillaries godoc
+// @Summary Ancillaries on a departure
+// @Description List the ancillaries sold on a departure of a schedule, priced for the segment, with the units left on it when their capacity is limited
+// @Tags ancillaries
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Param travel_date query string true "Travel date (YYYY-MM-DD)"
+// @Param origin_stop_sequence query int false "Boarding stop sequence (defaults to the first stop)"
+// @Param destination_stop_sequence query int false "Alighting stop sequence (defaults to the last stop)"
+// @Success 200 {array} models.AncillaryOffer
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /schedules/{id}/ancillaries [get]
+func GetDepartureAncillaries(c *gin.Context, db *sql.DB) {
+	scheduleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+	travelDate, err := time.Parse("2006-01-02", c.Query("travel_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel date format. Use YYYY-MM-DD"})
+		return
+	}
+	originStopSequence, err := optionalIntQuery(c, "origin_stop_sequence")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid origin_stop_sequence"})
+		return
+	}
+	destinationStopSequence, err := optionalIntQuery(c, "destination_stop_sequence")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid destination_stop_sequence"})
+		return
+	}
+
+	offers, err := services.AncillaryOffers(db, scheduleID, travelDate, originStopSequence, destinationStopSequence)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		case errors.Is(err, services.ErrInvalidSegment):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
+
+// GetBookingAncillaries godoc
+// @Summary Booking ancillaries
+// @Description List the ancillaries of a booking of the authenticated user with the price they were sold for
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {array} models.BookingAncillary
+// @Failure 404 {object} map[string]string
+// @Router /bookings/{id}/ancillaries [get]
+func GetBookingAncillaries(c *gin.Context, db *sql.DB) {
+	booking, ok := loadUserBooking(c, db)
+	if !ok {
+		return
+	}
+
+	ancillaries, err := repository.GetBookingAncillaries(db, booking.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ancillaries)
+}
+
+// AddBookingAncillaries godoc
+// @Summary Add ancillaries to a booking
+// @Description Buy ancillaries for a confirmed, paid booking of the authenticated user before departure. They are charged with the booking's payment method, added to its total and refunded with it
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param ancillaries body AddBookingAncillariesRequest true "Ancillaries to add"
+// @Success 201 {object} services.AncillaryPurchase
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /bookings/{id}/ancillaries [post]
+func AddBookingAncillaries(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	var req AddBookingAncillariesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	purchase, err := services.AddBookingAncillaries(db, id, c.GetInt("user_id"), req.Ancillaries)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		case errors.Is(err, services.ErrBookingCancelled), errors.Is(err, services.ErrBookingDeparted),
+			errors.Is(err, services.ErrAncillariesUnavailable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			respondBookingError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, purchase)
+}
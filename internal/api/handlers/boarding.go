@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BoardingScanRequest is a ticket read by a scanner
+type BoardingScanRequest struct {
+	// Generated by the scanner; a scan uploaded twice with the same id is only processed once
+	ScanID  string `json:"scan_id" binding:"max=64"`
+	Payload string `json:"payload" binding:"required"`
+	// RFC 3339, defaults to the time of the request
+	ScannedAt string `json:"scanned_at"`
+}
+
+// BoardingSyncRequest is a batch of scans made while offline
+type BoardingSyncRequest struct {
+	Scans []BoardingScanRequest `json:"scans" binding:"required,min=1,max=500,dive"`
+}
+
+// DeviceBoardingSyncRequest is a batch of scans made by an on-board device for a trip of its vehicle
+type DeviceBoardingSyncRequest struct {
+	TripID int                   `json:"trip_id" binding:"required"`
+	Scans  []BoardingScanRequest `json:"scans" binding:"required,min=1,max=500,dive"`
+}
+
+// ScanTicket godoc
+// @Summary Scan a ticket
+// @Description Validate a ticket against a trip and board its seat. Boarded scans return 201, already boarded seats 409 and rejected tickets 422, with the reason (invalid_ticket, ticket_not_yet_valid, ticket_expired, trip_cancelled, wrong_trip, ticket_not_found, booking_cancelled, already_boarded)
+// @Tags boarding
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param scan body BoardingScanRequest true "Scanned ticket"
+// @Success 201 {object} models.BoardingScan
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} models.BoardingScan
+// @Failure 422 {object} models.BoardingScan
+// @Router /trips/{id}/boardings [post]
+func ScanTicket(c *gin.Context, db *sql.DB, key *utils.TicketKey) {
+	tripID, ok := loadTripCompany(c, db)
+	if !ok {
+		return
+	}
+
+	var req BoardingScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	scans, ok := parseTicketScans(c, []BoardingScanRequest{req})
+	if !ok {
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	results, err := services.ScanTickets(db, key.PublicKey(), tripID, scans, services.Scanner{UserID: &userID})
+	if err != nil {
+		respondTripError(c, err)
+		return
+	}
+
+	scan := results[0]
+	switch scan.Result {
+	case models.BoardingResultBoarded:
+		c.JSON(http.StatusCreated, scan)
+	case models.BoardingResultDuplicate:
+		c.JSON(http.StatusConflict, scan)
+	default:
+		c.JSON(http.StatusUnprocessableEntity, scan)
+	}
+}
+
+// SyncTripBoardings godoc
+// @Summary Upload offline scans
+// @Description Upload tickets scanned offline for a trip. Scans are processed in the order they were made: a passenger boards at their first scan and later scans of the seat are duplicates. Scans already uploaded with the same scan_id are returned unchanged, so uploads can be retried
+// @Tags boarding
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param scans body BoardingSyncRequest true "Scans"
+// @Success 200 {array} models.BoardingScan
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id}/boardings/sync [post]
+func SyncTripBoardings(c *gin.Context, db *sql.DB, key *utils.TicketKey) {
+	tripID, ok := loadTripCompany(c, db)
+	if !ok {
+		return
+	}
+
+	var req BoardingSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	scans, ok := parseTicketScans(c, req.Scans)
+	if !ok {
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	results, err := services.ScanTickets(db, key.PublicKey(), tripID, scans, services.Scanner{UserID: &userID})
+	if err != nil {
+		respondTripError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// SyncDeviceBoardings godoc
+// @Summary Upload device scans
+// @Description Upload tickets scanned by an on-board device, authenticated with "Authorization: Device <token>", for a trip run by its vehicle. Scans are resolved as in /trips/{id}/boardings/sync
+// @Tags boarding
+// @Accept json
+// @Produce json
+// @Param scans body DeviceBoardingSyncRequest true "Trip and scans"
+// @Success 200 {array} models.BoardingScan
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /devices/boardings/sync [post]
+func SyncDeviceBoardings(c *gin.Context, db *sql.DB, key *utils.TicketKey) {
+	var req DeviceBoardingSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	scans, ok := parseTicketScans(c, req.Scans)
+	if !ok {
+		return
+	}
+
+	trip, err := repository.GetTripByID(db, req.TripID)
+	if err != nil {
+		respondTripError(c, err)
+		return
+	}
+	if trip.VehicleID != c.GetInt("vehicle_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Trip is not run by this vehicle"})
+		return
+	}
+
+	deviceID := c.GetInt("device_id")
+	results, err := services.ScanTickets(db, key.PublicKey(), trip.ID, scans, services.Scanner{DeviceID: &deviceID})
+	if err != nil {
+		respondTripError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetTripBoardings godoc
+// @Summary Trip boarding scans
+// @Description List the tickets scanned for a trip with their outcome, oldest first
+// @Tags boarding
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {array} models.BoardingScan
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id}/boardings [get]
+func GetTripBoardings(c *gin.Context, db *sql.DB) {
+	tripID, ok := loadTripCompany(c, db)
+	if !ok {
+		return
+	}
+
+	scans, err := repository.GetTripBoardingScans(db, tripID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scans)
+}
+
+// parseTicketScans reads the scan times of scan requests. It writes an error response and returns false when one is invalid.
+func parseTicketScans(c *gin.Context, requests []BoardingScanRequest) ([]services.TicketScan, bool) {
+	now := time.Now()
+	scans := make([]services.TicketScan, len(requests))
+	for i, req := range requests {
+		scannedAt := now
+		if req.ScannedAt != "" {
+			var err error
+			if scannedAt, err = time.Parse(time.RFC3339, req.ScannedAt); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scanned_at format. Use RFC 3339"})
+				return nil, false
+			}
+		}
+		scans[i] = services.TicketScan{ScanID: req.ScanID, Payload: req.Payload, ScannedAt: scannedAt}
+	}
+	return scans, true
+}
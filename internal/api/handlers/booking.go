@@ -2,39 +2,51 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
-// CreateBookingRequest represents the booking creation request with seat selection
+// CreateBookingRequest represents the booking creation request with seat selection.
+// A connecting journey is booked by listing its legs instead of a single schedule;
+// a round trip adds a return leg.
 type CreateBookingRequest struct {
-	ScheduleID        int      `json:"schedule_id" binding:"required"`
-	TravelDate        string   `json:"travel_date" binding:"required"`
-	PassengerName     string   `json:"passenger_name" binding:"required"`
-	PassengerDocument string   `json:"passenger_document" binding:"required"`
-	PassengerPhone    string   `json:"passenger_phone" binding:"required"`
-	SeatIDs           []int    `json:"seat_ids" binding:"required,min=1"`
-	PaymentMethod     string   `json:"payment_method"`
-	Notes             string   `json:"notes"`
+	ScheduleID        int    `json:"schedule_id"`
+	TravelDate        string `json:"travel_date"`
+	PassengerName     string `json:"passenger_name" binding:"required"`
+	PassengerDocument string `json:"passenger_document" binding:"required"`
+	PassengerPhone    string `json:"passenger_phone" binding:"required"`
+	SeatIDs           []int  `json:"seat_ids"`
+	// Optional boarding and alighting stops; the whole route is booked when omitted
+	OriginStopSequence      *int                        `json:"origin_stop_sequence"`
+	DestinationStopSequence *int                        `json:"destination_stop_sequence"`
+	FareFamilyID            *int                        `json:"fare_family_id"`
+	Ancillaries             []services.AncillaryRequest `json:"ancillaries"`
+	Legs                    []services.BookingLeg       `json:"legs"`
+	Return                  *services.BookingLeg        `json:"return"`
+	PaymentMethod           string                      `json:"payment_method"`
+	Notes                   string                      `json:"notes"`
 }
 
 // CreateBooking godoc
 // @Summary Create a new booking with seat selection
-// @Description Create a new booking with seat selection and simulated payment
+// @Description Create a new booking with seat selection and simulated payment. When several legs, or a return leg, are given they are booked atomically as one journey with a single payment. Ancillaries such as luggage, bicycles, pets or insurance can be bought with each leg, within the capacity left on the departure. A fare family of the route sets the seat price, change and cancellation rules and included ancillaries. The route's yield rules adjust the seat price to the departure's load factor and the days left, and the price is locked into the booking.
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param booking body CreateBookingRequest true "Booking data with seat selection"
 // @Success 201 {object} models.Booking
+// @Success 201 {object} models.Journey
 // @Failure 400 {object} map[string]string
 // @Failure 409 {object} map[string]string
 // @Router /bookings [post]
-func CreateBooking(c *gin.Context, db *sql.DB) {
+func CreateBooking(c *gin.Context, db *sql.DB, window services.ConnectionWindow) {
 	// Get user ID from context
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
@@ -53,124 +65,68 @@ func CreateBooking(c *gin.Context, db *sql.DB) {
 		return
 	}
 
-	// Parse travel date
-	travelDate, err := time.Parse("2006-01-02", req.TravelDate)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel date format. Use YYYY-MM-DD"})
-		return
-	}
-
-	// Check if seats are available for the selected schedule and date
-	availableSeats, err := repository.GetAvailableSeatsForSchedule(db, req.ScheduleID, req.TravelDate)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check seat availability"})
-		return
-	}
-
-	// Create a map of available seat IDs for quick lookup
-	availableSeatMap := make(map[int]bool)
-	for _, seat := range availableSeats {
-		availableSeatMap[seat.ID] = true
-	}
-
-	// Check if all requested seats are available
-	for _, seatID := range req.SeatIDs {
-		if !availableSeatMap[seatID] {
-			c.JSON(http.StatusConflict, gin.H{"error": "One or more selected seats are not available"})
+	legs := req.Legs
+	if len(legs) == 0 {
+		if req.ScheduleID == 0 || req.TravelDate == "" || len(req.SeatIDs) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "schedule_id, travel_date and seat_ids are required"})
 			return
 		}
+		legs = []services.BookingLeg{{
+			ScheduleID:              req.ScheduleID,
+			TravelDate:              req.TravelDate,
+			OriginStopSequence:      req.OriginStopSequence,
+			DestinationStopSequence: req.DestinationStopSequence,
+			SeatIDs:                 req.SeatIDs,
+			FareFamilyID:            req.FareFamilyID,
+			Ancillaries:             req.Ancillaries,
+		}}
 	}
 
-	// Get schedule details to calculate departure datetime
-	schedule, err := repository.GetScheduleByID(db, req.ScheduleID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get schedule details"})
-		return
-	}
-
-	// Get route details to get the base price
-	route, err := repository.GetRouteByID(db, schedule.RouteID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get route details"})
-		return
-	}
-
-	// Calculate departure datetime by combining travel date with schedule departure time
-	departureTime, err := time.Parse("15:04:05", schedule.DepartureTime)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid schedule departure time"})
-		return
-	}
-
-	departureDateTime := time.Date(
-		travelDate.Year(), travelDate.Month(), travelDate.Day(),
-		departureTime.Hour(), departureTime.Minute(), departureTime.Second(),
-		0, time.UTC,
-	)
-
-	// Calculate total amount (base price * number of seats)
-	// In a real app, you'd calculate this based on seat types and modifiers
-	totalAmount := route.BasePrice * float64(len(req.SeatIDs))
-
-	// Create booking
-	booking := models.Booking{
+	bookings, journey, err := services.CreateBookings(db, services.NewBooking{
 		UserID:            userID,
-		ScheduleID:        req.ScheduleID,
-		TravelDate:        travelDate,
-		DepartureDatetime: departureDateTime,
+		Legs:              legs,
+		Return:            req.Return,
 		PassengerName:     req.PassengerName,
 		PassengerDocument: req.PassengerDocument,
 		PassengerPhone:    req.PassengerPhone,
-		TotalAmount:       totalAmount,
-		PaymentStatus:     "paid", // Simulate payment success
-		BookingStatus:     "confirmed",
 		PaymentMethod:     req.PaymentMethod,
 		Notes:             req.Notes,
-	}
-
-	// Generate booking code
-	booking.BookingCode = "BK" + strconv.Itoa(userID) + strconv.FormatInt(time.Now().Unix(), 10)
-
-	// Start transaction
-	tx, err := db.Begin()
+	}, window)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		respondBookingError(c, err)
 		return
 	}
-	defer tx.Rollback()
 
-	// Create booking
-	if err := repository.CreateBooking(tx, &booking); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
+	if journey != nil {
+		c.JSON(http.StatusCreated, journey)
 		return
 	}
+	c.JSON(http.StatusCreated, bookings[0])
+}
 
-	// Assign seats to booking
-	for _, seatID := range req.SeatIDs {
-		bookingSeat := models.BookingSeat{
-			BookingID: booking.ID,
-			SeatID:    seatID,
-		}
-
-		if err := repository.CreateBookingSeat(tx, &bookingSeat); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign seat"})
-			return
-		}
-
-		// Mark seat as unavailable
-		if err := repository.UpdateSeatAvailability(tx, seatID, false); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update seat availability"})
-			return
-		}
-	}
-
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
-		return
+func respondBookingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTravelDate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel date format. Use YYYY-MM-DD"})
+	case errors.Is(err, services.ErrDepartureCancelled):
+		c.JSON(http.StatusConflict, gin.H{"error": "This departure has been cancelled"})
+	case errors.Is(err, services.ErrSeatsUnavailable):
+		c.JSON(http.StatusConflict, gin.H{"error": "One or more selected seats are not available"})
+	case errors.Is(err, services.ErrAncillarySoldOut):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidAncillary), errors.Is(err, services.ErrAncillaryNotFound),
+		errors.Is(err, services.ErrFareFamilyNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNoLegs), errors.Is(err, services.ErrInvalidSegment),
+		errors.Is(err, services.ErrInvalidConnection), errors.Is(err, services.ErrInvalidConnectionTime),
+		errors.Is(err, services.ErrRoundTripLegs), errors.Is(err, services.ErrInvalidReturn),
+		errors.Is(err, services.ErrScheduleNotRunning):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
 	}
-
-	c.JSON(http.StatusCreated, booking)
 }
 
 // GetBookings godoc
@@ -205,7 +161,7 @@ func GetBookings(c *gin.Context, db *sql.DB) {
 
 // GetBooking godoc
 // @Summary Get booking by ID
-// @Description Get booking information by ID
+// @Description Get a booking of the authenticated user by ID
 // @Tags bookings
 // @Accept json
 // @Produce json
@@ -214,34 +170,74 @@ func GetBookings(c *gin.Context, db *sql.DB) {
 // @Failure 404 {object} map[string]string
 // @Router /bookings/{id} [get]
 func GetBooking(c *gin.Context, db *sql.DB) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
-		return
-	}
-
-	booking, err := repository.GetBookingByID(db, id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+	booking, ok := loadUserBooking(c, db)
+	if !ok {
 		return
 	}
 
 	c.JSON(http.StatusOK, booking)
 }
 
+// UpdateBookingRequest holds the booking details a passenger may change directly.
+// Departures, segments and seats are changed through an exchange.
+type UpdateBookingRequest struct {
+	PassengerName  string `json:"passenger_name" binding:"required"`
+	PassengerPhone string `json:"passenger_phone" binding:"required"`
+	Notes          string `json:"notes"`
+}
+
 // UpdateBooking godoc
 // @Summary Update booking
-// @Description Update booking information
+// @Description Update the passenger name, phone and notes of a booking of the authenticated user. Use an exchange to change the departure or seats
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param id path int true "Booking ID"
-// @Param booking body models.Booking true "Booking data"
+// @Param booking body UpdateBookingRequest true "Booking details"
 // @Success 200 {object} models.Booking
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /bookings/{id} [put]
 func UpdateBooking(c *gin.Context, db *sql.DB) {
+	booking, ok := loadUserBooking(c, db)
+	if !ok {
+		return
+	}
+
+	var req UpdateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if booking.BookingStatus == "cancelled" {
+		c.JSON(http.StatusConflict, gin.H{"error": services.ErrBookingCancelled.Error()})
+		return
+	}
+
+	booking.PassengerName = req.PassengerName
+	booking.PassengerPhone = req.PassengerPhone
+	booking.Notes = req.Notes
+	if err := repository.UpdateBookingDetails(db, booking); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+// CancelBooking godoc
+// @Summary Cancel booking
+// @Description Cancel a booking of the authenticated user and refund it. Freed seats are offered to the departure's waitlist. Connecting journeys are cancelled as a whole; cancelling the outbound leg of a round trip cancels the return too, while cancelling only the return refunds it minus the round-trip discount. Fare families keep their cancellation fee, or the whole fare when not refundable. Bookings issued for a group follow the group's terms and cannot be cancelled on their own
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} services.BookingCancellation
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /bookings/{id}/cancel [post]
+func CancelBooking(c *gin.Context, db *sql.DB, claimPeriod time.Duration) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -249,43 +245,100 @@ func UpdateBooking(c *gin.Context, db *sql.DB) {
 		return
 	}
 
-	var booking models.Booking
-	if err := c.ShouldBindJSON(&booking); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	cancellation, err := services.CancelBooking(db, id, c.GetInt("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		case errors.Is(err, services.ErrBookingCancelled), errors.Is(err, services.ErrBookingDeparted),
+			errors.Is(err, services.ErrBookingClosed), errors.Is(err, services.ErrGroupMemberBooking):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	offerFreedSeats(db, cancellation.CancelledBookings, claimPeriod)
+	c.JSON(http.StatusOK, cancellation)
+}
+
+// GetBookingTracking godoc
+// @Summary Track a booking's bus
+// @Description Live position of the bus running the authenticated user's booking, with estimated times at its stops and at the booking's destination
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} services.BookingTracking
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /bookings/{id}/tracking [get]
+func GetBookingTracking(c *gin.Context, db *sql.DB) {
+	booking, ok := loadUserBooking(c, db)
+	if !ok {
+		return
+	}
+	if booking.BookingStatus == "cancelled" {
+		c.JSON(http.StatusConflict, gin.H{"error": services.ErrBookingCancelled.Error()})
 		return
 	}
-	booking.ID = id
 
-	if err := repository.UpdateBooking(db, &booking); err != nil {
+	tracking, err := services.TrackBooking(db, booking, time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrTripCancelled) || errors.Is(err, services.ErrTrackingFinished) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, booking)
+	c.JSON(http.StatusOK, tracking)
 }
 
 // DeleteBooking godoc
 // @Summary Delete booking
-// @Description Delete a booking by ID
+// @Description Delete a booking of the authenticated user by ID
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param id path int true "Booking ID"
 // @Success 204
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /bookings/{id} [delete]
 func DeleteBooking(c *gin.Context, db *sql.DB) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+	booking, ok := loadUserBooking(c, db)
+	if !ok {
 		return
 	}
 
-	if err := repository.DeleteBooking(db, id); err != nil {
+	if err := repository.DeleteBooking(db, booking.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
-}
\ No newline at end of file
+}
+
+// loadUserBooking returns the booking of the request path if it belongs to the authenticated user.
+// It writes an error response and returns false otherwise.
+func loadUserBooking(c *gin.Context, db *sql.DB) (*models.Booking, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return nil, false
+	}
+
+	booking, err := repository.GetBookingByID(db, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && booking.UserID != c.GetInt("user_id")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return booking, true
+}
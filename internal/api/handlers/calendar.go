@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarExceptionRequest represents a date with added or removed service
+type CalendarExceptionRequest struct {
+	ExceptionDate string `json:"exception_date" binding:"required"` // YYYY-MM-DD
+	ExceptionType string `json:"exception_type" binding:"required"` // added or removed
+	Description   string `json:"description"`
+}
+
+// CreateCalendar godoc
+// @Summary Create a service calendar
+// @Description Create a reusable operating calendar for a company. Operators create calendars for their own company; admins must give company_id
+// @Tags calendars
+// @Accept json
+// @Produce json
+// @Param calendar body models.ServiceCalendar true "Calendar data"
+// @Success 201 {object} models.ServiceCalendar
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /calendars [post]
+func CreateCalendar(c *gin.Context, db *sql.DB) {
+	var calendar models.ServiceCalendar
+	if err := c.ShouldBindJSON(&calendar); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	companyID, ok := resolveCompany(c, calendar.CompanyID)
+	if !ok {
+		return
+	}
+	calendar.CompanyID = companyID
+
+	if err := repository.CreateCalendar(db, &calendar); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	calendar.Exceptions = []models.CalendarException{}
+
+	c.JSON(http.StatusCreated, calendar)
+}
+
+// GetCalendars godoc
+// @Summary List service calendars
+// @Description List the calendars of the operator's company, or of company_id for admins
+// @Tags calendars
+// @Accept json
+// @Produce json
+// @Param company_id query int false "Company ID (admins)"
+// @Success 200 {array} models.ServiceCalendar
+// @Failure 403 {object} map[string]string
+// @Router /calendars [get]
+func GetCalendars(c *gin.Context, db *sql.DB) {
+	requestedCompanyID, _ := strconv.Atoi(c.Query("company_id"))
+	companyID, ok := resolveCompany(c, requestedCompanyID)
+	if !ok {
+		return
+	}
+
+	calendars, err := repository.GetCalendarsByCompanyID(db, companyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendars)
+}
+
+// GetCalendar godoc
+// @Summary Get service calendar
+// @Description Get a calendar with its exception dates
+// @Tags calendars
+// @Accept json
+// @Produce json
+// @Param id path int true "Calendar ID"
+// @Success 200 {object} models.ServiceCalendar
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /calendars/{id} [get]
+func GetCalendar(c *gin.Context, db *sql.DB) {
+	calendar, ok := loadCalendar(c, db)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// UpdateCalendar godoc
+// @Summary Update service calendar
+// @Description Rename a calendar
+// @Tags calendars
+// @Accept json
+// @Produce json
+// @Param id path int true "Calendar ID"
+// @Param calendar body models.ServiceCalendar true "Calendar data"
+// @Success 200 {object} models.ServiceCalendar
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /calendars/{id} [put]
+func UpdateCalendar(c *gin.Context, db *sql.DB) {
+	calendar, ok := loadCalendar(c, db)
+	if !ok {
+		return
+	}
+
+	var req models.ServiceCalendar
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	calendar.Name = req.Name
+
+	if err := repository.UpdateCalendar(db, calendar); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// DeleteCalendar godoc
+// @Summary Delete service calendar
+// @Description Delete a calendar; schedules following it keep running on their days of week
+// @Tags calendars
+// @Accept json
+// @Produce json
+// @Param id path int true "Calendar ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /calendars/{id} [delete]
+func DeleteCalendar(c *gin.Context, db *sql.DB) {
+	calendar, ok := loadCalendar(c, db)
+	if !ok {
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := repository.DeleteCalendar(tx, calendar.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// AddCalendarException godoc
+// @Summary Add a calendar exception
+// @Description Add or remove service on a date (e.g. no service on 25 December, extra departures on Ferragosto). An existing exception on the same date is replaced
+// @Tags calendars
+// @Accept json
+// @Produce json
+// @Param id path int true "Calendar ID"
+// @Param exception body CalendarExceptionRequest true "Exception date and type"
+// @Success 201 {object} models.CalendarException
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /calendars/{id}/exceptions [post]
+func AddCalendarException(c *gin.Context, db *sql.DB) {
+	calendar, ok := loadCalendar(c, db)
+	if !ok {
+		return
+	}
+
+	var req CalendarExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exceptionDate, err := time.Parse("2006-01-02", req.ExceptionDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exception date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	exception := models.CalendarException{
+		CalendarID:    calendar.ID,
+		ExceptionDate: exceptionDate,
+		ExceptionType: req.ExceptionType,
+		Description:   req.Description,
+	}
+	if err := services.AddCalendarException(db, &exception); err != nil {
+		if errors.Is(err, services.ErrInvalidException) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, exception)
+}
+
+// DeleteCalendarException godoc
+// @Summary Delete a calendar exception
+// @Description Remove an exception date from a calendar
+// @Tags calendars
+// @Accept json
+// @Produce json
+// @Param id path int true "Calendar ID"
+// @Param exception_id path int true "Exception ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /calendars/{id}/exceptions/{exception_id} [delete]
+func DeleteCalendarException(c *gin.Context, db *sql.DB) {
+	calendar, ok := loadCalendar(c, db)
+	if !ok {
+		return
+	}
+
+	exceptionID, err := strconv.Atoi(c.Param("exception_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exception ID"})
+		return
+	}
+
+	if err := repository.DeleteCalendarException(db, calendar.ID, exceptionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// loadCalendar loads the calendar of the request path and checks the operator may manage it.
+// It writes an error response and returns false otherwise.
+func loadCalendar(c *gin.Context, db *sql.DB) (*models.ServiceCalendar, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid calendar ID"})
+		return nil, false
+	}
+
+	calendar, err := services.GetCalendarWithExceptions(db, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !canAccessCompany(c, calendar.CompanyID) {
+		return nil, false
+	}
+
+	return calendar, true
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CheckInRequest is the passenger data collected at check-in
+type CheckInRequest struct {
+	DocumentType string `json:"document_type" binding:"required"`
+	// Defaults to the document given at booking
+	DocumentNumber string `json:"document_number"`
+	// YYYY-MM-DD
+	DocumentExpiry string `json:"document_expiry" binding:"required"`
+	// ISO 3166-1 alpha-2
+	Nationality string `json:"nationality" binding:"required"`
+}
+
+// GetCheckIn godoc
+// @Summary Check-in status
+// @Description Get when online check-in opens and closes for a booking of the authenticated user, and whether it is checked in
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} services.CheckInStatus
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /bookings/{id}/check-in [get]
+func GetCheckIn(c *gin.Context, db *sql.DB, window services.CheckInWindow) {
+	booking, ok := loadUserBooking(c, db)
+	if !ok {
+		return
+	}
+
+	status, err := services.BookingCheckInStatus(db, window, booking, time.Now())
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// CheckIn godoc
+// @Summary Check in
+// @Description Check in a confirmed, paid booking of the authenticated user within the check-in window, completing the passenger document and nationality, and get the boarding passes
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param check_in body CheckInRequest true "Passenger document and nationality"
+// @Success 200 {object} services.CheckInResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /bookings/{id}/check-in [post]
+func CheckIn(c *gin.Context, db *sql.DB, key *utils.TicketKey, window services.CheckInWindow) {
+	booking, ok := loadUserBooking(c, db)
+	if !ok {
+		return
+	}
+
+	var req CheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	expiry, err := time.Parse(utils.DateLayout, req.DocumentExpiry)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document_expiry format. Use YYYY-MM-DD"})
+		return
+	}
+
+	details := services.CheckInDetails{
+		DocumentType:   req.DocumentType,
+		DocumentNumber: req.DocumentNumber,
+		DocumentExpiry: expiry,
+		Nationality:    req.Nationality,
+	}
+	result, err := services.CheckIn(db, key, window, booking, details, time.Now())
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func respondCheckInError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPassengerDocument), errors.Is(err, services.ErrDocumentExpired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCheckInClosed), errors.Is(err, services.ErrAlreadyCheckedIn),
+		errors.Is(err, services.ErrBookingCancelled), errors.Is(err, services.ErrTicketsUnavailable),
+		errors.Is(err, services.ErrTripCancelled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
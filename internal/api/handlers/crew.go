@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CrewMemberRequest represents the crew member creation and update request
+type CrewMemberRequest struct {
+	CompanyID     int     `json:"company_id"` // admins only
+	UserID        *int    `json:"user_id"`    // account the crew member uses to see their duties
+	FirstName     string  `json:"first_name" binding:"required"`
+	LastName      string  `json:"last_name" binding:"required"`
+	Role          string  `json:"role" binding:"required"` // driver or attendant
+	LicenceNumber string  `json:"licence_number"`
+	LicenceExpiry *string `json:"licence_expiry"` // YYYY-MM-DD
+	IsActive      *bool   `json:"is_active"`
+}
+
+// TripCrewRequest assigns a crew member to a trip
+type TripCrewRequest struct {
+	CrewMemberID int    `json:"crew_member_id" binding:"required"`
+	Duty         string `json:"duty" binding:"required"` // driver or attendant
+}
+
+// apply copies the request onto a crew member, writing a 400 response on invalid data
+func (req CrewMemberRequest) apply(c *gin.Context, db *sql.DB, member *models.CrewMember) bool {
+	member.UserID = req.UserID
+	member.FirstName = req.FirstName
+	member.LastName = req.LastName
+	member.Role = req.Role
+	member.LicenceNumber = req.LicenceNumber
+	member.IsActive = req.IsActive == nil || *req.IsActive
+	member.LicenceExpiry = nil
+	if req.LicenceExpiry != nil {
+		expiry, err := time.Parse("2006-01-02", *req.LicenceExpiry)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid licence_expiry format. Use YYYY-MM-DD"})
+			return false
+		}
+		member.LicenceExpiry = &expiry
+	}
+
+	if err := services.ValidateCrewMember(member); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if member.UserID != nil {
+		if _, err := repository.GetUserByID(db, *member.UserID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+			return false
+		}
+	}
+	return true
+}
+
+// CreateCrewMember godoc
+// @Summary Create a crew member
+// @Description Add a driver or attendant to a company. Drivers need a licence number and expiry date. Operators create crew for their own company; admins must give company_id
+// @Tags crew
+// @Accept json
+// @Produce json
+// @Param crew body CrewMemberRequest true "Crew member data"
+// @Success 201 {object} models.CrewMember
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /crew [post]
+func CreateCrewMember(c *gin.Context, db *sql.DB) {
+	var req CrewMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	companyID, ok := resolveCompany(c, req.CompanyID)
+	if !ok {
+		return
+	}
+	member := models.CrewMember{CompanyID: companyID}
+	if !req.apply(c, db, &member) {
+		return
+	}
+
+	if err := repository.CreateCrewMember(db, &member); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// GetCrewMembers godoc
+// @Summary List crew members
+// @Description List the crew of the operator's company, or of company_id for admins
+// @Tags crew
+// @Produce json
+// @Param company_id query int false "Company ID (admins)"
+// @Success 200 {array} models.CrewMember
+// @Failure 403 {object} map[string]string
+// @Router /crew [get]
+func GetCrewMembers(c *gin.Context, db *sql.DB) {
+	requestedCompanyID, _ := strconv.Atoi(c.Query("company_id"))
+	companyID, ok := resolveCompany(c, requestedCompanyID)
+	if !ok {
+		return
+	}
+
+	members, err := repository.GetCrewMembersByCompanyID(db, companyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// GetCrewMember godoc
+// @Summary Get crew member
+// @Description Get a crew member of the operator's company
+// @Tags crew
+// @Produce json
+// @Param id path int true "Crew member ID"
+// @Success 200 {object} models.CrewMember
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /crew/{id} [get]
+func GetCrewMember(c *gin.Context, db *sql.DB) {
+	member, ok := loadCrewMember(c, db)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// UpdateCrewMember godoc
+// @Summary Update crew member
+// @Description Update a crew member, e.g. renew a licence or deactivate someone who left. Existing duties are kept
+// @Tags crew
+// @Accept json
+// @Produce json
+// @Param id path int true "Crew member ID"
+// @Param crew body CrewMemberRequest true "Crew member data"
+// @Success 200 {object} models.CrewMember
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /crew/{id} [put]
+func UpdateCrewMember(c *gin.Context, db *sql.DB) {
+	member, ok := loadCrewMember(c, db)
+	if !ok {
+		return
+	}
+
+	var req CrewMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.apply(c, db, member) {
+		return
+	}
+
+	if err := repository.UpdateCrewMember(db, member); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// GetCrewMemberDuties godoc
+// @Summary Crew member duties
+// @Description List the duties of a crew member. Defaults to the next 7 days
+// @Tags crew
+// @Produce json
+// @Param id path int true "Crew member ID"
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date (YYYY-MM-DD)"
+// @Success 200 {array} models.CrewDuty
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /crew/{id}/duties [get]
+func GetCrewMemberDuties(c *gin.Context, db *sql.DB) {
+	member, ok := loadCrewMember(c, db)
+	if !ok {
+		return
+	}
+
+	respondCrewDuties(c, db, member)
+}
+
+// GetMyDuties godoc
+// @Summary My duties
+// @Description List the duties of the authenticated crew member. Defaults to the next 7 days
+// @Tags crew
+// @Produce json
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date (YYYY-MM-DD)"
+// @Success 200 {array} models.CrewDuty
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /crew/me/duties [get]
+func GetMyDuties(c *gin.Context, db *sql.DB) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	member, err := repository.GetCrewMemberByUserID(db, userID.(int))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No crew member is linked to this user"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondCrewDuties(c, db, member)
+}
+
+func respondCrewDuties(c *gin.Context, db *sql.DB, member *models.CrewMember) {
+	from, to, ok := parseAssignmentPeriod(c)
+	if !ok {
+		return
+	}
+
+	duties, err := services.CrewDuties(db, member.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, duties)
+}
+
+// AssignTripCrew godoc
+// @Summary Assign crew to a trip
+// @Description Assign a driver or attendant of the operator's company to a trip. Refused when the driver's licence expires before the trip, when the crew member has an overlapping duty, or when a driver would exceed the maximum driving time within 24 hours
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param crew body TripCrewRequest true "Crew member and duty"
+// @Success 201 {object} models.TripCrew
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /trips/{id}/crew [post]
+func AssignTripCrew(c *gin.Context, db *sql.DB, maxDrivingMinutes int) {
+	tripID, ok := loadTripCompany(c, db)
+	if !ok {
+		return
+	}
+
+	var req TripCrewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := repository.GetCrewMemberByID(db, req.CrewMemberID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Crew member not found"})
+		return
+	}
+
+	assignment, err := services.AssignCrew(db, tripID, req.CrewMemberID, req.Duty, maxDrivingMinutes)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidDuty), errors.Is(err, services.ErrCrewInactive),
+			errors.Is(err, services.ErrCrewCompanyMismatch), errors.Is(err, services.ErrLicenceExpired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTripCancelled), errors.Is(err, services.ErrCrewAssigned),
+			errors.Is(err, services.ErrDutyConflict), errors.Is(err, services.ErrDrivingTimeExceeded):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, assignment)
+}
+
+// GetTripCrew godoc
+// @Summary Trip crew
+// @Description List the crew assigned to a trip
+// @Tags trips
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {array} models.TripCrew
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id}/crew [get]
+func GetTripCrew(c *gin.Context, db *sql.DB) {
+	tripID, ok := loadTripCompany(c, db)
+	if !ok {
+		return
+	}
+
+	crew, err := repository.GetTripCrew(db, tripID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, crew)
+}
+
+// RemoveTripCrew godoc
+// @Summary Remove crew from a trip
+// @Description Remove a crew member's duty on a trip
+// @Tags trips
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param crew_member_id path int true "Crew member ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id}/crew/{crew_member_id} [delete]
+func RemoveTripCrew(c *gin.Context, db *sql.DB) {
+	tripID, ok := loadTripCompany(c, db)
+	if !ok {
+		return
+	}
+
+	crewMemberID, err := strconv.Atoi(c.Param("crew_member_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid crew member ID"})
+		return
+	}
+
+	if err := repository.DeleteTripCrew(db, tripID, crewMemberID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Crew member is not assigned to this trip"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// loadCrewMember loads the crew member of the request path and checks the operator may manage it.
+// It writes an error response and returns false otherwise.
+func loadCrewMember(c *gin.Context, db *sql.DB) (*models.CrewMember, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid crew member ID"})
+		return nil, false
+	}
+
+	member, err := repository.GetCrewMemberByID(db, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Crew member not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !canAccessCompany(c, member.CompanyID) {
+		return nil, false
+	}
+
+	return member, true
+}
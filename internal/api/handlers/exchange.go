@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ExchangeBookingRequest describes the new departure, segment or seats of a booking.
+// Fields left out keep the booking's current values.
+type ExchangeBookingRequest struct {
+	ScheduleID              int    `json:"schedule_id"`
+	TravelDate              string `json:"travel_date"`
+	OriginStopSequence      *int   `json:"origin_stop_sequence"`
+	DestinationStopSequence *int   `json:"destination_stop_sequence"`
+	SeatIDs                 []int  `json:"seat_ids"`
+	// Fare family on the new departure; the booking's family is kept on the same route
+	FareFamilyID *int `json:"fare_family_id"`
+}
+
+// QuoteBookingExchange godoc
+// @Summary Quote a booking exchange
+// @Description Price moving a booking of the authenticated user to another departure, segment or seats: the fare difference plus the change fee of the booking's fare family, or the company's, waived when only the seats change. Seats changed within the same departure and segment keep the yield price locked at booking time. Nothing is reserved
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param exchange body ExchangeBookingRequest true "New departure and seats"
+// @Success 200 {object} services.BookingExchangeResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /bookings/{id}/exchange/quote [post]
+func QuoteBookingExchange(c *gin.Context, db *sql.DB) {
+	exchangeBooking(c, db, false, 0)
+}
+
+// ExchangeBooking godoc
+// @Summary Exchange a booking
+// @Description Move a booking of the authenticated user to another departure, segment or seats. Fare families that do not allow changes can only change seats or segment. The new seats are reserved, the amount due is charged or refunded when negative, and the original booking is kept in the exchange history. Check-in has to be done again. Released seats are offered to the waitlist
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param exchange body ExchangeBookingRequest true "New departure and seats"
+// @Success 200 {object} services.BookingExchangeResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /bookings/{id}/exchange [post]
+func ExchangeBooking(c *gin.Context, db *sql.DB, claimPeriod time.Duration) {
+	exchangeBooking(c, db, true, claimPeriod)
+}
+
+func exchangeBooking(c *gin.Context, db *sql.DB, commit bool, claimPeriod time.Duration) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	var req ExchangeBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := services.ExchangeBooking(db, id, c.GetInt("user_id"), services.BookingLeg{
+		ScheduleID:              req.ScheduleID,
+		TravelDate:              req.TravelDate,
+		OriginStopSequence:      req.OriginStopSequence,
+		DestinationStopSequence: req.DestinationStopSequence,
+		SeatIDs:                 req.SeatIDs,
+		FareFamilyID:            req.FareFamilyID,
+	}, commit)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		case errors.Is(err, services.ErrBookingCancelled), errors.Is(err, services.ErrBookingDeparted),
+			errors.Is(err, services.ErrExchangeUnavailable), errors.Is(err, services.ErrExchangeJourney),
+			errors.Is(err, services.ErrFareChangesNotAllowed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrExchangeRoute), errors.Is(err, services.ErrExchangeSeats):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			respondBookingError(c, err)
+		}
+		return
+	}
+
+	if commit {
+		offerFreedSeats(db, []models.Booking{result.Exchange.PreviousBooking.Booking}, claimPeriod)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetBookingExchanges godoc
+// @Summary Booking exchange history
+// @Description List the exchanges of a booking of the authenticated user, oldest first, each with the booking as it was before
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {array} models.BookingExchange
+// @Failure 404 {object} map[string]string
+// @Router /bookings/{id}/exchanges [get]
+func GetBookingExchanges(c *gin.Context, db *sql.DB) {
+	booking, ok := loadUserBooking(c, db)
+	if !ok {
+		return
+	}
+
+	exchanges, err := repository.GetBookingExchanges(db, booking.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, exchanges)
+}
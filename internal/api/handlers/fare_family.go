@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// FareFamilyRequest represents the fare family creation and update request
+type FareFamilyRequest struct {
+	RouteID     int    `json:"route_id"` // on creation only
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	// Seats cost price_percent of the segment fare plus the surcharge; 100 when omitted
+	PricePercent *float64 `json:"price_percent"`
+	Surcharge    float64  `json:"surcharge"`
+	AllowChanges *bool    `json:"allow_changes"`
+	// Fee to move to another departure; the company's change fee when omitted
+	ChangeFee              *float64                     `json:"change_fee"`
+	AllowRefunds           *bool                        `json:"allow_refunds"`
+	CancellationFeePercent float64                      `json:"cancellation_fee_percent"`
+	SortOrder              int                          `json:"sort_order"`
+	IsActive               *bool                        `json:"is_active"`
+	IncludedAncillaries    []models.FareFamilyAncillary `json:"included_ancillaries"`
+}
+
+// apply copies the request onto a fare family
+func (req FareFamilyRequest) apply(family *models.FareFamily) {
+	family.Name = req.Name
+	family.Description = req.Description
+	family.PricePercent = 100
+	if req.PricePercent != nil {
+		family.PricePercent = *req.PricePercent
+	}
+	family.Surcharge = req.Surcharge
+	family.AllowChanges = req.AllowChanges == nil || *req.AllowChanges
+	family.ChangeFee = req.ChangeFee
+	family.AllowRefunds = req.AllowRefunds == nil || *req.AllowRefunds
+	family.CancellationFeePercent = req.CancellationFeePercent
+	family.SortOrder = req.SortOrder
+	family.IsActive = req.IsActive == nil || *req.IsActive
+	family.IncludedAncillaries = req.IncludedAncillaries
+}
+
+// CreateFareFamily godoc
+// @Summary Create a fare family
+// @Description Add a fare family, such as Basic, Flex or Premium, to a route of the operator's company. Seats cost price_percent of the segment fare plus the surcharge; change and cancellation rules apply to bookings sold under it, and included ancillaries come with each seat
+// @Tags fare-families
+// @Accept json
+// @Produce json
+// @Param family body FareFamilyRequest true "Fare family data"
+// @Success 201 {object} models.FareFamily
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /fare-families [post]
+func CreateFareFamily(c *gin.Context, db *sql.DB) {
+	var req FareFamilyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, err := repository.GetRouteByID(db, req.RouteID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+		return
+	}
+	if !canAccessCompany(c, route.CompanyID) {
+		return
+	}
+
+	family := models.FareFamily{RouteID: route.ID}
+	req.apply(&family)
+	if err := services.SaveFareFamily(db, &family, route.CompanyID); err != nil {
+		respondFareFamilyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, family)
+}
+
+// GetFareFamilies godoc
+// @Summary List fare families of a route
+// @Description List the fare families of a route of the operator's company in display order, including inactive ones
+// @Tags fare-families
+// @Produce json
+// @Param route_id query int true "Route ID"
+// @Success 200 {array} models.FareFamily
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /fare-families [get]
+func GetFareFamilies(c *gin.Context, db *sql.DB) {
+	routeID, _ := strconv.Atoi(c.Query("route_id"))
+	route, err := repository.GetRouteByID(db, routeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+		return
+	}
+	if !canAccessCompany(c, route.CompanyID) {
+		return
+	}
+
+	families, err := services.RouteFareFamilies(db, route.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, families)
+}
+
+// UpdateFareFamily godoc
+// @Summary Update a fare family
+// @Description Update a fare family of the operator's company, replacing its included ancillaries. Bookings already sold keep their price and the change and cancellation rules they were sold with. Set is_active to false to stop selling it
+// @Tags fare-families
+// @Accept json
+// @Produce json
+// @Param id path int true "Fare family ID"
+// @Param family body FareFamilyRequest true "Fare family data"
+// @Success 200 {object} models.FareFamily
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /fare-families/{id} [put]
+func UpdateFareFamily(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fare family ID"})
+		return
+	}
+
+	var req FareFamilyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	family, err := repository.GetFareFamilyByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fare family not found"})
+		return
+	}
+	route, err := repository.GetRouteByID(db, family.RouteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !canAccessCompany(c, route.CompanyID) {
+		return
+	}
+
+	req.apply(family)
+	if err := services.SaveFareFamily(db, family, route.CompanyID); err != nil {
+		respondFareFamilyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, family)
+}
+
+// GetRouteFareFamilies godoc
+// @Summary Fare families of a route
+// @Description List the fare families sold on a route in display order, with their rules and included ancillaries
+// @Tags fare-families
+// @Produce json
+// @Param id path int true "Route ID"
+// @Success 200 {array} models.FareFamily
+// @Failure 400 {object} map[string]string
+// @Router /routes/{id}/fare-families [get]
+func GetRouteFareFamilies(c *gin.Context, db *sql.DB) {
+	routeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid route ID"})
+		return
+	}
+
+	families, err := services.RouteFareFamilies(db, routeID, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, families)
+}
+
+func respondFareFamilyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidFareFamily):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
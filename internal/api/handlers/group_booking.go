@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateGroupBookingRequest represents a group's request for a quote
+type CreateGroupBookingRequest struct {
+	ScheduleID   int    `json:"schedule_id" binding:"required"`
+	TravelDate   string `json:"travel_date" binding:"required"`
+	SeatCount    int    `json:"seat_count" binding:"required"`
+	GroupName    string `json:"group_name" binding:"required"`
+	ContactName  string `json:"contact_name" binding:"required"`
+	ContactPhone string `json:"contact_phone" binding:"required"`
+	ContactEmail string `json:"contact_email"`
+	Notes        string `json:"notes"`
+	// Optional boarding and alighting stops; the whole route when omitted
+	OriginStopSequence      *int `json:"origin_stop_sequence"`
+	DestinationStopSequence *int `json:"destination_stop_sequence"`
+}
+
+// QuoteGroupBookingRequest is the operator's quote for a group
+type QuoteGroupBookingRequest struct {
+	PricePerSeat      float64   `json:"price_per_seat" binding:"required"`
+	DepositPercent    float64   `json:"deposit_percent"`
+	SeatIDs           []int     `json:"seat_ids"`
+	QuoteExpiresAt    time.Time `json:"quote_expires_at" binding:"required"`
+	PassengerDeadline time.Time `json:"passenger_deadline" binding:"required"`
+	BalanceDeadline   time.Time `json:"balance_deadline" binding:"required"`
+}
+
+// GroupPaymentRequest holds the payment method of a group deposit or balance
+type GroupPaymentRequest struct {
+	PaymentMethod string `json:"payment_method" binding:"required"`
+}
+
+// GroupPassengersRequest holds the passengers of a group's seats
+type GroupPassengersRequest struct {
+	Passengers []services.GroupPassenger `json:"passengers" binding:"required,dive"`
+}
+
+// CreateGroupBooking godoc
+// @Summary Request a group booking
+// @Description Ask the operator for a quote on a block of seats for a group of at least 10 passengers
+// @Tags group-bookings
+// @Accept json
+// @Produce json
+// @Param group body CreateGroupBookingRequest true "Departure and group"
+// @Success 201 {object} models.GroupBooking
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /group-bookings [post]
+func CreateGroupBooking(c *gin.Context, db *sql.DB) {
+	var req CreateGroupBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := services.RequestGroupBooking(db, services.GroupBookingRequest{
+		UserID:                  c.GetInt("user_id"),
+		ScheduleID:              req.ScheduleID,
+		TravelDate:              req.TravelDate,
+		OriginStopSequence:      req.OriginStopSequence,
+		DestinationStopSequence: req.DestinationStopSequence,
+		SeatCount:               req.SeatCount,
+		GroupName:               req.GroupName,
+		ContactName:             req.ContactName,
+		ContactPhone:            req.ContactPhone,
+		ContactEmail:            req.ContactEmail,
+		Notes:                   req.Notes,
+	}, time.Now())
+	if err != nil {
+		respondGroupBookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// GetGroupBookings godoc
+// @Summary List my group bookings
+// @Description List the group bookings of the authenticated user, newest first
+// @Tags group-bookings
+// @Produce json
+// @Success 200 {array} models.GroupBooking
+// @Router /group-bookings [get]
+func GetGroupBookings(c *gin.Context, db *sql.DB) {
+	groups, err := repository.GetGroupBookingsByUserID(db, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetGroupBooking godoc
+// @Summary Get a group booking
+// @Description Get a group booking of the authenticated user with its seat block and passengers
+// @Tags group-bookings
+// @Produce json
+// @Param id path int true "Group booking ID"
+// @Success 200 {object} models.GroupBooking
+// @Failure 404 {object} map[string]string
+// @Router /group-bookings/{id} [get]
+func GetGroupBooking(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group booking ID"})
+		return
+	}
+
+	group, err := services.GetGroupBooking(db, id)
+	if err == nil && group.UserID != c.GetInt("user_id") {
+		err = services.ErrGroupBookingNotFound
+	}
+	if err != nil {
+		respondGroupBookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// ConfirmGroupBooking godoc
+// @Summary Confirm a group quote
+// @Description Accept the operator's quote by paying the deposit, before the quote expires
+// @Tags group-bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Group booking ID"
+// @Param payment body GroupPaymentRequest true "Payment"
+// @Success 200 {object} models.GroupBooking
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /group-bookings/{id}/confirm [post]
+func ConfirmGroupBooking(c *gin.Context, db *sql.DB) {
+	groupPayment(c, func(id int, paymentMethod string) (*models.GroupBooking, error) {
+		return services.ConfirmGroupBooking(db, id, c.GetInt("user_id"), paymentMethod, time.Now())
+	})
+}
+
+// PayGroupBalance godoc
+// @Summary Pay a group's balance
+// @Description Pay the rest of a confirmed group's total before the balance deadline. Every seat needs a passenger; a booking is issued for each
+// @Tags group-bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Group booking ID"
+// @Param payment body GroupPaymentRequest true "Payment"
+// @Success 200 {object} models.GroupBooking
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /group-bookings/{id}/balance [post]
+func PayGroupBalance(c *gin.Context, db *sql.DB) {
+	groupPayment(c, func(id int, paymentMethod string) (*models.GroupBooking, error) {
+		return services.PayGroupBalance(db, id, c.GetInt("user_id"), paymentMethod, time.Now())
+	})
+}
+
+func groupPayment(c *gin.Context, pay func(id int, paymentMethod string) (*models.GroupBooking, error)) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group booking ID"})
+		return
+	}
+
+	var req GroupPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := pay(id, req.PaymentMethod)
+	if err != nil {
+		respondGroupBookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// SetGroupPassengers godoc
+// @Summary Supply a group's passengers
+// @Description Set the passenger of seats of a confirmed group's block, before the passenger deadline. Seats still without a passenger at the deadline are released
+// @Tags group-bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Group booking ID"
+// @Param passengers body GroupPassengersRequest true "Passengers by seat"
+// @Success 200 {object} models.GroupBooking
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /group-bookings/{id}/passengers [put]
+func SetGroupPassengers(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group booking ID"})
+		return
+	}
+
+	var req GroupPassengersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := services.SetGroupPassengers(db, id, c.GetInt("user_id"), req.Passengers, time.Now())
+	if err != nil {
+		respondGroupBookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// CancelGroupBooking godoc
+// @Summary Cancel a group booking
+// @Description Withdraw a group booking that is not completed, releasing its seat block. A deposit already paid is not refunded
+// @Tags group-bookings
+// @Produce json
+// @Param id path int true "Group booking ID"
+// @Success 200 {object} models.GroupBooking
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /group-bookings/{id}/cancel [post]
+func CancelGroupBooking(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group booking ID"})
+		return
+	}
+
+	group, err := services.CancelGroupBooking(db, id, c.GetInt("user_id"))
+	if err != nil {
+		respondGroupBookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// GetCompanyGroupBookings godoc
+// @Summary List group requests
+// @Description List the group bookings on the departures of the operator's company, or of company_id for admins, by departure
+// @Tags group-bookings
+// @Produce json
+// @Param company_id query int false "Company ID (admins)"
+// @Param status query string false "Status (requested, quoted, confirmed, completed, declined, expired, cancelled)"
+// @Success 200 {array} models.GroupBooking
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /group-requests [get]
+func GetCompanyGroupBookings(c *gin.Context, db *sql.DB) {
+	requestedCompanyID, _ := strconv.Atoi(c.Query("company_id"))
+	companyID, ok := resolveCompany(c, requestedCompanyID)
+	if !ok {
+		return
+	}
+
+	groups, err := repository.GetCompanyGroupBookings(db, companyID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetCompanyGroupBooking godoc
+// @Summary Get a group request
+// @Description Get a group booking on a departure of the operator's company, with its seat block and passengers
+// @Tags group-bookings
+// @Produce json
+// @Param id path int true "Group booking ID"
+// @Success 200 {object} models.GroupBooking
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /group-requests/{id} [get]
+func GetCompanyGroupBooking(c *gin.Context, db *sql.DB) {
+	id, ok := loadGroupBookingCompany(c, db)
+	if !ok {
+		return
+	}
+
+	group, err := services.GetGroupBooking(db, id)
+	if err != nil {
+		respondGroupBookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// QuoteGroupBooking godoc
+// @Summary Quote a group request
+// @Description Price a group request with a custom price per seat and a deposit, and hold a block of seats, the given ones or the first free ones, until the quote expires. Quoting again replaces the previous quote
+// @Tags group-bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Group booking ID"
+// @Param quote body QuoteGroupBookingRequest true "Quote"
+// @Success 200 {object} models.GroupBooking
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /group-requests/{id}/quote [post]
+func QuoteGroupBooking(c *gin.Context, db *sql.DB) {
+	id, ok := loadGroupBookingCompany(c, db)
+	if !ok {
+		return
+	}
+
+	var req QuoteGroupBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := services.QuoteGroupBooking(db, id, c.GetInt("user_id"), services.GroupQuote{
+		PricePerSeat:      req.PricePerSeat,
+		DepositPercent:    req.DepositPercent,
+		SeatIDs:           req.SeatIDs,
+		QuoteExpiresAt:    req.QuoteExpiresAt,
+		PassengerDeadline: req.PassengerDeadline,
+		BalanceDeadline:   req.BalanceDeadline,
+	}, time.Now())
+	if err != nil {
+		respondGroupBookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// DeclineGroupBooking godoc
+// @Summary Decline a group request
+// @Description Turn down a group request that has not been confirmed, releasing any quoted block
+// @Tags group-bookings
+// @Produce json
+// @Param id path int true "Group booking ID"
+// @Success 200 {object} models.GroupBooking
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /group-requests/{id}/decline [post]
+func DeclineGroupBooking(c *gin.Context, db *sql.DB) {
+	id, ok := loadGroupBookingCompany(c, db)
+	if !ok {
+		return
+	}
+
+	group, err := services.DeclineGroupBooking(db, id)
+	if err != nil {
+		respondGroupBookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// loadGroupBookingCompany returns the group booking id of the request path if the operator may manage its trip.
+// It writes an error response and returns false otherwise.
+func loadGroupBookingCompany(c *gin.Context, db *sql.DB) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group booking ID"})
+		return 0, false
+	}
+
+	group, err := repository.GetGroupBookingByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group booking not found"})
+		return 0, false
+	}
+	companyID, err := repository.GetTripCompanyID(db, group.TripID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	if !canAccessCompany(c, companyID) {
+		return 0, false
+	}
+
+	return id, true
+}
+
+func respondGroupBookingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrGroupBookingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group booking not found"})
+	case errors.Is(err, services.ErrGroupTooSmall), errors.Is(err, services.ErrInvalidGroupQuote),
+		errors.Is(err, services.ErrGroupSeatNotInBlock), errors.Is(err, services.ErrScheduleInactive):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrGroupBookingStatus), errors.Is(err, services.ErrGroupBookingExpired),
+		errors.Is(err, services.ErrGroupPassengersMissing), errors.Is(err, services.ErrBookingDeparted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err)
+	}
+}
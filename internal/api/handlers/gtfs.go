@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/gtfs"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ImportGTFS godoc
+// @Summary Import a GTFS feed
+// @Description Import the routes, trips and services of one agency of a GTFS zip into a company. Entities are matched by GTFS id, so feeds can be imported again; dry_run reports the changes without applying them
+// @Tags gtfs
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "GTFS zip"
+// @Param company_id formData int true "Company ID"
+// @Param agency_id formData string false "Agency of the feed, required when it has several"
+// @Param vehicle_id formData int false "Vehicle of the schedules, defaults to the company's first active vehicle"
+// @Param dry_run formData bool false "Report changes without applying them"
+// @Success 200 {object} gtfs.ImportReport
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/gtfs/import [post]
+func ImportGTFS(c *gin.Context, db *sql.DB) {
+	companyID, err := strconv.Atoi(c.PostForm("company_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id is required"})
+		return
+	}
+	vehicleID, _ := strconv.Atoi(c.PostForm("vehicle_id"))
+	dryRun, _ := strconv.ParseBool(c.PostForm("dry_run"))
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	feed, err := gtfs.ReadFeed(file, header.Size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := gtfs.Import(db, feed, gtfs.ImportOptions{
+		CompanyID: companyID,
+		AgencyID:  c.PostForm("agency_id"),
+		VehicleID: vehicleID,
+		DryRun:    dryRun,
+	})
+	if err != nil {
+		respondGTFSError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportGTFS godoc
+// @Summary Export a GTFS feed
+// @Description Export the active routes and schedules of a company as a GTFS zip. Every stop needs coordinates
+// @Tags gtfs
+// @Produce application/zip
+// @Param company_id query int true "Company ID"
+// @Param agency_url query string true "Agency website, required by GTFS"
+// @Param currency query string false "ISO 4217 currency of route prices; fares are left out without one"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/gtfs/export [get]
+func ExportGTFS(c *gin.Context, db *sql.DB) {
+	companyID, err := strconv.Atoi(c.Query("company_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id is required"})
+		return
+	}
+
+	feed, warnings, err := gtfs.Export(db, companyID, gtfs.ExportOptions{
+		AgencyURL: c.Query("agency_url"),
+		Currency:  c.Query("currency"),
+	})
+	if err != nil {
+		respondGTFSError(c, err)
+		return
+	}
+
+	var archive bytes.Buffer
+	if err := gtfs.WriteFeed(&archive, feed); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(warnings) > 0 {
+		c.Header("X-GTFS-Warnings", strings.Join(warnings, "; "))
+	}
+	c.Header("Content-Disposition", "attachment; filename=gtfs-company-"+strconv.Itoa(companyID)+".zip")
+	c.Data(http.StatusOK, "application/zip", archive.Bytes())
+}
+
+func respondGTFSError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Company or vehicle not found"})
+	case errors.Is(err, gtfs.ErrAgencyRequired), errors.Is(err, gtfs.ErrAgencyNotFound),
+		errors.Is(err, gtfs.ErrNoVehicle), errors.Is(err, gtfs.ErrVehicleCompany),
+		errors.Is(err, gtfs.ErrAgencyURLRequired), errors.Is(err, gtfs.ErrMissingCoordinates),
+		errors.Is(err, services.ErrInvalidStops), errors.Is(err, services.ErrInvalidTimeZone),
+		errors.Is(err, services.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRouteStopsInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GetTripUpdatesFeed godoc
+// @Summary GTFS-Realtime trip updates
+// @Description Delays and cancellations of trips that have not arrived yet, as a GTFS-Realtime protobuf feed. format=json returns the same feed as JSON for debugging
+// @Tags gtfs
+// @Produce application/x-protobuf
+// @Produce json
+// @Param format query string false "json for the debug variant"
+// @Success 200 {object} gtfs.FeedMessage
+// @Router /gtfs-rt/trip-updates [get]
+func GetTripUpdatesFeed(c *gin.Context, db *sql.DB) {
+	feed, err := gtfs.BuildTripUpdates(db, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	respondRealtimeFeed(c, feed)
+}
+
+// GetVehiclePositionsFeed godoc
+// @Summary GTFS-Realtime vehicle positions
+// @Description Latest reported position of each running trip, as a GTFS-Realtime protobuf feed. format=json returns the same feed as JSON for debugging
+// @Tags gtfs
+// @Produce application/x-protobuf
+// @Produce json
+// @Param format query string false "json for the debug variant"
+// @Success 200 {object} gtfs.FeedMessage
+// @Router /gtfs-rt/vehicle-positions [get]
+func GetVehiclePositionsFeed(c *gin.Context, db *sql.DB) {
+	feed, err := gtfs.BuildVehiclePositions(db, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	respondRealtimeFeed(c, feed)
+}
+
+func respondRealtimeFeed(c *gin.Context, feed *gtfs.FeedMessage) {
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, feed)
+		return
+	}
+	c.Data(http.StatusOK, "application/x-protobuf", feed.MarshalProto())
+}
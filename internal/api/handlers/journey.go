@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// maxTransfersLimit bounds the transfers a client may ask for, to keep itinerary search cheap
+const maxTransfersLimit = 2
+
+// SearchItineraries godoc
+// @Summary Search direct and connecting itineraries
+// @Description Combine departures into itineraries from origin to destination, changing at a shared city or terminal. Results are ranked by total duration, then price
+// @Tags travels
+// @Accept json
+// @Produce json
+// @Param origin query string true "Origin city"
+// @Param destination query string true "Destination city"
+// @Param date query string true "Travel date (YYYY-MM-DD)"
+// @Param max_transfers query int false "Maximum number of transfers (default 1, at most 2)"
+// @Param min_connection_minutes query int false "Minimum connection time in minutes"
+// @Param max_connection_minutes query int false "Maximum connection time in minutes"
+// @Param passengers query int false "Number of passengers (default 1)"
+// @Success 200 {array} models.Itinerary
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/travels/itineraries [get]
+func SearchItineraries(c *gin.Context, db *sql.DB, window services.ConnectionWindow) {
+	origin := c.Query("origin")
+	destination := c.Query("destination")
+	if origin == "" || destination == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "origin and destination are required"})
+		return
+	}
+
+	travelDate, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	opts := services.ItineraryOptions{
+		MaxTransfers:  1,
+		MinConnection: window.Min,
+		MaxConnection: window.Max,
+		Passengers:    1,
+	}
+	params := []struct {
+		key     string
+		apply   func(int)
+		minimum int
+	}{
+		{"max_transfers", func(v int) { opts.MaxTransfers = v }, 0},
+		{"min_connection_minutes", func(v int) { opts.MinConnection = time.Duration(v) * time.Minute }, 0},
+		{"max_connection_minutes", func(v int) { opts.MaxConnection = time.Duration(v) * time.Minute }, 0},
+		{"passengers", func(v int) { opts.Passengers = v }, 1},
+	}
+	for _, param := range params {
+		value, err := optionalIntQuery(c, param.key)
+		if err != nil || (value != nil && *value < param.minimum) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param.key})
+			return
+		}
+		if value != nil {
+			param.apply(*value)
+		}
+	}
+	if opts.MaxTransfers > maxTransfersLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_transfers cannot exceed " + strconv.Itoa(maxTransfersLimit)})
+		return
+	}
+	if opts.MinConnection > opts.MaxConnection {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_connection_minutes cannot exceed max_connection_minutes"})
+		return
+	}
+
+	itineraries, err := services.SearchItineraries(db, origin, destination, travelDate, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itineraries)
+}
+
+// GetJourney godoc
+// @Summary Get journey by ID
+// @Description Get a journey of the authenticated user with the bookings of all its legs
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Journey ID"
+// @Success 200 {object} models.Journey
+// @Failure 404 {object} map[string]string
+// @Router /journeys/{id} [get]
+func GetJourney(c *gin.Context, db *sql.DB) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid journey ID"})
+		return
+	}
+
+	journey, err := repository.GetJourneyByID(db, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && journey.UserID != c.GetInt("user_id")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Journey not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	journey.Bookings, err = repository.GetBookingsByJourneyID(db, journey.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, journey)
+}
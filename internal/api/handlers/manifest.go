@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/documents"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTripManifest godoc
+// @Summary Trip passenger manifest
+// @Description List the passengers of a trip in seat order with their document, boarding and alighting stops and status. Cancelled bookings are left out. Available as JSON, CSV or printable PDF
+// @Tags trips
+// @Produce json
+// @Produce text/csv
+// @Produce application/pdf
+// @Param id path int true "Trip ID"
+// @Param format query string false "json (default), csv or pdf"
+// @Success 200 {object} services.Manifest
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id}/manifest [get]
+func GetTripManifest(c *gin.Context, db *sql.DB) {
+	format := c.DefaultQuery("format", "json")
+	var write func(io.Writer, *services.Manifest) error
+	var contentType string
+	switch format {
+	case "json":
+	case "csv":
+		write, contentType = documents.ManifestCSV, "text/csv; charset=utf-8"
+	case "pdf":
+		write, contentType = documents.ManifestPDF, "application/pdf"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format. Use json, csv or pdf"})
+		return
+	}
+
+	tripID, ok := loadTripCompany(c, db)
+	if !ok {
+		return
+	}
+
+	manifest, err := services.TripManifest(db, tripID)
+	if err != nil {
+		respondTripError(c, err)
+		return
+	}
+	if write == nil {
+		c.JSON(http.StatusOK, manifest)
+		return
+	}
+
+	var file bytes.Buffer
+	if err := write(&file, manifest); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=manifest-trip-%d.%s", tripID, format))
+	c.Data(http.StatusOK, contentType, file.Bytes())
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotifications godoc
+// @Summary Get notifications for user
+// @Description Get the notifications of the authenticated user (trip cancellations, delays, ...)
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Notification
+// @Router /notifications [get]
+func GetNotifications(c *gin.Context, db *sql.DB) {
+	userID := c.GetInt("user_id")
+
+	notifications, err := repository.GetNotificationsByUserID(db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkNotificationRead godoc
+// @Summary Mark notification as read
+// @Description Mark a notification of the authenticated user as read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /notifications/{id}/read [put]
+func MarkNotificationRead(c *gin.Context, db *sql.DB) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	if err := repository.MarkNotificationRead(db, id, c.GetInt("user_id")); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
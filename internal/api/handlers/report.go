@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAttendanceReport godoc
+// @Summary Attendance report
+// @Description Count the bookings that travelled and the no-shows of a company's departures, with the no-show fees kept. Operators see their own company; admins pass company_id. Defaults to the last 7 days
+// @Tags reports
+// @Produce json
+// @Param company_id query int false "Company ID (admins)"
+// @Param from query string false "First travel date (YYYY-MM-DD)"
+// @Param to query string false "Last travel date (YYYY-MM-DD)"
+// @Success 200 {object} services.AttendanceReport
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /reports/attendance [get]
+func GetAttendanceReport(c *gin.Context, db *sql.DB) {
+	requestedCompanyID, _ := strconv.Atoi(c.Query("company_id"))
+	companyID, ok := resolveCompany(c, requestedCompanyID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	from, to, ok := parsePeriod(c, time.Date(now.Year(), now.Month(), now.Day()-assignmentPeriodDays, 0, 0, 0, 0, time.UTC))
+	if !ok {
+		return
+	}
+
+	report, err := services.DepartureAttendanceReport(db, companyID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
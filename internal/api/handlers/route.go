@@ -2,17 +2,22 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
 // CreateRoute godoc
 // @Summary Create a new route
-// @Description Create a new route
+// @Description Create a new route. Stops are optional; without them the route goes straight from origin to destination
 // @Tags routes
 // @Accept json
 // @Produce json
@@ -27,8 +32,8 @@ func CreateRoute(c *gin.Context, db *sql.DB) {
 		return
 	}
 
-	if err := repository.CreateRoute(db, &route); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := services.CreateRoute(db, &route); err != nil {
+		respondRouteError(c, err)
 		return
 	}
 
@@ -37,7 +42,7 @@ func CreateRoute(c *gin.Context, db *sql.DB) {
 
 // GetRoute godoc
 // @Summary Get route by ID
-// @Description Get route information by ID
+// @Description Get route information by ID, including its stops and stop-pair fares
 // @Tags routes
 // @Accept json
 // @Produce json
@@ -53,7 +58,7 @@ func GetRoute(c *gin.Context, db *sql.DB) {
 		return
 	}
 
-	route, err := repository.GetRouteByID(db, id)
+	route, err := services.GetRouteWithStops(db, id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
 		return
@@ -82,28 +87,141 @@ func GetAllRoutes(c *gin.Context, db *sql.DB) {
 
 // SearchAvailableTravels godoc
 // @Summary Search available bus travels
-// @Description Search for available bus travels by origin, destination, and date
+// @Description Search for available bus travels by origin, destination, and date. Origin and destination match any pair of stops of a route in travel order. With a date, prices follow the route's yield rules for the seats sold and the days left. With a return date, outbound and return travels are listed together
 // @Tags travels
 // @Accept json
 // @Produce json
 // @Param origin query string false "Origin city"
 // @Param destination query string false "Destination city"
 // @Param date query string false "Travel date (YYYY-MM-DD)"
-// @Success 200 {array} object
+// @Param return_date query string false "Return date (YYYY-MM-DD); requires origin and destination"
+// @Success 200 {array} TravelResult
+// @Success 200 {object} RoundTripSearchResult
+// @Failure 400 {object} map[string]string
 // @Router /api/v1/travels/search [get]
 func SearchAvailableTravels(c *gin.Context, db *sql.DB) {
 	origin := c.Query("origin")
 	destination := c.Query("destination")
 	date := c.Query("date")
+	returnDate := c.Query("return_date")
+
+	if returnDate != "" {
+		if origin == "" || destination == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "origin and destination are required for a round trip"})
+			return
+		}
+		returning, err := time.Parse("2006-01-02", returnDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid return date format. Use YYYY-MM-DD"})
+			return
+		}
+		if outbound, err := time.Parse(utils.DateLayout, date); err == nil && returning.Before(outbound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "return_date cannot be before date"})
+			return
+		}
+	}
+
+	var travelDate *time.Time
+	if date != "" {
+		parsed, err := time.Parse(utils.DateLayout, date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel date format. Use YYYY-MM-DD"})
+			return
+		}
+		travelDate = &parsed
+	}
+
+	results, err := searchTravels(db, origin, destination, travelDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Build query to get routes with schedules - including ALL relationship IDs
+	if returnDate == "" {
+		c.JSON(http.StatusOK, results)
+		return
+	}
+
+	// The return goes the other way round
+	returning, _ := time.Parse(utils.DateLayout, returnDate)
+	returnResults, err := searchTravels(db, destination, origin, &returning)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RoundTripSearchResult{Outbound: results, Return: returnResults})
+}
+
+// TravelResult is a departure found by the travel search, priced between the searched stops
+type TravelResult struct {
+	// Route Information (origin and destination are the searched stops)
+	RouteID                  int     `json:"route_id"`
+	CompanyID                int     `json:"company_id"` // Links route to company
+	OriginStopSequence       int     `json:"origin_stop_sequence"`
+	OriginCity               string  `json:"origin_city"`
+	OriginTerminal           string  `json:"origin_terminal"`
+	OriginTimeZone           string  `json:"origin_time_zone"`
+	DestinationStopSequence  int     `json:"destination_stop_sequence"`
+	DestinationCity          string  `json:"destination_city"`
+	DestinationTerminal      string  `json:"destination_terminal"`
+	DestinationTimeZone      string  `json:"destination_time_zone"`
+	DistanceKm               int     `json:"distance_km"`
+	EstimatedDurationMinutes int     `json:"estimated_duration_minutes"`
+	BasePrice                float64 `json:"base_price"`
+	Price                    float64 `json:"price"` // Fare between the searched stops
+	// Price band applied by the route's yield rules on the travel date
+	Yield *models.YieldQuote `json:"yield,omitempty"`
+	// Fare families sold on the route, priced for the searched stops
+	FareFamilies []models.FareOption `json:"fare_families,omitempty"`
+
+	// Schedule Information (links to route and vehicle)
+	ScheduleID    int     `json:"schedule_id"`
+	VehicleID     int     `json:"vehicle_id"`     // Links schedule to vehicle
+	DepartureTime string  `json:"departure_time"` // Local time at the origin stop
+	ArrivalTime   string  `json:"arrival_time"`   // Local time at the destination stop
+	DaysOfWeek    []int64 `json:"days_of_week"`
+
+	// Company Information
+	CompanyIDFull int    `json:"company_id_full"`
+	CompanyName   string `json:"company_name"`
+	CompanyEmail  string `json:"company_email"`
+	// Discount on both legs when the return is booked with the same company
+	RoundTripDiscountPercent float64 `json:"round_trip_discount_percent"`
+
+	// Vehicle Information (belongs to company, used by schedule)
+	VehicleIDFull int    `json:"vehicle_id_full"`
+	LicensePlate  string `json:"license_plate"`
+	VehicleType   string `json:"vehicle_type"`
+	Brand         string `json:"brand"`
+	Model         string `json:"model"`
+	TotalSeats    int    `json:"total_seats"`
+	Amenities     string `json:"amenities"`
+}
+
+// RoundTripSearchResult lists outbound and return travels of a round-trip search
+type RoundTripSearchResult struct {
+	Outbound []TravelResult `json:"outbound"`
+	Return   []TravelResult `json:"return"`
+}
+
+// searchTravels lists the departures between two cities, each priced for its segment.
+// An empty origin or destination means the first or last stop of the route.
+// With a travel date, only schedules running that day (calendar exceptions included) are listed and
+// local times follow that day's daylight saving time; without one they are computed for today.
+func searchTravels(db *sql.DB, origin, destination string, travelDate *time.Time) ([]TravelResult, error) {
+	// Build query to get routes with schedules - including ALL relationship IDs.
+	// Boarding (o) and alighting (d) stops can be any two stops of the route in travel order.
 	query := `
-		SELECT r.id, r.company_id, r.origin_city, r.origin_terminal, r.destination_city, r.destination_terminal,
-			   r.distance_km, r.estimated_duration_minutes, r.base_price,
-			   s.id as schedule_id, s.vehicle_id, s.departure_time, s.arrival_time, s.days_of_week,
-			   c.id as company_id_full, c.name as company_name, c.email as company_email,
+		SELECT r.id, r.company_id, o.stop_sequence, o.city, COALESCE(o.terminal, ''), o.time_zone, d.stop_sequence, d.city, COALESCE(d.terminal, ''), d.time_zone,
+			   d.distance_km - o.distance_km, d.offset_minutes - o.offset_minutes, r.base_price,
+			   s.id as schedule_id, s.vehicle_id,
+			   s.departure_time, s.days_of_week,
+			   c.id as company_id_full, c.name as company_name, c.email as company_email, c.round_trip_discount_percent,
 			   v.id as vehicle_id, v.license_plate, v.vehicle_type, v.brand, v.model, v.total_seats, v.amenities
 		FROM routes r
+		JOIN route_stops o ON o.route_id = r.id
+		JOIN route_stops d ON d.route_id = r.id AND d.stop_sequence > o.stop_sequence
 		JOIN schedules s ON r.id = s.route_id
 		JOIN companies c ON r.company_id = c.id
 		JOIN vehicles v ON s.vehicle_id = v.id
@@ -115,84 +233,106 @@ func SearchAvailableTravels(c *gin.Context, db *sql.DB) {
 
 	if origin != "" {
 		argCount++
-		query += " AND r.origin_city ILIKE $" + strconv.Itoa(argCount)
+		query += " AND o.city ILIKE $" + strconv.Itoa(argCount)
 		args = append(args, "%"+origin+"%")
+	} else {
+		query += " AND o.stop_sequence = (SELECT MIN(stop_sequence) FROM route_stops WHERE route_id = r.id)"
 	}
 
 	if destination != "" {
 		argCount++
-		query += " AND r.destination_city ILIKE $" + strconv.Itoa(argCount)
+		query += " AND d.city ILIKE $" + strconv.Itoa(argCount)
 		args = append(args, "%"+destination+"%")
+	} else {
+		query += " AND d.stop_sequence = (SELECT MAX(stop_sequence) FROM route_stops WHERE route_id = r.id)"
 	}
 
-	if date != "" {
-		// For simplicity, we'll just return all matching routes
-		// In a real app, you'd check the specific date against the schedule
+	referenceDate := time.Now()
+	if travelDate != nil {
+		referenceDate = *travelDate
+		argCount++
+		query += " AND " + repository.ScheduleRunsOnCondition("$"+strconv.Itoa(argCount)+"::date")
+		args = append(args, travelDate.Format(utils.DateLayout))
 	}
 
-	query += " ORDER BY r.origin_city, r.destination_city, s.departure_time"
+	query += " ORDER BY o.city, d.city, s.departure_time + make_interval(mins => o.offset_minutes)"
 
 	rows, err := db.Query(query, args...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
-	type TravelResult struct {
-		// Route Information
-		RouteID                  int     `json:"route_id"`
-		CompanyID                int     `json:"company_id"` // Links route to company
-		OriginCity               string  `json:"origin_city"`
-		OriginTerminal           string  `json:"origin_terminal"`
-		DestinationCity          string  `json:"destination_city"`
-		DestinationTerminal      string  `json:"destination_terminal"`
-		DistanceKm               int     `json:"distance_km"`
-		EstimatedDurationMinutes int     `json:"estimated_duration_minutes"`
-		BasePrice                float64 `json:"base_price"`
-
-		// Schedule Information (links to route and vehicle)
-		ScheduleID               int     `json:"schedule_id"`
-		VehicleID                int     `json:"vehicle_id"` // Links schedule to vehicle
-		DepartureTime            string  `json:"departure_time"`
-		ArrivalTime              string  `json:"arrival_time"`
-		DaysOfWeek               []int   `json:"days_of_week"`
-
-		// Company Information
-		CompanyIDFull            int     `json:"company_id_full"`
-		CompanyName              string  `json:"company_name"`
-		CompanyEmail             string  `json:"company_email"`
-
-		// Vehicle Information (belongs to company, used by schedule)
-		VehicleIDFull            int     `json:"vehicle_id_full"`
-		LicensePlate             string  `json:"license_plate"`
-		VehicleType              string  `json:"vehicle_type"`
-		Brand                    string  `json:"brand"`
-		Model                    string  `json:"model"`
-		TotalSeats               int     `json:"total_seats"`
-		Amenities                string  `json:"amenities"`
-	}
-
 	var results []TravelResult
 	for rows.Next() {
 		var result TravelResult
 		err := rows.Scan(
-			&result.RouteID, &result.CompanyID, &result.OriginCity, &result.OriginTerminal,
-			&result.DestinationCity, &result.DestinationTerminal, &result.DistanceKm,
+			&result.RouteID, &result.CompanyID, &result.OriginStopSequence, &result.OriginCity, &result.OriginTerminal, &result.OriginTimeZone,
+			&result.DestinationStopSequence, &result.DestinationCity, &result.DestinationTerminal, &result.DestinationTimeZone, &result.DistanceKm,
 			&result.EstimatedDurationMinutes, &result.BasePrice, &result.ScheduleID,
-			&result.VehicleID, &result.DepartureTime, &result.ArrivalTime, &result.DaysOfWeek,
-			&result.CompanyIDFull, &result.CompanyName, &result.CompanyEmail,
+			&result.VehicleID, &result.DepartureTime, pq.Array(&result.DaysOfWeek),
+			&result.CompanyIDFull, &result.CompanyName, &result.CompanyEmail, &result.RoundTripDiscountPercent,
 			&result.VehicleIDFull, &result.LicensePlate, &result.VehicleType, &result.Brand,
 			&result.Model, &result.TotalSeats, &result.Amenities,
 		)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+			return nil, err
 		}
 		results = append(results, result)
 	}
 
-	c.JSON(http.StatusOK, results)
+	// Price each result for its segment, loading stops, fares, yield rules and fare families once per route
+	routes := make(map[int]*models.Route)
+	yieldRules := make(map[int][]models.YieldRule)
+	fareFamilies := make(map[int][]models.FareFamily)
+	now := time.Now()
+	for i := range results {
+		result := &results[i]
+		route, ok := routes[result.RouteID]
+		if !ok {
+			route, err = services.GetRouteWithStops(db, result.RouteID)
+			if err != nil {
+				return nil, err
+			}
+			routes[result.RouteID] = route
+			yieldRules[result.RouteID], err = repository.GetYieldRulesByRouteID(db, result.RouteID, true)
+			if err != nil {
+				return nil, err
+			}
+			fareFamilies[result.RouteID], err = services.RouteFareFamilies(db, result.RouteID, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		originStop, destinationStop, err := services.ResolveSegment(route.Stops, &result.OriginStopSequence, &result.DestinationStopSequence)
+		if err != nil {
+			return nil, err
+		}
+		result.Price = services.SegmentFare(route.BasePrice, route.Stops, route.Fares, originStop, destinationStop)
+
+		// Schedule times are local to the first stop; express them in the searched stops' zones
+		departure, arrival, err := services.SegmentDatetimes(result.DepartureTime, route.Stops, originStop, destinationStop, referenceDate)
+		if err != nil {
+			return nil, err
+		}
+		result.DepartureTime = departure.Format(utils.ClockLayout)
+		result.ArrivalTime = arrival.Format(utils.ClockLayout)
+
+		// Yield rules depend on the seats sold on a departure, so they only apply to a searched date
+		if travelDate != nil {
+			result.Yield, err = services.DepartureYield(db, route, yieldRules[result.RouteID], result.ScheduleID, travelDate.Format("2006-01-02"), originStop, destinationStop, departure, result.Price, now)
+			if err != nil {
+				return nil, err
+			}
+			if result.Yield != nil {
+				result.Price = result.Yield.Price
+			}
+		}
+		result.FareFamilies = services.FareOptions(fareFamilies[result.RouteID], result.Price)
+	}
+
+	return results, nil
 }
 
 // GetAvailableSeatsForSchedule godoc
@@ -203,6 +343,8 @@ func SearchAvailableTravels(c *gin.Context, db *sql.DB) {
 // @Produce json
 // @Param schedule_id query int true "Schedule ID"
 // @Param travel_date query string true "Travel date (YYYY-MM-DD)"
+// @Param origin_stop_sequence query int false "Boarding stop sequence (defaults to the first stop)"
+// @Param destination_stop_sequence query int false "Alighting stop sequence (defaults to the last stop)"
 // @Success 200 {array} models.Seat
 // @Router /travels/seats [get]
 func GetAvailableSeatsForSchedule(c *gin.Context, db *sql.DB) {
@@ -220,7 +362,34 @@ func GetAvailableSeatsForSchedule(c *gin.Context, db *sql.DB) {
 		return
 	}
 
-	seats, err := repository.GetAvailableSeatsForSchedule(db, scheduleID, travelDate)
+	originStopSequence, err := optionalIntQuery(c, "origin_stop_sequence")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid origin_stop_sequence"})
+		return
+	}
+	destinationStopSequence, err := optionalIntQuery(c, "destination_stop_sequence")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid destination_stop_sequence"})
+		return
+	}
+
+	schedule, err := repository.GetScheduleByID(db, scheduleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+	stops, err := repository.GetRouteStopsByRouteID(db, schedule.RouteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	origin, destination, err := services.ResolveSegment(stops, originStopSequence, destinationStopSequence)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	seats, err := repository.GetAvailableSeatsForSegment(db, scheduleID, travelDate, origin.StopSequence, destination.StopSequence)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -231,7 +400,7 @@ func GetAvailableSeatsForSchedule(c *gin.Context, db *sql.DB) {
 
 // UpdateRoute godoc
 // @Summary Update route
-// @Description Update route information
+// @Description Update route information. Omitted stops and fares are kept; stops can only be changed while the route has no upcoming bookings, except for their coordinates and GTFS ids
 // @Tags routes
 // @Accept json
 // @Produce json
@@ -239,6 +408,7 @@ func GetAvailableSeatsForSchedule(c *gin.Context, db *sql.DB) {
 // @Param route body models.Route true "Route data"
 // @Success 200 {object} models.Route
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /routes/{id} [put]
 func UpdateRoute(c *gin.Context, db *sql.DB) {
 	idStr := c.Param("id")
@@ -255,8 +425,8 @@ func UpdateRoute(c *gin.Context, db *sql.DB) {
 	}
 	route.ID = id
 
-	if err := repository.UpdateRoute(db, &route); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := services.UpdateRoute(db, &route); err != nil {
+		respondRouteError(c, err)
 		return
 	}
 
@@ -287,4 +457,32 @@ func DeleteRoute(c *gin.Context, db *sql.DB) {
 	}
 
 	c.JSON(http.StatusNoContent, nil)
-}
\ No newline at end of file
+}
+
+func respondRouteError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidStops) || errors.Is(err, services.ErrInvalidFare) || errors.Is(err, services.ErrInvalidTimeZone) ||
+		errors.Is(err, services.ErrInvalidYieldRule) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrRouteStopsInUse) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// optionalIntQuery parses an optional integer query parameter
+func optionalIntQuery(c *gin.Context, key string) (*int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
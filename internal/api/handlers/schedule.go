@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleRequest represents the schedule creation and update request.
+// Times are local to the first and last stop of the route.
+type ScheduleRequest struct {
+	RouteID       int     `json:"route_id" binding:"required"`
+	VehicleID     int     `json:"vehicle_id" binding:"required"`
+	DepartureTime string  `json:"departure_time" binding:"required"` // HH:MM:SS
+	ArrivalTime   string  `json:"arrival_time" binding:"required"`   // HH:MM:SS
+	DaysOfWeek    []int64 `json:"days_of_week"`                      // ISO days, 1 = Monday
+	CalendarID    *int    `json:"calendar_id"`
+	ValidFrom     string  `json:"valid_from" binding:"required"` // YYYY-MM-DD
+	ValidUntil    *string `json:"valid_until"`                   // YYYY-MM-DD
+	IsActive      *bool   `json:"is_active"`
+}
+
+// toSchedule converts the request into a schedule, writing a 400 response on invalid dates
+func (req ScheduleRequest) toSchedule(c *gin.Context) (*models.Schedule, bool) {
+	validFrom, err := time.Parse("2006-01-02", req.ValidFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid valid_from format. Use YYYY-MM-DD"})
+		return nil, false
+	}
+
+	schedule := &models.Schedule{
+		RouteID:       req.RouteID,
+		VehicleID:     req.VehicleID,
+		DepartureTime: req.DepartureTime,
+		ArrivalTime:   req.ArrivalTime,
+		DaysOfWeek:    req.DaysOfWeek,
+		CalendarID:    req.CalendarID,
+		ValidFrom:     validFrom,
+		IsActive:      req.IsActive == nil || *req.IsActive,
+	}
+	if req.ValidUntil != nil {
+		validUntil, err := time.Parse("2006-01-02", *req.ValidUntil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid valid_until format. Use YYYY-MM-DD"})
+			return nil, false
+		}
+		schedule.ValidUntil = &validUntil
+	}
+
+	return schedule, true
+}
+
+// CreateSchedule godoc
+// @Summary Create a schedule
+// @Description Create a recurring departure for a route of the operator's company, optionally following a service calendar. The vehicle must belong to the route's company and be free for the departures
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param schedule body ScheduleRequest true "Schedule data"
+// @Success 201 {object} models.Schedule
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /schedules [post]
+func CreateSchedule(c *gin.Context, db *sql.DB) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, ok := req.toSchedule(c)
+	if !ok {
+		return
+	}
+
+	route, err := repository.GetRouteByID(db, schedule.RouteID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Route not found"})
+		return
+	}
+	if !canAccessCompany(c, route.CompanyID) {
+		return
+	}
+
+	if err := services.CreateSchedule(db, schedule); err != nil {
+		respondScheduleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// GetSchedule godoc
+// @Summary Get schedule by ID
+// @Description Get schedule information by ID
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} models.Schedule
+// @Failure 404 {object} map[string]string
+// @Router /schedules/{id} [get]
+func GetSchedule(c *gin.Context, db *sql.DB) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	schedule, err := repository.GetScheduleByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateSchedule godoc
+// @Summary Update schedule
+// @Description Update a schedule of the operator's company, including its service calendar
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Param schedule body ScheduleRequest true "Schedule data"
+// @Success 200 {object} models.Schedule
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /schedules/{id} [put]
+func UpdateSchedule(c *gin.Context, db *sql.DB) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := repository.GetScheduleByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+	companyID, err := repository.GetScheduleCompanyID(db, existing.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !canAccessCompany(c, companyID) {
+		return
+	}
+
+	schedule, ok := req.toSchedule(c)
+	if !ok {
+		return
+	}
+	schedule.ID = existing.ID
+
+	// Moving a schedule to another route must stay within the operator's company
+	if schedule.RouteID != existing.RouteID {
+		route, err := repository.GetRouteByID(db, schedule.RouteID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Route not found"})
+			return
+		}
+		if !canAccessCompany(c, route.CompanyID) {
+			return
+		}
+	}
+
+	if err := services.UpdateSchedule(db, schedule); err != nil {
+		respondScheduleError(c, err)
+		return
+	}
+
+	updated, err := repository.GetScheduleByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func respondScheduleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidSchedule), errors.Is(err, services.ErrCalendarMismatch), errors.Is(err, services.ErrVehicleCompanyMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrVehicleConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Route, vehicle or calendar not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SeatBlockRequest struct {
+	SeatIDs              []int  `json:"seat_ids"`
+	SeatCount            int    `json:"seat_count"`
+	Reason               string `json:"reason" binding:"required"`
+	Note                 string `json:"note"`
+	ReleaseMinutesBefore *int   `json:"release_minutes_before"`
+}
+
+// CreateTripSeatBlock godoc
+// @Summary Block trip seats
+// @Description Keep specific seats, or a number of seats, of a trip off sale, optionally until some minutes before departure
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param block body SeatBlockRequest true "Seats to block"
+// @Success 201 {array} models.SeatBlock
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /trips/{id}/seat-blocks [post]
+func CreateTripSeatBlock(c *gin.Context, db *sql.DB) {
+	trip, ok := loadSeatBlockTrip(c, db)
+	if !ok {
+		return
+	}
+	createSeatBlock(c, db, trip.ScheduleID, &trip.TravelDate)
+}
+
+// GetTripSeatBlocks godoc
+// @Summary Trip seat blocks
+// @Description List the seat blocks applying to a trip, including those of every trip of its schedule
+// @Tags trips
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {array} models.SeatBlock
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id}/seat-blocks [get]
+func GetTripSeatBlocks(c *gin.Context, db *sql.DB) {
+	trip, ok := loadSeatBlockTrip(c, db)
+	if !ok {
+		return
+	}
+	listSeatBlocks(c, db, trip.ScheduleID, &trip.TravelDate)
+}
+
+// DeleteTripSeatBlock godoc
+// @Summary Release trip seat block
+// @Description Put the seats of a block of the trip back on sale
+// @Tags trips
+// @Param id path int true "Trip ID"
+// @Param block_id path int true "Seat block ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id}/seat-blocks/{block_id} [delete]
+func DeleteTripSeatBlock(c *gin.Context, db *sql.DB) {
+	trip, ok := loadSeatBlockTrip(c, db)
+	if !ok {
+		return
+	}
+	deleteSeatBlock(c, db, trip.ScheduleID, &trip.TravelDate)
+}
+
+// CreateScheduleSeatBlock godoc
+// @Summary Block schedule seats
+// @Description Keep specific seats, or a number of seats, off sale on every trip of a schedule, optionally until some minutes before departure
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Param block body SeatBlockRequest true "Seats to block"
+// @Success 201 {array} models.SeatBlock
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /schedules/{id}/seat-blocks [post]
+func CreateScheduleSeatBlock(c *gin.Context, db *sql.DB) {
+	scheduleID, ok := loadScheduleCompany(c, db)
+	if !ok {
+		return
+	}
+	createSeatBlock(c, db, scheduleID, nil)
+}
+
+// GetScheduleSeatBlocks godoc
+// @Summary Schedule seat blocks
+// @Description List the seat blocks of a schedule, those of every trip first
+// @Tags schedules
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {array} models.SeatBlock
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /schedules/{id}/seat-blocks [get]
+func GetScheduleSeatBlocks(c *gin.Context, db *sql.DB) {
+	scheduleID, ok := loadScheduleCompany(c, db)
+	if !ok {
+		return
+	}
+	listSeatBlocks(c, db, scheduleID, nil)
+}
+
+// DeleteScheduleSeatBlock godoc
+// @Summary Release schedule seat block
+// @Description Put the seats of a block of the schedule, or of one of its trips, back on sale
+// @Tags schedules
+// @Param id path int true "Schedule ID"
+// @Param block_id path int true "Seat block ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /schedules/{id}/seat-blocks/{block_id} [delete]
+func DeleteScheduleSeatBlock(c *gin.Context, db *sql.DB) {
+	scheduleID, ok := loadScheduleCompany(c, db)
+	if !ok {
+		return
+	}
+	deleteSeatBlock(c, db, scheduleID, nil)
+}
+
+// loadSeatBlockTrip loads the trip in the path after checking it belongs to the operator's company
+func loadSeatBlockTrip(c *gin.Context, db *sql.DB) (*models.Trip, bool) {
+	tripID, ok := loadTripCompany(c, db)
+	if !ok {
+		return nil, false
+	}
+
+	trip, err := repository.GetTripByID(db, tripID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		return nil, false
+	}
+	return trip, true
+}
+
+// loadScheduleCompany checks that the schedule in the path belongs to the operator's company
+func loadScheduleCompany(c *gin.Context, db *sql.DB) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return 0, false
+	}
+
+	companyID, err := repository.GetScheduleCompanyID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return 0, false
+	}
+	if !canAccessCompany(c, companyID) {
+		return 0, false
+	}
+
+	return id, true
+}
+
+func createSeatBlock(c *gin.Context, db *sql.DB, scheduleID int, travelDate *time.Time) {
+	var req SeatBlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	blocks, err := services.BlockSeats(db, scheduleID, travelDate, services.SeatBlockRequest{
+		SeatIDs:              req.SeatIDs,
+		SeatCount:            req.SeatCount,
+		Reason:               req.Reason,
+		Note:                 req.Note,
+		ReleaseMinutesBefore: req.ReleaseMinutesBefore,
+		CreatedBy:            c.GetInt("user_id"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSeatBlock):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrSeatsUnavailable):
+			c.JSON(http.StatusConflict, gin.H{"error": "Seats are not available to block"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, blocks)
+}
+
+func listSeatBlocks(c *gin.Context, db *sql.DB, scheduleID int, travelDate *time.Time) {
+	blocks, err := repository.GetSeatBlocks(db, scheduleID, travelDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, blocks)
+}
+
+func deleteSeatBlock(c *gin.Context, db *sql.DB, scheduleID int, travelDate *time.Time) {
+	blockID, err := strconv.Atoi(c.Param("block_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seat block ID"})
+		return
+	}
+
+	if err := services.ReleaseSeatBlock(db, scheduleID, travelDate, blockID); err != nil {
+		if errors.Is(err, services.ErrSeatBlockNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Seat block not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
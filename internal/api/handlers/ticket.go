@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/documents"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// TicketPublicKey is the key scanners verify ticket signatures with
+type TicketPublicKey struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+}
+
+// GetTicketPublicKey godoc
+// @Summary Ticket verification key
+// @Description Get the Ed25519 public key (base64) that signs ticket QR codes. A ticket payload is "<key_id>.<claims>.<signature>", with the JSON claims and their signature in unpadded base64url
+// @Tags tickets
+// @Produce json
+// @Success 200 {object} TicketPublicKey
+// @Router /tickets/public-key [get]
+func GetTicketPublicKey(c *gin.Context, key *utils.TicketKey) {
+	c.JSON(http.StatusOK, TicketPublicKey{
+		KeyID:     key.ID,
+		Algorithm: "Ed25519",
+		PublicKey: base64.StdEncoding.EncodeToString(key.PublicKey()),
+	})
+}
+
+// GetBookingTickets godoc
+// @Summary Booking tickets
+// @Description Issue a signed ticket for each seat of a confirmed, paid booking of the authenticated user
+// @Tags tickets
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {array} services.Ticket
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /bookings/{id}/tickets [get]
+func GetBookingTickets(c *gin.Context, db *sql.DB, key *utils.TicketKey) {
+	tickets, ok := loadBookingTickets(c, db, key)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, tickets)
+}
+
+// GetBookingTicketQR godoc
+// @Summary Ticket QR code
+// @Description Download the QR code of a ticket as a PNG image
+// @Tags tickets
+// @Produce png
+// @Param id path int true "Booking ID"
+// @Param booking_seat_id path int true "Booking seat ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /bookings/{id}/tickets/{booking_seat_id}/qr [get]
+func GetBookingTicketQR(c *gin.Context, db *sql.DB, key *utils.TicketKey) {
+	bookingSeatID, err := strconv.Atoi(c.Param("booking_seat_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking seat ID"})
+		return
+	}
+
+	tickets, ok := loadBookingTickets(c, db, key)
+	if !ok {
+		return
+	}
+
+	for _, ticket := range tickets {
+		if ticket.Claims.BookingSeatID != bookingSeatID {
+			continue
+		}
+		png, err := documents.TicketQR(ticket)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=ticket-%s-%s.png", ticket.Claims.BookingCode, ticket.Claims.SeatNumber))
+		c.Data(http.StatusOK, "image/png", png)
+		return
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+}
+
+// GetBookingTicketsPDF godoc
+// @Summary Printable tickets
+// @Description Download the tickets of a booking as a PDF, one page per seat with its QR code
+// @Tags tickets
+// @Produce application/pdf
+// @Param id path int true "Booking ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /bookings/{id}/tickets/pdf [get]
+func GetBookingTicketsPDF(c *gin.Context, db *sql.DB, key *utils.TicketKey) {
+	tickets, ok := loadBookingTickets(c, db, key)
+	if !ok {
+		return
+	}
+	if len(tickets) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking has no seats"})
+		return
+	}
+
+	var file bytes.Buffer
+	if err := documents.TicketsPDF(&file, tickets); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=tickets-%s.pdf", tickets[0].Claims.BookingCode))
+	c.Data(http.StatusOK, "application/pdf", file.Bytes())
+}
+
+// loadBookingTickets issues the tickets of the booking of the request path if it belongs to the user.
+// It writes an error response and returns false otherwise.
+func loadBookingTickets(c *gin.Context, db *sql.DB, key *utils.TicketKey) ([]services.Ticket, bool) {
+	booking, ok := loadUserBooking(c, db)
+	if !ok {
+		return nil, false
+	}
+
+	tickets, err := services.BookingTickets(db, key, booking)
+	if err != nil {
+		if errors.Is(err, services.ErrBookingCancelled) || errors.Is(err, services.ErrTicketsUnavailable) || errors.Is(err, services.ErrTripCancelled) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return tickets, true
+}
@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateTripRequest identifies the departure to materialise
+type CreateTripRequest struct {
+	ScheduleID int    `json:"schedule_id" binding:"required"`
+	TravelDate string `json:"travel_date" binding:"required"`
+	VehicleID  int    `json:"vehicle_id"` // defaults to the schedule's vehicle
+}
+
+// ReassignTripRequest identifies the vehicle that will run a trip
+type ReassignTripRequest struct {
+	VehicleID      int  `json:"vehicle_id" binding:"required"`
+	AllowUnmatched bool `json:"allow_unmatched"` // swap even if some passengers get no seat
+}
+
+// CancelTripRequest carries the reason of a cancellation
+type CancelTripRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// DelayTripRequest carries the delay and its reason
+type DelayTripRequest struct {
+	DelayMinutes int    `json:"delay_minutes" binding:"required,min=1"`
+	Reason       string `json:"reason" binding:"required"`
+}
+
+// TripPositionRequest is a location of the vehicle running a trip
+type TripPositionRequest struct {
+	Latitude   *float64 `json:"latitude" binding:"required,min=-90,max=90"`
+	Longitude  *float64 `json:"longitude" binding:"required,min=-180,max=180"`
+	Bearing    *float64 `json:"bearing" binding:"omitempty,min=0,max=360"`
+	SpeedKmh   *float64 `json:"speed_kmh" binding:"omitempty,min=0"`
+	RecordedAt string   `json:"recorded_at"` // RFC 3339, defaults to now
+}
+
+// CreateTrip godoc
+// @Summary Materialise a trip
+// @Description Get or create the trip of a schedule on a travel date so it can be managed by operators, optionally with another vehicle than the schedule's. A new trip is refused when its vehicle is in maintenance or already assigned to an overlapping departure
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param trip body CreateTripRequest true "Schedule and travel date"
+// @Success 201 {object} models.Trip
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /trips [post]
+func CreateTrip(c *gin.Context, db *sql.DB) {
+	var req CreateTripRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	travelDate, err := time.Parse("2006-01-02", req.TravelDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	companyID, err := repository.GetScheduleCompanyID(db, req.ScheduleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+	if !canAccessCompany(c, companyID) {
+		return
+	}
+
+	if req.VehicleID != 0 {
+		if _, err := repository.GetVehicleByID(db, req.VehicleID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Vehicle not found"})
+			return
+		}
+	}
+
+	trip, err := services.OpenTrip(db, req.ScheduleID, travelDate, req.VehicleID)
+	if err != nil {
+		if errors.Is(err, services.ErrScheduleInactive) || errors.Is(err, services.ErrScheduleNotRunning) || errors.Is(err, services.ErrVehicleCompanyMismatch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrVehicleConflict) || errors.Is(err, services.ErrVehicleInMaintenance) ||
+			errors.Is(err, services.ErrSeatsUnmatched) || errors.Is(err, services.ErrGroupSeatsUnmatched) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, trip)
+}
+
+// GetTrip godoc
+// @Summary Get trip by ID
+// @Description Get trip information, including its status and delay
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {object} models.Trip
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id} [get]
+func GetTrip(c *gin.Context, db *sql.DB) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trip ID"})
+		return
+	}
+
+	trip, err := repository.GetTripByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		return
+	}
+	if err := services.LocalizeTrip(db, trip); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trip)
+}
+
+// CancelTrip godoc
+// @Summary Cancel a trip
+// @Description Cancel a trip, refund and cancel its bookings, offer rebooking and notify the passengers
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param cancellation body CancelTripRequest true "Cancellation reason"
+// @Success 200 {object} services.TripDisruption
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /trips/{id}/cancel [post]
+func CancelTrip(c *gin.Context, db *sql.DB) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trip ID"})
+		return
+	}
+
+	var req CancelTripRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	companyID, err := repository.GetTripCompanyID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		return
+	}
+	if !canAccessCompany(c, companyID) {
+		return
+	}
+
+	disruption, err := services.CancelTrip(db, id, req.Reason)
+	if err != nil {
+		respondTripError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, disruption)
+}
+
+// DelayTrip godoc
+// @Summary Delay a trip
+// @Description Record a delay on a trip and notify the passengers. Long delays entitle the bookings to a full refund on cancellation or a free exchange
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param delay body DelayTripRequest true "Delay in minutes and reason"
+// @Success 200 {object} services.TripDisruption
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /trips/{id}/delay [post]
+func DelayTrip(c *gin.Context, db *sql.DB, compensationMinutes int) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trip ID"})
+		return
+	}
+
+	var req DelayTripRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	companyID, err := repository.GetTripCompanyID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		return
+	}
+	if !canAccessCompany(c, companyID) {
+		return
+	}
+
+	disruption, err := services.DelayTrip(db, id, req.DelayMinutes, req.Reason, compensationMinutes)
+	if err != nil {
+		respondTripError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, disruption)
+}
+
+// ReassignTripVehicle godoc
+// @Summary Swap a trip's vehicle
+// @Description Move a trip to another vehicle of the operator's company, e.g. after a breakdown. Booked seats move to the seat with the same number, else one of the same type, else any free seat, and passengers whose seat changed are notified. Seats held for groups, seat blocks and waitlist offers move the same way and are listed under holds. Refused when the vehicle is in maintenance or already assigned to an overlapping departure, when a group would lose a seat, and, unless allow_unmatched is set, when some passengers or holds would get no seat; the 409 response then lists them under swap.unmatched and swap.unmatched_holds. With allow_unmatched, blocks of the trip that find no seat are lifted and waitlist offers withdrawn
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param vehicle body ReassignTripRequest true "Vehicle"
+// @Success 200 {object} services.VehicleSwap
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Router /trips/{id}/vehicle [put]
+func ReassignTripVehicle(c *gin.Context, db *sql.DB) {
+	id, ok := loadTripCompany(c, db)
+	if !ok {
+		return
+	}
+
+	var req ReassignTripRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := repository.GetVehicleByID(db, req.VehicleID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vehicle not found"})
+		return
+	}
+
+	swap, err := services.SwapTripVehicle(db, id, req.VehicleID, req.AllowUnmatched)
+	if errors.Is(err, services.ErrSeatsUnmatched) || errors.Is(err, services.ErrGroupSeatsUnmatched) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "swap": swap})
+		return
+	}
+	if err != nil {
+		respondTripError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, swap)
+}
+
+// ReportTripPosition godoc
+// @Summary Report a trip position
+// @Description Record where the vehicle running a trip is, for the GTFS-Realtime vehicle positions feed
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param position body TripPositionRequest true "Position"
+// @Success 201 {object} models.VehiclePosition
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id}/positions [post]
+func ReportTripPosition(c *gin.Context, db *sql.DB) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trip ID"})
+		return
+	}
+
+	var req TripPositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordedAt := time.Now()
+	if req.RecordedAt != "" {
+		if recordedAt, err = time.Parse(time.RFC3339, req.RecordedAt); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recorded_at format. Use RFC 3339"})
+			return
+		}
+	}
+
+	trip, err := repository.GetTripByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		return
+	}
+	companyID, err := repository.GetTripCompanyID(db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !canAccessCompany(c, companyID) {
+		return
+	}
+
+	position := models.VehiclePosition{
+		VehicleID:  trip.VehicleID,
+		TripID:     &trip.ID,
+		Latitude:   *req.Latitude,
+		Longitude:  *req.Longitude,
+		Bearing:    req.Bearing,
+		SpeedKmh:   req.SpeedKmh,
+		RecordedAt: recordedAt,
+	}
+	if err := repository.CreateVehiclePosition(db, &position); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, position)
+}
+
+// GetTripPositions godoc
+// @Summary Trip position history
+// @Description List the positions reported for a trip, oldest first
+// @Tags trips
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param since query string false "Only positions recorded from this time (RFC 3339)"
+// @Success 200 {array} models.VehiclePosition
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id}/positions [get]
+func GetTripPositions(c *gin.Context, db *sql.DB) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trip ID"})
+		return
+	}
+
+	var since time.Time
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		if since, err = time.Parse(time.RFC3339, sinceStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since format. Use RFC 3339"})
+			return
+		}
+	}
+
+	companyID, err := repository.GetTripCompanyID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		return
+	}
+	if !canAccessCompany(c, companyID) {
+		return
+	}
+
+	positions, err := repository.GetTripPositions(db, id, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, positions)
+}
+
+func respondTripError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+	case errors.Is(err, services.ErrTripCancelled), errors.Is(err, services.ErrVehicleConflict), errors.Is(err, services.ErrVehicleInMaintenance):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidDelay), errors.Is(err, services.ErrReasonRequired), errors.Is(err, services.ErrVehicleCompanyMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// loadTripCompany returns the trip id of the request path if the operator may manage the trip.
+// It writes an error response and returns false otherwise.
+func loadTripCompany(c *gin.Context, db *sql.DB) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trip ID"})
+		return 0, false
+	}
+
+	companyID, err := repository.GetTripCompanyID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		return 0, false
+	}
+	if !canAccessCompany(c, companyID) {
+		return 0, false
+	}
+
+	return id, true
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -10,9 +11,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// UserRoleRequest sets the role of a user and the company they operate for
+type UserRoleRequest struct {
+	Role      string `json:"role" binding:"required,oneof=passenger operator admin"`
+	CompanyID *int   `json:"company_id"` // required for operators
+}
+
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Create a new user account
+// @Description Create a new passenger account. Roles are granted by administrators
 // @Tags users
 // @Accept json
 // @Produce json
@@ -107,7 +114,7 @@ func GetUser(c *gin.Context, db *sql.DB) {
 
 // UpdateUser godoc
 // @Summary Update user
-// @Description Update user information
+// @Description Update user information. The role and company are kept; administrators change them through the role endpoint
 // @Tags users
 // @Accept json
 // @Produce json
@@ -136,6 +143,67 @@ func UpdateUser(c *gin.Context, db *sql.DB) {
 		return
 	}
 
+	updated, err := repository.GetUserByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// UpdateUserRole godoc
+// @Summary Set a user's role
+// @Description Make a user a passenger, an operator of a company or an administrator. Administrators only
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param role body UserRoleRequest true "Role and company"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/role [put]
+func UpdateUserRole(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Role == models.RoleOperator && req.CompanyID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Operators need a company_id"})
+		return
+	}
+	if req.CompanyID != nil {
+		if _, err := repository.GetCompanyByID(db, *req.CompanyID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Company not found"})
+			return
+		}
+	}
+
+	if err := repository.UpdateUserRole(db, id, req.Role, req.CompanyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user role"})
+		return
+	}
+
+	user, err := repository.GetUserByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, user)
 }
 
@@ -163,4 +231,4 @@ func DeleteUser(c *gin.Context, db *sql.DB) {
 	}
 
 	c.JSON(http.StatusNoContent, nil)
-}
\ No newline at end of file
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// assignmentPeriodDays is the period shown when no dates are given
+const assignmentPeriodDays = 7
+
+// parseAssignmentPeriod reads the from and to dates of the query, both inclusive, starting today by default.
+// It writes a 400 response and returns false when they are invalid.
+func parseAssignmentPeriod(c *gin.Context) (time.Time, time.Time, bool) {
+	now := time.Now().UTC()
+	return parsePeriod(c, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+}
+
+// parsePeriod reads the from and to dates of the query, both inclusive. The period lasts
+// assignmentPeriodDays from defaultFrom when no dates are given.
+func parsePeriod(c *gin.Context, defaultFrom time.Time) (time.Time, time.Time, bool) {
+	from := defaultFrom
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format. Use YYYY-MM-DD"})
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+
+	to := from.AddDate(0, 0, assignmentPeriodDays)
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format. Use YYYY-MM-DD"})
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	if !from.Before(to) || to.After(from.AddDate(0, 0, services.AssignmentHorizonDays)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be on or after from and at most " + strconv.Itoa(services.AssignmentHorizonDays) + " days later"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
+
+// GetVehicleTimeline godoc
+// @Summary Vehicle timeline
+// @Description List the departures a vehicle is assigned to: its trips and the upcoming departures of its schedules. Defaults to the next 7 days
+// @Tags vehicles
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date (YYYY-MM-DD)"
+// @Success 200 {array} services.Assignment
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /vehicles/{id}/timeline [get]
+func GetVehicleTimeline(c *gin.Context, db *sql.DB) {
+	vehicle, ok := loadVehicle(c, db)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseAssignmentPeriod(c)
+	if !ok {
+		return
+	}
+
+	timeline, err := services.VehicleTimeline(db, vehicle.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
+
+// GetVehicleConflicts godoc
+// @Summary Vehicle assignment conflicts
+// @Description Report the vehicles of the operator's company, or of company_id for admins, assigned to overlapping departures, and the schedules using a vehicle of another company. Defaults to the next 7 days
+// @Tags vehicles
+// @Produce json
+// @Param company_id query int false "Company ID (admins)"
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date (YYYY-MM-DD)"
+// @Success 200 {array} services.AssignmentConflict
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /vehicles/conflicts [get]
+func GetVehicleConflicts(c *gin.Context, db *sql.DB) {
+	requestedCompanyID, _ := strconv.Atoi(c.Query("company_id"))
+	companyID, ok := resolveCompany(c, requestedCompanyID)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseAssignmentPeriod(c)
+	if !ok {
+		return
+	}
+
+	conflicts, err := services.AssignmentConflicts(db, companyID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, conflicts)
+}
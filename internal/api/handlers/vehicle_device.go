@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// deviceTokenBytes is the size of the random part of device tokens
+const deviceTokenBytes = 32
+
+// VehicleDeviceRegistration is a new device with its token, which is only returned once
+type VehicleDeviceRegistration struct {
+	Device models.VehicleDevice `json:"device"`
+	Token  string               `json:"token"`
+}
+
+// DevicePosition is a position reported by an on-board device
+type DevicePosition struct {
+	Latitude   *float64 `json:"latitude" binding:"required"`
+	Longitude  *float64 `json:"longitude" binding:"required"`
+	Bearing    *float64 `json:"bearing" binding:"omitempty,min=0,max=360"`
+	SpeedKmh   *float64 `json:"speed_kmh" binding:"omitempty,min=0"`
+	RecordedAt string   `json:"recorded_at" binding:"required"` // RFC 3339
+}
+
+// DevicePositionsRequest is a batch of positions, so devices can send what they buffered while offline
+type DevicePositionsRequest struct {
+	Positions []DevicePosition `json:"positions" binding:"required,min=1,max=500,dive"`
+}
+
+// loadVehicle returns the vehicle with the id in the path if the operator may manage it.
+// It writes an error response and returns false otherwise.
+func loadVehicle(c *gin.Context, db *sql.DB) (*models.Vehicle, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vehicle ID"})
+		return nil, false
+	}
+
+	vehicle, err := repository.GetVehicleByID(db, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vehicle not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !canAccessCompany(c, vehicle.CompanyID) {
+		return nil, false
+	}
+
+	return vehicle, true
+}
+
+// RegisterVehicleDevice godoc
+// @Summary Register a vehicle device
+// @Description Register an on-board device that reports the vehicle's positions. The returned token authenticates the device and is only shown once
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Param device body models.VehicleDevice true "Device name"
+// @Success 201 {object} VehicleDeviceRegistration
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /vehicles/{id}/devices [post]
+func RegisterVehicleDevice(c *gin.Context, db *sql.DB) {
+	vehicle, ok := loadVehicle(c, db)
+	if !ok {
+		return
+	}
+
+	var device models.VehicleDevice
+	if err := c.ShouldBindJSON(&device); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	device.VehicleID = vehicle.ID
+
+	token, err := utils.GenerateToken(deviceTokenBytes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate device token"})
+		return
+	}
+	if err := repository.CreateVehicleDevice(db, &device, utils.HashToken(token)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, VehicleDeviceRegistration{Device: device, Token: token})
+}
+
+// GetVehicleDevices godoc
+// @Summary List vehicle devices
+// @Description List the devices registered for a vehicle
+// @Tags vehicles
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Success 200 {array} models.VehicleDevice
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /vehicles/{id}/devices [get]
+func GetVehicleDevices(c *gin.Context, db *sql.DB) {
+	vehicle, ok := loadVehicle(c, db)
+	if !ok {
+		return
+	}
+
+	devices, err := repository.GetVehicleDevicesByVehicleID(db, vehicle.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, devices)
+}
+
+// RevokeVehicleDevice godoc
+// @Summary Revoke a vehicle device
+// @Description Deactivate a device so its token is no longer accepted
+// @Tags vehicles
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Param device_id path int true "Device ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /vehicles/{id}/devices/{device_id} [delete]
+func RevokeVehicleDevice(c *gin.Context, db *sql.DB) {
+	vehicle, ok := loadVehicle(c, db)
+	if !ok {
+		return
+	}
+	deviceID, err := strconv.Atoi(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	if err := repository.DeactivateVehicleDevice(db, vehicle.ID, deviceID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Device revoked successfully"})
+}
+
+// ReportDevicePositions godoc
+// @Summary Report vehicle positions
+// @Description Positions reported by an on-board device, authenticated with "Authorization: Device <token>". Each position is linked to the trip the vehicle was running at the time
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Param positions body DevicePositionsRequest true "Positions, oldest first"
+// @Success 201 {array} models.VehiclePosition
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /devices/positions [post]
+func ReportDevicePositions(c *gin.Context, db *sql.DB) {
+	var req DevicePositionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	positions := make([]models.VehiclePosition, len(req.Positions))
+	for i, reported := range req.Positions {
+		recordedAt, err := time.Parse(time.RFC3339, reported.RecordedAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recorded_at format. Use RFC 3339"})
+			return
+		}
+		positions[i] = models.VehiclePosition{
+			Latitude:   *reported.Latitude,
+			Longitude:  *reported.Longitude,
+			Bearing:    reported.Bearing,
+			SpeedKmh:   reported.SpeedKmh,
+			RecordedAt: recordedAt,
+		}
+	}
+
+	positions, err := services.RecordVehiclePositions(db, c.GetInt("vehicle_id"), positions)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPosition) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, positions)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceSchedule is a new maintenance window with the departures it affects
+type MaintenanceSchedule struct {
+	Window   models.MaintenanceWindow      `json:"window"`
+	Warnings []services.MaintenanceWarning `json:"warnings"`
+}
+
+// CreateMaintenanceWindow godoc
+// @Summary Schedule vehicle maintenance
+// @Description Block a vehicle for maintenance. Trips can no longer be assigned to it during the window; the departures already planned in it are returned as warnings with alternative vehicles of the same company and compatible seats
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Param window body models.MaintenanceWindow true "Start, end (RFC 3339) and reason"
+// @Success 201 {object} MaintenanceSchedule
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /vehicles/{id}/maintenance [post]
+func CreateMaintenanceWindow(c *gin.Context, db *sql.DB) {
+	vehicle, ok := loadVehicle(c, db)
+	if !ok {
+		return
+	}
+
+	var window models.MaintenanceWindow
+	if err := c.ShouldBindJSON(&window); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	window.VehicleID = vehicle.ID
+
+	warnings, err := services.ScheduleMaintenance(db, &window)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMaintenanceWindow) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, MaintenanceSchedule{Window: window, Warnings: warnings})
+}
+
+// GetMaintenanceWindows godoc
+// @Summary List vehicle maintenance
+// @Description List the maintenance windows of a vehicle. Defaults to the next 7 days
+// @Tags vehicles
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date (YYYY-MM-DD)"
+// @Success 200 {array} models.MaintenanceWindow
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /vehicles/{id}/maintenance [get]
+func GetMaintenanceWindows(c *gin.Context, db *sql.DB) {
+	vehicle, ok := loadVehicle(c, db)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseAssignmentPeriod(c)
+	if !ok {
+		return
+	}
+
+	windows, err := repository.GetMaintenanceWindows(db, vehicle.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, windows)
+}
+
+// GetMaintenanceWarnings godoc
+// @Summary Departures in maintenance
+// @Description List the departures of a vehicle that fall inside its maintenance windows, with alternative vehicles for each. Defaults to the next 7 days
+// @Tags vehicles
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date (YYYY-MM-DD)"
+// @Success 200 {array} services.MaintenanceWarning
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /vehicles/{id}/maintenance/warnings [get]
+func GetMaintenanceWarnings(c *gin.Context, db *sql.DB) {
+	vehicle, ok := loadVehicle(c, db)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseAssignmentPeriod(c)
+	if !ok {
+		return
+	}
+
+	windows, err := repository.GetMaintenanceWindows(db, vehicle.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	warnings, err := services.MaintenanceWarnings(db, vehicle.ID, windows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, warnings)
+}
+
+// DeleteMaintenanceWindow godoc
+// @Summary Cancel vehicle maintenance
+// @Description Remove a maintenance window so the vehicle can be assigned again
+// @Tags vehicles
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Param window_id path int true "Maintenance window ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /vehicles/{id}/maintenance/{window_id} [delete]
+func DeleteMaintenanceWindow(c *gin.Context, db *sql.DB) {
+	vehicle, ok := loadVehicle(c, db)
+	if !ok {
+		return
+	}
+
+	windowID, err := strconv.Atoi(c.Param("window_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+		return
+	}
+
+	if err := repository.DeleteMaintenanceWindow(db, vehicle.ID, windowID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Maintenance window not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetTripAlternativeVehicles godoc
+// @Summary Alternative vehicles for a trip
+// @Description List the active vehicles of the trip's company with compatible seats that are free and out of maintenance during the trip
+// @Tags trips
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {array} models.Vehicle
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id}/alternative-vehicles [get]
+func GetTripAlternativeVehicles(c *gin.Context, db *sql.DB) {
+	tripID, ok := loadTripCompany(c, db)
+	if !ok {
+		return
+	}
+
+	trip, err := repository.GetTripByID(db, tripID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	assignment := services.Assignment{
+		VehicleID:         trip.VehicleID,
+		ScheduleID:        trip.ScheduleID,
+		TripID:            &trip.ID,
+		TravelDate:        trip.TravelDate.Format(utils.DateLayout),
+		DepartureDatetime: trip.DepartureDatetime,
+		ArrivalDatetime:   trip.ArrivalDatetime.Add(time.Duration(trip.DelayMinutes) * time.Minute),
+		Status:            trip.Status,
+	}
+	vehicles, err := services.AlternativeVehicles(db, trip.VehicleID, assignment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, vehicles)
+}
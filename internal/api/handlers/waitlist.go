@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// JoinWaitlistRequest represents a request to wait for a seat on a sold-out departure
+type JoinWaitlistRequest struct {
+	ScheduleID        int    `json:"schedule_id" binding:"required"`
+	TravelDate        string `json:"travel_date" binding:"required"`
+	PassengerName     string `json:"passenger_name" binding:"required"`
+	PassengerDocument string `json:"passenger_document" binding:"required"`
+	PassengerPhone    string `json:"passenger_phone" binding:"required"`
+	// Optional seat type; any seat is offered when omitted
+	SeatType *string `json:"seat_type"`
+	// Optional boarding and alighting stops; the whole route when omitted
+	OriginStopSequence      *int `json:"origin_stop_sequence"`
+	DestinationStopSequence *int `json:"destination_stop_sequence"`
+}
+
+// ClaimWaitlistOfferRequest holds the claim token of a waitlist offer and the payment of the seat
+type ClaimWaitlistOfferRequest struct {
+	Token         string `json:"token" binding:"required"`
+	PaymentMethod string `json:"payment_method"`
+}
+
+// JoinWaitlist godoc
+// @Summary Join the waitlist of a departure
+// @Description Wait for a seat, optionally of a given type, on a departure with none free. Seats freed by cancellations or lapsed offers are offered in the order passengers joined, through a notification with a time-limited claim token
+// @Tags waitlist
+// @Accept json
+// @Produce json
+// @Param entry body JoinWaitlistRequest true "Departure and passenger"
+// @Success 201 {object} services.WaitlistPosition
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /waitlist [post]
+func JoinWaitlist(c *gin.Context, db *sql.DB) {
+	var req JoinWaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	position, err := services.JoinWaitlist(db, services.WaitlistRequest{
+		UserID:                  c.GetInt("user_id"),
+		ScheduleID:              req.ScheduleID,
+		TravelDate:              req.TravelDate,
+		SeatType:                req.SeatType,
+		OriginStopSequence:      req.OriginStopSequence,
+		DestinationStopSequence: req.DestinationStopSequence,
+		PassengerName:           req.PassengerName,
+		PassengerDocument:       req.PassengerDocument,
+		PassengerPhone:          req.PassengerPhone,
+	}, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrWaitlistSeatsAvailable), errors.Is(err, services.ErrWaitlistClosed),
+			errors.Is(err, services.ErrAlreadyWaitlisted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrScheduleInactive):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			respondBookingError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, position)
+}
+
+// GetWaitlist godoc
+// @Summary List my waitlist entries
+// @Description List the waitlist entries of the authenticated user, newest first, with the number of passengers waiting ahead
+// @Tags waitlist
+// @Produce json
+// @Success 200 {array} services.WaitlistPosition
+// @Router /waitlist [get]
+func GetWaitlist(c *gin.Context, db *sql.DB) {
+	positions, err := services.UserWaitlist(db, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, positions)
+}
+
+// LeaveWaitlist godoc
+// @Summary Leave a waitlist
+// @Description Take an entry of the authenticated user off the waitlist. A seat offered to it goes to the next passenger
+// @Tags waitlist
+// @Produce json
+// @Param id path int true "Waitlist entry ID"
+// @Success 200 {object} models.WaitlistEntry
+// @Failure 404 {object} map[string]string
+// @Router /waitlist/{id} [delete]
+func LeaveWaitlist(c *gin.Context, db *sql.DB, claimPeriod time.Duration) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid waitlist entry ID"})
+		return
+	}
+
+	entry, err := services.LeaveWaitlist(db, id, c.GetInt("user_id"), claimPeriod, time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrWaitlistEntryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// ClaimWaitlistOffer godoc
+// @Summary Claim a waitlist seat
+// @Description Book the seat offered to the authenticated user, while the offer lasts, by posting the claim token of the waitlist notification
+// @Tags waitlist
+// @Accept json
+// @Produce json
+// @Param claim body ClaimWaitlistOfferRequest true "Claim token and payment"
+// @Success 201 {object} models.Booking
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /waitlist/claim [post]
+func ClaimWaitlistOffer(c *gin.Context, db *sql.DB) {
+	var req ClaimWaitlistOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, err := services.ClaimWaitlistOffer(db, req.Token, c.GetInt("user_id"), req.PaymentMethod, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrWaitlistEntryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist offer not found"})
+		case errors.Is(err, services.ErrWaitlistOfferExpired):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			respondBookingError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, booking)
+}
+
+// GetTripWaitlist godoc
+// @Summary Trip waitlist
+// @Description List the waitlist of a trip of the operator's company in the order passengers joined
+// @Tags trips
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {array} models.WaitlistEntry
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id}/waitlist [get]
+func GetTripWaitlist(c *gin.Context, db *sql.DB) {
+	tripID, ok := loadTripCompany(c, db)
+	if !ok {
+		return
+	}
+
+	entries, err := repository.GetTripWaitlist(db, tripID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// offerFreedSeats offers the seats released by the given bookings to waitlisted passengers.
+// Failures are only logged: the waitlist job offers the seats on its next run.
+func offerFreedSeats(db *sql.DB, bookings []models.Booking, claimPeriod time.Duration) {
+	if err := services.OfferFreedSeats(db, bookings, claimPeriod, time.Now()); err != nil {
+		log.Printf("Offering freed seats to the waitlist failed: %v", err)
+	}
+}
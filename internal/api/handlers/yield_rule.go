@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// YieldRuleRequest represents the yield rule creation and update request
+type YieldRuleRequest struct {
+	RouteID int    `json:"route_id"` // on creation only
+	Name    string `json:"name" binding:"required"`
+	// Band of the share of seats sold on the segment; 0 to 100 when omitted
+	MinLoadPercent float64  `json:"min_load_percent"`
+	MaxLoadPercent *float64 `json:"max_load_percent"`
+	// Band of whole days left before departure; unbounded when omitted
+	MinDaysBefore *int `json:"min_days_before"`
+	MaxDaysBefore *int `json:"max_days_before"`
+	// Share of the segment fare charged
+	PricePercent float64 `json:"price_percent" binding:"required"`
+	Priority     int     `json:"priority"`
+	IsActive     *bool   `json:"is_active"`
+}
+
+// apply copies the request onto a yield rule
+func (req YieldRuleRequest) apply(rule *models.YieldRule) {
+	rule.Name = req.Name
+	rule.MinLoadPercent = req.MinLoadPercent
+	rule.MaxLoadPercent = 100
+	if req.MaxLoadPercent != nil {
+		rule.MaxLoadPercent = *req.MaxLoadPercent
+	}
+	rule.MinDaysBefore = req.MinDaysBefore
+	rule.MaxDaysBefore = req.MaxDaysBefore
+	rule.PricePercent = req.PricePercent
+	rule.Priority = req.Priority
+	rule.IsActive = req.IsActive == nil || *req.IsActive
+}
+
+// YieldSimulationRequest previews yield rules of a route
+type YieldSimulationRequest struct {
+	RouteID int `json:"route_id" binding:"required"`
+	// Rules to preview in place of the route's active ones
+	Rules []YieldRuleRequest `json:"rules"`
+	// Caps to preview in place of the route's
+	MinYieldPercent *float64 `json:"min_yield_percent"`
+	MaxYieldPercent *float64 `json:"max_yield_percent"`
+	// Load factors and days before departure to price; a default grid when omitted
+	LoadPercents []float64 `json:"load_percents"`
+	DaysBefore   []int     `json:"days_before"`
+}
+
+// YieldSimulationResult lists the price of a whole-route seat at each simulated load factor and booking horizon
+type YieldSimulationResult struct {
+	RouteID         int                 `json:"route_id"`
+	Fare            float64             `json:"fare"` // Static whole-route fare
+	MinYieldPercent *float64            `json:"min_yield_percent"`
+	MaxYieldPercent *float64            `json:"max_yield_percent"`
+	Rules           []models.YieldRule  `json:"rules"`
+	Quotes          []models.YieldQuote `json:"quotes"`
+}
+
+// CreateYieldRule godoc
+// @Summary Create a yield rule
+// @Description Add a price band to a route of the operator's company. Seats sold while the segment's load factor and the days left before departure fall within the band cost price_percent of the segment fare. Rules are evaluated by priority and the first matching one applies, within the route's min_yield_percent and max_yield_percent caps. The price is locked into each booking when it is made
+// @Tags yield-rules
+// @Accept json
+// @Produce json
+// @Param rule body YieldRuleRequest true "Yield rule data"
+// @Success 201 {object} models.YieldRule
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /yield-rules [post]
+func CreateYieldRule(c *gin.Context, db *sql.DB) {
+	var req YieldRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, err := repository.GetRouteByID(db, req.RouteID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+		return
+	}
+	if !canAccessCompany(c, route.CompanyID) {
+		return
+	}
+
+	rule := models.YieldRule{RouteID: route.ID}
+	req.apply(&rule)
+	if err := services.SaveYieldRule(db, &rule); err != nil {
+		respondYieldRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// GetYieldRules godoc
+// @Summary List yield rules of a route
+// @Description List the yield rules of a route of the operator's company in evaluation order, including inactive ones
+// @Tags yield-rules
+// @Produce json
+// @Param route_id query int true "Route ID"
+// @Success 200 {array} models.YieldRule
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /yield-rules [get]
+func GetYieldRules(c *gin.Context, db *sql.DB) {
+	routeID, _ := strconv.Atoi(c.Query("route_id"))
+	route, err := repository.GetRouteByID(db, routeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+		return
+	}
+	if !canAccessCompany(c, route.CompanyID) {
+		return
+	}
+
+	rules, err := repository.GetYieldRulesByRouteID(db, route.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpdateYieldRule godoc
+// @Summary Update a yield rule
+// @Description Update a yield rule of the operator's company. Bookings already made keep their price
+// @Tags yield-rules
+// @Accept json
+// @Produce json
+// @Param id path int true "Yield rule ID"
+// @Param rule body YieldRuleRequest true "Yield rule data"
+// @Success 200 {object} models.YieldRule
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /yield-rules/{id} [put]
+func UpdateYieldRule(c *gin.Context, db *sql.DB) {
+	var req YieldRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, ok := loadYieldRule(c, db)
+	if !ok {
+		return
+	}
+
+	req.apply(rule)
+	if err := services.SaveYieldRule(db, rule); err != nil {
+		respondYieldRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteYieldRule godoc
+// @Summary Delete a yield rule
+// @Description Delete a yield rule of the operator's company. Bookings already made keep their price
+// @Tags yield-rules
+// @Param id path int true "Yield rule ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /yield-rules/{id} [delete]
+func DeleteYieldRule(c *gin.Context, db *sql.DB) {
+	rule, ok := loadYieldRule(c, db)
+	if !ok {
+		return
+	}
+
+	if err := repository.DeleteYieldRule(db, rule.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SimulateYieldRules godoc
+// @Summary Simulate yield rules
+// @Description Preview the price of a whole-route seat of a route of the operator's company at each combination of load factor and days before departure, under the route's active rules and caps or under the rules and caps given. Nothing is stored
+// @Tags yield-rules
+// @Accept json
+// @Produce json
+// @Param simulation body YieldSimulationRequest true "Rules and scenarios to preview"
+// @Success 200 {object} YieldSimulationResult
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /yield-rules/simulate [post]
+func SimulateYieldRules(c *gin.Context, db *sql.DB) {
+	var req YieldSimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, err := services.GetRouteWithStops(db, req.RouteID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+		return
+	}
+	if !canAccessCompany(c, route.CompanyID) {
+		return
+	}
+
+	if req.MinYieldPercent != nil {
+		route.MinYieldPercent = req.MinYieldPercent
+	}
+	if req.MaxYieldPercent != nil {
+		route.MaxYieldPercent = req.MaxYieldPercent
+	}
+	if err := services.ValidateYieldCaps(route.MinYieldPercent, route.MaxYieldPercent); err != nil {
+		respondYieldRuleError(c, err)
+		return
+	}
+
+	var rules []models.YieldRule
+	if req.Rules == nil {
+		rules, err = repository.GetYieldRulesByRouteID(db, route.ID, true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	for _, ruleReq := range req.Rules {
+		rule := models.YieldRule{RouteID: route.ID}
+		ruleReq.apply(&rule)
+		if err := services.ValidateYieldRule(&rule); err != nil {
+			respondYieldRuleError(c, err)
+			return
+		}
+		rules = append(rules, rule)
+	}
+	for _, load := range req.LoadPercents {
+		if load < 0 || load > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "load_percents must be between 0 and 100"})
+			return
+		}
+	}
+	for _, days := range req.DaysBefore {
+		if days < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days_before cannot be negative"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, YieldSimulationResult{
+		RouteID:         route.ID,
+		Fare:            services.RouteFare(route),
+		MinYieldPercent: route.MinYieldPercent,
+		MaxYieldPercent: route.MaxYieldPercent,
+		Rules:           rules,
+		Quotes:          services.SimulateYield(route, rules, req.LoadPercents, req.DaysBefore),
+	})
+}
+
+// loadYieldRule loads the yield rule of the path and checks that its route belongs to the operator's company
+func loadYieldRule(c *gin.Context, db *sql.DB) (*models.YieldRule, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid yield rule ID"})
+		return nil, false
+	}
+
+	rule, err := repository.GetYieldRuleByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Yield rule not found"})
+		return nil, false
+	}
+	route, err := repository.GetRouteByID(db, rule.RouteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !canAccessCompany(c, route.CompanyID) {
+		return nil, false
+	}
+	return rule, true
+}
+
+func respondYieldRuleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidYieldRule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
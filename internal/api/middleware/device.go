@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceRequired only lets through requests carrying the token of an active vehicle device,
+// as "Authorization: Device <token>". It stores the device and its vehicle in the context.
+func DeviceRequired(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Device ")
+		if token == "" || token == c.GetHeader("Authorization") {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Device token required"})
+			c.Abort()
+			return
+		}
+
+		device, err := repository.GetActiveVehicleDeviceByToken(db, utils.HashToken(token))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid device token"})
+			c.Abort()
+			return
+		}
+		repository.TouchVehicleDevice(db, device.ID)
+
+		c.Set("device_id", device.ID)
+		c.Set("vehicle_id", device.VehicleID)
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// RoleRequired only lets through authenticated users with one of the given roles.
+// It must run after AuthRequired and stores the user's role and company in the context.
+func RoleRequired(db *sql.DB, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get("user_id")
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+
+		user, err := repository.GetUserByID(db, userID.(int))
+		if err != nil || !user.IsActive {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+
+		allowed := false
+		for _, role := range roles {
+			if user.Role == role {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		c.Set("role", user.Role)
+		if user.CompanyID != nil {
+			c.Set("company_id", *user.CompanyID)
+		}
+		c.Next()
+	}
+}
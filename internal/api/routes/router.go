@@ -2,10 +2,15 @@ package routes
 
 import (
 	"database/sql"
+	"log"
+	"time"
 
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/api/handlers"
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/api/middleware"
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/config"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,6 +24,30 @@ func SetupRoutes(router *gin.Engine, db *sql.DB, cfg *config.Config) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	connectionWindow := services.ConnectionWindow{
+		Min: time.Duration(cfg.MinConnectionMinutes) * time.Minute,
+		Max: time.Duration(cfg.MaxConnectionMinutes) * time.Minute,
+	}
+
+	checkInWindow := services.CheckInWindow{
+		OpensBefore:  time.Duration(cfg.CheckInOpensMinutes) * time.Minute,
+		ClosesBefore: time.Duration(cfg.CheckInClosesMinutes) * time.Minute,
+	}
+
+	waitlistClaimPeriod := time.Duration(cfg.WaitlistClaimMinutes) * time.Minute
+
+	// A key derived from JWT_SECRET would let anyone knowing the default secret forge tickets
+	if cfg.TicketSigningKey == "" {
+		if cfg.Environment == "production" {
+			log.Fatal("TICKET_SIGNING_KEY is required in production")
+		}
+		log.Println("TICKET_SIGNING_KEY is not set, deriving the ticket signing key from JWT_SECRET (development only)")
+	}
+	ticketKey, err := utils.LoadTicketKey(cfg.TicketSigningKey, cfg.JWTSecret)
+	if err != nil {
+		log.Fatalf("Invalid TICKET_SIGNING_KEY: %v", err)
+	}
+
 	// API v1 group
 	v1 := router.Group("/api/v1")
 	{
@@ -31,6 +60,7 @@ func SetupRoutes(router *gin.Engine, db *sql.DB, cfg *config.Config) {
 
 		// All routes public (no auth required)
 		v1.GET("/travels/search", func(c *gin.Context) { handlers.SearchAvailableTravels(c, db) })
+		v1.GET("/travels/itineraries", func(c *gin.Context) { handlers.SearchItineraries(c, db, connectionWindow) })
 		v1.GET("/travels/seats", func(c *gin.Context) { handlers.GetAvailableSeatsForSchedule(c, db) })
 		v1.GET("/companies", func(c *gin.Context) { handlers.GetAllCompanies(c, db) })
 		v1.POST("/companies", func(c *gin.Context) { handlers.CreateCompany(c, db) })
@@ -42,6 +72,7 @@ func SetupRoutes(router *gin.Engine, db *sql.DB, cfg *config.Config) {
 		v1.GET("/routes", func(c *gin.Context) { handlers.GetAllRoutes(c, db) })
 		v1.POST("/routes", func(c *gin.Context) { handlers.CreateRoute(c, db) })
 		v1.GET("/routes/:id", func(c *gin.Context) { handlers.GetRoute(c, db) })
+		v1.GET("/routes/:id/fare-families", func(c *gin.Context) { handlers.GetRouteFareFamilies(c, db) })
 		v1.PUT("/routes/:id", func(c *gin.Context) { handlers.UpdateRoute(c, db) })
 		v1.DELETE("/routes/:id", func(c *gin.Context) { handlers.DeleteRoute(c, db) })
 
@@ -51,13 +82,188 @@ func SetupRoutes(router *gin.Engine, db *sql.DB, cfg *config.Config) {
 		v1.GET("/users/search", func(c *gin.Context) { handlers.SearchUsers(c, db) })
 		v1.GET("/users/:id", func(c *gin.Context) { handlers.GetUser(c, db) })
 		v1.PUT("/users/:id", func(c *gin.Context) { handlers.UpdateUser(c, db) })
+		v1.PUT("/users/:id/role", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleAdmin), func(c *gin.Context) { handlers.UpdateUserRole(c, db) })
 		v1.DELETE("/users/:id", func(c *gin.Context) { handlers.DeleteUser(c, db) })
 
 		// Booking routes
 		v1.GET("/bookings", func(c *gin.Context) { handlers.GetBookings(c, db) })
-		v1.POST("/bookings", func(c *gin.Context) { handlers.CreateBooking(c, db) })
-		v1.GET("/bookings/:id", func(c *gin.Context) { handlers.GetBooking(c, db) })
-		v1.PUT("/bookings/:id", func(c *gin.Context) { handlers.UpdateBooking(c, db) })
-		v1.DELETE("/bookings/:id", func(c *gin.Context) { handlers.DeleteBooking(c, db) })
+		v1.POST("/bookings", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.CreateBooking(c, db, connectionWindow) })
+		v1.GET("/bookings/:id", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.GetBooking(c, db) })
+		v1.PUT("/bookings/:id", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.UpdateBooking(c, db) })
+		v1.DELETE("/bookings/:id", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.DeleteBooking(c, db) })
+		v1.POST("/bookings/:id/cancel", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.CancelBooking(c, db, waitlistClaimPeriod) })
+		v1.POST("/bookings/:id/exchange/quote", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.QuoteBookingExchange(c, db) })
+		v1.POST("/bookings/:id/exchange", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.ExchangeBooking(c, db, waitlistClaimPeriod) })
+		v1.GET("/bookings/:id/exchanges", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.GetBookingExchanges(c, db) })
+		v1.GET("/bookings/:id/tracking", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.GetBookingTracking(c, db) })
+		v1.GET("/bookings/:id/ancillaries", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.GetBookingAncillaries(c, db) })
+		v1.POST("/bookings/:id/ancillaries", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.AddBookingAncillaries(c, db) })
+
+		v1.GET("/bookings/:id/check-in", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.GetCheckIn(c, db, checkInWindow) })
+		v1.POST("/bookings/:id/check-in", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.CheckIn(c, db, ticketKey, checkInWindow) })
+
+		// Ticket routes
+		v1.GET("/tickets/public-key", func(c *gin.Context) { handlers.GetTicketPublicKey(c, ticketKey) })
+		v1.GET("/bookings/:id/tickets", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.GetBookingTickets(c, db, ticketKey) })
+		v1.GET("/bookings/:id/tickets/pdf", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.GetBookingTicketsPDF(c, db, ticketKey) })
+		v1.GET("/bookings/:id/tickets/:booking_seat_id/qr", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.GetBookingTicketQR(c, db, ticketKey) })
+
+		// Waitlist routes
+		waitlist := v1.Group("/waitlist", middleware.AuthRequired(cfg.JWTSecret))
+		{
+			waitlist.POST("", func(c *gin.Context) { handlers.JoinWaitlist(c, db) })
+			waitlist.GET("", func(c *gin.Context) { handlers.GetWaitlist(c, db) })
+			waitlist.POST("/claim", func(c *gin.Context) { handlers.ClaimWaitlistOffer(c, db) })
+			waitlist.DELETE("/:id", func(c *gin.Context) { handlers.LeaveWaitlist(c, db, waitlistClaimPeriod) })
+		}
+
+		// Group booking routes
+		groupBookings := v1.Group("/group-bookings", middleware.AuthRequired(cfg.JWTSecret))
+		{
+			groupBookings.POST("", func(c *gin.Context) { handlers.CreateGroupBooking(c, db) })
+			groupBookings.GET("", func(c *gin.Context) { handlers.GetGroupBookings(c, db) })
+			groupBookings.GET("/:id", func(c *gin.Context) { handlers.GetGroupBooking(c, db) })
+			groupBookings.POST("/:id/confirm", func(c *gin.Context) { handlers.ConfirmGroupBooking(c, db) })
+			groupBookings.PUT("/:id/passengers", func(c *gin.Context) { handlers.SetGroupPassengers(c, db) })
+			groupBookings.POST("/:id/balance", func(c *gin.Context) { handlers.PayGroupBalance(c, db) })
+			groupBookings.POST("/:id/cancel", func(c *gin.Context) { handlers.CancelGroupBooking(c, db) })
+		}
+
+		// Group request routes (operators)
+		groupRequests := v1.Group("/group-requests", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleOperator, models.RoleAdmin))
+		{
+			groupRequests.GET("", func(c *gin.Context) { handlers.GetCompanyGroupBookings(c, db) })
+			groupRequests.GET("/:id", func(c *gin.Context) { handlers.GetCompanyGroupBooking(c, db) })
+			groupRequests.POST("/:id/quote", func(c *gin.Context) { handlers.QuoteGroupBooking(c, db) })
+			groupRequests.POST("/:id/decline", func(c *gin.Context) { handlers.DeclineGroupBooking(c, db) })
+		}
+
+		// Ancillary routes (operators)
+		ancillaries := v1.Group("/ancillaries", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleOperator, models.RoleAdmin))
+		{
+			ancillaries.POST("", func(c *gin.Context) { handlers.CreateAncillary(c, db) })
+			ancillaries.GET("", func(c *gin.Context) { handlers.GetAncillaries(c, db) })
+			ancillaries.PUT("/:id", func(c *gin.Context) { handlers.UpdateAncillary(c, db) })
+		}
+
+		// Fare family routes (operators)
+		fareFamilies := v1.Group("/fare-families", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleOperator, models.RoleAdmin))
+		{
+			fareFamilies.POST("", func(c *gin.Context) { handlers.CreateFareFamily(c, db) })
+			fareFamilies.GET("", func(c *gin.Context) { handlers.GetFareFamilies(c, db) })
+			fareFamilies.PUT("/:id", func(c *gin.Context) { handlers.UpdateFareFamily(c, db) })
+		}
+
+		// Yield rule routes (operators)
+		yieldRules := v1.Group("/yield-rules", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleOperator, models.RoleAdmin))
+		{
+			yieldRules.POST("", func(c *gin.Context) { handlers.CreateYieldRule(c, db) })
+			yieldRules.GET("", func(c *gin.Context) { handlers.GetYieldRules(c, db) })
+			yieldRules.POST("/simulate", func(c *gin.Context) { handlers.SimulateYieldRules(c, db) })
+			yieldRules.PUT("/:id", func(c *gin.Context) { handlers.UpdateYieldRule(c, db) })
+			yieldRules.DELETE("/:id", func(c *gin.Context) { handlers.DeleteYieldRule(c, db) })
+		}
+
+		// Journey routes
+		v1.GET("/journeys/:id", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.GetJourney(c, db) })
+
+		// Trip routes (operators)
+		v1.GET("/trips/:id", func(c *gin.Context) { handlers.GetTrip(c, db) })
+		trips := v1.Group("/trips", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleOperator, models.RoleAdmin))
+		{
+			trips.POST("", func(c *gin.Context) { handlers.CreateTrip(c, db) })
+			trips.POST("/:id/cancel", func(c *gin.Context) { handlers.CancelTrip(c, db) })
+			trips.PUT("/:id/vehicle", func(c *gin.Context) { handlers.ReassignTripVehicle(c, db) })
+			trips.POST("/:id/delay", func(c *gin.Context) { handlers.DelayTrip(c, db, cfg.DelayCompensationMinutes) })
+			trips.POST("/:id/positions", func(c *gin.Context) { handlers.ReportTripPosition(c, db) })
+			trips.GET("/:id/positions", func(c *gin.Context) { handlers.GetTripPositions(c, db) })
+			trips.POST("/:id/boardings", func(c *gin.Context) { handlers.ScanTicket(c, db, ticketKey) })
+			trips.POST("/:id/boardings/sync", func(c *gin.Context) { handlers.SyncTripBoardings(c, db, ticketKey) })
+			trips.GET("/:id/boardings", func(c *gin.Context) { handlers.GetTripBoardings(c, db) })
+			trips.GET("/:id/waitlist", func(c *gin.Context) { handlers.GetTripWaitlist(c, db) })
+			trips.POST("/:id/seat-blocks", func(c *gin.Context) { handlers.CreateTripSeatBlock(c, db) })
+			trips.GET("/:id/seat-blocks", func(c *gin.Context) { handlers.GetTripSeatBlocks(c, db) })
+			trips.DELETE("/:id/seat-blocks/:block_id", func(c *gin.Context) { handlers.DeleteTripSeatBlock(c, db) })
+			trips.GET("/:id/manifest", func(c *gin.Context) { handlers.GetTripManifest(c, db) })
+			trips.GET("/:id/alternative-vehicles", func(c *gin.Context) { handlers.GetTripAlternativeVehicles(c, db) })
+			trips.POST("/:id/crew", func(c *gin.Context) { handlers.AssignTripCrew(c, db, cfg.MaxDailyDrivingMinutes) })
+			trips.GET("/:id/crew", func(c *gin.Context) { handlers.GetTripCrew(c, db) })
+			trips.DELETE("/:id/crew/:crew_member_id", func(c *gin.Context) { handlers.RemoveTripCrew(c, db) })
+		}
+
+		// Vehicle routes (operators)
+		vehicles := v1.Group("/vehicles", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleOperator, models.RoleAdmin))
+		{
+			vehicles.GET("/conflicts", func(c *gin.Context) { handlers.GetVehicleConflicts(c, db) })
+			vehicles.GET("/:id/timeline", func(c *gin.Context) { handlers.GetVehicleTimeline(c, db) })
+			vehicles.POST("/:id/maintenance", func(c *gin.Context) { handlers.CreateMaintenanceWindow(c, db) })
+			vehicles.GET("/:id/maintenance", func(c *gin.Context) { handlers.GetMaintenanceWindows(c, db) })
+			vehicles.GET("/:id/maintenance/warnings", func(c *gin.Context) { handlers.GetMaintenanceWarnings(c, db) })
+			vehicles.DELETE("/:id/maintenance/:window_id", func(c *gin.Context) { handlers.DeleteMaintenanceWindow(c, db) })
+			vehicles.POST("/:id/devices", func(c *gin.Context) { handlers.RegisterVehicleDevice(c, db) })
+			vehicles.GET("/:id/devices", func(c *gin.Context) { handlers.GetVehicleDevices(c, db) })
+			vehicles.DELETE("/:id/devices/:device_id", func(c *gin.Context) { handlers.RevokeVehicleDevice(c, db) })
+		}
+
+		// Crew routes (operators), and the duties of the authenticated crew member
+		v1.GET("/crew/me/duties", middleware.AuthRequired(cfg.JWTSecret), func(c *gin.Context) { handlers.GetMyDuties(c, db) })
+		crew := v1.Group("/crew", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleOperator, models.RoleAdmin))
+		{
+			crew.POST("", func(c *gin.Context) { handlers.CreateCrewMember(c, db) })
+			crew.GET("", func(c *gin.Context) { handlers.GetCrewMembers(c, db) })
+			crew.GET("/:id", func(c *gin.Context) { handlers.GetCrewMember(c, db) })
+			crew.PUT("/:id", func(c *gin.Context) { handlers.UpdateCrewMember(c, db) })
+			crew.GET("/:id/duties", func(c *gin.Context) { handlers.GetCrewMemberDuties(c, db) })
+		}
+
+		// Report routes (operators)
+		reports := v1.Group("/reports", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleOperator, models.RoleAdmin))
+		{
+			reports.GET("/attendance", func(c *gin.Context) { handlers.GetAttendanceReport(c, db) })
+		}
+
+		// On-board device routes
+		v1.POST("/devices/positions", middleware.DeviceRequired(db), func(c *gin.Context) { handlers.ReportDevicePositions(c, db) })
+		v1.POST("/devices/boardings/sync", middleware.DeviceRequired(db), func(c *gin.Context) { handlers.SyncDeviceBoardings(c, db, ticketKey) })
+
+		// Schedule and calendar routes (operators)
+		v1.GET("/schedules/:id", func(c *gin.Context) { handlers.GetSchedule(c, db) })
+		v1.GET("/schedules/:id/ancillaries", func(c *gin.Context) { handlers.GetDepartureAncillaries(c, db) })
+		schedules := v1.Group("/schedules", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleOperator, models.RoleAdmin))
+		{
+			schedules.POST("", func(c *gin.Context) { handlers.CreateSchedule(c, db) })
+			schedules.PUT("/:id", func(c *gin.Context) { handlers.UpdateSchedule(c, db) })
+			schedules.POST("/:id/seat-blocks", func(c *gin.Context) { handlers.CreateScheduleSeatBlock(c, db) })
+			schedules.GET("/:id/seat-blocks", func(c *gin.Context) { handlers.GetScheduleSeatBlocks(c, db) })
+			schedules.DELETE("/:id/seat-blocks/:block_id", func(c *gin.Context) { handlers.DeleteScheduleSeatBlock(c, db) })
+		}
+		calendars := v1.Group("/calendars", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleOperator, models.RoleAdmin))
+		{
+			calendars.POST("", func(c *gin.Context) { handlers.CreateCalendar(c, db) })
+			calendars.GET("", func(c *gin.Context) { handlers.GetCalendars(c, db) })
+			calendars.GET("/:id", func(c *gin.Context) { handlers.GetCalendar(c, db) })
+			calendars.PUT("/:id", func(c *gin.Context) { handlers.UpdateCalendar(c, db) })
+			calendars.DELETE("/:id", func(c *gin.Context) { handlers.DeleteCalendar(c, db) })
+			calendars.POST("/:id/exceptions", func(c *gin.Context) { handlers.AddCalendarException(c, db) })
+			calendars.DELETE("/:id/exceptions/:exception_id", func(c *gin.Context) { handlers.DeleteCalendarException(c, db) })
+		}
+
+		// GTFS feed routes (admins)
+		gtfs := v1.Group("/admin/gtfs", middleware.AuthRequired(cfg.JWTSecret), middleware.RoleRequired(db, models.RoleAdmin))
+		{
+			gtfs.POST("/import", func(c *gin.Context) { handlers.ImportGTFS(c, db) })
+			gtfs.GET("/export", func(c *gin.Context) { handlers.ExportGTFS(c, db) })
+		}
+
+		// GTFS-Realtime feeds (public, for journey planners)
+		v1.GET("/gtfs-rt/trip-updates", func(c *gin.Context) { handlers.GetTripUpdatesFeed(c, db) })
+		v1.GET("/gtfs-rt/vehicle-positions", func(c *gin.Context) { handlers.GetVehiclePositionsFeed(c, db) })
+
+		// Notification routes
+		notifications := v1.Group("/notifications", middleware.AuthRequired(cfg.JWTSecret))
+		{
+			notifications.GET("", func(c *gin.Context) { handlers.GetNotifications(c, db) })
+			notifications.PUT("/:id/read", func(c *gin.Context) { handlers.MarkNotificationRead(c, db) })
+		}
 	}
-}
\ No newline at end of file
+}
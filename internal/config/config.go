@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +13,36 @@ type Config struct {
 	Port        string
 	Environment string
 	JWTSecret   string
+
+	// Delay (in minutes) from which passengers are offered a refund or rebooking
+	DelayCompensationMinutes int
+
+	// Connection time window (in minutes) between the legs of a journey
+	MinConnectionMinutes int
+	MaxConnectionMinutes int
+
+	// Driving time (in minutes) a driver may accumulate within 24 hours
+	MaxDailyDrivingMinutes int
+
+	// Online check-in opens and closes these many minutes before boarding
+	CheckInOpensMinutes  int
+	CheckInClosesMinutes int
+
+	// Bookings are closed as completed or no-show this many minutes after departure,
+	// by a job running every NoShowJobIntervalMinutes (0 disables it)
+	NoShowGraceMinutes       int
+	NoShowJobIntervalMinutes int
+
+	// Waitlisted passengers have WaitlistClaimMinutes to claim an offered seat. A job running every
+	// WaitlistJobIntervalMinutes (0 disables it) offers freed seats and expires lapsed offers
+	WaitlistClaimMinutes       int
+	WaitlistJobIntervalMinutes int
+
+	// How often group booking deadlines are enforced (0 disables it)
+	GroupBookingJobIntervalMinutes int
+
+	// Base64 Ed25519 seed signing ticket QR codes; required in production, derived from JWTSecret elsewhere
+	TicketSigningKey string
 }
 
 func Load() *Config {
@@ -25,6 +56,26 @@ func Load() *Config {
 		Port:        getEnv("PORT", "8080"),
 		Environment: getEnv("ENVIRONMENT", "development"),
 		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key"),
+
+		DelayCompensationMinutes: getEnvInt("DELAY_COMPENSATION_MINUTES", 120),
+
+		MinConnectionMinutes: getEnvInt("MIN_CONNECTION_MINUTES", 30),
+		MaxConnectionMinutes: getEnvInt("MAX_CONNECTION_MINUTES", 240),
+
+		MaxDailyDrivingMinutes: getEnvInt("MAX_DAILY_DRIVING_MINUTES", 540),
+
+		CheckInOpensMinutes:  getEnvInt("CHECK_IN_OPENS_MINUTES", 2880),
+		CheckInClosesMinutes: getEnvInt("CHECK_IN_CLOSES_MINUTES", 30),
+
+		NoShowGraceMinutes:       getEnvInt("NO_SHOW_GRACE_MINUTES", 120),
+		NoShowJobIntervalMinutes: getEnvInt("NO_SHOW_JOB_INTERVAL_MINUTES", 15),
+
+		WaitlistClaimMinutes:       getEnvInt("WAITLIST_CLAIM_MINUTES", 30),
+		WaitlistJobIntervalMinutes: getEnvInt("WAITLIST_JOB_INTERVAL_MINUTES", 5),
+
+		GroupBookingJobIntervalMinutes: getEnvInt("GROUP_BOOKING_JOB_INTERVAL_MINUTES", 15),
+
+		TicketSigningKey: getEnv("TICKET_SIGNING_KEY", ""),
 	}
 }
 
@@ -33,4 +84,14 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid value for %s, using default %d", key, defaultValue)
+	}
+	return defaultValue
+}
@@ -0,0 +1,95 @@
+// Package documents renders booking data as files for download and printing
+package documents
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+	"github.com/go-pdf/fpdf"
+)
+
+var manifestHeader = []string{"seat", "booking_code", "passenger_name", "passenger_document", "passenger_phone",
+	"boarding_stop_sequence", "boarding_stop", "alighting_stop_sequence", "alighting_stop", "booking_status", "payment_status", "checked_in_at", "boarded_at", "ancillaries"}
+
+// ManifestCSV writes the passengers of a manifest as CSV, one row per booked seat
+func ManifestCSV(w io.Writer, manifest *services.Manifest) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(manifestHeader); err != nil {
+		return err
+	}
+	for _, entry := range manifest.Passengers {
+		record := []string{
+			entry.SeatNumber, entry.BookingCode, entry.PassengerName, entry.PassengerDocument, entry.PassengerPhone,
+			strconv.Itoa(entry.BoardingStopSequence), entry.BoardingStop,
+			strconv.Itoa(entry.AlightingStopSequence), entry.AlightingStop,
+			entry.BookingStatus, entry.PaymentStatus, formatTimestamp(entry.CheckedInAt), formatTimestamp(entry.BoardedAt),
+			entry.Ancillaries,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ManifestPDF writes a printable A4 manifest
+func ManifestPDF(w io.Writer, manifest *services.Manifest) error {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle("Passenger manifest", true)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, tr(fmt.Sprintf("Passenger manifest - %s to %s", manifest.OriginCity, manifest.DestinationCity)), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, tr(fmt.Sprintf("Trip %d - departure %s - vehicle %s - %d passengers",
+		manifest.Trip.ID, manifest.Trip.DepartureDatetime.Format("2006-01-02 15:04 MST"), manifest.LicensePlate, len(manifest.Passengers))), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Generated "+manifest.GeneratedAt.Format(utils.DateLayout+" 15:04 MST"), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	columns := []struct {
+		title string
+		width float64
+	}{
+		{"Seat", 15}, {"Booking", 30}, {"Passenger", 45}, {"Document", 30}, {"Boarding", 45}, {"Alighting", 45}, {"Status", 27}, {"Extras", 40},
+	}
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	for _, column := range columns {
+		pdf.CellFormat(column.width, 7, column.title, "1", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, entry := range manifest.Passengers {
+		status := entry.BookingStatus
+		switch {
+		case entry.BoardedAt != nil:
+			status = "boarded"
+		case entry.CheckedInAt != nil:
+			status = "checked in"
+		}
+		values := []string{entry.SeatNumber, entry.BookingCode, entry.PassengerName, entry.PassengerDocument,
+			entry.BoardingStop, entry.AlightingStop, status, entry.Ancillaries}
+		for i, column := range columns {
+			pdf.CellFormat(column.width, 6, tr(values[i]), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	return pdf.Output(w)
+}
+
+// formatTimestamp formats an optional time as RFC 3339 in UTC, or as an empty string
+func formatTimestamp(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
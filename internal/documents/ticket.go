@@ -0,0 +1,57 @@
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/go-pdf/fpdf"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// TicketQRSize is the width and height in pixels of ticket QR codes
+const TicketQRSize = 512
+
+// TicketQR encodes the signed payload of a ticket as a PNG QR code
+func TicketQR(ticket services.Ticket) ([]byte, error) {
+	return qrcode.Encode(ticket.Payload, qrcode.Medium, TicketQRSize)
+}
+
+// TicketsPDF writes a printable A4 page per ticket with its QR code
+func TicketsPDF(w io.Writer, tickets []services.Ticket) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle("Tickets", true)
+
+	for _, ticket := range tickets {
+		png, err := TicketQR(ticket)
+		if err != nil {
+			return err
+		}
+
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "B", 18)
+		pdf.CellFormat(0, 12, tr(fmt.Sprintf("%s to %s", ticket.OriginStop, ticket.DestinationStop)), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 12)
+		lines := []string{
+			"Passenger: " + ticket.Claims.PassengerName,
+			"Booking: " + ticket.Claims.BookingCode,
+			"Seat: " + ticket.Claims.SeatNumber,
+			"Departure: " + ticket.DepartureDatetime.Format("2006-01-02 15:04 MST"),
+			"Arrival: " + ticket.ArrivalDatetime.Format("2006-01-02 15:04 MST"),
+		}
+		if len(ticket.Ancillaries) > 0 {
+			lines = append(lines, "Extras: "+services.AncillarySummary(ticket.Ancillaries))
+		}
+		for _, line := range lines {
+			pdf.CellFormat(0, 8, tr(line), "", 1, "L", false, 0, "")
+		}
+
+		name := fmt.Sprintf("ticket-%d", ticket.Claims.BookingSeatID)
+		pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+		pdf.ImageOptions(name, 55, pdf.GetY()+10, 100, 100, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	}
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,351 @@
+package gtfs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+var (
+	ErrAgencyURLRequired  = errors.New("GTFS agencies need a URL")
+	ErrMissingCoordinates = errors.New("every exported stop needs coordinates")
+)
+
+// ExportOptions completes company data that GTFS requires but the booking model does not hold
+type ExportOptions struct {
+	AgencyURL string
+	// ISO 4217 currency of route prices; fares are left out without one
+	Currency string
+}
+
+// ExportData is the timetable of a company to export
+type ExportData struct {
+	Company  models.Company
+	AgencyID string
+	Routes   []ExportRoute
+	// Exceptions of the calendars used by the schedules, by calendar id
+	Exceptions map[int][]models.CalendarException
+}
+
+// ExportRoute is a route with its stops and schedules. Empty GTFS ids are derived from database ids.
+type ExportRoute struct {
+	GTFSID    string
+	Route     models.Route
+	Schedules []ExportSchedule
+}
+
+type ExportSchedule struct {
+	GTFSID   string
+	Schedule models.Schedule
+}
+
+// Export loads the active routes and schedules of a company and builds its feed.
+// Schedules that cannot be represented are left out and reported as warnings.
+func Export(db repository.DBInterface, companyID int, options ExportOptions) (*Feed, []string, error) {
+	company, err := repository.GetCompanyByID(db, companyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := ExportData{Company: *company, Exceptions: make(map[int][]models.CalendarException)}
+	if data.AgencyID, err = repository.GetCompanyGTFSID(db, companyID); err != nil {
+		return nil, nil, err
+	}
+
+	routeGTFSIDs, err := repository.GetRouteGTFSIDs(db, companyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	scheduleGTFSIDs, err := repository.GetScheduleGTFSIDs(db, companyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	routes, err := repository.GetRoutesByCompanyID(db, companyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, route := range routes {
+		if !route.IsActive {
+			continue
+		}
+		exportRoute := ExportRoute{GTFSID: gtfsIDOf(routeGTFSIDs, route.ID)}
+		withStops, err := services.GetRouteWithStops(db, route.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		exportRoute.Route = *withStops
+
+		schedules, err := repository.GetSchedulesByRouteID(db, route.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, schedule := range schedules {
+			if !schedule.IsActive {
+				continue
+			}
+			if schedule.CalendarID != nil {
+				if _, loaded := data.Exceptions[*schedule.CalendarID]; !loaded {
+					if data.Exceptions[*schedule.CalendarID], err = repository.GetCalendarExceptions(db, *schedule.CalendarID); err != nil {
+						return nil, nil, err
+					}
+				}
+			}
+			exportRoute.Schedules = append(exportRoute.Schedules, ExportSchedule{GTFSID: gtfsIDOf(scheduleGTFSIDs, schedule.ID), Schedule: schedule})
+		}
+		data.Routes = append(data.Routes, exportRoute)
+	}
+
+	return BuildFeed(data, options)
+}
+
+// RouteID is the GTFS route_id of a route: its imported id, or one derived from its database id
+func RouteID(gtfsID string, routeID int) string {
+	if gtfsID != "" {
+		return gtfsID
+	}
+	return "R" + strconv.Itoa(routeID)
+}
+
+// TripID is the GTFS trip_id of a schedule: its imported id, or one derived from its database id
+func TripID(gtfsID string, scheduleID int) string {
+	if gtfsID != "" {
+		return gtfsID
+	}
+	return "T" + strconv.Itoa(scheduleID)
+}
+
+// StopID is the GTFS stop_id of a route stop
+func StopID(stop models.RouteStop) string {
+	if stop.GTFSStopID != "" {
+		return stop.GTFSStopID
+	}
+	return "S" + strconv.Itoa(stop.ID)
+}
+
+func gtfsIDOf(ids map[string]int, id int) string {
+	for gtfsID, candidate := range ids {
+		if candidate == id {
+			return gtfsID
+		}
+	}
+	return ""
+}
+
+// BuildFeed converts a company timetable into a GTFS feed. Stop times count from midnight of the
+// schedule's day in the agency zone, which is the zone of the first exported stop.
+func BuildFeed(data ExportData, options ExportOptions) (*Feed, []string, error) {
+	if options.AgencyURL == "" {
+		return nil, nil, ErrAgencyURLRequired
+	}
+
+	var warnings []string
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	agency := Agency{
+		AgencyID:       data.AgencyID,
+		AgencyName:     data.Company.Name,
+		AgencyURL:      options.AgencyURL,
+		AgencyTimezone: "UTC",
+		AgencyPhone:    data.Company.Phone,
+		AgencyEmail:    data.Company.Email,
+	}
+	if agency.AgencyID == "" {
+		agency.AgencyID = "C" + strconv.Itoa(data.Company.ID)
+	}
+	for _, route := range data.Routes {
+		if len(route.Route.Stops) > 0 && len(route.Schedules) > 0 {
+			agency.AgencyTimezone = route.Route.Stops[0].TimeZone
+			break
+		}
+	}
+	agencyLocation, err := utils.LoadLocation(agency.AgencyTimezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	feed := &Feed{Agencies: []Agency{agency}}
+	stopIDs := make(map[string]string)
+	serviceIDs := make(map[string]string)
+	for _, exportRoute := range data.Routes {
+		route := exportRoute.Route
+		if len(route.Stops) < 2 || len(exportRoute.Schedules) == 0 {
+			continue
+		}
+
+		routeID := RouteID(exportRoute.GTFSID, route.ID)
+
+		routeStopIDs := make([]string, len(route.Stops))
+		for i, stop := range route.Stops {
+			if routeStopIDs[i], err = exportStop(feed, stopIDs, stop, agency.AgencyTimezone); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		trips := 0
+		for _, exportSchedule := range exportRoute.Schedules {
+			schedule := exportSchedule.Schedule
+			tripID := TripID(exportSchedule.GTFSID, schedule.ID)
+
+			firstLocation, err := utils.LoadLocation(route.Stops[0].TimeZone)
+			if err != nil {
+				return nil, nil, err
+			}
+			departure, err := utils.LocalDatetime(schedule.ValidFrom, schedule.DepartureTime, firstLocation)
+			if err != nil {
+				return nil, nil, err
+			}
+			serviceDay := time.Date(schedule.ValidFrom.Year(), schedule.ValidFrom.Month(), schedule.ValidFrom.Day(), 0, 0, 0, 0, agencyLocation)
+			start := int(departure.Sub(serviceDay).Seconds())
+			if start < 0 {
+				warn("trip %s: departs before its service day starts in %s, left out", tripID, agency.AgencyTimezone)
+				continue
+			}
+
+			serviceID, ok := exportService(feed, serviceIDs, schedule, data.Exceptions)
+			if !ok {
+				warn("trip %s: runs on no date, left out", tripID)
+				continue
+			}
+
+			feed.Trips = append(feed.Trips, Trip{RouteID: routeID, ServiceID: serviceID, TripID: tripID, TripHeadsign: route.DestinationCity})
+			for i, stop := range route.Stops {
+				clock := FormatTime(start + stop.OffsetMinutes*60)
+				feed.StopTimes = append(feed.StopTimes, StopTime{
+					TripID:        tripID,
+					ArrivalTime:   clock,
+					DepartureTime: clock,
+					StopID:        routeStopIDs[i],
+					StopSequence:  strconv.Itoa(stop.StopSequence),
+				})
+			}
+			trips++
+		}
+		if trips == 0 {
+			continue
+		}
+
+		feed.Routes = append(feed.Routes, Route{
+			RouteID:       routeID,
+			AgencyID:      agency.AgencyID,
+			RouteLongName: route.OriginCity + " - " + route.DestinationCity,
+			RouteType:     BusRouteType,
+		})
+		if options.Currency != "" && route.BasePrice > 0 {
+			fareID := "F" + routeID
+			feed.FareAttributes = append(feed.FareAttributes, FareAttribute{
+				FareID:        fareID,
+				Price:         strconv.FormatFloat(route.BasePrice, 'f', 2, 64),
+				CurrencyType:  options.Currency,
+				PaymentMethod: "1", // paid before boarding
+				Transfers:     "0",
+				AgencyID:      agency.AgencyID,
+			})
+			feed.FareRules = append(feed.FareRules, FareRule{FareID: fareID, RouteID: routeID})
+		}
+	}
+
+	return feed, warnings, nil
+}
+
+// exportStop adds a stop, and its city as parent station when it differs from the terminal, once per feed
+func exportStop(feed *Feed, stopIDs map[string]string, stop models.RouteStop, agencyTimezone string) (string, error) {
+	key := strings.Join([]string{stop.GTFSStopID, stop.City, stop.Terminal, stop.TimeZone}, "|")
+	if id, ok := stopIDs[key]; ok {
+		return id, nil
+	}
+	if stop.Latitude == nil || stop.Longitude == nil {
+		return "", fmt.Errorf("%w: %s, %s", ErrMissingCoordinates, stop.Terminal, stop.City)
+	}
+
+	id := StopID(stop)
+	timezone := stop.TimeZone
+	if timezone == agencyTimezone {
+		timezone = ""
+	}
+	lat := strconv.FormatFloat(*stop.Latitude, 'f', 6, 64)
+	lon := strconv.FormatFloat(*stop.Longitude, 'f', 6, 64)
+
+	parentID := ""
+	if stop.Terminal != "" && stop.Terminal != stop.City {
+		parentKey := "station|" + stop.City + "|" + stop.TimeZone
+		if parentID = stopIDs[parentKey]; parentID == "" {
+			parentID = "P" + strconv.Itoa(len(stopIDs)+1)
+			stopIDs[parentKey] = parentID
+			feed.Stops = append(feed.Stops, Stop{StopID: parentID, StopName: stop.City, StopLat: lat, StopLon: lon, LocationType: "1", StopTimezone: timezone})
+		}
+	}
+
+	name := stop.Terminal
+	if name == "" {
+		name = stop.City
+	}
+	feed.Stops = append(feed.Stops, Stop{StopID: id, StopName: name, StopLat: lat, StopLon: lon, ParentStation: parentID, StopTimezone: timezone})
+	stopIDs[key] = id
+	return id, nil
+}
+
+// exportService returns the service of a schedule, adding it on first use. Schedules with the same days,
+// validity and calendar share a service. Calendar-only schedules are described by calendar_dates alone.
+func exportService(feed *Feed, serviceIDs map[string]string, schedule models.Schedule, exceptions map[int][]models.CalendarException) (string, bool) {
+	validUntil := schedule.ValidFrom.AddDate(1, 0, 0)
+	if schedule.ValidUntil != nil {
+		validUntil = *schedule.ValidUntil
+	}
+	calendarID := 0
+	if schedule.CalendarID != nil {
+		calendarID = *schedule.CalendarID
+	}
+
+	key := fmt.Sprint(schedule.DaysOfWeek, schedule.ValidFrom.Format(DateLayout), validUntil.Format(DateLayout), calendarID)
+	if id, ok := serviceIDs[key]; ok {
+		return id, true
+	}
+
+	id := "SV" + strconv.Itoa(len(serviceIDs)+1)
+	var dates []CalendarDate
+	for _, exception := range exceptions[calendarID] {
+		exceptionType := "1"
+		if exception.ExceptionType == models.ExceptionTypeRemoved {
+			exceptionType = "2"
+		}
+		dates = append(dates, CalendarDate{ServiceID: id, Date: exception.ExceptionDate.Format(DateLayout), ExceptionType: exceptionType})
+	}
+
+	if len(schedule.DaysOfWeek) == 0 {
+		added := false
+		for _, date := range dates {
+			added = added || date.ExceptionType == "1"
+		}
+		if !added {
+			return "", false
+		}
+	} else {
+		days := make([]string, 7)
+		for i := range days {
+			days[i] = "0"
+		}
+		for _, day := range schedule.DaysOfWeek {
+			days[day-1] = "1"
+		}
+		feed.Calendars = append(feed.Calendars, Calendar{
+			ServiceID: id,
+			Monday:    days[0], Tuesday: days[1], Wednesday: days[2], Thursday: days[3],
+			Friday: days[4], Saturday: days[5], Sunday: days[6],
+			StartDate: schedule.ValidFrom.Format(DateLayout),
+			EndDate:   validUntil.Format(DateLayout),
+		})
+	}
+
+	feed.CalendarDates = append(feed.CalendarDates, dates...)
+	serviceIDs[key] = id
+	return id, true
+}
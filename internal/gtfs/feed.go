@@ -0,0 +1,281 @@
+// Package gtfs reads and writes GTFS static feeds and maps them onto companies, routes,
+// schedules and calendars.
+package gtfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+var ErrMissingFile = errors.New("required GTFS file is missing")
+
+// Agency is a row of agency.txt
+type Agency struct {
+	AgencyID       string `csv:"agency_id"`
+	AgencyName     string `csv:"agency_name"`
+	AgencyURL      string `csv:"agency_url"`
+	AgencyTimezone string `csv:"agency_timezone"`
+	AgencyLang     string `csv:"agency_lang"`
+	AgencyPhone    string `csv:"agency_phone"`
+	AgencyEmail    string `csv:"agency_email"`
+}
+
+// Stop is a row of stops.txt
+type Stop struct {
+	StopID        string `csv:"stop_id"`
+	StopName      string `csv:"stop_name"`
+	StopLat       string `csv:"stop_lat"`
+	StopLon       string `csv:"stop_lon"`
+	LocationType  string `csv:"location_type"`
+	ParentStation string `csv:"parent_station"`
+	StopTimezone  string `csv:"stop_timezone"`
+}
+
+// Route is a row of routes.txt
+type Route struct {
+	RouteID        string `csv:"route_id"`
+	AgencyID       string `csv:"agency_id"`
+	RouteShortName string `csv:"route_short_name"`
+	RouteLongName  string `csv:"route_long_name"`
+	RouteType      string `csv:"route_type"`
+}
+
+// Trip is a row of trips.txt
+type Trip struct {
+	RouteID      string `csv:"route_id"`
+	ServiceID    string `csv:"service_id"`
+	TripID       string `csv:"trip_id"`
+	TripHeadsign string `csv:"trip_headsign"`
+}
+
+// StopTime is a row of stop_times.txt
+type StopTime struct {
+	TripID        string `csv:"trip_id"`
+	ArrivalTime   string `csv:"arrival_time"`
+	DepartureTime string `csv:"departure_time"`
+	StopID        string `csv:"stop_id"`
+	StopSequence  string `csv:"stop_sequence"`
+}
+
+// Calendar is a row of calendar.txt
+type Calendar struct {
+	ServiceID string `csv:"service_id"`
+	Monday    string `csv:"monday"`
+	Tuesday   string `csv:"tuesday"`
+	Wednesday string `csv:"wednesday"`
+	Thursday  string `csv:"thursday"`
+	Friday    string `csv:"friday"`
+	Saturday  string `csv:"saturday"`
+	Sunday    string `csv:"sunday"`
+	StartDate string `csv:"start_date"`
+	EndDate   string `csv:"end_date"`
+}
+
+// CalendarDate is a row of calendar_dates.txt
+type CalendarDate struct {
+	ServiceID     string `csv:"service_id"`
+	Date          string `csv:"date"`
+	ExceptionType string `csv:"exception_type"` // 1 added, 2 removed
+}
+
+// FareAttribute is a row of fare_attributes.txt
+type FareAttribute struct {
+	FareID        string `csv:"fare_id"`
+	Price         string `csv:"price"`
+	CurrencyType  string `csv:"currency_type"`
+	PaymentMethod string `csv:"payment_method"`
+	Transfers     string `csv:"transfers"`
+	AgencyID      string `csv:"agency_id"`
+}
+
+// FareRule is a row of fare_rules.txt
+type FareRule struct {
+	FareID  string `csv:"fare_id"`
+	RouteID string `csv:"route_id"`
+}
+
+// Feed holds the files of a GTFS static feed used by the booking model
+type Feed struct {
+	Agencies       []Agency
+	Stops          []Stop
+	Routes         []Route
+	Trips          []Trip
+	StopTimes      []StopTime
+	Calendars      []Calendar
+	CalendarDates  []CalendarDate
+	FareAttributes []FareAttribute
+	FareRules      []FareRule
+}
+
+// ReadFeed parses a GTFS zip archive
+func ReadFeed(r io.ReaderAt, size int64) (*Feed, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		// Some producers nest the files in a folder
+		name := file.Name[strings.LastIndex(file.Name, "/")+1:]
+		files[name] = file
+	}
+
+	feed := &Feed{}
+	tables := []struct {
+		name     string
+		required bool
+		target   interface{}
+	}{
+		{"agency.txt", true, &feed.Agencies},
+		{"stops.txt", true, &feed.Stops},
+		{"routes.txt", true, &feed.Routes},
+		{"trips.txt", true, &feed.Trips},
+		{"stop_times.txt", true, &feed.StopTimes},
+		{"calendar.txt", false, &feed.Calendars},
+		{"calendar_dates.txt", false, &feed.CalendarDates},
+		{"fare_attributes.txt", false, &feed.FareAttributes},
+		{"fare_rules.txt", false, &feed.FareRules},
+	}
+	for _, table := range tables {
+		file, ok := files[table.name]
+		if !ok {
+			if table.required {
+				return nil, fmt.Errorf("%w: %s", ErrMissingFile, table.name)
+			}
+			continue
+		}
+		if err := readTable(file, table.target); err != nil {
+			return nil, fmt.Errorf("%s: %w", table.name, err)
+		}
+	}
+	if len(feed.Calendars) == 0 && len(feed.CalendarDates) == 0 {
+		return nil, fmt.Errorf("%w: calendar.txt or calendar_dates.txt", ErrMissingFile)
+	}
+
+	return feed, nil
+}
+
+// WriteFeed writes a feed as a GTFS zip archive. Optional files without rows are left out.
+func WriteFeed(w io.Writer, feed *Feed) error {
+	archive := zip.NewWriter(w)
+
+	tables := []struct {
+		name     string
+		required bool
+		rows     interface{}
+	}{
+		{"agency.txt", true, feed.Agencies},
+		{"stops.txt", true, feed.Stops},
+		{"routes.txt", true, feed.Routes},
+		{"trips.txt", true, feed.Trips},
+		{"stop_times.txt", true, feed.StopTimes},
+		{"calendar.txt", false, feed.Calendars},
+		{"calendar_dates.txt", false, feed.CalendarDates},
+		{"fare_attributes.txt", false, feed.FareAttributes},
+		{"fare_rules.txt", false, feed.FareRules},
+	}
+	for _, table := range tables {
+		rows := reflect.ValueOf(table.rows)
+		if rows.Len() == 0 && !table.required {
+			continue
+		}
+
+		file, err := archive.Create(table.name)
+		if err != nil {
+			return err
+		}
+		if err := writeTable(file, rows); err != nil {
+			return fmt.Errorf("%s: %w", table.name, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+// readTable decodes a CSV file into a pointer to a slice of structs with csv tags.
+// Unknown columns are ignored and missing columns are left empty.
+func readTable(file *zip.File, target interface{}) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")) // UTF-8 byte order mark
+
+	records := csv.NewReader(bytes.NewReader(content))
+	records.FieldsPerRecord = -1
+	records.TrimLeadingSpace = true
+
+	header, err := records.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	slice := reflect.ValueOf(target).Elem()
+	rowType := slice.Type().Elem()
+	for {
+		record, err := records.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		row := reflect.New(rowType).Elem()
+		for i := 0; i < rowType.NumField(); i++ {
+			if column, ok := columns[rowType.Field(i).Tag.Get("csv")]; ok && column < len(record) {
+				row.Field(i).SetString(strings.TrimSpace(record[column]))
+			}
+		}
+		slice.Set(reflect.Append(slice, row))
+	}
+
+	return nil
+}
+
+// writeTable encodes a slice of structs with csv tags, with a header row
+func writeTable(w io.Writer, rows reflect.Value) error {
+	writer := csv.NewWriter(w)
+	rowType := rows.Type().Elem()
+
+	header := make([]string, rowType.NumField())
+	for i := range header {
+		header[i] = rowType.Field(i).Tag.Get("csv")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, rowType.NumField())
+	for i := 0; i < rows.Len(); i++ {
+		for j := range record {
+			record[j] = rows.Index(i).Field(j).String()
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,420 @@
+package gtfs
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+const (
+	ActionCreate     = "create"
+	ActionUpdate     = "update"
+	ActionUnchanged  = "unchanged"
+	ActionDeactivate = "deactivate"
+)
+
+var (
+	ErrNoVehicle      = errors.New("the company has no active vehicle to run the imported schedules")
+	ErrVehicleCompany = errors.New("the vehicle must belong to the company")
+)
+
+// ImportOptions selects the agency of a feed and the company it is imported into
+type ImportOptions struct {
+	CompanyID int
+	AgencyID  string
+	// Vehicle of new schedules; defaults to the company's first active vehicle.
+	// Existing schedules keep their vehicle unless one is given.
+	VehicleID int
+	DryRun    bool
+}
+
+// Change is what an import does, or would do, to one entity
+type Change struct {
+	Entity string `json:"entity"`
+	GTFSID string `json:"gtfs_id"`
+	Action string `json:"action"`
+	ID     int    `json:"id,omitempty"`
+}
+
+// ImportReport lists the changes of an import. Dry runs are rolled back, so new entities have no id.
+type ImportReport struct {
+	DryRun   bool           `json:"dry_run"`
+	Summary  map[string]int `json:"summary"`
+	Changes  []Change       `json:"changes"`
+	Warnings []string       `json:"warnings"`
+}
+
+func (r *ImportReport) record(entity, gtfsID, action string, id int) {
+	if r.DryRun && action == ActionCreate {
+		id = 0
+	}
+	r.Changes = append(r.Changes, Change{Entity: entity, GTFSID: gtfsID, Action: action, ID: id})
+	r.Summary[action]++
+}
+
+// Import creates or updates the company's calendars, routes and schedules from one agency of a feed,
+// matching them by GTFS id. Schedules imported earlier whose trips left the feed are deactivated.
+func Import(db *sql.DB, feed *Feed, options ImportOptions) (*ImportReport, error) {
+	timetable, err := BuildTimetable(feed, options.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	report := &ImportReport{DryRun: options.DryRun, Summary: make(map[string]int), Changes: []Change{}, Warnings: timetable.Warnings}
+	if report.Warnings == nil {
+		report.Warnings = []string{}
+	}
+
+	if err := importAgency(tx, timetable.Agency, options.CompanyID, report); err != nil {
+		return nil, err
+	}
+
+	vehicleID := options.VehicleID
+	if vehicleID == 0 {
+		vehicleID, err = repository.GetFirstActiveVehicleID(tx, options.CompanyID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoVehicle
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		vehicle, err := repository.GetVehicleByID(tx, vehicleID)
+		if err != nil {
+			return nil, err
+		}
+		if vehicle.CompanyID != options.CompanyID {
+			return nil, ErrVehicleCompany
+		}
+	}
+
+	calendarIDs, err := importCalendars(tx, timetable.Calendars, options.CompanyID, report)
+	if err != nil {
+		return nil, err
+	}
+	routeIDs, err := importRoutes(tx, timetable.Routes, options.CompanyID, report)
+	if err != nil {
+		return nil, err
+	}
+	if err := importSchedules(tx, timetable.Schedules, options, vehicleID, routeIDs, calendarIDs, report); err != nil {
+		return nil, err
+	}
+
+	if options.DryRun {
+		return report, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// importAgency links the company to the agency and fills its missing contact details
+func importAgency(tx *sql.Tx, agency Agency, companyID int, report *ImportReport) error {
+	company, err := repository.GetCompanyByID(tx, companyID)
+	if err != nil {
+		return err
+	}
+	gtfsID, err := repository.GetCompanyGTFSID(tx, companyID)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	if company.Phone == "" && agency.AgencyPhone != "" {
+		company.Phone = agency.AgencyPhone
+		changed = true
+	}
+	if company.Email == "" && agency.AgencyEmail != "" {
+		company.Email = agency.AgencyEmail
+		changed = true
+	}
+	if changed {
+		if err := repository.UpdateCompany(tx, company); err != nil {
+			return err
+		}
+	}
+	if agency.AgencyID != "" && agency.AgencyID != gtfsID {
+		if err := repository.SetCompanyGTFSID(tx, companyID, agency.AgencyID); err != nil {
+			return err
+		}
+		changed = true
+	}
+
+	action := ActionUnchanged
+	if changed {
+		action = ActionUpdate
+	}
+	report.record("company", agency.AgencyID, action, companyID)
+	return nil
+}
+
+// importCalendars stores the calendar_dates of each service as a calendar and returns their ids by service
+func importCalendars(tx *sql.Tx, calendars []TimetableCalendar, companyID int, report *ImportReport) (map[string]int, error) {
+	existing, err := repository.GetCalendarGTFSIDs(tx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int)
+	for _, timetableCalendar := range calendars {
+		action := ActionUpdate
+		id, ok := existing[timetableCalendar.ServiceID]
+		if ok {
+			current, err := repository.GetCalendarExceptions(tx, id)
+			if err != nil {
+				return nil, err
+			}
+			if sameExceptions(current, timetableCalendar.Exceptions) {
+				ids[timetableCalendar.ServiceID] = id
+				report.record("calendar", timetableCalendar.ServiceID, ActionUnchanged, id)
+				continue
+			}
+			if err := repository.DeleteCalendarExceptionsByCalendarID(tx, id); err != nil {
+				return nil, err
+			}
+		} else {
+			action = ActionCreate
+			calendar := &models.ServiceCalendar{CompanyID: companyID, Name: "GTFS service " + timetableCalendar.ServiceID}
+			if err := repository.CreateCalendar(tx, calendar); err != nil {
+				return nil, err
+			}
+			if err := repository.SetCalendarGTFSID(tx, calendar.ID, timetableCalendar.ServiceID); err != nil {
+				return nil, err
+			}
+			id = calendar.ID
+		}
+
+		for _, exception := range timetableCalendar.Exceptions {
+			exception.CalendarID = id
+			if err := repository.CreateCalendarException(tx, &exception); err != nil {
+				return nil, err
+			}
+		}
+		ids[timetableCalendar.ServiceID] = id
+		report.record("calendar", timetableCalendar.ServiceID, action, id)
+	}
+
+	return ids, nil
+}
+
+func sameExceptions(current, imported []models.CalendarException) bool {
+	if len(current) != len(imported) {
+		return false
+	}
+	dates := make(map[string]string)
+	for _, exception := range current {
+		dates[exception.ExceptionDate.Format(utils.DateLayout)] = exception.ExceptionType
+	}
+	for _, exception := range imported {
+		if dates[exception.ExceptionDate.Format(utils.DateLayout)] != exception.ExceptionType {
+			return false
+		}
+	}
+	return true
+}
+
+// importRoutes stores each stop pattern as a route and returns their ids by GTFS id.
+// Routes without a fare in the feed keep their current price, and unchanged routes keep their stop-pair fares.
+func importRoutes(tx *sql.Tx, routes []TimetableRoute, companyID int, report *ImportReport) (map[string]int, error) {
+	existing, err := repository.GetRouteGTFSIDs(tx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int)
+	for _, timetableRoute := range routes {
+		route := timetableRoute.Route
+		route.CompanyID = companyID
+		route.Stops = append([]models.RouteStop(nil), timetableRoute.Route.Stops...)
+
+		id, ok := existing[timetableRoute.GTFSID]
+		if !ok {
+			if err := services.StoreRoute(tx, &route); err != nil {
+				return nil, fmt.Errorf("route %s: %w", timetableRoute.GTFSID, err)
+			}
+			if err := repository.SetRouteGTFSID(tx, route.ID, timetableRoute.GTFSID); err != nil {
+				return nil, err
+			}
+			ids[timetableRoute.GTFSID] = route.ID
+			report.record("route", timetableRoute.GTFSID, ActionCreate, route.ID)
+			continue
+		}
+
+		current, err := services.GetRouteWithStops(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !timetableRoute.HasFare {
+			route.BasePrice = current.BasePrice
+		}
+		ids[timetableRoute.GTFSID] = id
+		if !routeChanged(current, &route) {
+			report.record("route", timetableRoute.GTFSID, ActionUnchanged, id)
+			continue
+		}
+
+		route.ID = id
+		if err := services.ReplaceRoute(tx, &route); err != nil {
+			return nil, fmt.Errorf("route %s: %w", timetableRoute.GTFSID, err)
+		}
+		report.record("route", timetableRoute.GTFSID, ActionUpdate, id)
+	}
+
+	return ids, nil
+}
+
+func routeChanged(current, imported *models.Route) bool {
+	if current.BasePrice != imported.BasePrice || !current.IsActive || len(current.Stops) != len(imported.Stops) {
+		return true
+	}
+	for i, stop := range imported.Stops {
+		existing := current.Stops[i]
+		if existing.City != stop.City || existing.Terminal != stop.Terminal || existing.TimeZone != stop.TimeZone ||
+			existing.OffsetMinutes != stop.OffsetMinutes || existing.DistanceKm != stop.DistanceKm || existing.GTFSStopID != stop.GTFSStopID ||
+			!sameCoordinate(existing.Latitude, stop.Latitude) || !sameCoordinate(existing.Longitude, stop.Longitude) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameCoordinate compares coordinates at the six decimals they are stored with
+func sameCoordinate(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return math.Round(*a*1e6) == math.Round(*b*1e6)
+}
+
+// importSchedules stores each trip as a schedule and deactivates earlier imported trips missing from the feed
+func importSchedules(tx *sql.Tx, schedules []TimetableSchedule, options ImportOptions, vehicleID int, routeIDs, calendarIDs map[string]int, report *ImportReport) error {
+	existing, err := repository.GetScheduleGTFSIDs(tx, options.CompanyID)
+	if err != nil {
+		return err
+	}
+
+	imported := make(map[string]bool)
+	var stored []models.Schedule
+	for _, timetableSchedule := range schedules {
+		schedule := timetableSchedule.Schedule
+		schedule.RouteID = routeIDs[timetableSchedule.RouteGTFSID]
+		schedule.VehicleID = vehicleID
+		if calendarID, ok := calendarIDs[timetableSchedule.ServiceID]; ok {
+			schedule.CalendarID = &calendarID
+		}
+		imported[timetableSchedule.GTFSID] = true
+
+		id, ok := existing[timetableSchedule.GTFSID]
+		if !ok {
+			if err := services.StoreSchedule(tx, &schedule); err != nil {
+				return fmt.Errorf("trip %s: %w", timetableSchedule.GTFSID, err)
+			}
+			if err := repository.SetScheduleGTFSID(tx, schedule.ID, timetableSchedule.GTFSID); err != nil {
+				return err
+			}
+			report.record("schedule", timetableSchedule.GTFSID, ActionCreate, schedule.ID)
+			stored = append(stored, schedule)
+			continue
+		}
+
+		current, err := repository.GetScheduleByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if options.VehicleID == 0 {
+			schedule.VehicleID = current.VehicleID
+		}
+		if !scheduleChanged(current, &schedule) {
+			report.record("schedule", timetableSchedule.GTFSID, ActionUnchanged, id)
+			continue
+		}
+
+		schedule.ID = id
+		if err := services.ReplaceSchedule(tx, &schedule); err != nil {
+			return fmt.Errorf("trip %s: %w", timetableSchedule.GTFSID, err)
+		}
+		report.record("schedule", timetableSchedule.GTFSID, ActionUpdate, id)
+		stored = append(stored, schedule)
+	}
+
+	// Vehicle conflicts are checked once every trip is stored and reported without failing the import
+	for i := range stored {
+		err := services.CheckScheduleAssignment(tx, &stored[i])
+		if errors.Is(err, services.ErrVehicleConflict) {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("schedule %d: %v", stored[i].ID, err))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	removed := make([]string, 0)
+	for gtfsID := range existing {
+		if !imported[gtfsID] {
+			removed = append(removed, gtfsID)
+		}
+	}
+	sort.Strings(removed)
+	for _, gtfsID := range removed {
+		schedule, err := repository.GetScheduleByID(tx, existing[gtfsID])
+		if err != nil {
+			return err
+		}
+		if !schedule.IsActive {
+			continue
+		}
+		schedule.IsActive = false
+		if err := repository.UpdateSchedule(tx, schedule); err != nil {
+			return err
+		}
+		report.record("schedule", gtfsID, ActionDeactivate, schedule.ID)
+	}
+
+	return nil
+}
+
+func scheduleChanged(current, imported *models.Schedule) bool {
+	if current.RouteID != imported.RouteID || current.VehicleID != imported.VehicleID || !current.IsActive ||
+		!sameClock(current.DepartureTime, imported.DepartureTime) || !sameClock(current.ArrivalTime, imported.ArrivalTime) ||
+		current.ValidFrom.Format(utils.DateLayout) != imported.ValidFrom.Format(utils.DateLayout) {
+		return true
+	}
+	if (current.ValidUntil == nil) != (imported.ValidUntil == nil) ||
+		(current.ValidUntil != nil && current.ValidUntil.Format(utils.DateLayout) != imported.ValidUntil.Format(utils.DateLayout)) {
+		return true
+	}
+	if (current.CalendarID == nil) != (imported.CalendarID == nil) || (current.CalendarID != nil && *current.CalendarID != *imported.CalendarID) {
+		return true
+	}
+	if len(current.DaysOfWeek) != len(imported.DaysOfWeek) {
+		return true
+	}
+	for i := range current.DaysOfWeek {
+		if current.DaysOfWeek[i] != imported.DaysOfWeek[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func sameClock(a, b string) bool {
+	first, err := utils.ParseClock(a)
+	if err != nil {
+		return false
+	}
+	second, err := utils.ParseClock(b)
+	return err == nil && first.Equal(second)
+}
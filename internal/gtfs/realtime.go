@@ -0,0 +1,221 @@
+package gtfs
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const RealtimeVersion = "2.0"
+
+// Trip schedule relationships of GTFS-Realtime
+const (
+	TripScheduled = "SCHEDULED"
+	TripCanceled  = "CANCELED"
+)
+
+// FeedMessage is a GTFS-Realtime feed. Field names follow the JSON form of the specification,
+// which serves as the debug variant of the protobuf encoding.
+type FeedMessage struct {
+	Header FeedHeader   `json:"header"`
+	Entity []FeedEntity `json:"entity"`
+}
+
+type FeedHeader struct {
+	GTFSRealtimeVersion string `json:"gtfs_realtime_version"`
+	Incrementality      string `json:"incrementality"`
+	Timestamp           uint64 `json:"timestamp"`
+}
+
+type FeedEntity struct {
+	ID         string           `json:"id"`
+	TripUpdate *TripUpdate      `json:"trip_update,omitempty"`
+	Vehicle    *VehiclePosition `json:"vehicle,omitempty"`
+}
+
+type TripDescriptor struct {
+	TripID               string `json:"trip_id"`
+	RouteID              string `json:"route_id,omitempty"`
+	StartTime            string `json:"start_time,omitempty"`
+	StartDate            string `json:"start_date,omitempty"`
+	ScheduleRelationship string `json:"schedule_relationship"`
+}
+
+type VehicleDescriptor struct {
+	ID           string `json:"id"`
+	Label        string `json:"label,omitempty"`
+	LicensePlate string `json:"license_plate,omitempty"`
+}
+
+type TripUpdate struct {
+	Trip           TripDescriptor     `json:"trip"`
+	Vehicle        *VehicleDescriptor `json:"vehicle,omitempty"`
+	StopTimeUpdate []StopTimeUpdate   `json:"stop_time_update,omitempty"`
+	Timestamp      uint64             `json:"timestamp,omitempty"`
+	Delay          *int32             `json:"delay,omitempty"` // seconds
+}
+
+type StopTimeUpdate struct {
+	StopSequence uint32         `json:"stop_sequence"`
+	StopID       string         `json:"stop_id,omitempty"`
+	Arrival      *StopTimeEvent `json:"arrival,omitempty"`
+	Departure    *StopTimeEvent `json:"departure,omitempty"`
+}
+
+type StopTimeEvent struct {
+	Delay int32 `json:"delay"` // seconds
+	Time  int64 `json:"time,omitempty"`
+}
+
+type VehiclePosition struct {
+	Trip      *TripDescriptor    `json:"trip,omitempty"`
+	Vehicle   *VehicleDescriptor `json:"vehicle,omitempty"`
+	Position  Position           `json:"position"`
+	Timestamp uint64             `json:"timestamp,omitempty"`
+}
+
+type Position struct {
+	Latitude  float32  `json:"latitude"`
+	Longitude float32  `json:"longitude"`
+	Bearing   *float32 `json:"bearing,omitempty"`
+	Speed     *float32 `json:"speed,omitempty"` // metres per second
+}
+
+// MarshalProto encodes the feed in the GTFS-Realtime protobuf format (gtfs-realtime.proto field numbers)
+func (m *FeedMessage) MarshalProto() []byte {
+	var header []byte
+	header = appendString(header, 1, m.Header.GTFSRealtimeVersion)
+	if m.Header.Incrementality == "DIFFERENTIAL" {
+		header = appendVarint(header, 2, 1)
+	}
+	header = appendVarint(header, 3, m.Header.Timestamp)
+
+	message := appendMessage(nil, 1, header)
+	for _, entity := range m.Entity {
+		message = appendMessage(message, 2, entity.marshal())
+	}
+	return message
+}
+
+func (e *FeedEntity) marshal() []byte {
+	b := appendString(nil, 1, e.ID)
+	if e.TripUpdate != nil {
+		b = appendMessage(b, 3, e.TripUpdate.marshal())
+	}
+	if e.Vehicle != nil {
+		b = appendMessage(b, 4, e.Vehicle.marshal())
+	}
+	return b
+}
+
+func (t *TripDescriptor) marshal() []byte {
+	b := appendString(nil, 1, t.TripID)
+	if t.StartTime != "" {
+		b = appendString(b, 2, t.StartTime)
+	}
+	if t.StartDate != "" {
+		b = appendString(b, 3, t.StartDate)
+	}
+	if t.ScheduleRelationship == TripCanceled {
+		b = appendVarint(b, 4, 3)
+	}
+	if t.RouteID != "" {
+		b = appendString(b, 5, t.RouteID)
+	}
+	return b
+}
+
+func (v *VehicleDescriptor) marshal() []byte {
+	b := appendString(nil, 1, v.ID)
+	if v.Label != "" {
+		b = appendString(b, 2, v.Label)
+	}
+	if v.LicensePlate != "" {
+		b = appendString(b, 3, v.LicensePlate)
+	}
+	return b
+}
+
+func (u *TripUpdate) marshal() []byte {
+	b := appendMessage(nil, 1, u.Trip.marshal())
+	for _, update := range u.StopTimeUpdate {
+		b = appendMessage(b, 2, update.marshal())
+	}
+	if u.Vehicle != nil {
+		b = appendMessage(b, 3, u.Vehicle.marshal())
+	}
+	if u.Timestamp != 0 {
+		b = appendVarint(b, 4, u.Timestamp)
+	}
+	if u.Delay != nil {
+		b = appendVarint(b, 5, uint64(int64(*u.Delay)))
+	}
+	return b
+}
+
+func (s *StopTimeUpdate) marshal() []byte {
+	b := appendVarint(nil, 1, uint64(s.StopSequence))
+	if s.Arrival != nil {
+		b = appendMessage(b, 2, s.Arrival.marshal())
+	}
+	if s.Departure != nil {
+		b = appendMessage(b, 3, s.Departure.marshal())
+	}
+	if s.StopID != "" {
+		b = appendString(b, 4, s.StopID)
+	}
+	return b
+}
+
+func (e *StopTimeEvent) marshal() []byte {
+	b := appendVarint(nil, 1, uint64(int64(e.Delay)))
+	if e.Time != 0 {
+		b = appendVarint(b, 2, uint64(e.Time))
+	}
+	return b
+}
+
+func (v *VehiclePosition) marshal() []byte {
+	var b []byte
+	if v.Trip != nil {
+		b = appendMessage(b, 1, v.Trip.marshal())
+	}
+
+	position := appendFloat(nil, 1, v.Position.Latitude)
+	position = appendFloat(position, 2, v.Position.Longitude)
+	if v.Position.Bearing != nil {
+		position = appendFloat(position, 3, *v.Position.Bearing)
+	}
+	if v.Position.Speed != nil {
+		position = appendFloat(position, 5, *v.Position.Speed)
+	}
+	b = appendMessage(b, 2, position)
+
+	if v.Timestamp != 0 {
+		b = appendVarint(b, 5, v.Timestamp)
+	}
+	if v.Vehicle != nil {
+		b = appendMessage(b, 8, v.Vehicle.marshal())
+	}
+	return b
+}
+
+func appendString(b []byte, field protowire.Number, value string) []byte {
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func appendMessage(b []byte, field protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func appendVarint(b []byte, field protowire.Number, value uint64) []byte {
+	b = protowire.AppendTag(b, field, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+func appendFloat(b []byte, field protowire.Number, value float32) []byte {
+	b = protowire.AppendTag(b, field, protowire.Fixed32Type)
+	return protowire.AppendFixed32(b, math.Float32bits(value))
+}
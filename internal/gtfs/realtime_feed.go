@@ -0,0 +1,185 @@
+package gtfs
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+)
+
+// Disrupted trips departing within this horizon are published
+const TripUpdateHorizon = 7 * 24 * time.Hour
+
+// tripResolver looks up the GTFS identifiers of trips, loading each schedule and route once per feed
+type tripResolver struct {
+	db             *sql.DB
+	schedules      map[int]string
+	routes         map[int]*models.Route
+	routeIDs       map[int]string
+	scheduleRoutes map[int]int
+	vehicles       map[int]*VehicleDescriptor
+}
+
+func newTripResolver(db *sql.DB) *tripResolver {
+	return &tripResolver{
+		db:             db,
+		schedules:      make(map[int]string),
+		routes:         make(map[int]*models.Route),
+		routeIDs:       make(map[int]string),
+		scheduleRoutes: make(map[int]int),
+		vehicles:       make(map[int]*VehicleDescriptor),
+	}
+}
+
+// descriptor returns the GTFS descriptor of a trip and the route it runs on, ids matching the static export
+func (r *tripResolver) descriptor(trip *models.Trip) (*TripDescriptor, *models.Route, error) {
+	gtfsID, ok := r.schedules[trip.ScheduleID]
+	if !ok {
+		schedule, err := repository.GetScheduleByID(r.db, trip.ScheduleID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if gtfsID, err = repository.GetScheduleGTFSID(r.db, trip.ScheduleID); err != nil {
+			return nil, nil, err
+		}
+		r.schedules[trip.ScheduleID] = gtfsID
+		r.scheduleRoutes[trip.ScheduleID] = schedule.RouteID
+	}
+
+	routeID := r.scheduleRoutes[trip.ScheduleID]
+	route, ok := r.routes[routeID]
+	if !ok {
+		var err error
+		if route, err = services.GetRouteWithStops(r.db, routeID); err != nil {
+			return nil, nil, err
+		}
+		if r.routeIDs[routeID], err = repository.GetRouteGTFSID(r.db, routeID); err != nil {
+			return nil, nil, err
+		}
+		r.routes[routeID] = route
+	}
+
+	descriptor := &TripDescriptor{
+		TripID:               TripID(gtfsID, trip.ScheduleID),
+		RouteID:              RouteID(r.routeIDs[routeID], routeID),
+		StartDate:            trip.TravelDate.Format(DateLayout),
+		ScheduleRelationship: TripScheduled,
+	}
+	if trip.Status == models.TripStatusCancelled {
+		descriptor.ScheduleRelationship = TripCanceled
+	}
+	return descriptor, route, nil
+}
+
+func (r *tripResolver) vehicle(vehicleID int) (*VehicleDescriptor, error) {
+	if descriptor, ok := r.vehicles[vehicleID]; ok {
+		return descriptor, nil
+	}
+
+	vehicle, err := repository.GetVehicleByID(r.db, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	descriptor := &VehicleDescriptor{ID: strconv.Itoa(vehicle.ID), Label: vehicle.Brand + " " + vehicle.Model, LicensePlate: vehicle.LicensePlate}
+	r.vehicles[vehicleID] = descriptor
+	return descriptor, nil
+}
+
+func newFeedMessage(now time.Time) *FeedMessage {
+	return &FeedMessage{
+		Header: FeedHeader{GTFSRealtimeVersion: RealtimeVersion, Incrementality: "FULL_DATASET", Timestamp: uint64(now.Unix())},
+		Entity: []FeedEntity{},
+	}
+}
+
+// BuildTripUpdates publishes the delays and cancellations of trips that have not arrived yet.
+// Delays are given at the first stop and propagate downstream, as the specification prescribes.
+func BuildTripUpdates(db *sql.DB, now time.Time) (*FeedMessage, error) {
+	trips, err := repository.GetDisruptedTrips(db, now, now.Add(TripUpdateHorizon))
+	if err != nil {
+		return nil, err
+	}
+
+	feed := newFeedMessage(now)
+	resolver := newTripResolver(db)
+	for i := range trips {
+		trip := &trips[i]
+		descriptor, route, err := resolver.descriptor(trip)
+		if err != nil {
+			return nil, err
+		}
+		vehicle, err := resolver.vehicle(trip.VehicleID)
+		if err != nil {
+			return nil, err
+		}
+
+		update := &TripUpdate{Trip: *descriptor, Vehicle: vehicle}
+		if trip.StatusChangedAt != nil {
+			update.Timestamp = uint64(trip.StatusChangedAt.Unix())
+		}
+		if trip.Status == models.TripStatusDelayed && len(route.Stops) > 0 {
+			delay := int32(trip.DelayMinutes * 60)
+			update.Delay = &delay
+			update.StopTimeUpdate = []StopTimeUpdate{{
+				StopSequence: uint32(route.Stops[0].StopSequence),
+				StopID:       StopID(route.Stops[0]),
+				Departure:    &StopTimeEvent{Delay: delay, Time: trip.DepartureDatetime.Add(time.Duration(trip.DelayMinutes) * time.Minute).Unix()},
+			}}
+		}
+
+		feed.Entity = append(feed.Entity, FeedEntity{ID: "trip-" + strconv.Itoa(trip.ID), TripUpdate: update})
+	}
+
+	return feed, nil
+}
+
+// BuildVehiclePositions publishes the last position of each running trip, unless older than services.PositionMaxAge
+func BuildVehiclePositions(db *sql.DB, now time.Time) (*FeedMessage, error) {
+	positions, err := repository.GetLatestTripPositions(db, now.Add(-services.PositionMaxAge))
+	if err != nil {
+		return nil, err
+	}
+
+	feed := newFeedMessage(now)
+	resolver := newTripResolver(db)
+	for _, position := range positions {
+		trip, err := repository.GetTripByID(db, *position.TripID)
+		if err != nil {
+			return nil, err
+		}
+		if trip.Status == models.TripStatusCancelled {
+			continue
+		}
+
+		descriptor, _, err := resolver.descriptor(trip)
+		if err != nil {
+			return nil, err
+		}
+		vehicle, err := resolver.vehicle(position.VehicleID)
+		if err != nil {
+			return nil, err
+		}
+
+		vehiclePosition := &VehiclePosition{
+			Trip:      descriptor,
+			Vehicle:   vehicle,
+			Position:  Position{Latitude: float32(position.Latitude), Longitude: float32(position.Longitude)},
+			Timestamp: uint64(position.RecordedAt.Unix()),
+		}
+		if position.Bearing != nil {
+			bearing := float32(*position.Bearing)
+			vehiclePosition.Position.Bearing = &bearing
+		}
+		if position.SpeedKmh != nil {
+			speed := float32(*position.SpeedKmh / 3.6)
+			vehiclePosition.Position.Speed = &speed
+		}
+
+		feed.Entity = append(feed.Entity, FeedEntity{ID: "vehicle-" + strconv.Itoa(trip.ID), Vehicle: vehiclePosition})
+	}
+
+	return feed, nil
+}
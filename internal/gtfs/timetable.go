@@ -0,0 +1,454 @@
+package gtfs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+const (
+	DateLayout = "20060102"
+	// BusRouteType is the GTFS route_type of bus services
+	BusRouteType = "3"
+)
+
+var (
+	ErrAgencyRequired = errors.New("the feed has several agencies, choose one with agency_id")
+	ErrAgencyNotFound = errors.New("agency not found in the feed")
+	ErrInvalidTime    = errors.New("GTFS times must be formatted as H:MM:SS")
+)
+
+// Timetable is the part of a feed operated by one agency, translated into the booking model
+type Timetable struct {
+	Agency    Agency
+	Routes    []TimetableRoute
+	Schedules []TimetableSchedule
+	Calendars []TimetableCalendar
+	Warnings  []string
+}
+
+// TimetableRoute is one stop pattern of a GTFS route. Routes whose trips serve different stops
+// or run at different offsets become several routes, with "~N" appended to the route_id.
+type TimetableRoute struct {
+	GTFSID  string
+	Route   models.Route
+	HasFare bool
+}
+
+// TimetableSchedule is a GTFS trip
+type TimetableSchedule struct {
+	GTFSID      string
+	RouteGTFSID string
+	ServiceID   string
+	Schedule    models.Schedule
+}
+
+// TimetableCalendar holds the calendar_dates of a service
+type TimetableCalendar struct {
+	ServiceID  string
+	Exceptions []models.CalendarException
+}
+
+// service is the regular pattern of a GTFS service from calendar.txt, or the span of its calendar_dates
+type service struct {
+	days       []int64
+	validFrom  time.Time
+	validUntil time.Time
+}
+
+// ParseTime parses a GTFS time into seconds after the start of the service day.
+// Hours may exceed 23 for trips that run past midnight.
+func ParseTime(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, ErrInvalidTime
+	}
+
+	var fields [3]int
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 || (i > 0 && (len(part) != 2 || number > 59)) {
+			return 0, ErrInvalidTime
+		}
+		fields[i] = number
+	}
+
+	return fields[0]*3600 + fields[1]*60 + fields[2], nil
+}
+
+// FormatTime formats seconds after the start of the service day as a GTFS time
+func FormatTime(seconds int) string {
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// SelectAgency returns the agency with the given id, or the only agency of the feed when the id is empty
+func SelectAgency(feed *Feed, agencyID string) (Agency, error) {
+	if agencyID == "" {
+		if len(feed.Agencies) != 1 {
+			return Agency{}, ErrAgencyRequired
+		}
+		return feed.Agencies[0], nil
+	}
+
+	for _, agency := range feed.Agencies {
+		if agency.AgencyID == agencyID {
+			return agency, nil
+		}
+	}
+	return Agency{}, ErrAgencyNotFound
+}
+
+// BuildTimetable translates the routes, trips and services of one agency. Trips that cannot be
+// represented are skipped and reported as warnings.
+func BuildTimetable(feed *Feed, agencyID string) (*Timetable, error) {
+	agency, err := SelectAgency(feed, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	agencyLocation, err := utils.LoadLocation(agency.AgencyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("agency_timezone %q: %w", agency.AgencyTimezone, err)
+	}
+
+	timetable := &Timetable{Agency: agency}
+	warn := func(format string, args ...interface{}) {
+		timetable.Warnings = append(timetable.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	stops := make(map[string]Stop)
+	for _, stop := range feed.Stops {
+		stops[stop.StopID] = stop
+	}
+
+	routes := make(map[string]Route)
+	for _, route := range feed.Routes {
+		// agency_id may be omitted when the feed has a single agency
+		if route.AgencyID == agency.AgencyID || (route.AgencyID == "" && len(feed.Agencies) == 1) {
+			routes[route.RouteID] = route
+		}
+	}
+
+	prices := farePrices(feed)
+	services, exceptions, err := buildServices(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	stopTimes := make(map[string][]StopTime)
+	for _, stopTime := range feed.StopTimes {
+		stopTimes[stopTime.TripID] = append(stopTimes[stopTime.TripID], stopTime)
+	}
+
+	patterns := make(map[string]int)
+	patternCounts := make(map[string]int)
+	usedServices := make(map[string]bool)
+	for _, trip := range feed.Trips {
+		if _, ok := routes[trip.RouteID]; !ok {
+			continue
+		}
+		svc, ok := services[trip.ServiceID]
+		if !ok {
+			warn("trip %s: service %s has no calendar or calendar dates, skipped", trip.TripID, trip.ServiceID)
+			continue
+		}
+
+		times, err := tripTimes(stopTimes[trip.TripID])
+		if err != nil {
+			warn("trip %s: %v, skipped", trip.TripID, err)
+			continue
+		}
+
+		routeStops, err := tripStops(stopTimes[trip.TripID], times, stops, agency.AgencyTimezone)
+		if err != nil {
+			warn("trip %s: %v, skipped", trip.TripID, err)
+			continue
+		}
+
+		// Times count from midnight of the service day in the agency zone; schedules are local to their end stops
+		serviceDay := time.Date(svc.validFrom.Year(), svc.validFrom.Month(), svc.validFrom.Day(), 0, 0, 0, 0, agencyLocation)
+		firstLocation, _ := utils.LoadLocation(routeStops[0].TimeZone)
+		lastLocation, _ := utils.LoadLocation(routeStops[len(routeStops)-1].TimeZone)
+		departure := serviceDay.Add(time.Duration(times[0]) * time.Second).In(firstLocation)
+		arrival := serviceDay.Add(time.Duration(times[len(times)-1]) * time.Second).In(lastLocation)
+		if departure.Format(utils.DateLayout) != svc.validFrom.Format(utils.DateLayout) {
+			warn("trip %s: departs on the day after its service day, skipped", trip.TripID)
+			continue
+		}
+
+		key := patternKey(trip.RouteID, routeStops)
+		index, ok := patterns[key]
+		if !ok {
+			route := models.Route{Stops: routeStops, IsActive: true}
+			price, hasFare := prices[trip.RouteID]
+			route.BasePrice = price
+			patternCounts[trip.RouteID]++
+			timetable.Routes = append(timetable.Routes, TimetableRoute{
+				GTFSID:  fmt.Sprintf("%s~%d", trip.RouteID, patternCounts[trip.RouteID]),
+				Route:   route,
+				HasFare: hasFare,
+			})
+			index = len(timetable.Routes) - 1
+			patterns[key] = index
+		}
+
+		validUntil := svc.validUntil
+		timetable.Schedules = append(timetable.Schedules, TimetableSchedule{
+			GTFSID:      trip.TripID,
+			RouteGTFSID: timetable.Routes[index].GTFSID,
+			ServiceID:   trip.ServiceID,
+			Schedule: models.Schedule{
+				DepartureTime: departure.Format(utils.ClockLayout),
+				ArrivalTime:   arrival.Format(utils.ClockLayout),
+				DaysOfWeek:    svc.days,
+				ValidFrom:     svc.validFrom,
+				ValidUntil:    &validUntil,
+				IsActive:      true,
+			},
+		})
+		usedServices[trip.ServiceID] = true
+	}
+
+	// Routes with a single stop pattern keep their GTFS route_id
+	for i := range timetable.Routes {
+		routeID := timetable.Routes[i].GTFSID[:strings.LastIndex(timetable.Routes[i].GTFSID, "~")]
+		if patternCounts[routeID] == 1 {
+			timetable.Routes[i].GTFSID = routeID
+		}
+		if !timetable.Routes[i].HasFare {
+			warn("route %s: no fare in fare_rules.txt, base price left unset", timetable.Routes[i].GTFSID)
+		}
+	}
+	for i := range timetable.Schedules {
+		routeID := timetable.Schedules[i].RouteGTFSID[:strings.LastIndex(timetable.Schedules[i].RouteGTFSID, "~")]
+		if patternCounts[routeID] == 1 {
+			timetable.Schedules[i].RouteGTFSID = routeID
+		}
+	}
+
+	serviceIDs := make([]string, 0, len(exceptions))
+	for serviceID := range exceptions {
+		if usedServices[serviceID] {
+			serviceIDs = append(serviceIDs, serviceID)
+		}
+	}
+	sort.Strings(serviceIDs)
+	for _, serviceID := range serviceIDs {
+		timetable.Calendars = append(timetable.Calendars, TimetableCalendar{ServiceID: serviceID, Exceptions: exceptions[serviceID]})
+	}
+
+	return timetable, nil
+}
+
+// farePrices returns the price of each route that has a fare rule
+func farePrices(feed *Feed) map[string]float64 {
+	fares := make(map[string]float64)
+	for _, fare := range feed.FareAttributes {
+		if price, err := strconv.ParseFloat(fare.Price, 64); err == nil {
+			fares[fare.FareID] = price
+		}
+	}
+
+	prices := make(map[string]float64)
+	for _, rule := range feed.FareRules {
+		price, ok := fares[rule.FareID]
+		if _, seen := prices[rule.RouteID]; ok && rule.RouteID != "" && !seen {
+			prices[rule.RouteID] = price
+		}
+	}
+	return prices
+}
+
+// buildServices reads calendar.txt and calendar_dates.txt. Services defined only by dates run on
+// their added dates and span from the first to the last of them.
+func buildServices(feed *Feed) (map[string]service, map[string][]models.CalendarException, error) {
+	services := make(map[string]service)
+	regular := make(map[string]bool)
+	for _, calendar := range feed.Calendars {
+		validFrom, err := time.Parse(DateLayout, calendar.StartDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("calendar.txt service %s: invalid start_date", calendar.ServiceID)
+		}
+		validUntil, err := time.Parse(DateLayout, calendar.EndDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("calendar.txt service %s: invalid end_date", calendar.ServiceID)
+		}
+
+		days := []int64{}
+		for i, flag := range []string{calendar.Monday, calendar.Tuesday, calendar.Wednesday, calendar.Thursday, calendar.Friday, calendar.Saturday, calendar.Sunday} {
+			if flag == "1" {
+				days = append(days, int64(i+1))
+			}
+		}
+		services[calendar.ServiceID] = service{days: days, validFrom: validFrom, validUntil: validUntil}
+		regular[calendar.ServiceID] = true
+	}
+
+	exceptions := make(map[string][]models.CalendarException)
+	for _, calendarDate := range feed.CalendarDates {
+		date, err := time.Parse(DateLayout, calendarDate.Date)
+		if err != nil {
+			return nil, nil, fmt.Errorf("calendar_dates.txt service %s: invalid date", calendarDate.ServiceID)
+		}
+
+		exceptionType := models.ExceptionTypeAdded
+		if calendarDate.ExceptionType == "2" {
+			exceptionType = models.ExceptionTypeRemoved
+		}
+		exceptions[calendarDate.ServiceID] = append(exceptions[calendarDate.ServiceID], models.CalendarException{
+			ExceptionDate: date,
+			ExceptionType: exceptionType,
+		})
+
+		if regular[calendarDate.ServiceID] || exceptionType != models.ExceptionTypeAdded {
+			continue
+		}
+		svc, ok := services[calendarDate.ServiceID]
+		if !ok {
+			svc = service{days: []int64{}, validFrom: date, validUntil: date}
+		}
+		if date.Before(svc.validFrom) {
+			svc.validFrom = date
+		}
+		if date.After(svc.validUntil) {
+			svc.validUntil = date
+		}
+		services[calendarDate.ServiceID] = svc
+	}
+
+	for serviceID := range exceptions {
+		sort.Slice(exceptions[serviceID], func(i, j int) bool {
+			return exceptions[serviceID][i].ExceptionDate.Before(exceptions[serviceID][j].ExceptionDate)
+		})
+	}
+	return services, exceptions, nil
+}
+
+// tripTimes returns the arrival time of each stop of a trip in seconds, in stop_sequence order.
+// Stops without times are interpolated between the surrounding timepoints.
+func tripTimes(stopTimes []StopTime) ([]int, error) {
+	if len(stopTimes) < 2 {
+		return nil, errors.New("fewer than two stop times")
+	}
+	sortStopTimes(stopTimes)
+
+	times := make([]int, len(stopTimes))
+	known := make([]bool, len(stopTimes))
+	for i, stopTime := range stopTimes {
+		value := stopTime.ArrivalTime
+		if i == 0 || value == "" {
+			value = stopTime.DepartureTime
+		}
+		if value == "" {
+			value = stopTime.ArrivalTime
+		}
+		if value == "" {
+			continue
+		}
+
+		seconds, err := ParseTime(value)
+		if err != nil {
+			return nil, fmt.Errorf("stop %s: %w", stopTime.StopID, err)
+		}
+		times[i] = seconds
+		known[i] = true
+	}
+	if !known[0] || !known[len(times)-1] {
+		return nil, errors.New("first and last stops need times")
+	}
+
+	previous := 0
+	for i := 1; i < len(times); i++ {
+		if !known[i] {
+			continue
+		}
+		for j := previous + 1; j < i; j++ {
+			times[j] = times[previous] + (times[i]-times[previous])*(j-previous)/(i-previous)
+		}
+		previous = i
+	}
+
+	if times[0] >= 24*3600 {
+		return nil, errors.New("departs after the end of its service day")
+	}
+	return times, nil
+}
+
+func sortStopTimes(stopTimes []StopTime) {
+	sort.SliceStable(stopTimes, func(i, j int) bool {
+		a, _ := strconv.Atoi(stopTimes[i].StopSequence)
+		b, _ := strconv.Atoi(stopTimes[j].StopSequence)
+		return a < b
+	})
+}
+
+// tripStops builds the route stops of a trip. Offsets count from the first departure and must grow,
+// so stops served in the same minute are spaced one minute apart. Distances follow stop coordinates.
+func tripStops(stopTimes []StopTime, times []int, stops map[string]Stop, agencyTimezone string) ([]models.RouteStop, error) {
+	routeStops := make([]models.RouteStop, len(stopTimes))
+	distance := 0.0
+	for i, stopTime := range stopTimes {
+		stop, ok := stops[stopTime.StopID]
+		if !ok {
+			return nil, fmt.Errorf("unknown stop %s", stopTime.StopID)
+		}
+
+		routeStop := models.RouteStop{
+			City:       stop.StopName,
+			Terminal:   stop.StopName,
+			TimeZone:   stop.StopTimezone,
+			GTFSStopID: stop.StopID,
+		}
+		if parent, ok := stops[stop.ParentStation]; ok && stop.ParentStation != "" {
+			routeStop.City = parent.StopName
+			if routeStop.TimeZone == "" {
+				routeStop.TimeZone = parent.StopTimezone
+			}
+		}
+		if routeStop.TimeZone == "" {
+			routeStop.TimeZone = agencyTimezone
+		}
+		if _, err := utils.LoadLocation(routeStop.TimeZone); err != nil {
+			return nil, fmt.Errorf("stop %s: unknown time zone %q", stop.StopID, routeStop.TimeZone)
+		}
+
+		if lat, err := strconv.ParseFloat(stop.StopLat, 64); err == nil {
+			routeStop.Latitude = &lat
+		}
+		if lon, err := strconv.ParseFloat(stop.StopLon, 64); err == nil {
+			routeStop.Longitude = &lon
+		}
+
+		if i > 0 {
+			previous := routeStops[i-1]
+			routeStop.OffsetMinutes = (times[i] - times[0]) / 60
+			if routeStop.OffsetMinutes <= previous.OffsetMinutes {
+				routeStop.OffsetMinutes = previous.OffsetMinutes + 1
+			}
+
+			if previous.Latitude != nil && previous.Longitude != nil && routeStop.Latitude != nil && routeStop.Longitude != nil {
+				distance += utils.HaversineKm(*previous.Latitude, *previous.Longitude, *routeStop.Latitude, *routeStop.Longitude)
+			}
+			routeStop.DistanceKm = int(math.Round(distance))
+		}
+
+		routeStops[i] = routeStop
+	}
+
+	return routeStops, nil
+}
+
+// patternKey identifies trips of a route that serve the same stops at the same offsets
+func patternKey(routeID string, stops []models.RouteStop) string {
+	parts := []string{routeID}
+	for _, stop := range stops {
+		parts = append(parts, stop.GTFSStopID+"@"+strconv.Itoa(stop.OffsetMinutes))
+	}
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Ancillary is an extra a company sells next to seats, such as luggage or insurance
+type Ancillary struct {
+	ID          int    `json:"id" db:"id"`
+	CompanyID   int    `json:"company_id" db:"company_id"`
+	Category    string `json:"category" db:"category"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+	// Price per unit and leg, to which a share of the seat fare is added
+	Price       float64 `json:"price" db:"price"`
+	FarePercent float64 `json:"fare_percent" db:"fare_percent"`
+	// Units that can be sold on a trip at any point of the route; unlimited when nil
+	CapacityPerTrip *int      `json:"capacity_per_trip" db:"capacity_per_trip"`
+	IsActive        bool      `json:"is_active" db:"is_active"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+const (
+	AncillaryCategoryExtraBag        = "extra_bag"
+	AncillaryCategoryOversizeLuggage = "oversize_luggage"
+	AncillaryCategoryBicycle         = "bicycle"
+	AncillaryCategoryPet             = "pet"
+	AncillaryCategoryInsurance       = "insurance"
+)
+
+// BookingAncillary is an ancillary added to a booking, at the price it was sold for
+type BookingAncillary struct {
+	ID          int       `json:"id" db:"id"`
+	BookingID   int       `json:"booking_id" db:"booking_id"`
+	AncillaryID int       `json:"ancillary_id" db:"ancillary_id"`
+	Category    string    `json:"category" db:"category"`
+	Name        string    `json:"name" db:"name"`
+	Quantity    int       `json:"quantity" db:"quantity"`
+	UnitPrice   float64   `json:"unit_price" db:"unit_price"`
+	TotalPrice  float64   `json:"total_price" db:"total_price"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// AncillaryOffer is an ancillary as sold on a departure, priced for the segment
+type AncillaryOffer struct {
+	Ancillary
+	UnitPrice float64 `json:"unit_price"`
+	// Units left on the segment; unlimited when nil
+	Remaining *int `json:"remaining"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+const (
+	BoardingResultBoarded   = "boarded"
+	BoardingResultDuplicate = "duplicate"
+	BoardingResultRejected  = "rejected"
+)
+
+// BoardingScan is a ticket scanned for a trip, by an operator or an on-board device, with its outcome
+type BoardingScan struct {
+	ID            int       `json:"id" db:"id"`
+	TripID        int       `json:"trip_id" db:"trip_id"`
+	BookingSeatID *int      `json:"booking_seat_id" db:"booking_seat_id"`
+	DeviceID      *int      `json:"device_id" db:"device_id"`
+	ScannedBy     *int      `json:"scanned_by" db:"scanned_by"`
+	ScanID        string    `json:"scan_id,omitempty" db:"scan_id"`
+	BookingCode   string    `json:"booking_code" db:"booking_code"`
+	SeatNumber    string    `json:"seat_number" db:"seat_number"`
+	PassengerName string    `json:"passenger_name" db:"passenger_name"`
+	Result        string    `json:"result" db:"result"`
+	Reason        string    `json:"reason,omitempty" db:"reason"`
+	ScannedAt     time.Time `json:"scanned_at" db:"scanned_at"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// BoardingSeat is the booked seat a ticket was issued for, as currently booked
+type BoardingSeat struct {
+	BookingSeatID int
+	BookingID     int
+	BookingCode   string
+	ScheduleID    int
+	TravelDate    time.Time
+	BookingStatus string
+	SeatNumber    string
+	PassengerName string
+	BoardedAt     *time.Time
+}
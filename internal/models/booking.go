@@ -3,20 +3,37 @@ package models
 import "time"
 
 type Booking struct {
-	ID                int       `json:"id" db:"id"`
-	UserID            int       `json:"user_id" db:"user_id"`
-	ScheduleID        int       `json:"schedule_id" db:"schedule_id"`
-	BookingCode       string    `json:"booking_code" db:"booking_code"`
-	TravelDate        time.Time `json:"travel_date" db:"travel_date"`
-	DepartureDatetime time.Time `json:"departure_datetime" db:"departure_datetime"`
-	PassengerName     string    `json:"passenger_name" db:"passenger_name"`
-	PassengerDocument string    `json:"passenger_document" db:"passenger_document"`
-	PassengerPhone    string    `json:"passenger_phone" db:"passenger_phone"`
-	TotalAmount       float64   `json:"total_amount" db:"total_amount"`
-	PaymentStatus     string    `json:"payment_status" db:"payment_status"`
-	BookingStatus     string    `json:"booking_status" db:"booking_status"`
-	PaymentMethod     string    `json:"payment_method" db:"payment_method"`
-	Notes             string    `json:"notes" db:"notes"`
-	CreatedAt         time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
-}
\ No newline at end of file
+	ID                      int        `json:"id" db:"id"`
+	UserID                  int        `json:"user_id" db:"user_id"`
+	ScheduleID              int        `json:"schedule_id" db:"schedule_id"`
+	JourneyID               *int       `json:"journey_id" db:"journey_id"`
+	LegSequence             *int       `json:"leg_sequence" db:"leg_sequence"`
+	FareFamilyID            *int       `json:"fare_family_id" db:"fare_family_id"`
+	YieldPercent            *float64   `json:"yield_percent" db:"yield_percent"`
+	FareRules               *FareRules `json:"fare_rules" db:"-"`
+	BookingCode             string     `json:"booking_code" db:"booking_code"`
+	TravelDate              time.Time  `json:"travel_date" db:"travel_date"`
+	OriginStopSequence      *int       `json:"origin_stop_sequence" db:"origin_stop_sequence"`
+	DestinationStopSequence *int       `json:"destination_stop_sequence" db:"destination_stop_sequence"`
+	DepartureDatetime       time.Time  `json:"departure_datetime" db:"departure_datetime"`
+	PassengerName           string     `json:"passenger_name" db:"passenger_name"`
+	PassengerDocument       string     `json:"passenger_document" db:"passenger_document"`
+	PassengerPhone          string     `json:"passenger_phone" db:"passenger_phone"`
+	PassengerDocumentType   string     `json:"passenger_document_type" db:"passenger_document_type"`
+	PassengerDocumentExpiry *time.Time `json:"passenger_document_expiry" db:"passenger_document_expiry"`
+	PassengerNationality    string     `json:"passenger_nationality" db:"passenger_nationality"`
+	TotalAmount             float64    `json:"total_amount" db:"total_amount"`
+	DiscountAmount          float64    `json:"discount_amount" db:"discount_amount"`
+	AncillaryAmount         float64    `json:"ancillary_amount" db:"ancillary_amount"`
+	NoShowFee               float64    `json:"no_show_fee" db:"no_show_fee"`
+	DelayCompensation       bool       `json:"delay_compensation" db:"delay_compensation"`
+	PaymentStatus           string     `json:"payment_status" db:"payment_status"`
+	BookingStatus           string     `json:"booking_status" db:"booking_status"`
+	PaymentMethod           string     `json:"payment_method" db:"payment_method"`
+	Notes                   string     `json:"notes" db:"notes"`
+	CheckedInAt             *time.Time `json:"checked_in_at" db:"checked_in_at"`
+	CreatedAt               time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at" db:"updated_at"`
+	// Ancillaries bought with the booking, only filled in when it is created
+	Ancillaries []BookingAncillary `json:"ancillaries,omitempty" db:"-"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// PreviousBooking is a booking and its seats as they were before an exchange
+type PreviousBooking struct {
+	Booking Booking      `json:"booking"`
+	Seats   []BookedSeat `json:"seats"`
+}
+
+// BookingExchange records a change of date, time or seat of a booking and what it cost
+type BookingExchange struct {
+	ID              int             `json:"id" db:"id"`
+	BookingID       int             `json:"booking_id" db:"booking_id"`
+	PreviousBooking PreviousBooking `json:"previous_booking" db:"previous_booking"`
+	ScheduleID      int             `json:"schedule_id" db:"schedule_id"`
+	TravelDate      time.Time       `json:"travel_date" db:"travel_date"`
+	PreviousFare    float64         `json:"previous_fare" db:"previous_fare"`
+	NewFare         float64         `json:"new_fare" db:"new_fare"`
+	ChangeFee       float64         `json:"change_fee" db:"change_fee"`
+	// Charged when positive, refunded when negative
+	AmountDue float64   `json:"amount_due" db:"amount_due"`
+	PaymentID *int      `json:"payment_id" db:"payment_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -7,4 +7,38 @@ type BookingSeat struct {
 	BookingID int       `json:"booking_id" db:"booking_id"`
 	SeatID    int       `json:"seat_id" db:"seat_id"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
-}
\ No newline at end of file
+}
+
+// BookedSeat is a seat held by an active booking, with what is needed to move it to another seat
+type BookedSeat struct {
+	BookingSeatID           int    `json:"booking_seat_id"`
+	BookingID               int    `json:"booking_id"`
+	BookingCode             string `json:"booking_code"`
+	UserID                  int    `json:"user_id"`
+	PassengerName           string `json:"passenger_name"`
+	SeatID                  int    `json:"seat_id"`
+	SeatNumber              string `json:"seat_number"`
+	SeatType                string `json:"seat_type"`
+	OriginStopSequence      *int   `json:"origin_stop_sequence"`
+	DestinationStopSequence *int   `json:"destination_stop_sequence"`
+}
+
+// HeldSeat is a seat of a trip kept off sale without a booking: by a group block, a seat block or a waitlist offer
+type HeldSeat struct {
+	Kind string `json:"kind"`
+	// ID of the group booking seat, seat block or waitlist entry holding the seat
+	ID                      int    `json:"id"`
+	SeatID                  int    `json:"seat_id"`
+	SeatNumber              string `json:"seat_number"`
+	SeatType                string `json:"seat_type"`
+	OriginStopSequence      *int   `json:"origin_stop_sequence"`
+	DestinationStopSequence *int   `json:"destination_stop_sequence"`
+	// Seat blocks applying to every trip of the schedule
+	EveryTrip bool `json:"every_trip,omitempty"`
+}
+
+const (
+	SeatHoldGroup    = "group"
+	SeatHoldBlock    = "block"
+	SeatHoldWaitlist = "waitlist"
+)
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ServiceCalendar is a reusable operating calendar of a company, made of exception dates
+type ServiceCalendar struct {
+	ID         int                 `json:"id" db:"id"`
+	CompanyID  int                 `json:"company_id" db:"company_id"`
+	Name       string              `json:"name" db:"name" binding:"required"`
+	Exceptions []CalendarException `json:"exceptions" db:"-"`
+	CreatedAt  time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// CalendarException adds or removes service on a date, whatever the schedule's days of week
+type CalendarException struct {
+	ID            int       `json:"id" db:"id"`
+	CalendarID    int       `json:"calendar_id" db:"calendar_id"`
+	ExceptionDate time.Time `json:"exception_date" db:"exception_date"`
+	ExceptionType string    `json:"exception_type" db:"exception_type"`
+	Description   string    `json:"description" db:"description"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+const (
+	ExceptionTypeAdded   = "added"
+	ExceptionTypeRemoved = "removed"
+)
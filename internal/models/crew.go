@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// CrewMember is a driver or attendant employed by a company
+type CrewMember struct {
+	ID            int        `json:"id" db:"id"`
+	CompanyID     int        `json:"company_id" db:"company_id"`
+	UserID        *int       `json:"user_id" db:"user_id"`
+	FirstName     string     `json:"first_name" db:"first_name"`
+	LastName      string     `json:"last_name" db:"last_name"`
+	Role          string     `json:"role" db:"crew_role"`
+	LicenceNumber string     `json:"licence_number" db:"licence_number"`
+	LicenceExpiry *time.Time `json:"licence_expiry" db:"licence_expiry"`
+	IsActive      bool       `json:"is_active" db:"is_active"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// TripCrew assigns a crew member to a trip with a duty
+type TripCrew struct {
+	ID           int         `json:"id" db:"id"`
+	TripID       int         `json:"trip_id" db:"trip_id"`
+	CrewMemberID int         `json:"crew_member_id" db:"crew_member_id"`
+	Duty         string      `json:"duty" db:"duty"`
+	CrewMember   *CrewMember `json:"crew_member,omitempty" db:"-"`
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
+}
+
+const (
+	CrewRoleDriver    = "driver"
+	CrewRoleAttendant = "attendant"
+)
+
+// CrewDuty is a trip a crew member works on, with what they need to report for it
+type CrewDuty struct {
+	TripID            int       `json:"trip_id"`
+	Duty              string    `json:"duty"`
+	ScheduleID        int       `json:"schedule_id"`
+	RouteID           int       `json:"route_id"`
+	VehicleID         int       `json:"vehicle_id"`
+	LicensePlate      string    `json:"license_plate"`
+	OriginCity        string    `json:"origin_city"`
+	DestinationCity   string    `json:"destination_city"`
+	TravelDate        time.Time `json:"travel_date"`
+	DepartureDatetime time.Time `json:"departure_datetime"`
+	ArrivalDatetime   time.Time `json:"arrival_datetime"`
+	DelayMinutes      int       `json:"delay_minutes"`
+	Status            string    `json:"status"`
+}
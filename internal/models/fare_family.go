@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// FareFamily is a fare of a route, such as Basic, Flex or Premium, with its own price and flexibility rules
+type FareFamily struct {
+	ID          int    `json:"id" db:"id"`
+	RouteID     int    `json:"route_id" db:"route_id"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+	// Seats cost this share of the segment fare plus the surcharge
+	PricePercent float64 `json:"price_percent" db:"price_percent"`
+	Surcharge    float64 `json:"surcharge" db:"surcharge"`
+	FareRules
+	SortOrder int  `json:"sort_order" db:"sort_order"`
+	IsActive  bool `json:"is_active" db:"is_active"`
+	// Ancillaries included with each seat
+	IncludedAncillaries []FareFamilyAncillary `json:"included_ancillaries" db:"-"`
+	CreatedAt           time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// FareRules are the change and cancellation rules of a fare family. Bookings keep the rules in effect when they were sold.
+type FareRules struct {
+	AllowChanges bool `json:"allow_changes" db:"allow_changes"`
+	// Fee to move to another departure; the company's change fee applies when nil
+	ChangeFee    *float64 `json:"change_fee" db:"change_fee"`
+	AllowRefunds bool     `json:"allow_refunds" db:"allow_refunds"`
+	// Share of the fare kept when the passenger cancels
+	CancellationFeePercent float64 `json:"cancellation_fee_percent" db:"cancellation_fee_percent"`
+}
+
+// FareFamilyAncillary is an ancillary included with each seat of a fare family
+type FareFamilyAncillary struct {
+	AncillaryID int    `json:"ancillary_id" db:"ancillary_id"`
+	Category    string `json:"category" db:"category"`
+	Name        string `json:"name" db:"name"`
+	Quantity    int    `json:"quantity" db:"quantity"`
+}
+
+// FareOption is a fare family as offered on a departure, priced for the segment
+type FareOption struct {
+	FareFamily
+	Price float64 `json:"price"`
+}
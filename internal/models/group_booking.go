@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// GroupBooking is a block of seats requested by a group, priced by the operator, secured with a deposit
+// and turned into one booking per passenger once the balance is paid
+type GroupBooking struct {
+	ID                      int       `json:"id" db:"id"`
+	UserID                  int       `json:"user_id" db:"user_id"`
+	TripID                  int       `json:"trip_id" db:"trip_id"`
+	OriginStopSequence      int       `json:"origin_stop_sequence" db:"origin_stop_sequence"`
+	DestinationStopSequence int       `json:"destination_stop_sequence" db:"destination_stop_sequence"`
+	DepartureDatetime       time.Time `json:"departure_datetime" db:"departure_datetime"`
+	GroupName               string    `json:"group_name" db:"group_name"`
+	ContactName             string    `json:"contact_name" db:"contact_name"`
+	ContactPhone            string    `json:"contact_phone" db:"contact_phone"`
+	ContactEmail            string    `json:"contact_email" db:"contact_email"`
+	SeatCount               int       `json:"seat_count" db:"seat_count"`
+	Notes                   string    `json:"notes" db:"notes"`
+	Status                  string    `json:"status" db:"status"`
+	// Set by the operator's quote
+	PricePerSeat      *float64   `json:"price_per_seat" db:"price_per_seat"`
+	TotalAmount       *float64   `json:"total_amount" db:"total_amount"`
+	DepositAmount     *float64   `json:"deposit_amount" db:"deposit_amount"`
+	AmountPaid        float64    `json:"amount_paid" db:"amount_paid"`
+	QuoteExpiresAt    *time.Time `json:"quote_expires_at" db:"quote_expires_at"`
+	PassengerDeadline *time.Time `json:"passenger_deadline" db:"passenger_deadline"`
+	BalanceDeadline   *time.Time `json:"balance_deadline" db:"balance_deadline"`
+	QuotedBy          *int       `json:"quoted_by" db:"quoted_by"`
+	PaymentMethod     string     `json:"payment_method" db:"payment_method"`
+	ConfirmedAt       *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	CompletedAt       *time.Time `json:"completed_at" db:"completed_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+
+	Seats []GroupBookingSeat `json:"seats,omitempty"`
+}
+
+// GroupBookingSeat is a seat of a group's block and the passenger travelling on it
+type GroupBookingSeat struct {
+	ID                int       `json:"id" db:"id"`
+	GroupBookingID    int       `json:"group_booking_id" db:"group_booking_id"`
+	SeatID            int       `json:"seat_id" db:"seat_id"`
+	SeatNumber        string    `json:"seat_number" db:"seat_number"`
+	PassengerName     string    `json:"passenger_name" db:"passenger_name"`
+	PassengerDocument string    `json:"passenger_document" db:"passenger_document"`
+	PassengerPhone    string    `json:"passenger_phone" db:"passenger_phone"`
+	BookingID         *int      `json:"booking_id" db:"booking_id"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+}
+
+const (
+	GroupBookingStatusRequested = "requested"
+	GroupBookingStatusQuoted    = "quoted"
+	GroupBookingStatusConfirmed = "confirmed"
+	GroupBookingStatusCompleted = "completed"
+	GroupBookingStatusDeclined  = "declined"
+	GroupBookingStatusExpired   = "expired"
+	GroupBookingStatusCancelled = "cancelled"
+)
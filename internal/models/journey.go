@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Journey groups the bookings of a trip made of several legs
+type Journey struct {
+	ID          int       `json:"id" db:"id"`
+	UserID      int       `json:"user_id" db:"user_id"`
+	JourneyCode string    `json:"journey_code" db:"journey_code"`
+	JourneyType string    `json:"journey_type" db:"journey_type"`
+	TotalAmount float64   `json:"total_amount" db:"total_amount"`
+	Bookings    []Booking `json:"bookings,omitempty" db:"-"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+const (
+	JourneyTypeConnection = "connection"
+	JourneyTypeRoundTrip  = "round_trip"
+)
+
+// ItineraryLeg is one departure between two stops, as used by itinerary search
+type ItineraryLeg struct {
+	ScheduleID              int       `json:"schedule_id"`
+	RouteID                 int       `json:"route_id"`
+	CompanyID               int       `json:"company_id"`
+	CompanyName             string    `json:"company_name"`
+	TravelDate              string    `json:"travel_date"`
+	OriginStopSequence      int       `json:"origin_stop_sequence"`
+	OriginCity              string    `json:"origin_city"`
+	OriginTerminal          string    `json:"origin_terminal"`
+	DestinationStopSequence int       `json:"destination_stop_sequence"`
+	DestinationCity         string    `json:"destination_city"`
+	DestinationTerminal     string    `json:"destination_terminal"`
+	DepartureDatetime       time.Time `json:"departure_datetime"`
+	ArrivalDatetime         time.Time `json:"arrival_datetime"`
+	Price                   float64   `json:"price"`
+	AvailableSeats          int       `json:"available_seats"`
+}
+
+// Itinerary is a sequence of legs connecting an origin to a destination
+type Itinerary struct {
+	Legs                 []ItineraryLeg `json:"legs"`
+	Transfers            int            `json:"transfers"`
+	DepartureDatetime    time.Time      `json:"departure_datetime"`
+	ArrivalDatetime      time.Time      `json:"arrival_datetime"`
+	TotalDurationMinutes int            `json:"total_duration_minutes"`
+	TotalPrice           float64        `json:"total_price"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ManifestEntry is a booked seat on a departure, as listed on the passenger manifest
+type ManifestEntry struct {
+	BookingID               int        `json:"booking_id"`
+	BookingCode             string     `json:"booking_code"`
+	SeatNumber              string     `json:"seat_number"`
+	PassengerName           string     `json:"passenger_name"`
+	PassengerDocument       string     `json:"passenger_document"`
+	PassengerPhone          string     `json:"passenger_phone"`
+	BoardingStopSequence    int        `json:"boarding_stop_sequence"`
+	BoardingStop            string     `json:"boarding_stop"`
+	AlightingStopSequence   int        `json:"alighting_stop_sequence"`
+	AlightingStop           string     `json:"alighting_stop"`
+	BookingStatus           string     `json:"booking_status"`
+	PaymentStatus           string     `json:"payment_status"`
+	CheckedInAt             *time.Time `json:"checked_in_at"`
+	BoardedAt               *time.Time `json:"boarded_at"`
+	Ancillaries             string     `json:"ancillaries"`
+	OriginStopSequence      *int       `json:"-"`
+	DestinationStopSequence *int       `json:"-"`
+}
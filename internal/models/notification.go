@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Notification struct {
+	ID               int         `json:"id" db:"id"`
+	UserID           int         `json:"user_id" db:"user_id"`
+	BookingID        *int        `json:"booking_id" db:"booking_id"`
+	NotificationType string      `json:"notification_type" db:"notification_type"`
+	Title            string      `json:"title" db:"title"`
+	Message          string      `json:"message" db:"message"`
+	Data             interface{} `json:"data" db:"data"`
+	IsRead           bool        `json:"is_read" db:"is_read"`
+	CreatedAt        time.Time   `json:"created_at" db:"created_at"`
+}
@@ -5,6 +5,9 @@ import "time"
 type Payment struct {
 	ID             int       `json:"id" db:"id"`
 	BookingID      int       `json:"booking_id" db:"booking_id"`
+	JourneyID      *int      `json:"journey_id,omitempty" db:"journey_id"`
+	// Group deposits and balances are paid before the group's bookings exist, without a booking
+	GroupBookingID *int      `json:"group_booking_id,omitempty" db:"group_booking_id"`
 	Amount         float64   `json:"amount" db:"amount"`
 	PaymentMethod  string    `json:"payment_method" db:"payment_method"`
 	PaymentStatus  string    `json:"payment_status" db:"payment_status"`
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// DepartureAttendance counts the departed bookings of a departure by whether the passengers travelled
+type DepartureAttendance struct {
+	ScheduleID      int       `json:"schedule_id"`
+	TravelDate      time.Time `json:"travel_date"`
+	OriginCity      string    `json:"origin_city"`
+	DestinationCity string    `json:"destination_city"`
+	Completed       int       `json:"completed"`
+	NoShows         int       `json:"no_shows"`
+	NoShowFees      float64   `json:"no_show_fees"`
+}
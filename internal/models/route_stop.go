@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type RouteStop struct {
+	ID            int       `json:"id" db:"id"`
+	RouteID       int       `json:"route_id" db:"route_id"`
+	StopSequence  int       `json:"stop_sequence" db:"stop_sequence"`
+	City          string    `json:"city" db:"city"`
+	Terminal      string    `json:"terminal" db:"terminal"`
+	TimeZone      string    `json:"time_zone" db:"time_zone"` // IANA zone, e.g. Europe/Rome
+	OffsetMinutes int       `json:"offset_minutes" db:"offset_minutes"`
+	DistanceKm    int       `json:"distance_km" db:"distance_km"`
+	Latitude      *float64  `json:"latitude" db:"latitude"`
+	Longitude     *float64  `json:"longitude" db:"longitude"`
+	GTFSStopID    string    `json:"gtfs_stop_id,omitempty" db:"gtfs_stop_id"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// RouteFare is an explicit price between two stops, overriding the pro-rated fare
+type RouteFare struct {
+	ID                      int       `json:"id" db:"id"`
+	RouteID                 int       `json:"route_id" db:"route_id"`
+	OriginStopSequence      int       `json:"origin_stop_sequence" db:"origin_stop_sequence"`
+	DestinationStopSequence int       `json:"destination_stop_sequence" db:"destination_stop_sequence"`
+	Price                   float64   `json:"price" db:"price"`
+	CreatedAt               time.Time `json:"created_at" db:"created_at"`
+}
@@ -2,13 +2,16 @@ package models
 
 import "time"
 
+// Schedule is a recurring departure. Departure and arrival times are local to the first and last stop
+// of the route; an optional calendar adds or removes service on specific dates.
 type Schedule struct {
 	ID                int       `json:"id" db:"id"`
 	RouteID           int       `json:"route_id" db:"route_id"`
 	VehicleID         int       `json:"vehicle_id" db:"vehicle_id"`
 	DepartureTime     string    `json:"departure_time" db:"departure_time"`
 	ArrivalTime       string    `json:"arrival_time" db:"arrival_time"`
-	DaysOfWeek        []int     `json:"days_of_week" db:"days_of_week"`
+	DaysOfWeek        []int64   `json:"days_of_week" db:"days_of_week"` // ISO days, 1 = Monday
+	CalendarID        *int      `json:"calendar_id" db:"calendar_id"`
 	ValidFrom         time.Time `json:"valid_from" db:"valid_from"`
 	ValidUntil        *time.Time `json:"valid_until" db:"valid_until"`
 	IsActive          bool      `json:"is_active" db:"is_active"`
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// SeatBlock keeps a specific seat, or a number of seats, off sale on one trip of a schedule
+// or on all of them, optionally until some time before departure
+type SeatBlock struct {
+	ID         int        `json:"id" db:"id"`
+	ScheduleID int        `json:"schedule_id" db:"schedule_id"`
+	TravelDate *time.Time `json:"travel_date" db:"travel_date"`
+	SeatID     *int       `json:"seat_id" db:"seat_id"`
+	SeatNumber string     `json:"seat_number,omitempty" db:"seat_number"`
+	SeatCount  *int       `json:"seat_count" db:"seat_count"`
+	Reason     string     `json:"reason" db:"reason"`
+	Note       string     `json:"note" db:"note"`
+	// Minutes before departure when the seats go back on sale; blocked until departure when nil
+	ReleaseMinutesBefore *int      `json:"release_minutes_before" db:"release_minutes_before"`
+	CreatedBy            *int      `json:"created_by" db:"created_by"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+}
+
+const (
+	SeatBlockReasonStaff     = "staff"
+	SeatBlockReasonEscort    = "escort"
+	SeatBlockReasonAllotment = "allotment"
+	SeatBlockReasonOther     = "other"
+)
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Trip is a schedule materialised for a specific travel date.
+type Trip struct {
+	ID                int        `json:"id" db:"id"`
+	ScheduleID        int        `json:"schedule_id" db:"schedule_id"`
+	VehicleID         int        `json:"vehicle_id" db:"vehicle_id"`
+	TravelDate        time.Time  `json:"travel_date" db:"travel_date"`
+	DepartureDatetime time.Time  `json:"departure_datetime" db:"departure_datetime"`
+	ArrivalDatetime   time.Time  `json:"arrival_datetime" db:"arrival_datetime"`
+	Status            string     `json:"status" db:"status"`
+	DelayMinutes      int        `json:"delay_minutes" db:"delay_minutes"`
+	StatusReason      string     `json:"status_reason" db:"status_reason"`
+	StatusChangedAt   *time.Time `json:"status_changed_at" db:"status_changed_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+const (
+	TripStatusScheduled = "scheduled"
+	TripStatusDelayed   = "delayed"
+	TripStatusCancelled = "cancelled"
+)
+
+// Departure identifies a schedule running on a specific date, whether or not its trip has been materialised.
+type Departure struct {
+	ScheduleID        int       `json:"schedule_id"`
+	TravelDate        time.Time `json:"travel_date"`
+	DepartureDatetime time.Time `json:"departure_datetime"`
+}
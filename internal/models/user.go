@@ -11,20 +11,34 @@ type User struct {
 	Phone          string    `json:"phone" db:"phone"`
 	DocumentType   string    `json:"document_type" db:"document_type"`
 	DocumentNumber string    `json:"document_number" db:"document_number"`
+	Role           string    `json:"role" db:"role"`
+	CompanyID      *int      `json:"company_id" db:"company_id"`
 	IsActive       bool      `json:"is_active" db:"is_active"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 }
 
+const (
+	RolePassenger = "passenger"
+	RoleOperator  = "operator"
+	RoleAdmin     = "admin"
+)
+
 type Company struct {
-	ID        int       `json:"id" db:"id"`
-	Name      string    `json:"name" db:"name"`
-	Cuit      string    `json:"cuit" db:"cuit"`
-	Phone     string    `json:"phone" db:"phone"`
-	Email     string    `json:"email" db:"email"`
-	Address   string    `json:"address" db:"address"`
-	Icon      string    `json:"icon" db:"icon"`
+	ID      int    `json:"id" db:"id"`
+	Name    string `json:"name" db:"name"`
+	Cuit    string `json:"cuit" db:"cuit"`
+	Phone   string `json:"phone" db:"phone"`
+	Email   string `json:"email" db:"email"`
+	Address string `json:"address" db:"address"`
+	Icon    string `json:"icon" db:"icon"`
+	// Discount applied to both legs of a round trip operated by the company
+	RoundTripDiscountPercent float64 `json:"round_trip_discount_percent" db:"round_trip_discount_percent"`
+	// Share of the fare kept when a passenger does not show up; the rest is refunded
+	NoShowFeePercent float64 `json:"no_show_fee_percent" db:"no_show_fee_percent"`
+	// Fee charged to move a booking to another departure
+	ChangeFee float64   `json:"change_fee" db:"change_fee"`
 	IsActive  bool      `json:"is_active" db:"is_active"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// VehicleDevice is an on-board unit that reports the positions of a vehicle with its own token
+type VehicleDevice struct {
+	ID         int        `json:"id" db:"id"`
+	VehicleID  int        `json:"vehicle_id" db:"vehicle_id"`
+	Name       string     `json:"name" db:"name" binding:"required"`
+	IsActive   bool       `json:"is_active" db:"is_active"`
+	LastSeenAt *time.Time `json:"last_seen_at" db:"last_seen_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// MaintenanceWindow is a period during which a vehicle is in maintenance and cannot run trips
+type MaintenanceWindow struct {
+	ID        int       `json:"id" db:"id"`
+	VehicleID int       `json:"vehicle_id" db:"vehicle_id"`
+	StartsAt  time.Time `json:"starts_at" db:"starts_at" binding:"required"`
+	EndsAt    time.Time `json:"ends_at" db:"ends_at" binding:"required"`
+	Reason    string    `json:"reason" db:"reason" binding:"required"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// VehiclePosition is a location reported by a vehicle, linked to the trip it was running if any
+type VehiclePosition struct {
+	ID         int64     `json:"id" db:"id"`
+	VehicleID  int       `json:"vehicle_id" db:"vehicle_id"`
+	TripID     *int      `json:"trip_id" db:"trip_id"`
+	Latitude   float64   `json:"latitude" db:"latitude"`
+	Longitude  float64   `json:"longitude" db:"longitude"`
+	Bearing    *float64  `json:"bearing" db:"bearing"`
+	SpeedKmh   *float64  `json:"speed_kmh" db:"speed_kmh"`
+	RecordedAt time.Time `json:"recorded_at" db:"recorded_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
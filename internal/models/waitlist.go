@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// WaitlistEntry is a passenger waiting for a seat on a sold-out trip. Freed seats are offered
+// to entries in order and held until the offer is claimed or expires.
+type WaitlistEntry struct {
+	ID       int     `json:"id" db:"id"`
+	TripID   int     `json:"trip_id" db:"trip_id"`
+	UserID   int     `json:"user_id" db:"user_id"`
+	SeatType *string `json:"seat_type" db:"seat_type"`
+	// Segment the passenger wants to travel
+	OriginStopSequence      int        `json:"origin_stop_sequence" db:"origin_stop_sequence"`
+	DestinationStopSequence int        `json:"destination_stop_sequence" db:"destination_stop_sequence"`
+	PassengerName           string     `json:"passenger_name" db:"passenger_name"`
+	PassengerDocument       string     `json:"passenger_document" db:"passenger_document"`
+	PassengerPhone          string     `json:"passenger_phone" db:"passenger_phone"`
+	Status                  string     `json:"status" db:"status"`
+	OfferedSeatID           *int       `json:"offered_seat_id" db:"offered_seat_id"`
+	OfferedAt               *time.Time `json:"offered_at" db:"offered_at"`
+	OfferExpiresAt          *time.Time `json:"offer_expires_at" db:"offer_expires_at"`
+	BookingID               *int       `json:"booking_id" db:"booking_id"`
+	CreatedAt               time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusOffered   = "offered"
+	WaitlistStatusClaimed   = "claimed"
+	WaitlistStatusExpired   = "expired"
+	WaitlistStatusCancelled = "cancelled"
+)
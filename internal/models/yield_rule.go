@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// YieldRule is a price band of a route: seats sold within its load factor and booking horizon
+// cost a share of the segment fare
+type YieldRule struct {
+	ID      int    `json:"id" db:"id"`
+	RouteID int    `json:"route_id" db:"route_id"`
+	Name    string `json:"name" db:"name"`
+	// Band of the share of seats already sold on the segment
+	MinLoadPercent float64 `json:"min_load_percent" db:"min_load_percent"`
+	MaxLoadPercent float64 `json:"max_load_percent" db:"max_load_percent"`
+	// Band of whole days left before departure; nil for no bound
+	MinDaysBefore *int `json:"min_days_before" db:"min_days_before"`
+	MaxDaysBefore *int `json:"max_days_before" db:"max_days_before"`
+	// Share of the segment fare charged
+	PricePercent float64 `json:"price_percent" db:"price_percent"`
+	// Rules are evaluated lowest priority first; the first matching one applies
+	Priority  int       `json:"priority" db:"priority"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// YieldQuote is the price band applied to a departure at a given moment
+type YieldQuote struct {
+	LoadPercent  float64 `json:"load_percent"`
+	DaysBefore   int     `json:"days_before"`
+	RuleID       *int    `json:"rule_id"`
+	RuleName     string  `json:"rule_name,omitempty"`
+	PricePercent float64 `json:"price_percent"`
+	Price        float64 `json:"price"`
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+const ancillaryColumns = `id, company_id, category, name, COALESCE(description, ''), price, fare_percent, capacity_per_trip, is_active, created_at, updated_at`
+
+func scanAncillary(row rowScanner, ancillary *models.Ancillary) error {
+	return row.Scan(
+		&ancillary.ID, &ancillary.CompanyID, &ancillary.Category, &ancillary.Name, &ancillary.Description, &ancillary.Price, &ancillary.FarePercent, &ancillary.CapacityPerTrip, &ancillary.IsActive, &ancillary.CreatedAt, &ancillary.UpdatedAt,
+	)
+}
+
+func CreateAncillary(db DBInterface, ancillary *models.Ancillary) error {
+	query := `
+		INSERT INTO ancillaries (company_id, category, name, description, price, fare_percent, capacity_per_trip, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, ancillary.CompanyID, ancillary.Category, ancillary.Name, ancillary.Description, ancillary.Price, ancillary.FarePercent, ancillary.CapacityPerTrip, ancillary.IsActive).Scan(&ancillary.ID, &ancillary.CreatedAt, &ancillary.UpdatedAt)
+}
+
+func GetAncillaryByID(db DBInterface, id int) (*models.Ancillary, error) {
+	var ancillary models.Ancillary
+	query := `SELECT ` + ancillaryColumns + ` FROM ancillaries WHERE id = $1`
+
+	if err := scanAncillary(db.QueryRow(query, id), &ancillary); err != nil {
+		return nil, err
+	}
+	return &ancillary, nil
+}
+
+// GetAncillariesByCompanyID lists the ancillaries of a company, only the active ones when asked
+func GetAncillariesByCompanyID(db DBInterface, companyID int, activeOnly bool) ([]models.Ancillary, error) {
+	query := `SELECT ` + ancillaryColumns + ` FROM ancillaries WHERE company_id = $1 AND (is_active OR NOT $2) ORDER BY category, name, id`
+
+	rows, err := db.Query(query, companyID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ancillaries := []models.Ancillary{}
+	for rows.Next() {
+		var ancillary models.Ancillary
+		if err := scanAncillary(rows, &ancillary); err != nil {
+			return nil, err
+		}
+		ancillaries = append(ancillaries, ancillary)
+	}
+
+	return ancillaries, nil
+}
+
+func UpdateAncillary(db DBInterface, ancillary *models.Ancillary) error {
+	query := `
+		UPDATE ancillaries
+		SET category = $2, name = $3, description = NULLIF($4, ''), price = $5, fare_percent = $6, capacity_per_trip = $7, is_active = $8, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	return db.QueryRow(query, ancillary.ID, ancillary.Category, ancillary.Name, ancillary.Description, ancillary.Price, ancillary.FarePercent, ancillary.CapacityPerTrip, ancillary.IsActive).Scan(&ancillary.UpdatedAt)
+}
+
+// LockAncillary loads an ancillary and locks it until the end of the transaction,
+// so that concurrent sales are checked against its capacity one after the other
+func LockAncillary(db DBInterface, id int) (*models.Ancillary, error) {
+	var ancillary models.Ancillary
+	query := `SELECT ` + ancillaryColumns + ` FROM ancillaries WHERE id = $1 FOR UPDATE`
+
+	if err := scanAncillary(db.QueryRow(query, id), &ancillary); err != nil {
+		return nil, err
+	}
+	return &ancillary, nil
+}
+
+// CountSoldAncillaries returns the units of an ancillary sold on a departure for bookings, other than the excluded
+// one, whose segment overlaps the given one. Bookings without a segment cover the whole route.
+func CountSoldAncillaries(db DBInterface, ancillaryID, scheduleID int, travelDate time.Time, originStopSequence, destinationStopSequence, excludedBookingID int) (int, error) {
+	var sold int
+	query := `
+		SELECT COALESCE(SUM(ba.quantity), 0)
+		FROM booking_ancillaries ba
+		JOIN bookings b ON ba.booking_id = b.id
+		WHERE ba.ancillary_id = $1
+		AND b.schedule_id = $2
+		AND b.travel_date::date = $3::date
+		AND b.booking_status IN ('confirmed', 'pending')
+		AND COALESCE(b.origin_stop_sequence, 0) < $5
+		AND $4 < COALESCE(b.destination_stop_sequence, 2147483647)
+		AND b.id <> $6`
+
+	err := db.QueryRow(query, ancillaryID, scheduleID, travelDate, originStopSequence, destinationStopSequence, excludedBookingID).Scan(&sold)
+	return sold, err
+}
+
+const bookingAncillaryColumns = `ba.id, ba.booking_id, ba.ancillary_id, a.category, a.name, ba.quantity, ba.unit_price, ba.total_price, ba.created_at`
+
+func scanBookingAncillary(row rowScanner, item *models.BookingAncillary) error {
+	return row.Scan(
+		&item.ID, &item.BookingID, &item.AncillaryID, &item.Category, &item.Name, &item.Quantity, &item.UnitPrice, &item.TotalPrice, &item.CreatedAt,
+	)
+}
+
+func CreateBookingAncillary(db DBInterface, item *models.BookingAncillary) error {
+	query := `
+		INSERT INTO booking_ancillaries (booking_id, ancillary_id, quantity, unit_price, total_price, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id, created_at`
+
+	return db.QueryRow(query, item.BookingID, item.AncillaryID, item.Quantity, item.UnitPrice, item.TotalPrice).Scan(&item.ID, &item.CreatedAt)
+}
+
+func GetBookingAncillaries(db DBInterface, bookingID int) ([]models.BookingAncillary, error) {
+	query := `
+		SELECT ` + bookingAncillaryColumns + `
+		FROM booking_ancillaries ba
+		JOIN ancillaries a ON ba.ancillary_id = a.id
+		WHERE ba.booking_id = $1
+		ORDER BY ba.id`
+
+	return queryBookingAncillaries(db, query, bookingID)
+}
+
+// GetTripAncillaries lists the ancillaries of the bookings still active on a departure
+func GetTripAncillaries(db DBInterface, scheduleID int, travelDate time.Time) ([]models.BookingAncillary, error) {
+	query := `
+		SELECT ` + bookingAncillaryColumns + `
+		FROM booking_ancillaries ba
+		JOIN ancillaries a ON ba.ancillary_id = a.id
+		JOIN bookings b ON ba.booking_id = b.id
+		WHERE b.schedule_id = $1
+		AND b.travel_date::date = $2::date
+		AND b.booking_status <> 'cancelled'
+		ORDER BY ba.booking_id, ba.id`
+
+	return queryBookingAncillaries(db, query, scheduleID, travelDate)
+}
+
+func queryBookingAncillaries(db DBInterface, query string, args ...interface{}) ([]models.BookingAncillary, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.BookingAncillary{}
+	for rows.Next() {
+		var item models.BookingAncillary
+		if err := scanBookingAncillary(rows, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
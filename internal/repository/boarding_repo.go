@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+const boardingScanColumns = `id, trip_id, booking_seat_id, device_id, scanned_by, COALESCE(scan_id, ''), COALESCE(booking_code, ''), COALESCE(seat_number, ''), COALESCE(passenger_name, ''), result, COALESCE(reason, ''), scanned_at, created_at`
+
+func scanBoardingScan(row rowScanner) (*models.BoardingScan, error) {
+	var scan models.BoardingScan
+	err := row.Scan(
+		&scan.ID, &scan.TripID, &scan.BookingSeatID, &scan.DeviceID, &scan.ScannedBy, &scan.ScanID, &scan.BookingCode, &scan.SeatNumber, &scan.PassengerName, &scan.Result, &scan.Reason, &scan.ScannedAt, &scan.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &scan, nil
+}
+
+func CreateBoardingScan(db DBInterface, scan *models.BoardingScan) error {
+	query := `
+		INSERT INTO boarding_scans (trip_id, booking_seat_id, device_id, scanned_by, scan_id, booking_code, seat_number, passenger_name, result, reason, scanned_at, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9, NULLIF($10, ''), $11, NOW())
+		RETURNING id, created_at`
+
+	return db.QueryRow(query, scan.TripID, scan.BookingSeatID, scan.DeviceID, scan.ScannedBy, scan.ScanID, scan.BookingCode, scan.SeatNumber, scan.PassengerName, scan.Result, scan.Reason, scan.ScannedAt).Scan(&scan.ID, &scan.CreatedAt)
+}
+
+// GetBoardingScanByScanID returns a scan already uploaded for a trip by its scanner-generated id
+func GetBoardingScanByScanID(db DBInterface, tripID int, scanID string) (*models.BoardingScan, error) {
+	query := `SELECT ` + boardingScanColumns + ` FROM boarding_scans WHERE trip_id = $1 AND scan_id = $2`
+	return scanBoardingScan(db.QueryRow(query, tripID, scanID))
+}
+
+// GetTripBoardingScans returns the scans of a trip in the order they were made
+func GetTripBoardingScans(db DBInterface, tripID int) ([]models.BoardingScan, error) {
+	query := `SELECT ` + boardingScanColumns + ` FROM boarding_scans WHERE trip_id = $1 ORDER BY scanned_at, id`
+
+	rows, err := db.Query(query, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scans := []models.BoardingScan{}
+	for rows.Next() {
+		scan, err := scanBoardingScan(rows)
+		if err != nil {
+			return nil, err
+		}
+		scans = append(scans, *scan)
+	}
+
+	return scans, nil
+}
+
+// LockBoardingSeat returns a booked seat with its booking and locks it until the end of the transaction
+func LockBoardingSeat(db DBInterface, bookingSeatID int) (*models.BoardingSeat, error) {
+	var seat models.BoardingSeat
+	query := `
+		SELECT bs.id, b.id, b.booking_code, b.schedule_id, b.travel_date, b.booking_status, s.seat_number, b.passenger_name, bs.boarded_at
+		FROM booking_seats bs
+		JOIN bookings b ON bs.booking_id = b.id
+		JOIN seats s ON bs.seat_id = s.id
+		WHERE bs.id = $1
+		FOR UPDATE OF bs`
+
+	err := db.QueryRow(query, bookingSeatID).Scan(
+		&seat.BookingSeatID, &seat.BookingID, &seat.BookingCode, &seat.ScheduleID, &seat.TravelDate, &seat.BookingStatus, &seat.SeatNumber, &seat.PassengerName, &seat.BoardedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &seat, nil
+}
+
+// MarkSeatBoarded records that a seat boarded, keeping the earliest boarding time
+func MarkSeatBoarded(db DBInterface, bookingSeatID int, boardedAt time.Time) error {
+	query := `UPDATE booking_seats SET boarded_at = LEAST(COALESCE(boarded_at, $2), $2) WHERE id = $1`
+	_, err := db.Exec(query, bookingSeatID, boardedAt)
+	return err
+}
+
+// CountTripBoardingScans returns how many tickets were scanned for a trip
+func CountTripBoardingScans(db DBInterface, tripID int) (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM boarding_scans WHERE trip_id = $1`, tripID).Scan(&count)
+	return count, err
+}
+
+// CountBoardedSeats returns how many seats of a booking boarded
+func CountBoardedSeats(db DBInterface, bookingID int) (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM booking_seats WHERE booking_id = $1 AND boarded_at IS NOT NULL`, bookingID).Scan(&count)
+	return count, err
+}
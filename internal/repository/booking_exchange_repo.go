@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+func CreateBookingExchange(db DBInterface, exchange *models.BookingExchange) error {
+	previousJSON, err := json.Marshal(exchange.PreviousBooking)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO booking_exchanges (booking_id, previous_booking, schedule_id, travel_date, previous_fare, new_fare, change_fee, amount_due, payment_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING id, created_at`
+
+	return db.QueryRow(query, exchange.BookingID, previousJSON, exchange.ScheduleID, exchange.TravelDate, exchange.PreviousFare, exchange.NewFare, exchange.ChangeFee, exchange.AmountDue, exchange.PaymentID).Scan(&exchange.ID, &exchange.CreatedAt)
+}
+
+// GetBookingExchanges returns the exchanges of a booking, oldest first
+func GetBookingExchanges(db DBInterface, bookingID int) ([]models.BookingExchange, error) {
+	query := `
+		SELECT id, booking_id, previous_booking, schedule_id, travel_date, previous_fare, new_fare, change_fee, amount_due, payment_id, created_at
+		FROM booking_exchanges
+		WHERE booking_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := db.Query(query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exchanges := []models.BookingExchange{}
+	for rows.Next() {
+		var exchange models.BookingExchange
+		var previousJSON []byte
+		err := rows.Scan(
+			&exchange.ID, &exchange.BookingID, &previousJSON, &exchange.ScheduleID, &exchange.TravelDate, &exchange.PreviousFare, &exchange.NewFare, &exchange.ChangeFee, &exchange.AmountDue, &exchange.PaymentID, &exchange.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(previousJSON, &exchange.PreviousBooking); err != nil {
+			return nil, err
+		}
+		exchanges = append(exchanges, exchange)
+	}
+
+	return exchanges, nil
+}
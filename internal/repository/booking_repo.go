@@ -2,26 +2,62 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
 )
 
+const bookingColumns = `id, user_id, schedule_id, journey_id, leg_sequence, fare_family_id, yield_percent, fare_allow_changes, fare_change_fee, fare_allow_refunds, fare_cancellation_fee_percent, booking_code, travel_date, origin_stop_sequence, destination_stop_sequence, departure_datetime, passenger_name, passenger_document, passenger_phone, COALESCE(passenger_document_type, ''), passenger_document_expiry, COALESCE(passenger_nationality, ''), total_amount, discount_amount, ancillary_amount, no_show_fee, delay_compensation, payment_status, booking_status, payment_method, COALESCE(notes, ''), checked_in_at, created_at, updated_at`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBooking(row rowScanner, booking *models.Booking) error {
+	var allowChanges, allowRefunds sql.NullBool
+	var cancellationFeePercent sql.NullFloat64
+	var rules models.FareRules
+	err := row.Scan(
+		&booking.ID, &booking.UserID, &booking.ScheduleID, &booking.JourneyID, &booking.LegSequence, &booking.FareFamilyID, &booking.YieldPercent, &allowChanges, &rules.ChangeFee, &allowRefunds, &cancellationFeePercent, &booking.BookingCode, &booking.TravelDate, &booking.OriginStopSequence, &booking.DestinationStopSequence, &booking.DepartureDatetime, &booking.PassengerName, &booking.PassengerDocument, &booking.PassengerPhone, &booking.PassengerDocumentType, &booking.PassengerDocumentExpiry, &booking.PassengerNationality, &booking.TotalAmount, &booking.DiscountAmount, &booking.AncillaryAmount, &booking.NoShowFee, &booking.DelayCompensation, &booking.PaymentStatus, &booking.BookingStatus, &booking.PaymentMethod, &booking.Notes, &booking.CheckedInAt, &booking.CreatedAt, &booking.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	booking.FareRules = nil
+	if allowChanges.Valid {
+		rules.AllowChanges = allowChanges.Bool
+		rules.AllowRefunds = allowRefunds.Bool
+		rules.CancellationFeePercent = cancellationFeePercent.Float64
+		booking.FareRules = &rules
+	}
+	return nil
+}
+
+// fareRuleValues returns the fare rules of a booking as the values of its fare rule columns
+func fareRuleValues(rules *models.FareRules) (allowChanges, changeFee, allowRefunds, cancellationFeePercent interface{}) {
+	if rules == nil {
+		return nil, nil, nil, nil
+	}
+	return rules.AllowChanges, rules.ChangeFee, rules.AllowRefunds, rules.CancellationFeePercent
+}
+
 func CreateBooking(db DBInterface, booking *models.Booking) error {
 	query := `
-		INSERT INTO bookings (user_id, schedule_id, booking_code, travel_date, departure_datetime, passenger_name, passenger_document, passenger_phone, total_amount, payment_status, booking_status, payment_method, notes, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW())
-		RETURNING id`
+		INSERT INTO bookings (user_id, schedule_id, journey_id, leg_sequence, fare_family_id, yield_percent, fare_allow_changes, fare_change_fee, fare_allow_refunds, fare_cancellation_fee_percent, booking_code, travel_date, origin_stop_sequence, destination_stop_sequence, departure_datetime, passenger_name, passenger_document, passenger_phone, total_amount, discount_amount, ancillary_amount, payment_status, booking_status, payment_method, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
 
-	return db.QueryRow(query, booking.UserID, booking.ScheduleID, booking.BookingCode, booking.TravelDate, booking.DepartureDatetime, booking.PassengerName, booking.PassengerDocument, booking.PassengerPhone, booking.TotalAmount, booking.PaymentStatus, booking.BookingStatus, booking.PaymentMethod, booking.Notes).Scan(&booking.ID)
+	allowChanges, changeFee, allowRefunds, cancellationFeePercent := fareRuleValues(booking.FareRules)
+	return db.QueryRow(query, booking.UserID, booking.ScheduleID, booking.JourneyID, booking.LegSequence, booking.FareFamilyID, booking.YieldPercent, allowChanges, changeFee, allowRefunds, cancellationFeePercent, booking.BookingCode, booking.TravelDate, booking.OriginStopSequence, booking.DestinationStopSequence, booking.DepartureDatetime, booking.PassengerName, booking.PassengerDocument, booking.PassengerPhone, booking.TotalAmount, booking.DiscountAmount, booking.AncillaryAmount, booking.PaymentStatus, booking.BookingStatus, booking.PaymentMethod, booking.Notes).Scan(&booking.ID, &booking.CreatedAt, &booking.UpdatedAt)
 }
 
-func GetBookingByID(db *sql.DB, id int) (*models.Booking, error) {
+func GetBookingByID(db DBInterface, id int) (*models.Booking, error) {
 	var booking models.Booking
-	query := `SELECT id, user_id, schedule_id, booking_code, travel_date, departure_datetime, passenger_name, passenger_document, passenger_phone, total_amount, payment_status, booking_status, payment_method, notes, created_at, updated_at FROM bookings WHERE id = $1`
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1`
 
-	err := db.QueryRow(query, id).Scan(
-		&booking.ID, &booking.UserID, &booking.ScheduleID, &booking.BookingCode, &booking.TravelDate, &booking.DepartureDatetime, &booking.PassengerName, &booking.PassengerDocument, &booking.PassengerPhone, &booking.TotalAmount, &booking.PaymentStatus, &booking.BookingStatus, &booking.PaymentMethod, &booking.Notes, &booking.CreatedAt, &booking.UpdatedAt,
-	)
+	err := scanBooking(db.QueryRow(query, id), &booking)
 	if err != nil {
 		return nil, err
 	}
@@ -30,7 +66,7 @@ func GetBookingByID(db *sql.DB, id int) (*models.Booking, error) {
 }
 
 func GetBookingsByUserID(db *sql.DB, userID int) ([]models.Booking, error) {
-	query := `SELECT id, user_id, schedule_id, booking_code, travel_date, departure_datetime, passenger_name, passenger_document, passenger_phone, total_amount, payment_status, booking_status, payment_method, notes, created_at, updated_at FROM bookings WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE user_id = $1 ORDER BY created_at DESC`
 
 	rows, err := db.Query(query, userID)
 	if err != nil {
@@ -41,9 +77,87 @@ func GetBookingsByUserID(db *sql.DB, userID int) ([]models.Booking, error) {
 	var bookings []models.Booking
 	for rows.Next() {
 		var booking models.Booking
-		err := rows.Scan(
-			&booking.ID, &booking.UserID, &booking.ScheduleID, &booking.BookingCode, &booking.TravelDate, &booking.DepartureDatetime, &booking.PassengerName, &booking.PassengerDocument, &booking.PassengerPhone, &booking.TotalAmount, &booking.PaymentStatus, &booking.BookingStatus, &booking.PaymentMethod, &booking.Notes, &booking.CreatedAt, &booking.UpdatedAt,
-		)
+		err := scanBooking(rows, &booking)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	return bookings, nil
+}
+
+// GetActiveBookingsForTrip returns the confirmed and pending bookings of a schedule on a travel date
+func GetActiveBookingsForTrip(db DBInterface, scheduleID int, travelDate time.Time) ([]models.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE schedule_id = $1 AND travel_date = $2::date AND booking_status IN ('confirmed', 'pending') ORDER BY id`
+
+	rows, err := db.Query(query, scheduleID, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []models.Booking
+	for rows.Next() {
+		var booking models.Booking
+		err := scanBooking(rows, &booking)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	return bookings, nil
+}
+
+func GetBookingsByJourneyID(db DBInterface, journeyID int) ([]models.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE journey_id = $1 ORDER BY leg_sequence`
+
+	rows, err := db.Query(query, journeyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []models.Booking
+	for rows.Next() {
+		var booking models.Booking
+		err := scanBooking(rows, &booking)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	return bookings, nil
+}
+
+func UpdateBookingStatus(db DBInterface, id int, bookingStatus string, paymentStatus string) error {
+	query := `UPDATE bookings SET booking_status = $2, payment_status = $3, updated_at = NOW() WHERE id = $1`
+	_, err := db.Exec(query, id, bookingStatus, paymentStatus)
+	return err
+}
+
+// SetDelayCompensation entitles a booking to a fee-free refund or rebooking after a long delay
+func SetDelayCompensation(db DBInterface, id int) error {
+	_, err := db.Exec(`UPDATE bookings SET delay_compensation = true, updated_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// GetDepartedBookings returns the confirmed bookings that boarded before the given time, oldest first
+func GetDepartedBookings(db DBInterface, before time.Time) ([]models.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE booking_status = 'confirmed' AND departure_datetime < $1 ORDER BY departure_datetime, id`
+
+	rows, err := db.Query(query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []models.Booking
+	for rows.Next() {
+		var booking models.Booking
+		err := scanBooking(rows, &booking)
 		if err != nil {
 			return nil, err
 		}
@@ -53,18 +167,70 @@ func GetBookingsByUserID(db *sql.DB, userID int) ([]models.Booking, error) {
 	return bookings, nil
 }
 
-func UpdateBooking(db *sql.DB, booking *models.Booking) error {
+// SetBookingAttendance records whether a departed booking travelled. It returns sql.ErrNoRows when the
+// booking is no longer confirmed.
+func SetBookingAttendance(db DBInterface, booking *models.Booking) error {
 	query := `
 		UPDATE bookings
-		SET user_id = $2, schedule_id = $3, booking_code = $4, travel_date = $5, departure_datetime = $6, passenger_name = $7, passenger_document = $8, passenger_phone = $9, total_amount = $10, payment_status = $11, booking_status = $12, payment_method = $13, notes = $14, updated_at = NOW()
-		WHERE id = $1`
+		SET booking_status = $2, payment_status = $3, no_show_fee = $4, updated_at = NOW()
+		WHERE id = $1 AND booking_status = 'confirmed'
+		RETURNING updated_at`
 
-	_, err := db.Exec(query, booking.ID, booking.UserID, booking.ScheduleID, booking.BookingCode, booking.TravelDate, booking.DepartureDatetime, booking.PassengerName, booking.PassengerDocument, booking.PassengerPhone, booking.TotalAmount, booking.PaymentStatus, booking.BookingStatus, booking.PaymentMethod, booking.Notes)
-	return err
+	return db.QueryRow(query, booking.ID, booking.BookingStatus, booking.PaymentStatus, booking.NoShowFee).Scan(&booking.UpdatedAt)
+}
+
+// UpdateBookingDetails updates the passenger contact details and notes of a booking
+func UpdateBookingDetails(db DBInterface, booking *models.Booking) error {
+	query := `
+		UPDATE bookings
+		SET passenger_name = $2, passenger_phone = $3, notes = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	return db.QueryRow(query, booking.ID, booking.PassengerName, booking.PassengerPhone, booking.Notes).Scan(&booking.UpdatedAt)
+}
+
+// ExchangeBooking moves a booking to another departure, segment or fare, keeping its delay compensation as
+// given. Check-in has to be done again.
+func ExchangeBooking(db DBInterface, booking *models.Booking) error {
+	query := `
+		UPDATE bookings
+		SET schedule_id = $2, travel_date = $3, origin_stop_sequence = $4, destination_stop_sequence = $5, departure_datetime = $6, total_amount = $7, fare_family_id = $8, yield_percent = $9,
+		    fare_allow_changes = $10, fare_change_fee = $11, fare_allow_refunds = $12, fare_cancellation_fee_percent = $13, delay_compensation = $14, checked_in_at = NULL, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	booking.CheckedInAt = nil
+	allowChanges, changeFee, allowRefunds, cancellationFeePercent := fareRuleValues(booking.FareRules)
+	return db.QueryRow(query, booking.ID, booking.ScheduleID, booking.TravelDate, booking.OriginStopSequence, booking.DestinationStopSequence, booking.DepartureDatetime, booking.TotalAmount, booking.FareFamilyID, booking.YieldPercent,
+		allowChanges, changeFee, allowRefunds, cancellationFeePercent, booking.DelayCompensation).Scan(&booking.UpdatedAt)
+}
+
+// CheckInBooking stores the passenger data collected at check-in and marks the booking checked in.
+// It returns sql.ErrNoRows when the booking is already checked in.
+func CheckInBooking(db DBInterface, booking *models.Booking) error {
+	query := `
+		UPDATE bookings
+		SET passenger_document = $2, passenger_document_type = $3, passenger_document_expiry = $4, passenger_nationality = $5, checked_in_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND checked_in_at IS NULL
+		RETURNING checked_in_at, updated_at`
+
+	return db.QueryRow(query, booking.ID, booking.PassengerDocument, booking.PassengerDocumentType, booking.PassengerDocumentExpiry, booking.PassengerNationality).Scan(&booking.CheckedInAt, &booking.UpdatedAt)
 }
 
 func DeleteBooking(db *sql.DB, id int) error {
 	query := `DELETE FROM bookings WHERE id = $1`
 	_, err := db.Exec(query, id)
 	return err
-}
\ No newline at end of file
+}
+
+// AddBookingAncillaryAmount adds the price of ancillaries bought after booking to the booking total
+func AddBookingAncillaryAmount(db DBInterface, booking *models.Booking, amount float64) error {
+	query := `
+		UPDATE bookings
+		SET ancillary_amount = ancillary_amount + $2, total_amount = total_amount + $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ancillary_amount, total_amount, updated_at`
+
+	return db.QueryRow(query, booking.ID, amount).Scan(&booking.AncillaryAmount, &booking.TotalAmount, &booking.UpdatedAt)
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"database/sql"
+	"math"
+	"time"
 
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
 )
@@ -22,7 +24,7 @@ func CreateBookingSeat(db DBInterface, bookingSeat *models.BookingSeat) error {
 	return db.QueryRow(query, bookingSeat.BookingID, bookingSeat.SeatID).Scan(&bookingSeat.ID)
 }
 
-func GetBookingSeatsByBookingID(db *sql.DB, bookingID int) ([]models.BookingSeat, error) {
+func GetBookingSeatsByBookingID(db DBInterface, bookingID int) ([]models.BookingSeat, error) {
 	query := `SELECT id, booking_id, seat_id, created_at FROM booking_seats WHERE booking_id = $1`
 
 	rows, err := db.Query(query, bookingID)
@@ -46,11 +48,31 @@ func GetBookingSeatsByBookingID(db *sql.DB, bookingID int) ([]models.BookingSeat
 	return bookingSeats, nil
 }
 
+// GetAvailableSeatsForSchedule returns the seats free on the whole route of a schedule on a travel date
 func GetAvailableSeatsForSchedule(db DBInterface, scheduleID int, travelDate string) ([]models.Seat, error) {
+	return GetAvailableSeatsForSegment(db, scheduleID, travelDate, 0, math.MaxInt32)
+}
+
+// LockDeparture locks the departure of a schedule on a travel date until the end of the transaction, so that
+// concurrent sales and holds of its seats check availability one after the other
+func LockDeparture(db DBInterface, scheduleID int, travelDate string) error {
+	_, err := db.Exec(`SELECT pg_advisory_xact_lock($1, $2::date - DATE '2000-01-01')`, scheduleID, travelDate)
+	return err
+}
+
+// GetAvailableSeatsForSegment returns the seats free between two stops of a schedule on a travel date.
+// Seats are those of the trip's vehicle, which defaults to the schedule's until the trip is given another one.
+// A seat is taken when a booking's segment overlaps the requested one; bookings without a segment cover the whole route.
+// Seats held for a waitlist offer are taken on the whole route until the offer is claimed or expires, and
+// seats of a group's block on the group's segment while the quote runs or the group is confirmed.
+// Seats blocked by the operator are taken until their block is released.
+func GetAvailableSeatsForSegment(db DBInterface, scheduleID int, travelDate string, originStopSequence int, destinationStopSequence int) ([]models.Seat, error) {
 	query := `
 		SELECT s.id, s.vehicle_id, s.seat_number, s.seat_type, s.row_number, s.column_position, s.price_modifier, s.is_available, s.created_at
-		FROM seats s
-		JOIN schedules sch ON s.vehicle_id = sch.vehicle_id
+		FROM schedules sch
+		` + firstStopJoin + `
+		LEFT JOIN trips t ON t.schedule_id = sch.id AND t.travel_date = $2::date
+		JOIN seats s ON s.vehicle_id = COALESCE(t.vehicle_id, sch.vehicle_id)
 		WHERE sch.id = $1
 		AND s.is_available = true
 		AND NOT EXISTS (
@@ -60,10 +82,34 @@ func GetAvailableSeatsForSchedule(db DBInterface, scheduleID int, travelDate str
 			AND b.schedule_id = $1
 			AND b.travel_date::date = $2::date
 			AND b.booking_status IN ('confirmed', 'pending')
+			AND COALESCE(b.origin_stop_sequence, 0) < $4
+			AND $3 < COALESCE(b.destination_stop_sequence, 2147483647)
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM waitlist_entries w
+			WHERE w.trip_id = t.id
+			AND w.offered_seat_id = s.id
+			AND w.status = 'offered'
+			AND w.offer_expires_at > NOW()
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM group_booking_seats gs
+			JOIN group_bookings g ON gs.group_booking_id = g.id
+			WHERE g.trip_id = t.id
+			AND gs.seat_id = s.id
+			AND gs.booking_id IS NULL
+			AND (g.status = 'confirmed' OR (g.status = 'quoted' AND g.quote_expires_at > NOW()))
+			AND g.origin_stop_sequence < $4
+			AND $3 < g.destination_stop_sequence
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM seat_blocks b
+			WHERE b.seat_id = s.id
+			AND ` + seatBlockActiveCondition + `
 		)
 		ORDER BY s.row_number, s.column_position`
 
-	rows, err := db.Query(query, scheduleID, travelDate)
+	rows, err := db.Query(query, scheduleID, travelDate, originStopSequence, destinationStopSequence)
 	if err != nil {
 		return nil, err
 	}
@@ -81,6 +127,204 @@ func GetAvailableSeatsForSchedule(db DBInterface, scheduleID int, travelDate str
 		seats = append(seats, seat)
 	}
 
+	// Seats blocked by number are taken from the back of the vehicle
+	blocked, err := CountBlockedSeats(db, scheduleID, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	if blocked >= len(seats) {
+		return nil, nil
+	}
+	return seats[:len(seats)-blocked], nil
+}
+
+// CountDepartureSeats returns the number of seats offered on a schedule's departure on a travel date,
+// those of the trip's vehicle when it was given another one
+func CountDepartureSeats(db DBInterface, scheduleID int, travelDate string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM schedules sch
+		LEFT JOIN trips t ON t.schedule_id = sch.id AND t.travel_date = $2::date
+		JOIN seats s ON s.vehicle_id = COALESCE(t.vehicle_id, sch.vehicle_id)
+		WHERE sch.id = $1 AND s.is_available = true`
+
+	var count int
+	err := db.QueryRow(query, scheduleID, travelDate).Scan(&count)
+	return count, err
+}
+
+// CountSoldSeatsForSegment returns the number of offered seats of a departure held by confirmed or pending
+// bookings on any part of a segment. Seat blocks, group requests and waitlist offers are not sold.
+func CountSoldSeatsForSegment(db DBInterface, scheduleID int, travelDate string, originStopSequence int, destinationStopSequence int) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT s.id)
+		FROM schedules sch
+		LEFT JOIN trips t ON t.schedule_id = sch.id AND t.travel_date = $2::date
+		JOIN seats s ON s.vehicle_id = COALESCE(t.vehicle_id, sch.vehicle_id)
+		JOIN booking_seats bs ON bs.seat_id = s.id
+		JOIN bookings b ON bs.booking_id = b.id
+		WHERE sch.id = $1 AND s.is_available = true
+		AND b.schedule_id = $1
+		AND b.travel_date::date = $2::date
+		AND b.booking_status IN ('confirmed', 'pending')
+		AND COALESCE(b.origin_stop_sequence, 0) < $4
+		AND $3 < COALESCE(b.destination_stop_sequence, 2147483647)`
+
+	var count int
+	err := db.QueryRow(query, scheduleID, travelDate, originStopSequence, destinationStopSequence).Scan(&count)
+	return count, err
+}
+
+const bookedSeatColumns = `bs.id, b.id, b.booking_code, b.user_id, b.passenger_name, s.id, s.seat_number, s.seat_type, b.origin_stop_sequence, b.destination_stop_sequence`
+
+func scanBookedSeats(rows *sql.Rows) ([]models.BookedSeat, error) {
+	defer rows.Close()
+
+	var seats []models.BookedSeat
+	for rows.Next() {
+		var seat models.BookedSeat
+		err := rows.Scan(
+			&seat.BookingSeatID, &seat.BookingID, &seat.BookingCode, &seat.UserID, &seat.PassengerName, &seat.SeatID, &seat.SeatNumber, &seat.SeatType, &seat.OriginStopSequence, &seat.DestinationStopSequence,
+		)
+		if err != nil {
+			return nil, err
+		}
+		seats = append(seats, seat)
+	}
+
+	return seats, nil
+}
+
+// GetBookedSeats returns the seats held by the active bookings of a schedule on a travel date
+func GetBookedSeats(db DBInterface, scheduleID int, travelDate time.Time) ([]models.BookedSeat, error) {
+	query := `
+		SELECT ` + bookedSeatColumns + `
+		FROM booking_seats bs
+		JOIN bookings b ON bs.booking_id = b.id
+		JOIN seats s ON bs.seat_id = s.id
+		WHERE b.schedule_id = $1
+		AND b.travel_date::date = $2::date
+		AND b.booking_status IN ('confirmed', 'pending')
+		ORDER BY b.id, s.row_number, s.column_position`
+
+	rows, err := db.Query(query, scheduleID, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookedSeats(rows)
+}
+
+// GetBookedSeatsByBookingID returns the seats of a booking in seat order
+func GetBookedSeatsByBookingID(db DBInterface, bookingID int) ([]models.BookedSeat, error) {
+	query := `
+		SELECT ` + bookedSeatColumns + `
+		FROM booking_seats bs
+		JOIN bookings b ON bs.booking_id = b.id
+		JOIN seats s ON bs.seat_id = s.id
+		WHERE b.id = $1
+		ORDER BY s.row_number, s.column_position`
+
+	rows, err := db.Query(query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookedSeats(rows)
+}
+
+// GetManifestEntries returns the seats of the bookings of a schedule on a travel date that are not cancelled,
+// in seat order. Bookings without seats are listed once with an empty seat number. Stops are not filled in.
+func GetManifestEntries(db DBInterface, scheduleID int, travelDate time.Time) ([]models.ManifestEntry, error) {
+	query := `
+		SELECT b.id, b.booking_code, COALESCE(s.seat_number, ''), b.passenger_name, COALESCE(b.passenger_document, ''), COALESCE(b.passenger_phone, ''),
+		       b.origin_stop_sequence, b.destination_stop_sequence, b.booking_status, b.payment_status, b.checked_in_at, bs.boarded_at
+		FROM bookings b
+		LEFT JOIN booking_seats bs ON bs.booking_id = b.id
+		LEFT JOIN seats s ON bs.seat_id = s.id
+		WHERE b.schedule_id = $1
+		AND b.travel_date::date = $2::date
+		AND b.booking_status <> 'cancelled'
+		ORDER BY s.row_number NULLS LAST, s.column_position, b.id`
+
+	rows, err := db.Query(query, scheduleID, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.ManifestEntry{}
+	for rows.Next() {
+		var entry models.ManifestEntry
+		err := rows.Scan(
+			&entry.BookingID, &entry.BookingCode, &entry.SeatNumber, &entry.PassengerName, &entry.PassengerDocument, &entry.PassengerPhone,
+			&entry.OriginStopSequence, &entry.DestinationStopSequence, &entry.BookingStatus, &entry.PaymentStatus, &entry.CheckedInAt, &entry.BoardedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// MoveBookingSeat moves a booked seat to another seat, along with the group seat the booking was issued for
+func MoveBookingSeat(db DBInterface, bookingSeatID, seatID int) error {
+	groupQuery := `
+		UPDATE group_booking_seats gs
+		SET seat_id = $2
+		FROM booking_seats bs
+		WHERE bs.id = $1 AND gs.booking_id = bs.booking_id AND gs.seat_id = bs.seat_id`
+	if _, err := db.Exec(groupQuery, bookingSeatID, seatID); err != nil {
+		return err
+	}
+
+	query := `UPDATE booking_seats SET seat_id = $2 WHERE id = $1`
+	_, err := db.Exec(query, bookingSeatID, seatID)
+	return err
+}
+
+// GetHeldSeats returns the seats of a trip's vehicle held without a booking: by the open seat blocks of
+// groups, the seat blocks of the trip or of every trip of its schedule, and waitlist offers
+func GetHeldSeats(db DBInterface, trip *models.Trip) ([]models.HeldSeat, error) {
+	query := `
+		SELECT 'group', gs.id, s.id, s.seat_number, s.seat_type, g.origin_stop_sequence, g.destination_stop_sequence, false
+		FROM group_booking_seats gs
+		JOIN group_bookings g ON gs.group_booking_id = g.id
+		JOIN seats s ON gs.seat_id = s.id
+		WHERE g.trip_id = $1 AND s.vehicle_id = $2
+		AND gs.booking_id IS NULL
+		AND g.status IN ('requested', 'quoted', 'confirmed')
+		UNION ALL
+		SELECT 'block', b.id, s.id, s.seat_number, s.seat_type, NULL, NULL, b.travel_date IS NULL
+		FROM seat_blocks b
+		JOIN seats s ON b.seat_id = s.id
+		WHERE b.schedule_id = $3 AND s.vehicle_id = $2
+		AND (b.travel_date IS NULL OR b.travel_date = $4::date)
+		UNION ALL
+		SELECT 'waitlist', w.id, s.id, s.seat_number, s.seat_type, w.origin_stop_sequence, w.destination_stop_sequence, false
+		FROM waitlist_entries w
+		JOIN seats s ON w.offered_seat_id = s.id
+		WHERE w.trip_id = $1 AND s.vehicle_id = $2
+		AND w.status = 'offered'`
+
+	rows, err := db.Query(query, trip.ID, trip.VehicleID, trip.ScheduleID, trip.TravelDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seats []models.HeldSeat
+	for rows.Next() {
+		var seat models.HeldSeat
+		err := rows.Scan(
+			&seat.Kind, &seat.ID, &seat.SeatID, &seat.SeatNumber, &seat.SeatType, &seat.OriginStopSequence, &seat.DestinationStopSequence, &seat.EveryTrip,
+		)
+		if err != nil {
+			return nil, err
+		}
+		seats = append(seats, seat)
+	}
+
 	return seats, nil
 }
 
@@ -90,8 +334,14 @@ func UpdateSeatAvailability(db DBInterface, seatID int, isAvailable bool) error
 	return err
 }
 
+// DeleteBookingSeats releases every seat of a booking
+func DeleteBookingSeats(db DBInterface, bookingID int) error {
+	_, err := db.Exec(`DELETE FROM booking_seats WHERE booking_id = $1`, bookingID)
+	return err
+}
+
 func DeleteBookingSeat(db *sql.DB, bookingID int, seatID int) error {
 	query := `DELETE FROM booking_seats WHERE booking_id = $1 AND seat_id = $2`
 	_, err := db.Exec(query, bookingID, seatID)
 	return err
-}
\ No newline at end of file
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+func CreateCalendar(db DBInterface, calendar *models.ServiceCalendar) error {
+	query := `
+		INSERT INTO service_calendars (company_id, name, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, calendar.CompanyID, calendar.Name).Scan(&calendar.ID, &calendar.CreatedAt, &calendar.UpdatedAt)
+}
+
+func GetCalendarByID(db DBInterface, id int) (*models.ServiceCalendar, error) {
+	var calendar models.ServiceCalendar
+	query := `SELECT id, company_id, name, created_at, updated_at FROM service_calendars WHERE id = $1`
+
+	err := db.QueryRow(query, id).Scan(
+		&calendar.ID, &calendar.CompanyID, &calendar.Name, &calendar.CreatedAt, &calendar.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &calendar, nil
+}
+
+func GetCalendarsByCompanyID(db DBInterface, companyID int) ([]models.ServiceCalendar, error) {
+	query := `SELECT id, company_id, name, created_at, updated_at FROM service_calendars WHERE company_id = $1 ORDER BY name`
+
+	rows, err := db.Query(query, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var calendars []models.ServiceCalendar
+	for rows.Next() {
+		var calendar models.ServiceCalendar
+		err := rows.Scan(
+			&calendar.ID, &calendar.CompanyID, &calendar.Name, &calendar.CreatedAt, &calendar.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		calendars = append(calendars, calendar)
+	}
+
+	return calendars, nil
+}
+
+func UpdateCalendar(db DBInterface, calendar *models.ServiceCalendar) error {
+	query := `
+		UPDATE service_calendars
+		SET name = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	return db.QueryRow(query, calendar.ID, calendar.Name).Scan(&calendar.UpdatedAt)
+}
+
+// DeleteCalendar deletes a calendar and its exceptions; schedules following it keep their days of week only
+func DeleteCalendar(db DBInterface, id int) error {
+	if _, err := db.Exec(`UPDATE schedules SET calendar_id = NULL WHERE calendar_id = $1`, id); err != nil {
+		return err
+	}
+
+	query := `DELETE FROM service_calendars WHERE id = $1`
+	_, err := db.Exec(query, id)
+	return err
+}
+
+// CreateCalendarException adds an exception, replacing any exception of the calendar on the same date
+func CreateCalendarException(db DBInterface, exception *models.CalendarException) error {
+	query := `
+		INSERT INTO calendar_exceptions (calendar_id, exception_date, exception_type, description, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (calendar_id, exception_date) DO UPDATE SET exception_type = EXCLUDED.exception_type, description = EXCLUDED.description
+		RETURNING id, created_at`
+
+	return db.QueryRow(query, exception.CalendarID, exception.ExceptionDate, exception.ExceptionType, exception.Description).Scan(&exception.ID, &exception.CreatedAt)
+}
+
+func GetCalendarExceptions(db DBInterface, calendarID int) ([]models.CalendarException, error) {
+	query := `SELECT id, calendar_id, exception_date, exception_type, COALESCE(description, ''), created_at FROM calendar_exceptions WHERE calendar_id = $1 ORDER BY exception_date`
+
+	rows, err := db.Query(query, calendarID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exceptions []models.CalendarException
+	for rows.Next() {
+		var exception models.CalendarException
+		err := rows.Scan(
+			&exception.ID, &exception.CalendarID, &exception.ExceptionDate, &exception.ExceptionType, &exception.Description, &exception.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		exceptions = append(exceptions, exception)
+	}
+
+	return exceptions, nil
+}
+
+// GetCalendarExceptionByDate returns the exception of a calendar on a date, if any
+func GetCalendarExceptionByDate(db DBInterface, calendarID int, date time.Time) (*models.CalendarException, error) {
+	var exception models.CalendarException
+	query := `SELECT id, calendar_id, exception_date, exception_type, COALESCE(description, ''), created_at FROM calendar_exceptions WHERE calendar_id = $1 AND exception_date = $2::date`
+
+	err := db.QueryRow(query, calendarID, date.Format("2006-01-02")).Scan(
+		&exception.ID, &exception.CalendarID, &exception.ExceptionDate, &exception.ExceptionType, &exception.Description, &exception.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exception, nil
+}
+
+func DeleteCalendarException(db DBInterface, calendarID, exceptionID int) error {
+	query := `DELETE FROM calendar_exceptions WHERE id = $1 AND calendar_id = $2`
+	_, err := db.Exec(query, exceptionID, calendarID)
+	return err
+}
+
+func DeleteCalendarExceptionsByCalendarID(db DBInterface, calendarID int) error {
+	query := `DELETE FROM calendar_exceptions WHERE calendar_id = $1`
+	_, err := db.Exec(query, calendarID)
+	return err
+}
@@ -8,19 +8,19 @@ import (
 
 func CreateCompany(db *sql.DB, company *models.Company) error {
 	query := `
-		INSERT INTO companies (name, cuit, phone, email, address, icon, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		INSERT INTO companies (name, cuit, phone, email, address, icon, round_trip_discount_percent, no_show_fee_percent, change_fee, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
 		RETURNING id`
 
-	return db.QueryRow(query, company.Name, company.Cuit, company.Phone, company.Email, company.Address, company.Icon, company.IsActive).Scan(&company.ID)
+	return db.QueryRow(query, company.Name, company.Cuit, company.Phone, company.Email, company.Address, company.Icon, company.RoundTripDiscountPercent, company.NoShowFeePercent, company.ChangeFee, company.IsActive).Scan(&company.ID)
 }
 
-func GetCompanyByID(db *sql.DB, id int) (*models.Company, error) {
+func GetCompanyByID(db DBInterface, id int) (*models.Company, error) {
 	var company models.Company
-	query := `SELECT id, name, cuit, phone, email, address, icon, is_active, created_at, updated_at FROM companies WHERE id = $1`
+	query := `SELECT id, name, cuit, phone, email, address, icon, round_trip_discount_percent, no_show_fee_percent, change_fee, is_active, created_at, updated_at FROM companies WHERE id = $1`
 
 	err := db.QueryRow(query, id).Scan(
-		&company.ID, &company.Name, &company.Cuit, &company.Phone, &company.Email, &company.Address, &company.Icon, &company.IsActive, &company.CreatedAt, &company.UpdatedAt,
+		&company.ID, &company.Name, &company.Cuit, &company.Phone, &company.Email, &company.Address, &company.Icon, &company.RoundTripDiscountPercent, &company.NoShowFeePercent, &company.ChangeFee, &company.IsActive, &company.CreatedAt, &company.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -30,7 +30,7 @@ func GetCompanyByID(db *sql.DB, id int) (*models.Company, error) {
 }
 
 func GetAllCompanies(db *sql.DB) ([]models.Company, error) {
-	query := `SELECT id, name, cuit, phone, email, address, icon, is_active, created_at, updated_at FROM companies ORDER BY name`
+	query := `SELECT id, name, cuit, phone, email, address, icon, round_trip_discount_percent, no_show_fee_percent, change_fee, is_active, created_at, updated_at FROM companies ORDER BY name`
 
 	rows, err := db.Query(query)
 	if err != nil {
@@ -42,7 +42,7 @@ func GetAllCompanies(db *sql.DB) ([]models.Company, error) {
 	for rows.Next() {
 		var company models.Company
 		err := rows.Scan(
-			&company.ID, &company.Name, &company.Cuit, &company.Phone, &company.Email, &company.Address, &company.Icon, &company.IsActive, &company.CreatedAt, &company.UpdatedAt,
+			&company.ID, &company.Name, &company.Cuit, &company.Phone, &company.Email, &company.Address, &company.Icon, &company.RoundTripDiscountPercent, &company.NoShowFeePercent, &company.ChangeFee, &company.IsActive, &company.CreatedAt, &company.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -53,13 +53,13 @@ func GetAllCompanies(db *sql.DB) ([]models.Company, error) {
 	return companies, nil
 }
 
-func UpdateCompany(db *sql.DB, company *models.Company) error {
+func UpdateCompany(db DBInterface, company *models.Company) error {
 	query := `
 		UPDATE companies
-		SET name = $2, cuit = $3, phone = $4, email = $5, address = $6, icon = $7, is_active = $8, updated_at = NOW()
+		SET name = $2, cuit = $3, phone = $4, email = $5, address = $6, icon = $7, round_trip_discount_percent = $8, no_show_fee_percent = $9, change_fee = $10, is_active = $11, updated_at = NOW()
 		WHERE id = $1`
 
-	_, err := db.Exec(query, company.ID, company.Name, company.Cuit, company.Phone, company.Email, company.Address, company.Icon, company.IsActive)
+	_, err := db.Exec(query, company.ID, company.Name, company.Cuit, company.Phone, company.Email, company.Address, company.Icon, company.RoundTripDiscountPercent, company.NoShowFeePercent, company.ChangeFee, company.IsActive)
 	return err
 }
 
@@ -67,4 +67,4 @@ func DeleteCompany(db *sql.DB, id int) error {
 	query := `DELETE FROM companies WHERE id = $1`
 	_, err := db.Exec(query, id)
 	return err
-}
\ No newline at end of file
+}
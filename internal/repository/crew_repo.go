@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+const crewMemberColumns = `id, company_id, user_id, first_name, last_name, crew_role, COALESCE(licence_number, ''), licence_expiry, is_active, created_at, updated_at`
+
+func scanCrewMember(row rowScanner, member *models.CrewMember) error {
+	return row.Scan(
+		&member.ID, &member.CompanyID, &member.UserID, &member.FirstName, &member.LastName, &member.Role, &member.LicenceNumber, &member.LicenceExpiry, &member.IsActive, &member.CreatedAt, &member.UpdatedAt,
+	)
+}
+
+func CreateCrewMember(db DBInterface, member *models.CrewMember) error {
+	query := `
+		INSERT INTO crew_members (company_id, user_id, first_name, last_name, crew_role, licence_number, licence_expiry, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, member.CompanyID, member.UserID, member.FirstName, member.LastName, member.Role, member.LicenceNumber, member.LicenceExpiry, member.IsActive).Scan(&member.ID, &member.CreatedAt, &member.UpdatedAt)
+}
+
+func GetCrewMemberByID(db DBInterface, id int) (*models.CrewMember, error) {
+	var member models.CrewMember
+	query := `SELECT ` + crewMemberColumns + ` FROM crew_members WHERE id = $1`
+
+	if err := scanCrewMember(db.QueryRow(query, id), &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// LockCrewMember loads a crew member and locks it until the end of the transaction,
+// so concurrent duty assignments are checked one after the other
+func LockCrewMember(db DBInterface, id int) (*models.CrewMember, error) {
+	var member models.CrewMember
+	query := `SELECT ` + crewMemberColumns + ` FROM crew_members WHERE id = $1 FOR UPDATE`
+
+	if err := scanCrewMember(db.QueryRow(query, id), &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetCrewMemberByUserID returns the crew member linked to a user account
+func GetCrewMemberByUserID(db DBInterface, userID int) (*models.CrewMember, error) {
+	var member models.CrewMember
+	query := `SELECT ` + crewMemberColumns + ` FROM crew_members WHERE user_id = $1`
+
+	if err := scanCrewMember(db.QueryRow(query, userID), &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func GetCrewMembersByCompanyID(db DBInterface, companyID int) ([]models.CrewMember, error) {
+	query := `SELECT ` + crewMemberColumns + ` FROM crew_members WHERE company_id = $1 ORDER BY last_name, first_name`
+
+	rows, err := db.Query(query, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.CrewMember{}
+	for rows.Next() {
+		var member models.CrewMember
+		if err := scanCrewMember(rows, &member); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+func UpdateCrewMember(db DBInterface, member *models.CrewMember) error {
+	query := `
+		UPDATE crew_members
+		SET user_id = $2, first_name = $3, last_name = $4, crew_role = $5, licence_number = NULLIF($6, ''), licence_expiry = $7, is_active = $8, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	return db.QueryRow(query, member.ID, member.UserID, member.FirstName, member.LastName, member.Role, member.LicenceNumber, member.LicenceExpiry, member.IsActive).Scan(&member.UpdatedAt)
+}
+
+func CreateTripCrew(db DBInterface, assignment *models.TripCrew) error {
+	query := `
+		INSERT INTO trip_crew (trip_id, crew_member_id, duty, created_at)
+		VALUES ($1, $2, $3, NOW())
+		RETURNING id, created_at`
+
+	return db.QueryRow(query, assignment.TripID, assignment.CrewMemberID, assignment.Duty).Scan(&assignment.ID, &assignment.CreatedAt)
+}
+
+// GetTripCrew returns the crew assigned to a trip with their details
+func GetTripCrew(db DBInterface, tripID int) ([]models.TripCrew, error) {
+	query := `
+		SELECT tc.id, tc.trip_id, tc.crew_member_id, tc.duty, tc.created_at,
+		       c.id, c.company_id, c.user_id, c.first_name, c.last_name, c.crew_role, COALESCE(c.licence_number, ''), c.licence_expiry, c.is_active, c.created_at, c.updated_at
+		FROM trip_crew tc
+		JOIN crew_members c ON tc.crew_member_id = c.id
+		WHERE tc.trip_id = $1
+		ORDER BY tc.duty DESC, c.last_name`
+
+	rows, err := db.Query(query, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	crew := []models.TripCrew{}
+	for rows.Next() {
+		var assignment models.TripCrew
+		var member models.CrewMember
+		err := rows.Scan(
+			&assignment.ID, &assignment.TripID, &assignment.CrewMemberID, &assignment.Duty, &assignment.CreatedAt,
+			&member.ID, &member.CompanyID, &member.UserID, &member.FirstName, &member.LastName, &member.Role, &member.LicenceNumber, &member.LicenceExpiry, &member.IsActive, &member.CreatedAt, &member.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		assignment.CrewMember = &member
+		crew = append(crew, assignment)
+	}
+
+	return crew, nil
+}
+
+func DeleteTripCrew(db DBInterface, tripID, crewMemberID int) error {
+	query := `DELETE FROM trip_crew WHERE trip_id = $1 AND crew_member_id = $2`
+
+	result, err := db.Exec(query, tripID, crewMemberID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetCrewDuties returns the duties of a crew member on trips that are not cancelled and run during a period
+func GetCrewDuties(db DBInterface, crewMemberID int, from, to time.Time) ([]models.CrewDuty, error) {
+	query := `
+		SELECT t.id, tc.duty, t.schedule_id, s.route_id, t.vehicle_id, v.license_plate, r.origin_city, r.destination_city,
+		       t.travel_date, t.departure_datetime, t.arrival_datetime, t.delay_minutes, t.status
+		FROM trip_crew tc
+		JOIN trips t ON tc.trip_id = t.id
+		JOIN schedules s ON t.schedule_id = s.id
+		JOIN routes r ON s.route_id = r.id
+		JOIN vehicles v ON t.vehicle_id = v.id
+		WHERE tc.crew_member_id = $1
+		  AND t.status <> 'cancelled'
+		  AND t.departure_datetime < $3
+		  AND t.arrival_datetime + t.delay_minutes * INTERVAL '1 minute' > $2
+		ORDER BY t.departure_datetime`
+
+	rows, err := db.Query(query, crewMemberID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	duties := []models.CrewDuty{}
+	for rows.Next() {
+		var duty models.CrewDuty
+		err := rows.Scan(
+			&duty.TripID, &duty.Duty, &duty.ScheduleID, &duty.RouteID, &duty.VehicleID, &duty.LicensePlate, &duty.OriginCity, &duty.DestinationCity,
+			&duty.TravelDate, &duty.DepartureDatetime, &duty.ArrivalDatetime, &duty.DelayMinutes, &duty.Status,
+		)
+		if err != nil {
+			return nil, err
+		}
+		duties = append(duties, duty)
+	}
+
+	return duties, nil
+}
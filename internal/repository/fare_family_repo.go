@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+const fareFamilyColumns = `id, route_id, name, COALESCE(description, ''), price_percent, surcharge, allow_changes, change_fee, allow_refunds, cancellation_fee_percent, sort_order, is_active, created_at, updated_at`
+
+func scanFareFamily(row rowScanner, family *models.FareFamily) error {
+	return row.Scan(
+		&family.ID, &family.RouteID, &family.Name, &family.Description, &family.PricePercent, &family.Surcharge, &family.AllowChanges, &family.ChangeFee, &family.AllowRefunds, &family.CancellationFeePercent, &family.SortOrder, &family.IsActive, &family.CreatedAt, &family.UpdatedAt,
+	)
+}
+
+func CreateFareFamily(db DBInterface, family *models.FareFamily) error {
+	query := `
+		INSERT INTO fare_families (route_id, name, description, price_percent, surcharge, allow_changes, change_fee, allow_refunds, cancellation_fee_percent, sort_order, is_active, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, family.RouteID, family.Name, family.Description, family.PricePercent, family.Surcharge, family.AllowChanges, family.ChangeFee, family.AllowRefunds, family.CancellationFeePercent, family.SortOrder, family.IsActive).Scan(&family.ID, &family.CreatedAt, &family.UpdatedAt)
+}
+
+func GetFareFamilyByID(db DBInterface, id int) (*models.FareFamily, error) {
+	var family models.FareFamily
+	query := `SELECT ` + fareFamilyColumns + ` FROM fare_families WHERE id = $1`
+
+	if err := scanFareFamily(db.QueryRow(query, id), &family); err != nil {
+		return nil, err
+	}
+	return &family, nil
+}
+
+// GetFareFamiliesByRouteID lists the fare families of a route in display order, only the active ones when asked
+func GetFareFamiliesByRouteID(db DBInterface, routeID int, activeOnly bool) ([]models.FareFamily, error) {
+	query := `SELECT ` + fareFamilyColumns + ` FROM fare_families WHERE route_id = $1 AND (is_active OR NOT $2) ORDER BY sort_order, id`
+
+	rows, err := db.Query(query, routeID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	families := []models.FareFamily{}
+	for rows.Next() {
+		var family models.FareFamily
+		if err := scanFareFamily(rows, &family); err != nil {
+			return nil, err
+		}
+		families = append(families, family)
+	}
+
+	return families, nil
+}
+
+func UpdateFareFamily(db DBInterface, family *models.FareFamily) error {
+	query := `
+		UPDATE fare_families
+		SET name = $2, description = NULLIF($3, ''), price_percent = $4, surcharge = $5, allow_changes = $6, change_fee = $7, allow_refunds = $8, cancellation_fee_percent = $9, sort_order = $10, is_active = $11, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	return db.QueryRow(query, family.ID, family.Name, family.Description, family.PricePercent, family.Surcharge, family.AllowChanges, family.ChangeFee, family.AllowRefunds, family.CancellationFeePercent, family.SortOrder, family.IsActive).Scan(&family.UpdatedAt)
+}
+
+func GetFareFamilyAncillaries(db DBInterface, fareFamilyID int) ([]models.FareFamilyAncillary, error) {
+	query := `
+		SELECT fa.ancillary_id, a.category, a.name, fa.quantity
+		FROM fare_family_ancillaries fa
+		JOIN ancillaries a ON fa.ancillary_id = a.id
+		WHERE fa.fare_family_id = $1
+		ORDER BY a.category, a.name`
+
+	rows, err := db.Query(query, fareFamilyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	included := []models.FareFamilyAncillary{}
+	for rows.Next() {
+		var item models.FareFamilyAncillary
+		if err := rows.Scan(&item.AncillaryID, &item.Category, &item.Name, &item.Quantity); err != nil {
+			return nil, err
+		}
+		included = append(included, item)
+	}
+
+	return included, nil
+}
+
+// ReplaceFareFamilyAncillaries sets the ancillaries included with each seat of a fare family
+func ReplaceFareFamilyAncillaries(db DBInterface, fareFamilyID int, included []models.FareFamilyAncillary) error {
+	if _, err := db.Exec(`DELETE FROM fare_family_ancillaries WHERE fare_family_id = $1`, fareFamilyID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO fare_family_ancillaries (fare_family_id, ancillary_id, quantity) VALUES ($1, $2, $3)`
+	for _, item := range included {
+		if _, err := db.Exec(query, fareFamilyID, item.AncillaryID, item.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
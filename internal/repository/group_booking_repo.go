@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+const groupBookingColumns = `g.id, g.user_id, g.trip_id, g.origin_stop_sequence, g.destination_stop_sequence, g.departure_datetime, g.group_name, g.contact_name, g.contact_phone, COALESCE(g.contact_email, ''),
+	g.seat_count, COALESCE(g.notes, ''), g.status, g.price_per_seat, g.total_amount, g.deposit_amount, g.amount_paid, g.quote_expires_at, g.passenger_deadline, g.balance_deadline,
+	g.quoted_by, COALESCE(g.payment_method, ''), g.confirmed_at, g.completed_at, g.created_at, g.updated_at`
+
+func scanGroupBooking(row rowScanner) (*models.GroupBooking, error) {
+	var group models.GroupBooking
+	err := row.Scan(
+		&group.ID, &group.UserID, &group.TripID, &group.OriginStopSequence, &group.DestinationStopSequence, &group.DepartureDatetime, &group.GroupName, &group.ContactName, &group.ContactPhone, &group.ContactEmail,
+		&group.SeatCount, &group.Notes, &group.Status, &group.PricePerSeat, &group.TotalAmount, &group.DepositAmount, &group.AmountPaid, &group.QuoteExpiresAt, &group.PassengerDeadline, &group.BalanceDeadline,
+		&group.QuotedBy, &group.PaymentMethod, &group.ConfirmedAt, &group.CompletedAt, &group.CreatedAt, &group.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func scanGroupBookings(rows *sql.Rows) ([]models.GroupBooking, error) {
+	defer rows.Close()
+
+	groups := []models.GroupBooking{}
+	for rows.Next() {
+		group, err := scanGroupBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, *group)
+	}
+
+	return groups, nil
+}
+
+func CreateGroupBooking(db DBInterface, group *models.GroupBooking) error {
+	query := `
+		INSERT INTO group_bookings (user_id, trip_id, origin_stop_sequence, destination_stop_sequence, departure_datetime, group_name, contact_name, contact_phone, contact_email, seat_count, notes, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, NULLIF($11, ''), $12, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, group.UserID, group.TripID, group.OriginStopSequence, group.DestinationStopSequence, group.DepartureDatetime, group.GroupName, group.ContactName, group.ContactPhone, group.ContactEmail, group.SeatCount, group.Notes, group.Status).Scan(&group.ID, &group.CreatedAt, &group.UpdatedAt)
+}
+
+func GetGroupBookingByID(db DBInterface, id int) (*models.GroupBooking, error) {
+	query := `SELECT ` + groupBookingColumns + ` FROM group_bookings g WHERE g.id = $1`
+	return scanGroupBooking(db.QueryRow(query, id))
+}
+
+// LockGroupBooking returns a group booking, locking it until the end of the transaction
+func LockGroupBooking(db DBInterface, id int) (*models.GroupBooking, error) {
+	query := `SELECT ` + groupBookingColumns + ` FROM group_bookings g WHERE g.id = $1 FOR UPDATE`
+	return scanGroupBooking(db.QueryRow(query, id))
+}
+
+// GetGroupBookingsByUserID returns the group bookings of a customer, newest first
+func GetGroupBookingsByUserID(db DBInterface, userID int) ([]models.GroupBooking, error) {
+	query := `SELECT ` + groupBookingColumns + ` FROM group_bookings g WHERE g.user_id = $1 ORDER BY g.created_at DESC, g.id DESC`
+
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanGroupBookings(rows)
+}
+
+// GetCompanyGroupBookings returns the group bookings on the trips of a company, optionally with a given status,
+// by departure
+func GetCompanyGroupBookings(db DBInterface, companyID int, status string) ([]models.GroupBooking, error) {
+	query := `
+		SELECT ` + groupBookingColumns + `
+		FROM group_bookings g
+		JOIN trips t ON g.trip_id = t.id
+		JOIN schedules s ON t.schedule_id = s.id
+		JOIN routes r ON s.route_id = r.id
+		WHERE r.company_id = $1
+		AND ($2 = '' OR g.status = $2)
+		ORDER BY g.departure_datetime, g.id`
+
+	rows, err := db.Query(query, companyID, status)
+	if err != nil {
+		return nil, err
+	}
+	return scanGroupBookings(rows)
+}
+
+// QuoteGroupBooking stores the operator's price, deposit and deadlines
+func QuoteGroupBooking(db DBInterface, group *models.GroupBooking) error {
+	query := `
+		UPDATE group_bookings
+		SET status = $2, seat_count = $3, price_per_seat = $4, total_amount = $5, deposit_amount = $6, quote_expires_at = $7, passenger_deadline = $8, balance_deadline = $9, quoted_by = $10, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	return db.QueryRow(query, group.ID, group.Status, group.SeatCount, group.PricePerSeat, group.TotalAmount, group.DepositAmount, group.QuoteExpiresAt, group.PassengerDeadline, group.BalanceDeadline, group.QuotedBy).Scan(&group.UpdatedAt)
+}
+
+// UpdateGroupBookingPayment stores the status of a group booking after a deposit or balance payment
+func UpdateGroupBookingPayment(db DBInterface, group *models.GroupBooking) error {
+	query := `
+		UPDATE group_bookings
+		SET status = $2, amount_paid = $3, payment_method = $4, confirmed_at = $5, completed_at = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	return db.QueryRow(query, group.ID, group.Status, group.AmountPaid, group.PaymentMethod, group.ConfirmedAt, group.CompletedAt).Scan(&group.UpdatedAt)
+}
+
+func UpdateGroupBookingStatus(db DBInterface, group *models.GroupBooking) error {
+	query := `UPDATE group_bookings SET status = $2, updated_at = NOW() WHERE id = $1 RETURNING updated_at`
+	return db.QueryRow(query, group.ID, group.Status).Scan(&group.UpdatedAt)
+}
+
+// UpdateGroupBookingSize stores the seats left in a block and what they cost
+func UpdateGroupBookingSize(db DBInterface, group *models.GroupBooking) error {
+	query := `UPDATE group_bookings SET seat_count = $2, total_amount = $3, updated_at = NOW() WHERE id = $1 RETURNING updated_at`
+	return db.QueryRow(query, group.ID, group.SeatCount, group.TotalAmount).Scan(&group.UpdatedAt)
+}
+
+// ExpireGroupBookings expires the quotes not confirmed in time and the confirmed groups whose balance was
+// not paid in time. It returns the expired groups.
+func ExpireGroupBookings(db DBInterface, at time.Time) ([]models.GroupBooking, error) {
+	query := `
+		UPDATE group_bookings g
+		SET status = 'expired', updated_at = NOW()
+		WHERE (g.status = 'quoted' AND g.quote_expires_at <= $1)
+		OR (g.status = 'confirmed' AND g.balance_deadline <= $1)
+		RETURNING ` + groupBookingColumns
+
+	rows, err := db.Query(query, at)
+	if err != nil {
+		return nil, err
+	}
+	return scanGroupBookings(rows)
+}
+
+// GetGroupBookingsPastPassengerDeadline returns the confirmed groups whose passenger list is overdue
+// while some of their seats still have no passenger
+func GetGroupBookingsPastPassengerDeadline(db DBInterface, at time.Time) ([]models.GroupBooking, error) {
+	query := `
+		SELECT ` + groupBookingColumns + `
+		FROM group_bookings g
+		WHERE g.status = 'confirmed'
+		AND g.passenger_deadline <= $1
+		AND EXISTS (SELECT 1 FROM group_booking_seats gs WHERE gs.group_booking_id = g.id AND gs.passenger_name IS NULL)
+		ORDER BY g.id`
+
+	rows, err := db.Query(query, at)
+	if err != nil {
+		return nil, err
+	}
+	return scanGroupBookings(rows)
+}
+
+func CreateGroupBookingSeat(db DBInterface, seat *models.GroupBookingSeat) error {
+	query := `
+		INSERT INTO group_booking_seats (group_booking_id, seat_id, created_at)
+		VALUES ($1, $2, NOW())
+		RETURNING id, created_at`
+
+	return db.QueryRow(query, seat.GroupBookingID, seat.SeatID).Scan(&seat.ID, &seat.CreatedAt)
+}
+
+// GetGroupBookingSeats returns the seat block of a group in seat order
+func GetGroupBookingSeats(db DBInterface, groupBookingID int) ([]models.GroupBookingSeat, error) {
+	query := `
+		SELECT gs.id, gs.group_booking_id, gs.seat_id, s.seat_number, COALESCE(gs.passenger_name, ''), COALESCE(gs.passenger_document, ''), COALESCE(gs.passenger_phone, ''), gs.booking_id, gs.created_at
+		FROM group_booking_seats gs
+		JOIN seats s ON gs.seat_id = s.id
+		WHERE gs.group_booking_id = $1
+		ORDER BY s.row_number, s.column_position`
+
+	rows, err := db.Query(query, groupBookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seats := []models.GroupBookingSeat{}
+	for rows.Next() {
+		var seat models.GroupBookingSeat
+		err := rows.Scan(
+			&seat.ID, &seat.GroupBookingID, &seat.SeatID, &seat.SeatNumber, &seat.PassengerName, &seat.PassengerDocument, &seat.PassengerPhone, &seat.BookingID, &seat.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		seats = append(seats, seat)
+	}
+
+	return seats, nil
+}
+
+// DeleteGroupBookingSeats releases the whole seat block of a group
+func DeleteGroupBookingSeats(db DBInterface, groupBookingID int) error {
+	_, err := db.Exec(`DELETE FROM group_booking_seats WHERE group_booking_id = $1`, groupBookingID)
+	return err
+}
+
+// ReleaseUnnamedGroupSeats releases the seats of a group that have no passenger and returns how many were released
+func ReleaseUnnamedGroupSeats(db DBInterface, groupBookingID int) (int64, error) {
+	result, err := db.Exec(`DELETE FROM group_booking_seats WHERE group_booking_id = $1 AND passenger_name IS NULL`, groupBookingID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// UpdateGroupBookingPassenger stores the passenger travelling on a seat of a group
+func UpdateGroupBookingPassenger(db DBInterface, seat *models.GroupBookingSeat) error {
+	query := `
+		UPDATE group_booking_seats
+		SET passenger_name = $2, passenger_document = $3, passenger_phone = $4
+		WHERE id = $1`
+
+	_, err := db.Exec(query, seat.ID, seat.PassengerName, seat.PassengerDocument, seat.PassengerPhone)
+	return err
+}
+
+// MoveGroupBookingSeat moves a seat of a group's block to another seat
+func MoveGroupBookingSeat(db DBInterface, groupSeatID, seatID int) error {
+	_, err := db.Exec(`UPDATE group_booking_seats SET seat_id = $2 WHERE id = $1`, groupSeatID, seatID)
+	return err
+}
+
+// SetGroupSeatBooking links a seat of a group to the booking issued for its passenger
+func SetGroupSeatBooking(db DBInterface, groupSeatID, bookingID int) error {
+	_, err := db.Exec(`UPDATE group_booking_seats SET booking_id = $2 WHERE id = $1`, groupSeatID, bookingID)
+	return err
+}
+
+// IsGroupMemberBooking reports whether a booking was issued for a passenger of a group booking
+func IsGroupMemberBooking(db DBInterface, bookingID int) (bool, error) {
+	var member bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM group_booking_seats WHERE booking_id = $1)`, bookingID).Scan(&member)
+	return member, err
+}
@@ -0,0 +1,90 @@
+package repository
+
+func GetCompanyGTFSID(db DBInterface, companyID int) (string, error) {
+	var gtfsID string
+	query := `SELECT COALESCE(gtfs_id, '') FROM companies WHERE id = $1`
+
+	err := db.QueryRow(query, companyID).Scan(&gtfsID)
+	return gtfsID, err
+}
+
+func SetCompanyGTFSID(db DBInterface, companyID int, gtfsID string) error {
+	query := `UPDATE companies SET gtfs_id = NULLIF($2, ''), updated_at = NOW() WHERE id = $1`
+	_, err := db.Exec(query, companyID, gtfsID)
+	return err
+}
+
+// GetRouteGTFSIDs maps the GTFS ids of a company's routes to their ids
+func GetRouteGTFSIDs(db DBInterface, companyID int) (map[string]int, error) {
+	return gtfsIDs(db, `SELECT gtfs_id, id FROM routes WHERE company_id = $1 AND gtfs_id IS NOT NULL`, companyID)
+}
+
+func SetRouteGTFSID(db DBInterface, routeID int, gtfsID string) error {
+	query := `UPDATE routes SET gtfs_id = NULLIF($2, '') WHERE id = $1`
+	_, err := db.Exec(query, routeID, gtfsID)
+	return err
+}
+
+func GetRouteGTFSID(db DBInterface, routeID int) (string, error) {
+	var gtfsID string
+	query := `SELECT COALESCE(gtfs_id, '') FROM routes WHERE id = $1`
+
+	err := db.QueryRow(query, routeID).Scan(&gtfsID)
+	return gtfsID, err
+}
+
+func GetScheduleGTFSID(db DBInterface, scheduleID int) (string, error) {
+	var gtfsID string
+	query := `SELECT COALESCE(gtfs_id, '') FROM schedules WHERE id = $1`
+
+	err := db.QueryRow(query, scheduleID).Scan(&gtfsID)
+	return gtfsID, err
+}
+
+// GetScheduleGTFSIDs maps the GTFS trip ids of a company's schedules to their ids
+func GetScheduleGTFSIDs(db DBInterface, companyID int) (map[string]int, error) {
+	query := `
+		SELECT s.gtfs_id, s.id
+		FROM schedules s
+		JOIN routes r ON s.route_id = r.id
+		WHERE r.company_id = $1 AND s.gtfs_id IS NOT NULL`
+
+	return gtfsIDs(db, query, companyID)
+}
+
+func SetScheduleGTFSID(db DBInterface, scheduleID int, gtfsID string) error {
+	query := `UPDATE schedules SET gtfs_id = NULLIF($2, '') WHERE id = $1`
+	_, err := db.Exec(query, scheduleID, gtfsID)
+	return err
+}
+
+// GetCalendarGTFSIDs maps the GTFS service ids of a company's calendars to their ids
+func GetCalendarGTFSIDs(db DBInterface, companyID int) (map[string]int, error) {
+	return gtfsIDs(db, `SELECT gtfs_id, id FROM service_calendars WHERE company_id = $1 AND gtfs_id IS NOT NULL`, companyID)
+}
+
+func SetCalendarGTFSID(db DBInterface, calendarID int, gtfsID string) error {
+	query := `UPDATE service_calendars SET gtfs_id = NULLIF($2, '') WHERE id = $1`
+	_, err := db.Exec(query, calendarID, gtfsID)
+	return err
+}
+
+func gtfsIDs(db DBInterface, query string, companyID int) (map[string]int, error) {
+	rows, err := db.Query(query, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int)
+	for rows.Next() {
+		var gtfsID string
+		var id int
+		if err := rows.Scan(&gtfsID, &id); err != nil {
+			return nil, err
+		}
+		ids[gtfsID] = id
+	}
+
+	return ids, rows.Err()
+}
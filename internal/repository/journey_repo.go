@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+func CreateJourney(db DBInterface, journey *models.Journey) error {
+	query := `
+		INSERT INTO journeys (user_id, journey_code, journey_type, total_amount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, journey.UserID, journey.JourneyCode, journey.JourneyType, journey.TotalAmount).Scan(&journey.ID, &journey.CreatedAt, &journey.UpdatedAt)
+}
+
+func GetJourneyByID(db DBInterface, id int) (*models.Journey, error) {
+	var journey models.Journey
+	query := `SELECT id, user_id, journey_code, journey_type, total_amount, created_at, updated_at FROM journeys WHERE id = $1`
+
+	err := db.QueryRow(query, id).Scan(
+		&journey.ID, &journey.UserID, &journey.JourneyCode, &journey.JourneyType, &journey.TotalAmount, &journey.CreatedAt, &journey.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &journey, nil
+}
+
+// AddJourneyAmount adds an amount paid later on one of its legs to a journey total
+func AddJourneyAmount(db DBInterface, journeyID int, amount float64) error {
+	query := `UPDATE journeys SET total_amount = total_amount + $2, updated_at = NOW() WHERE id = $1`
+	_, err := db.Exec(query, journeyID, amount)
+	return err
+}
+
+// GetScheduledSegments lists every stop-to-stop segment of the schedules running on a travel date,
+// with departure and arrival datetimes at the segment's stops, in the stops' time zones. Prices are not filled in.
+func GetScheduledSegments(db DBInterface, travelDate time.Time) ([]models.ItineraryLeg, error) {
+	query := `
+		SELECT s.id, r.id, r.company_id, c.name,
+			   o.stop_sequence, o.city, COALESCE(o.terminal, ''), d.stop_sequence, d.city, COALESCE(d.terminal, ''),
+			   ($1::date + s.departure_time) AT TIME ZONE f.time_zone + make_interval(mins => o.offset_minutes), o.time_zone,
+			   ($1::date + s.departure_time) AT TIME ZONE f.time_zone + make_interval(mins => d.offset_minutes), d.time_zone
+		FROM schedules s
+		JOIN routes r ON s.route_id = r.id
+		JOIN companies c ON r.company_id = c.id
+		JOIN vehicles v ON s.vehicle_id = v.id
+		JOIN route_stops f ON f.route_id = r.id AND f.stop_sequence = (SELECT MIN(stop_sequence) FROM route_stops WHERE route_id = r.id)
+		JOIN route_stops o ON o.route_id = r.id
+		JOIN route_stops d ON d.route_id = r.id AND d.stop_sequence > o.stop_sequence
+		WHERE r.is_active = true AND s.is_active = true AND c.is_active = true AND v.is_active = true
+		AND ` + ScheduleRunsOnCondition("$1::date") + `
+		AND NOT EXISTS (
+			SELECT 1 FROM trips t
+			WHERE t.schedule_id = s.id
+			AND t.travel_date = $1::date
+			AND t.status = 'cancelled'
+		)
+		ORDER BY s.departure_time, o.stop_sequence, d.stop_sequence`
+
+	rows, err := db.Query(query, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var legs []models.ItineraryLeg
+	for rows.Next() {
+		leg := models.ItineraryLeg{TravelDate: travelDate.Format("2006-01-02")}
+		var originTimeZone, destinationTimeZone string
+		err := rows.Scan(
+			&leg.ScheduleID, &leg.RouteID, &leg.CompanyID, &leg.CompanyName,
+			&leg.OriginStopSequence, &leg.OriginCity, &leg.OriginTerminal, &leg.DestinationStopSequence, &leg.DestinationCity, &leg.DestinationTerminal,
+			&leg.DepartureDatetime, &originTimeZone, &leg.ArrivalDatetime, &destinationTimeZone,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		originLoc, err := utils.LoadLocation(originTimeZone)
+		if err != nil {
+			return nil, err
+		}
+		destinationLoc, err := utils.LoadLocation(destinationTimeZone)
+		if err != nil {
+			return nil, err
+		}
+		leg.DepartureDatetime = leg.DepartureDatetime.In(originLoc)
+		leg.ArrivalDatetime = leg.ArrivalDatetime.In(destinationLoc)
+		legs = append(legs, leg)
+	}
+
+	return legs, nil
+}
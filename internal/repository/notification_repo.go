@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+func CreateNotification(db DBInterface, notification *models.Notification) error {
+	dataJSON, _ := json.Marshal(notification.Data)
+
+	query := `
+		INSERT INTO notifications (user_id, booking_id, notification_type, title, message, data, is_read, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, false, NOW())
+		RETURNING id, created_at`
+
+	return db.QueryRow(query, notification.UserID, notification.BookingID, notification.NotificationType, notification.Title, notification.Message, dataJSON).Scan(&notification.ID, &notification.CreatedAt)
+}
+
+func GetNotificationsByUserID(db *sql.DB, userID int) ([]models.Notification, error) {
+	query := `SELECT id, user_id, booking_id, notification_type, title, message, data, is_read, created_at FROM notifications WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		var notification models.Notification
+		var dataJSON []byte
+		err := rows.Scan(
+			&notification.ID, &notification.UserID, &notification.BookingID, &notification.NotificationType, &notification.Title, &notification.Message, &dataJSON, &notification.IsRead, &notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		json.Unmarshal(dataJSON, &notification.Data)
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+func MarkNotificationRead(db *sql.DB, id int, userID int) error {
+	query := `UPDATE notifications SET is_read = true WHERE id = $1 AND user_id = $2`
+	result, err := db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
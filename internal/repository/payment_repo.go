@@ -6,21 +6,21 @@ import (
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
 )
 
-func CreatePayment(db *sql.DB, payment *models.Payment) error {
+func CreatePayment(db DBInterface, payment *models.Payment) error {
 	query := `
-		INSERT INTO payments (booking_id, amount, payment_method, payment_status, transaction_id, payment_gateway, paid_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		INSERT INTO payments (booking_id, journey_id, group_booking_id, amount, payment_method, payment_status, transaction_id, payment_gateway, paid_at, created_at)
+		VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, $7, $8, $9, NOW())
 		RETURNING id`
 
-	return db.QueryRow(query, payment.BookingID, payment.Amount, payment.PaymentMethod, payment.PaymentStatus, payment.TransactionID, payment.PaymentGateway, payment.PaidAt).Scan(&payment.ID)
+	return db.QueryRow(query, payment.BookingID, payment.JourneyID, payment.GroupBookingID, payment.Amount, payment.PaymentMethod, payment.PaymentStatus, payment.TransactionID, payment.PaymentGateway, payment.PaidAt).Scan(&payment.ID)
 }
 
 func GetPaymentByID(db *sql.DB, id int) (*models.Payment, error) {
 	var payment models.Payment
-	query := `SELECT id, booking_id, amount, payment_method, payment_status, transaction_id, payment_gateway, paid_at, created_at FROM payments WHERE id = $1`
+	query := `SELECT id, COALESCE(booking_id, 0), journey_id, group_booking_id, amount, payment_method, payment_status, transaction_id, payment_gateway, paid_at, created_at FROM payments WHERE id = $1`
 
 	err := db.QueryRow(query, id).Scan(
-		&payment.ID, &payment.BookingID, &payment.Amount, &payment.PaymentMethod, &payment.PaymentStatus, &payment.TransactionID, &payment.PaymentGateway, &payment.PaidAt, &payment.CreatedAt,
+		&payment.ID, &payment.BookingID, &payment.JourneyID, &payment.GroupBookingID, &payment.Amount, &payment.PaymentMethod, &payment.PaymentStatus, &payment.TransactionID, &payment.PaymentGateway, &payment.PaidAt, &payment.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -30,7 +30,7 @@ func GetPaymentByID(db *sql.DB, id int) (*models.Payment, error) {
 }
 
 func GetPaymentsByBookingID(db *sql.DB, bookingID int) ([]models.Payment, error) {
-	query := `SELECT id, booking_id, amount, payment_method, payment_status, transaction_id, payment_gateway, paid_at, created_at FROM payments WHERE booking_id = $1 ORDER BY created_at`
+	query := `SELECT id, COALESCE(booking_id, 0), journey_id, group_booking_id, amount, payment_method, payment_status, transaction_id, payment_gateway, paid_at, created_at FROM payments WHERE booking_id = $1 ORDER BY created_at`
 
 	rows, err := db.Query(query, bookingID)
 	if err != nil {
@@ -42,7 +42,7 @@ func GetPaymentsByBookingID(db *sql.DB, bookingID int) ([]models.Payment, error)
 	for rows.Next() {
 		var payment models.Payment
 		err := rows.Scan(
-			&payment.ID, &payment.BookingID, &payment.Amount, &payment.PaymentMethod, &payment.PaymentStatus, &payment.TransactionID, &payment.PaymentGateway, &payment.PaidAt, &payment.CreatedAt,
+			&payment.ID, &payment.BookingID, &payment.JourneyID, &payment.GroupBookingID, &payment.Amount, &payment.PaymentMethod, &payment.PaymentStatus, &payment.TransactionID, &payment.PaymentGateway, &payment.PaidAt, &payment.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -56,7 +56,7 @@ func GetPaymentsByBookingID(db *sql.DB, bookingID int) ([]models.Payment, error)
 func UpdatePayment(db *sql.DB, payment *models.Payment) error {
 	query := `
 		UPDATE payments
-		SET booking_id = $2, amount = $3, payment_method = $4, payment_status = $5, transaction_id = $6, payment_gateway = $7, paid_at = $8
+		SET booking_id = NULLIF($2, 0), amount = $3, payment_method = $4, payment_status = $5, transaction_id = $6, payment_gateway = $7, paid_at = $8
 		WHERE id = $1`
 
 	_, err := db.Exec(query, payment.ID, payment.BookingID, payment.Amount, payment.PaymentMethod, payment.PaymentStatus, payment.TransactionID, payment.PaymentGateway, payment.PaidAt)
@@ -67,4 +67,4 @@ func DeletePayment(db *sql.DB, id int) error {
 	query := `DELETE FROM payments WHERE id = $1`
 	_, err := db.Exec(query, id)
 	return err
-}
\ No newline at end of file
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+// GetDepartureAttendance returns the completed and no-show bookings of a company's departures with a travel date in [from, to)
+func GetDepartureAttendance(db DBInterface, companyID int, from, to time.Time) ([]models.DepartureAttendance, error) {
+	query := `
+		SELECT b.schedule_id, b.travel_date::date, r.origin_city, r.destination_city,
+		       COUNT(*) FILTER (WHERE b.booking_status = 'completed'),
+		       COUNT(*) FILTER (WHERE b.booking_status = 'no_show'),
+		       COALESCE(SUM(b.no_show_fee), 0)
+		FROM bookings b
+		JOIN schedules s ON b.schedule_id = s.id
+		JOIN routes r ON s.route_id = r.id
+		WHERE r.company_id = $1
+		AND b.travel_date >= $2 AND b.travel_date < $3
+		AND b.booking_status IN ('completed', 'no_show')
+		GROUP BY b.schedule_id, b.travel_date::date, r.origin_city, r.destination_city
+		ORDER BY b.travel_date::date, b.schedule_id`
+
+	rows, err := db.Query(query, companyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	departures := []models.DepartureAttendance{}
+	for rows.Next() {
+		var departure models.DepartureAttendance
+		err := rows.Scan(
+			&departure.ScheduleID, &departure.TravelDate, &departure.OriginCity, &departure.DestinationCity, &departure.Completed, &departure.NoShows, &departure.NoShowFees,
+		)
+		if err != nil {
+			return nil, err
+		}
+		departures = append(departures, departure)
+	}
+
+	return departures, nil
+}
@@ -2,25 +2,26 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
 )
 
-func CreateRoute(db *sql.DB, route *models.Route) error {
+func CreateRoute(db DBInterface, route *models.Route) error {
 	query := `
-		INSERT INTO routes (company_id, origin_city, origin_terminal, destination_city, destination_terminal, distance_km, estimated_duration_minutes, base_price, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		INSERT INTO routes (company_id, origin_city, origin_terminal, destination_city, destination_terminal, distance_km, estimated_duration_minutes, base_price, min_yield_percent, max_yield_percent, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
 		RETURNING id`
 
-	return db.QueryRow(query, route.CompanyID, route.OriginCity, route.OriginTerminal, route.DestinationCity, route.DestinationTerminal, route.DistanceKm, route.EstimatedDurationMinutes, route.BasePrice, route.IsActive).Scan(&route.ID)
+	return db.QueryRow(query, route.CompanyID, route.OriginCity, route.OriginTerminal, route.DestinationCity, route.DestinationTerminal, route.DistanceKm, route.EstimatedDurationMinutes, route.BasePrice, route.MinYieldPercent, route.MaxYieldPercent, route.IsActive).Scan(&route.ID)
 }
 
-func GetRouteByID(db *sql.DB, id int) (*models.Route, error) {
+func GetRouteByID(db DBInterface, id int) (*models.Route, error) {
 	var route models.Route
-	query := `SELECT id, company_id, origin_city, origin_terminal, destination_city, destination_terminal, distance_km, estimated_duration_minutes, base_price, is_active, created_at, updated_at FROM routes WHERE id = $1`
+	query := `SELECT id, company_id, origin_city, origin_terminal, destination_city, destination_terminal, distance_km, estimated_duration_minutes, base_price, min_yield_percent, max_yield_percent, is_active, created_at, updated_at FROM routes WHERE id = $1`
 
 	err := db.QueryRow(query, id).Scan(
-		&route.ID, &route.CompanyID, &route.OriginCity, &route.OriginTerminal, &route.DestinationCity, &route.DestinationTerminal, &route.DistanceKm, &route.EstimatedDurationMinutes, &route.BasePrice, &route.IsActive, &route.CreatedAt, &route.UpdatedAt,
+		&route.ID, &route.CompanyID, &route.OriginCity, &route.OriginTerminal, &route.DestinationCity, &route.DestinationTerminal, &route.DistanceKm, &route.EstimatedDurationMinutes, &route.BasePrice, &route.MinYieldPercent, &route.MaxYieldPercent, &route.IsActive, &route.CreatedAt, &route.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -30,7 +31,7 @@ func GetRouteByID(db *sql.DB, id int) (*models.Route, error) {
 }
 
 func GetAllRoutes(db *sql.DB) ([]models.Route, error) {
-	query := `SELECT id, company_id, origin_city, origin_terminal, destination_city, destination_terminal, distance_km, estimated_duration_minutes, base_price, is_active, created_at, updated_at FROM routes ORDER BY origin_city, destination_city`
+	query := `SELECT id, company_id, origin_city, origin_terminal, destination_city, destination_terminal, distance_km, estimated_duration_minutes, base_price, min_yield_percent, max_yield_percent, is_active, created_at, updated_at FROM routes ORDER BY origin_city, destination_city`
 
 	rows, err := db.Query(query)
 	if err != nil {
@@ -42,7 +43,7 @@ func GetAllRoutes(db *sql.DB) ([]models.Route, error) {
 	for rows.Next() {
 		var route models.Route
 		err := rows.Scan(
-			&route.ID, &route.CompanyID, &route.OriginCity, &route.OriginTerminal, &route.DestinationCity, &route.DestinationTerminal, &route.DistanceKm, &route.EstimatedDurationMinutes, &route.BasePrice, &route.IsActive, &route.CreatedAt, &route.UpdatedAt,
+			&route.ID, &route.CompanyID, &route.OriginCity, &route.OriginTerminal, &route.DestinationCity, &route.DestinationTerminal, &route.DistanceKm, &route.EstimatedDurationMinutes, &route.BasePrice, &route.MinYieldPercent, &route.MaxYieldPercent, &route.IsActive, &route.CreatedAt, &route.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -53,18 +54,67 @@ func GetAllRoutes(db *sql.DB) ([]models.Route, error) {
 	return routes, nil
 }
 
-func UpdateRoute(db *sql.DB, route *models.Route) error {
+func GetRoutesByCompanyID(db DBInterface, companyID int) ([]models.Route, error) {
+	query := `SELECT id, company_id, origin_city, origin_terminal, destination_city, destination_terminal, distance_km, estimated_duration_minutes, base_price, min_yield_percent, max_yield_percent, is_active, created_at, updated_at FROM routes WHERE company_id = $1 ORDER BY id`
+
+	rows, err := db.Query(query, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var routes []models.Route
+	for rows.Next() {
+		var route models.Route
+		err := rows.Scan(
+			&route.ID, &route.CompanyID, &route.OriginCity, &route.OriginTerminal, &route.DestinationCity, &route.DestinationTerminal, &route.DistanceKm, &route.EstimatedDurationMinutes, &route.BasePrice, &route.MinYieldPercent, &route.MaxYieldPercent, &route.IsActive, &route.CreatedAt, &route.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+func UpdateRoute(db DBInterface, route *models.Route) error {
 	query := `
 		UPDATE routes
-		SET company_id = $2, origin_city = $3, origin_terminal = $4, destination_city = $5, destination_terminal = $6, distance_km = $7, estimated_duration_minutes = $8, base_price = $9, is_active = $10, updated_at = NOW()
+		SET company_id = $2, origin_city = $3, origin_terminal = $4, destination_city = $5, destination_terminal = $6, distance_km = $7, estimated_duration_minutes = $8, base_price = $9, min_yield_percent = $10, max_yield_percent = $11, is_active = $12, updated_at = NOW()
 		WHERE id = $1`
 
-	_, err := db.Exec(query, route.ID, route.CompanyID, route.OriginCity, route.OriginTerminal, route.DestinationCity, route.DestinationTerminal, route.DistanceKm, route.EstimatedDurationMinutes, route.BasePrice, route.IsActive)
+	_, err := db.Exec(query, route.ID, route.CompanyID, route.OriginCity, route.OriginTerminal, route.DestinationCity, route.DestinationTerminal, route.DistanceKm, route.EstimatedDurationMinutes, route.BasePrice, route.MinYieldPercent, route.MaxYieldPercent, route.IsActive)
 	return err
 }
 
+// RouteHasUpcomingBookings reports whether bookings, open group bookings or waitlist entries still
+// depend on the stops of a route for departures after now
+func RouteHasUpcomingBookings(db DBInterface, routeID int, now time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM bookings b
+			JOIN schedules s ON b.schedule_id = s.id
+			WHERE s.route_id = $1 AND b.booking_status IN ('confirmed', 'pending') AND b.departure_datetime > $2
+		) OR EXISTS (
+			SELECT 1 FROM group_bookings g
+			JOIN trips t ON g.trip_id = t.id
+			JOIN schedules s ON t.schedule_id = s.id
+			WHERE s.route_id = $1 AND g.status IN ('requested', 'quoted', 'confirmed') AND t.departure_datetime > $2
+		) OR EXISTS (
+			SELECT 1 FROM waitlist_entries w
+			JOIN trips t ON w.trip_id = t.id
+			JOIN schedules s ON t.schedule_id = s.id
+			WHERE s.route_id = $1 AND w.status IN ('waiting', 'offered') AND t.departure_datetime > $2
+		)`
+
+	var exists bool
+	err := db.QueryRow(query, routeID, now).Scan(&exists)
+	return exists, err
+}
+
 func DeleteRoute(db *sql.DB, id int) error {
 	query := `DELETE FROM routes WHERE id = $1`
 	_, err := db.Exec(query, id)
 	return err
-}
\ No newline at end of file
+}
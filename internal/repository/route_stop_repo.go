@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+func CreateRouteStop(db DBInterface, stop *models.RouteStop) error {
+	query := `
+		INSERT INTO route_stops (route_id, stop_sequence, city, terminal, time_zone, offset_minutes, distance_km, latitude, longitude, gtfs_stop_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), NOW())
+		RETURNING id`
+
+	return db.QueryRow(query, stop.RouteID, stop.StopSequence, stop.City, stop.Terminal, stop.TimeZone, stop.OffsetMinutes, stop.DistanceKm, stop.Latitude, stop.Longitude, stop.GTFSStopID).Scan(&stop.ID)
+}
+
+func GetRouteStopsByRouteID(db DBInterface, routeID int) ([]models.RouteStop, error) {
+	query := `SELECT id, route_id, stop_sequence, city, COALESCE(terminal, ''), time_zone, offset_minutes, distance_km, latitude, longitude, COALESCE(gtfs_stop_id, ''), created_at FROM route_stops WHERE route_id = $1 ORDER BY stop_sequence`
+
+	rows, err := db.Query(query, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stops []models.RouteStop
+	for rows.Next() {
+		var stop models.RouteStop
+		err := rows.Scan(
+			&stop.ID, &stop.RouteID, &stop.StopSequence, &stop.City, &stop.Terminal, &stop.TimeZone, &stop.OffsetMinutes, &stop.DistanceKm, &stop.Latitude, &stop.Longitude, &stop.GTFSStopID, &stop.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		stops = append(stops, stop)
+	}
+
+	return stops, nil
+}
+
+// UpdateRouteStopDetails updates the coordinates and GTFS id of a stop, which do not affect bookings
+func UpdateRouteStopDetails(db DBInterface, stop *models.RouteStop) error {
+	query := `UPDATE route_stops SET latitude = $3, longitude = $4, gtfs_stop_id = $5 WHERE route_id = $1 AND stop_sequence = $2`
+	_, err := db.Exec(query, stop.RouteID, stop.StopSequence, stop.Latitude, stop.Longitude, stop.GTFSStopID)
+	return err
+}
+
+func DeleteRouteStopsByRouteID(db DBInterface, routeID int) error {
+	query := `DELETE FROM route_stops WHERE route_id = $1`
+	_, err := db.Exec(query, routeID)
+	return err
+}
+
+func CreateRouteFare(db DBInterface, fare *models.RouteFare) error {
+	query := `
+		INSERT INTO route_fares (route_id, origin_stop_sequence, destination_stop_sequence, price, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id`
+
+	return db.QueryRow(query, fare.RouteID, fare.OriginStopSequence, fare.DestinationStopSequence, fare.Price).Scan(&fare.ID)
+}
+
+func GetRouteFaresByRouteID(db DBInterface, routeID int) ([]models.RouteFare, error) {
+	query := `SELECT id, route_id, origin_stop_sequence, destination_stop_sequence, price, created_at FROM route_fares WHERE route_id = $1 ORDER BY origin_stop_sequence, destination_stop_sequence`
+
+	rows, err := db.Query(query, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fares []models.RouteFare
+	for rows.Next() {
+		var fare models.RouteFare
+		err := rows.Scan(
+			&fare.ID, &fare.RouteID, &fare.OriginStopSequence, &fare.DestinationStopSequence, &fare.Price, &fare.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		fares = append(fares, fare)
+	}
+
+	return fares, nil
+}
+
+func DeleteRouteFaresByRouteID(db DBInterface, routeID int) error {
+	query := `DELETE FROM route_fares WHERE route_id = $1`
+	_, err := db.Exec(query, routeID)
+	return err
+}
@@ -4,23 +4,24 @@ import (
 	"database/sql"
 
 	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/lib/pq"
 )
 
-func CreateSchedule(db *sql.DB, schedule *models.Schedule) error {
+func CreateSchedule(db DBInterface, schedule *models.Schedule) error {
 	query := `
-		INSERT INTO schedules (route_id, vehicle_id, departure_time, arrival_time, days_of_week, valid_from, valid_until, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
-		RETURNING id`
+		INSERT INTO schedules (route_id, vehicle_id, departure_time, arrival_time, days_of_week, calendar_id, valid_from, valid_until, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
 
-	return db.QueryRow(query, schedule.RouteID, schedule.VehicleID, schedule.DepartureTime, schedule.ArrivalTime, schedule.DaysOfWeek, schedule.ValidFrom, schedule.ValidUntil, schedule.IsActive).Scan(&schedule.ID)
+	return db.QueryRow(query, schedule.RouteID, schedule.VehicleID, schedule.DepartureTime, schedule.ArrivalTime, pq.Array(schedule.DaysOfWeek), schedule.CalendarID, schedule.ValidFrom, schedule.ValidUntil, schedule.IsActive).Scan(&schedule.ID, &schedule.CreatedAt, &schedule.UpdatedAt)
 }
 
-func GetScheduleByID(db *sql.DB, id int) (*models.Schedule, error) {
+func GetScheduleByID(db DBInterface, id int) (*models.Schedule, error) {
 	var schedule models.Schedule
-	query := `SELECT id, route_id, vehicle_id, departure_time, arrival_time, days_of_week, valid_from, valid_until, is_active, created_at, updated_at FROM schedules WHERE id = $1`
+	query := `SELECT id, route_id, vehicle_id, departure_time, arrival_time, days_of_week, calendar_id, valid_from, valid_until, is_active, created_at, updated_at FROM schedules WHERE id = $1`
 
 	err := db.QueryRow(query, id).Scan(
-		&schedule.ID, &schedule.RouteID, &schedule.VehicleID, &schedule.DepartureTime, &schedule.ArrivalTime, &schedule.DaysOfWeek, &schedule.ValidFrom, &schedule.ValidUntil, &schedule.IsActive, &schedule.CreatedAt, &schedule.UpdatedAt,
+		&schedule.ID, &schedule.RouteID, &schedule.VehicleID, &schedule.DepartureTime, &schedule.ArrivalTime, pq.Array(&schedule.DaysOfWeek), &schedule.CalendarID, &schedule.ValidFrom, &schedule.ValidUntil, &schedule.IsActive, &schedule.CreatedAt, &schedule.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -30,7 +31,7 @@ func GetScheduleByID(db *sql.DB, id int) (*models.Schedule, error) {
 }
 
 func GetAllSchedules(db *sql.DB) ([]models.Schedule, error) {
-	query := `SELECT id, route_id, vehicle_id, departure_time, arrival_time, days_of_week, valid_from, valid_until, is_active, created_at, updated_at FROM schedules ORDER BY valid_from`
+	query := `SELECT id, route_id, vehicle_id, departure_time, arrival_time, days_of_week, calendar_id, valid_from, valid_until, is_active, created_at, updated_at FROM schedules ORDER BY valid_from`
 
 	rows, err := db.Query(query)
 	if err != nil {
@@ -42,7 +43,7 @@ func GetAllSchedules(db *sql.DB) ([]models.Schedule, error) {
 	for rows.Next() {
 		var schedule models.Schedule
 		err := rows.Scan(
-			&schedule.ID, &schedule.RouteID, &schedule.VehicleID, &schedule.DepartureTime, &schedule.ArrivalTime, &schedule.DaysOfWeek, &schedule.ValidFrom, &schedule.ValidUntil, &schedule.IsActive, &schedule.CreatedAt, &schedule.UpdatedAt,
+			&schedule.ID, &schedule.RouteID, &schedule.VehicleID, &schedule.DepartureTime, &schedule.ArrivalTime, pq.Array(&schedule.DaysOfWeek), &schedule.CalendarID, &schedule.ValidFrom, &schedule.ValidUntil, &schedule.IsActive, &schedule.CreatedAt, &schedule.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -53,13 +54,94 @@ func GetAllSchedules(db *sql.DB) ([]models.Schedule, error) {
 	return schedules, nil
 }
 
-func UpdateSchedule(db *sql.DB, schedule *models.Schedule) error {
+func GetSchedulesByRouteID(db DBInterface, routeID int) ([]models.Schedule, error) {
+	query := `SELECT id, route_id, vehicle_id, departure_time, arrival_time, days_of_week, calendar_id, valid_from, valid_until, is_active, created_at, updated_at FROM schedules WHERE route_id = $1 ORDER BY departure_time, id`
+
+	rows, err := db.Query(query, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []models.Schedule
+	for rows.Next() {
+		var schedule models.Schedule
+		err := rows.Scan(
+			&schedule.ID, &schedule.RouteID, &schedule.VehicleID, &schedule.DepartureTime, &schedule.ArrivalTime, pq.Array(&schedule.DaysOfWeek), &schedule.CalendarID, &schedule.ValidFrom, &schedule.ValidUntil, &schedule.IsActive, &schedule.CreatedAt, &schedule.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+// GetActiveSchedulesByVehicleID lists the active schedules run by a vehicle
+func GetActiveSchedulesByVehicleID(db DBInterface, vehicleID int) ([]models.Schedule, error) {
+	query := `SELECT id, route_id, vehicle_id, departure_time, arrival_time, days_of_week, calendar_id, valid_from, valid_until, is_active, created_at, updated_at FROM schedules WHERE vehicle_id = $1 AND is_active = true ORDER BY id`
+
+	rows, err := db.Query(query, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []models.Schedule
+	for rows.Next() {
+		var schedule models.Schedule
+		err := rows.Scan(
+			&schedule.ID, &schedule.RouteID, &schedule.VehicleID, &schedule.DepartureTime, &schedule.ArrivalTime, pq.Array(&schedule.DaysOfWeek), &schedule.CalendarID, &schedule.ValidFrom, &schedule.ValidUntil, &schedule.IsActive, &schedule.CreatedAt, &schedule.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+// GetCrossCompanySchedules lists active schedules whose vehicle belongs to another company than their route,
+// where either company is the given one
+func GetCrossCompanySchedules(db DBInterface, companyID int) ([]models.Schedule, error) {
+	query := `
+		SELECT s.id, s.route_id, s.vehicle_id, s.departure_time, s.arrival_time, s.days_of_week, s.calendar_id, s.valid_from, s.valid_until, s.is_active, s.created_at, s.updated_at
+		FROM schedules s
+		JOIN routes r ON s.route_id = r.id
+		JOIN vehicles v ON s.vehicle_id = v.id
+		WHERE s.is_active = true AND r.company_id <> v.company_id AND (r.company_id = $1 OR v.company_id = $1)
+		ORDER BY s.id`
+
+	rows, err := db.Query(query, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []models.Schedule
+	for rows.Next() {
+		var schedule models.Schedule
+		err := rows.Scan(
+			&schedule.ID, &schedule.RouteID, &schedule.VehicleID, &schedule.DepartureTime, &schedule.ArrivalTime, pq.Array(&schedule.DaysOfWeek), &schedule.CalendarID, &schedule.ValidFrom, &schedule.ValidUntil, &schedule.IsActive, &schedule.CreatedAt, &schedule.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+func UpdateSchedule(db DBInterface, schedule *models.Schedule) error {
 	query := `
 		UPDATE schedules
-		SET route_id = $2, vehicle_id = $3, departure_time = $4, arrival_time = $5, days_of_week = $6, valid_from = $7, valid_until = $8, is_active = $9, updated_at = NOW()
+		SET route_id = $2, vehicle_id = $3, departure_time = $4, arrival_time = $5, days_of_week = $6, calendar_id = $7, valid_from = $8, valid_until = $9, is_active = $10, updated_at = NOW()
 		WHERE id = $1`
 
-	_, err := db.Exec(query, schedule.ID, schedule.RouteID, schedule.VehicleID, schedule.DepartureTime, schedule.ArrivalTime, schedule.DaysOfWeek, schedule.ValidFrom, schedule.ValidUntil, schedule.IsActive)
+	_, err := db.Exec(query, schedule.ID, schedule.RouteID, schedule.VehicleID, schedule.DepartureTime, schedule.ArrivalTime, pq.Array(schedule.DaysOfWeek), schedule.CalendarID, schedule.ValidFrom, schedule.ValidUntil, schedule.IsActive)
 	return err
 }
 
@@ -67,4 +149,33 @@ func DeleteSchedule(db *sql.DB, id int) error {
 	query := `DELETE FROM schedules WHERE id = $1`
 	_, err := db.Exec(query, id)
 	return err
-}
\ No newline at end of file
+}
+
+// GetScheduleCompanyID returns the company owning the route of a schedule
+func GetScheduleCompanyID(db DBInterface, scheduleID int) (int, error) {
+	var companyID int
+	query := `SELECT r.company_id FROM schedules s JOIN routes r ON s.route_id = r.id WHERE s.id = $1`
+
+	err := db.QueryRow(query, scheduleID).Scan(&companyID)
+	return companyID, err
+}
+
+// ScheduleRunsOnCondition returns an SQL condition, for a schedule aliased s, that holds when the schedule
+// runs on the given date expression. Calendar exceptions win over the validity period and days of week.
+func ScheduleRunsOnCondition(date string) string {
+	return `(
+		EXISTS (
+			SELECT 1 FROM calendar_exceptions ce
+			WHERE ce.calendar_id = s.calendar_id AND ce.exception_date = ` + date + ` AND ce.exception_type = 'added'
+		)
+		OR (
+			s.valid_from <= ` + date + `
+			AND (s.valid_until IS NULL OR s.valid_until >= ` + date + `)
+			AND EXTRACT(ISODOW FROM ` + date + `)::int = ANY(s.days_of_week)
+			AND NOT EXISTS (
+				SELECT 1 FROM calendar_exceptions ce
+				WHERE ce.calendar_id = s.calendar_id AND ce.exception_date = ` + date + ` AND ce.exception_type = 'removed'
+			)
+		)
+	)`
+}
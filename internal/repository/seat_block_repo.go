@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+// seatBlockActiveCondition holds for the blocks, aliased b, applying to schedule $1 on travel date $2 that are not
+// released yet. It needs the schedule aliased sch, its first stop f and its trip on that date, if any, t.
+const seatBlockActiveCondition = `b.schedule_id = $1
+	AND (b.travel_date IS NULL OR b.travel_date = $2::date)
+	AND (b.release_minutes_before IS NULL
+		OR NOW() < COALESCE(t.departure_datetime, ($2::date + sch.departure_time) AT TIME ZONE COALESCE(f.time_zone, 'UTC')) - make_interval(mins => b.release_minutes_before))`
+
+// firstStopJoin joins the first stop, if any, of the route of the schedule aliased sch
+const firstStopJoin = `LEFT JOIN route_stops f ON f.route_id = sch.route_id
+		AND f.stop_sequence = (SELECT MIN(stop_sequence) FROM route_stops WHERE route_id = sch.route_id)`
+
+const seatBlockColumns = `b.id, b.schedule_id, b.travel_date, b.seat_id, COALESCE(st.seat_number, ''), b.seat_count, b.reason, COALESCE(b.note, ''), b.release_minutes_before, b.created_by, b.created_at`
+
+func scanSeatBlock(row rowScanner) (*models.SeatBlock, error) {
+	var block models.SeatBlock
+	err := row.Scan(
+		&block.ID, &block.ScheduleID, &block.TravelDate, &block.SeatID, &block.SeatNumber, &block.SeatCount, &block.Reason, &block.Note, &block.ReleaseMinutesBefore, &block.CreatedBy, &block.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func CreateSeatBlock(db DBInterface, block *models.SeatBlock) error {
+	query := `
+		INSERT INTO seat_blocks (schedule_id, travel_date, seat_id, seat_count, reason, note, release_minutes_before, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, NOW())
+		RETURNING id, created_at`
+
+	return db.QueryRow(query, block.ScheduleID, block.TravelDate, block.SeatID, block.SeatCount, block.Reason, block.Note, block.ReleaseMinutesBefore, block.CreatedBy).Scan(&block.ID, &block.CreatedAt)
+}
+
+func GetSeatBlockByID(db DBInterface, id int) (*models.SeatBlock, error) {
+	query := `SELECT ` + seatBlockColumns + ` FROM seat_blocks b LEFT JOIN seats st ON b.seat_id = st.id WHERE b.id = $1`
+	return scanSeatBlock(db.QueryRow(query, id))
+}
+
+// GetSeatBlocks returns the blocks of a schedule, those of every trip first. With a travel date, only the
+// blocks applying to the trip of that date are returned.
+func GetSeatBlocks(db DBInterface, scheduleID int, travelDate *time.Time) ([]models.SeatBlock, error) {
+	query := `
+		SELECT ` + seatBlockColumns + `
+		FROM seat_blocks b
+		LEFT JOIN seats st ON b.seat_id = st.id
+		WHERE b.schedule_id = $1
+		AND ($2::date IS NULL OR b.travel_date IS NULL OR b.travel_date = $2::date)
+		ORDER BY b.travel_date NULLS FIRST, st.row_number, st.column_position, b.id`
+
+	rows, err := db.Query(query, scheduleID, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := []models.SeatBlock{}
+	for rows.Next() {
+		block, err := scanSeatBlock(rows)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, *block)
+	}
+
+	return blocks, nil
+}
+
+// MoveSeatBlock moves a seat block to another seat
+func MoveSeatBlock(db DBInterface, id, seatID int) error {
+	_, err := db.Exec(`UPDATE seat_blocks SET seat_id = $2 WHERE id = $1`, id, seatID)
+	return err
+}
+
+func DeleteSeatBlock(db DBInterface, id int) error {
+	_, err := db.Exec(`DELETE FROM seat_blocks WHERE id = $1`, id)
+	return err
+}
+
+// CountBlockedSeats returns the number of seats held by the count blocks of a schedule on a travel date
+// that are not released yet
+func CountBlockedSeats(db DBInterface, scheduleID int, travelDate string) (int, error) {
+	query := `
+		SELECT COALESCE(SUM(b.seat_count), 0)
+		FROM seat_blocks b
+		JOIN schedules sch ON b.schedule_id = sch.id
+		` + firstStopJoin + `
+		LEFT JOIN trips t ON t.schedule_id = sch.id AND t.travel_date = $2::date
+		WHERE b.seat_count IS NOT NULL
+		AND ` + seatBlockActiveCondition
+
+	var count int
+	err := db.QueryRow(query, scheduleID, travelDate).Scan(&count)
+	return count, err
+}
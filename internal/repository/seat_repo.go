@@ -29,7 +29,7 @@ func GetSeatByID(db *sql.DB, id int) (*models.Seat, error) {
 	return &seat, nil
 }
 
-func GetSeatsByVehicleID(db *sql.DB, vehicleID int) ([]models.Seat, error) {
+func GetSeatsByVehicleID(db DBInterface, vehicleID int) ([]models.Seat, error) {
 	query := `SELECT id, vehicle_id, seat_number, seat_type, row_number, column_position, price_modifier, is_available, created_at FROM seats WHERE vehicle_id = $1 ORDER BY row_number, column_position`
 
 	rows, err := db.Query(query, vehicleID)
@@ -67,4 +67,4 @@ func DeleteSeat(db *sql.DB, id int) error {
 	query := `DELETE FROM seats WHERE id = $1`
 	_, err := db.Exec(query, id)
 	return err
-}
\ No newline at end of file
+}
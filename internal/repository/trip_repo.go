@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+// CreateTrip inserts the trip for a schedule and date, or returns the existing one
+func CreateTrip(db DBInterface, trip *models.Trip) error {
+	query := `
+		INSERT INTO trips (schedule_id, vehicle_id, travel_date, departure_datetime, arrival_datetime, status, delay_minutes, status_reason, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 'scheduled', 0, '', NOW(), NOW())
+		ON CONFLICT (schedule_id, travel_date) DO UPDATE SET schedule_id = EXCLUDED.schedule_id
+		RETURNING id, vehicle_id, departure_datetime, arrival_datetime, status, delay_minutes, COALESCE(status_reason, ''), status_changed_at, created_at, updated_at`
+
+	return db.QueryRow(query, trip.ScheduleID, trip.VehicleID, trip.TravelDate, trip.DepartureDatetime, trip.ArrivalDatetime).Scan(
+		&trip.ID, &trip.VehicleID, &trip.DepartureDatetime, &trip.ArrivalDatetime, &trip.Status, &trip.DelayMinutes, &trip.StatusReason, &trip.StatusChangedAt, &trip.CreatedAt, &trip.UpdatedAt,
+	)
+}
+
+func GetTripByID(db DBInterface, id int) (*models.Trip, error) {
+	var trip models.Trip
+	query := `SELECT id, schedule_id, vehicle_id, travel_date, departure_datetime, arrival_datetime, status, delay_minutes, COALESCE(status_reason, ''), status_changed_at, created_at, updated_at FROM trips WHERE id = $1`
+
+	err := db.QueryRow(query, id).Scan(
+		&trip.ID, &trip.ScheduleID, &trip.VehicleID, &trip.TravelDate, &trip.DepartureDatetime, &trip.ArrivalDatetime, &trip.Status, &trip.DelayMinutes, &trip.StatusReason, &trip.StatusChangedAt, &trip.CreatedAt, &trip.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &trip, nil
+}
+
+func GetTripByScheduleAndDate(db DBInterface, scheduleID int, travelDate time.Time) (*models.Trip, error) {
+	var trip models.Trip
+	query := `SELECT id, schedule_id, vehicle_id, travel_date, departure_datetime, arrival_datetime, status, delay_minutes, COALESCE(status_reason, ''), status_changed_at, created_at, updated_at FROM trips WHERE schedule_id = $1 AND travel_date = $2::date`
+
+	err := db.QueryRow(query, scheduleID, travelDate).Scan(
+		&trip.ID, &trip.ScheduleID, &trip.VehicleID, &trip.TravelDate, &trip.DepartureDatetime, &trip.ArrivalDatetime, &trip.Status, &trip.DelayMinutes, &trip.StatusReason, &trip.StatusChangedAt, &trip.CreatedAt, &trip.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &trip, nil
+}
+
+func GetTripsByDate(db *sql.DB, travelDate time.Time) ([]models.Trip, error) {
+	query := `SELECT id, schedule_id, vehicle_id, travel_date, departure_datetime, arrival_datetime, status, delay_minutes, COALESCE(status_reason, ''), status_changed_at, created_at, updated_at FROM trips WHERE travel_date = $1::date ORDER BY departure_datetime`
+
+	rows, err := db.Query(query, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []models.Trip
+	for rows.Next() {
+		var trip models.Trip
+		err := rows.Scan(
+			&trip.ID, &trip.ScheduleID, &trip.VehicleID, &trip.TravelDate, &trip.DepartureDatetime, &trip.ArrivalDatetime, &trip.Status, &trip.DelayMinutes, &trip.StatusReason, &trip.StatusChangedAt, &trip.CreatedAt, &trip.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, trip)
+	}
+
+	return trips, nil
+}
+
+// GetDisruptedTrips lists delayed and cancelled trips departing before until that have not arrived by from
+func GetDisruptedTrips(db DBInterface, from, until time.Time) ([]models.Trip, error) {
+	query := `
+		SELECT id, schedule_id, vehicle_id, travel_date, departure_datetime, arrival_datetime, status, delay_minutes, COALESCE(status_reason, ''), status_changed_at, created_at, updated_at
+		FROM trips
+		WHERE status IN ('delayed', 'cancelled')
+		  AND departure_datetime <= $2
+		  AND arrival_datetime + delay_minutes * INTERVAL '1 minute' >= $1
+		ORDER BY departure_datetime`
+
+	rows, err := db.Query(query, from, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []models.Trip
+	for rows.Next() {
+		var trip models.Trip
+		err := rows.Scan(
+			&trip.ID, &trip.ScheduleID, &trip.VehicleID, &trip.TravelDate, &trip.DepartureDatetime, &trip.ArrivalDatetime, &trip.Status, &trip.DelayMinutes, &trip.StatusReason, &trip.StatusChangedAt, &trip.CreatedAt, &trip.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, trip)
+	}
+
+	return trips, nil
+}
+
+// GetVehicleTripAt returns the trip a vehicle is assigned to around a time, from margin before its
+// departure to margin after its (delayed) arrival. Cancelled trips are ignored.
+func GetVehicleTripAt(db DBInterface, vehicleID int, at time.Time, margin time.Duration) (*models.Trip, error) {
+	var trip models.Trip
+	query := `
+		SELECT id, schedule_id, vehicle_id, travel_date, departure_datetime, arrival_datetime, status, delay_minutes, COALESCE(status_reason, ''), status_changed_at, created_at, updated_at
+		FROM trips
+		WHERE vehicle_id = $1 AND status <> 'cancelled'
+		  AND departure_datetime - $3 * INTERVAL '1 second' <= $2
+		  AND arrival_datetime + delay_minutes * INTERVAL '1 minute' + $3 * INTERVAL '1 second' >= $2
+		ORDER BY ABS(EXTRACT(EPOCH FROM (departure_datetime - $2)))
+		LIMIT 1`
+
+	err := db.QueryRow(query, vehicleID, at, margin.Seconds()).Scan(
+		&trip.ID, &trip.ScheduleID, &trip.VehicleID, &trip.TravelDate, &trip.DepartureDatetime, &trip.ArrivalDatetime, &trip.Status, &trip.DelayMinutes, &trip.StatusReason, &trip.StatusChangedAt, &trip.CreatedAt, &trip.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &trip, nil
+}
+
+// GetVehicleTripsBetween lists the trips run by a vehicle, or materialised from its schedules, that overlap a period.
+// Trips of its schedules may have been reassigned to another vehicle.
+func GetVehicleTripsBetween(db DBInterface, vehicleID int, from, to time.Time) ([]models.Trip, error) {
+	query := `
+		SELECT id, schedule_id, vehicle_id, travel_date, departure_datetime, arrival_datetime, status, delay_minutes, COALESCE(status_reason, ''), status_changed_at, created_at, updated_at
+		FROM trips
+		WHERE (vehicle_id = $1 OR schedule_id IN (SELECT id FROM schedules WHERE vehicle_id = $1))
+		  AND departure_datetime < $3
+		  AND arrival_datetime + delay_minutes * INTERVAL '1 minute' > $2
+		ORDER BY departure_datetime`
+
+	rows, err := db.Query(query, vehicleID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []models.Trip
+	for rows.Next() {
+		var trip models.Trip
+		err := rows.Scan(
+			&trip.ID, &trip.ScheduleID, &trip.VehicleID, &trip.TravelDate, &trip.DepartureDatetime, &trip.ArrivalDatetime, &trip.Status, &trip.DelayMinutes, &trip.StatusReason, &trip.StatusChangedAt, &trip.CreatedAt, &trip.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, trip)
+	}
+
+	return trips, nil
+}
+
+func UpdateTripVehicle(db DBInterface, tripID, vehicleID int) error {
+	query := `UPDATE trips SET vehicle_id = $2, updated_at = NOW() WHERE id = $1`
+	_, err := db.Exec(query, tripID, vehicleID)
+	return err
+}
+
+func UpdateTripStatus(db DBInterface, trip *models.Trip) error {
+	query := `
+		UPDATE trips
+		SET status = $2, delay_minutes = $3, status_reason = $4, status_changed_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+		RETURNING status_changed_at, updated_at`
+
+	return db.QueryRow(query, trip.ID, trip.Status, trip.DelayMinutes, trip.StatusReason).Scan(&trip.StatusChangedAt, &trip.UpdatedAt)
+}
+
+// GetAlternativeDepartures lists departures of the same route leaving after a given time, within the next days.
+// Schedule times are read in the time zone of the route's first stop.
+func GetAlternativeDepartures(db DBInterface, routeID int, after time.Time, days int, excludeScheduleID int) ([]models.Departure, error) {
+	query := `
+		SELECT s.id, d::date, (d::date + s.departure_time) AT TIME ZONE f.time_zone
+		FROM schedules s
+		JOIN route_stops f ON f.route_id = s.route_id
+			AND f.stop_sequence = (SELECT MIN(stop_sequence) FROM route_stops WHERE route_id = s.route_id)
+		CROSS JOIN generate_series($2::date, $2::date + $3::int, INTERVAL '1 day') d
+		WHERE s.route_id = $1
+		AND s.is_active = true
+		AND ` + ScheduleRunsOnCondition("d::date") + `
+		AND (d::date + s.departure_time) AT TIME ZONE f.time_zone > $2
+		AND NOT (s.id = $4 AND d::date = $2::date)
+		AND NOT EXISTS (
+			SELECT 1 FROM trips t
+			WHERE t.schedule_id = s.id
+			AND t.travel_date = d::date
+			AND t.status = 'cancelled'
+		)
+		ORDER BY d, s.departure_time`
+
+	rows, err := db.Query(query, routeID, after, days, excludeScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var departures []models.Departure
+	for rows.Next() {
+		var departure models.Departure
+		if err := rows.Scan(&departure.ScheduleID, &departure.TravelDate, &departure.DepartureDatetime); err != nil {
+			return nil, err
+		}
+		departures = append(departures, departure)
+	}
+
+	return departures, nil
+}
+
+// GetTripCompanyID returns the company operating a trip
+func GetTripCompanyID(db DBInterface, tripID int) (int, error) {
+	var companyID int
+	query := `
+		SELECT r.company_id
+		FROM trips t
+		JOIN schedules s ON t.schedule_id = s.id
+		JOIN routes r ON s.route_id = r.id
+		WHERE t.id = $1`
+
+	err := db.QueryRow(query, tripID).Scan(&companyID)
+	return companyID, err
+}
@@ -10,17 +10,17 @@ func CreateUser(db *sql.DB, user *models.User) error {
 	query := `
 		INSERT INTO users (email, password_hash, first_name, last_name, phone, document_type, document_number, is_active, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
-		RETURNING id`
+		RETURNING id, role, company_id`
 
-	return db.QueryRow(query, user.Email, user.Password, user.FirstName, user.LastName, user.Phone, user.DocumentType, user.DocumentNumber, user.IsActive).Scan(&user.ID)
+	return db.QueryRow(query, user.Email, user.Password, user.FirstName, user.LastName, user.Phone, user.DocumentType, user.DocumentNumber, user.IsActive).Scan(&user.ID, &user.Role, &user.CompanyID)
 }
 
 func GetUserByEmail(db *sql.DB, email string) (*models.User, error) {
 	var user models.User
-	query := `SELECT id, email, password_hash, first_name, last_name, phone, document_type, document_number, is_active, created_at, updated_at FROM users WHERE email = $1`
+	query := `SELECT id, email, password_hash, first_name, last_name, phone, document_type, document_number, role, company_id, is_active, created_at, updated_at FROM users WHERE email = $1`
 
 	err := db.QueryRow(query, email).Scan(
-		&user.ID, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.Phone, &user.DocumentType, &user.DocumentNumber, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.Phone, &user.DocumentType, &user.DocumentNumber, &user.Role, &user.CompanyID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -30,7 +30,7 @@ func GetUserByEmail(db *sql.DB, email string) (*models.User, error) {
 }
 
 func GetAllUsers(db *sql.DB) ([]models.User, error) {
-	query := `SELECT id, email, password_hash, first_name, last_name, phone, document_type, document_number, is_active, created_at, updated_at FROM users ORDER BY created_at DESC`
+	query := `SELECT id, email, password_hash, first_name, last_name, phone, document_type, document_number, role, company_id, is_active, created_at, updated_at FROM users ORDER BY created_at DESC`
 
 	rows, err := db.Query(query)
 	if err != nil {
@@ -43,7 +43,7 @@ func GetAllUsers(db *sql.DB) ([]models.User, error) {
 		var user models.User
 		err := rows.Scan(
 			&user.ID, &user.Email, &user.Password, &user.FirstName, &user.LastName,
-			&user.Phone, &user.DocumentType, &user.DocumentNumber, &user.IsActive,
+			&user.Phone, &user.DocumentType, &user.DocumentNumber, &user.Role, &user.CompanyID, &user.IsActive,
 			&user.CreatedAt, &user.UpdatedAt,
 		)
 		if err != nil {
@@ -57,7 +57,7 @@ func GetAllUsers(db *sql.DB) ([]models.User, error) {
 
 func SearchUsers(db *sql.DB, query string) ([]models.User, error) {
 	searchQuery := `
-		SELECT id, email, password_hash, first_name, last_name, phone, document_type, document_number, is_active, created_at, updated_at
+		SELECT id, email, password_hash, first_name, last_name, phone, document_type, document_number, role, company_id, is_active, created_at, updated_at
 		FROM users
 		WHERE first_name ILIKE $1 OR last_name ILIKE $1 OR email ILIKE $1
 		ORDER BY first_name, last_name`
@@ -73,7 +73,7 @@ func SearchUsers(db *sql.DB, query string) ([]models.User, error) {
 		var user models.User
 		err := rows.Scan(
 			&user.ID, &user.Email, &user.Password, &user.FirstName, &user.LastName,
-			&user.Phone, &user.DocumentType, &user.DocumentNumber, &user.IsActive,
+			&user.Phone, &user.DocumentType, &user.DocumentNumber, &user.Role, &user.CompanyID, &user.IsActive,
 			&user.CreatedAt, &user.UpdatedAt,
 		)
 		if err != nil {
@@ -97,6 +97,19 @@ func UpdateUser(db *sql.DB, user *models.User) error {
 	return err
 }
 
+// UpdateUserRole sets the role of a user and the company they operate for
+func UpdateUserRole(db *sql.DB, id int, role string, companyID *int) error {
+	query := `UPDATE users SET role = $2, company_id = $3, updated_at = NOW() WHERE id = $1`
+	result, err := db.Exec(query, id, role, companyID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 func DeleteUser(db *sql.DB, id int) error {
 	query := `DELETE FROM users WHERE id = $1`
 	_, err := db.Exec(query, id)
@@ -105,14 +118,14 @@ func DeleteUser(db *sql.DB, id int) error {
 
 func GetUserByID(db *sql.DB, id int) (*models.User, error) {
 	var user models.User
-	query := `SELECT id, email, password_hash, first_name, last_name, phone, document_type, document_number, is_active, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT id, email, password_hash, first_name, last_name, phone, document_type, document_number, role, company_id, is_active, created_at, updated_at FROM users WHERE id = $1`
 
 	err := db.QueryRow(query, id).Scan(
-		&user.ID, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.Phone, &user.DocumentType, &user.DocumentNumber, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.Phone, &user.DocumentType, &user.DocumentNumber, &user.Role, &user.CompanyID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	return &user, nil
-}
\ No newline at end of file
+}
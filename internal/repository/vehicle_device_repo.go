@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+func CreateVehicleDevice(db DBInterface, device *models.VehicleDevice, tokenHash string) error {
+	query := `
+		INSERT INTO vehicle_devices (vehicle_id, name, token_hash, is_active, created_at)
+		VALUES ($1, $2, $3, true, NOW())
+		RETURNING id, is_active, created_at`
+
+	return db.QueryRow(query, device.VehicleID, device.Name, tokenHash).Scan(&device.ID, &device.IsActive, &device.CreatedAt)
+}
+
+// GetActiveVehicleDeviceByToken returns the active device holding the token with the given hash
+func GetActiveVehicleDeviceByToken(db DBInterface, tokenHash string) (*models.VehicleDevice, error) {
+	var device models.VehicleDevice
+	query := `SELECT id, vehicle_id, name, is_active, last_seen_at, created_at FROM vehicle_devices WHERE token_hash = $1 AND is_active = true`
+
+	err := db.QueryRow(query, tokenHash).Scan(&device.ID, &device.VehicleID, &device.Name, &device.IsActive, &device.LastSeenAt, &device.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &device, nil
+}
+
+func GetVehicleDevicesByVehicleID(db DBInterface, vehicleID int) ([]models.VehicleDevice, error) {
+	query := `SELECT id, vehicle_id, name, is_active, last_seen_at, created_at FROM vehicle_devices WHERE vehicle_id = $1 ORDER BY id`
+
+	rows, err := db.Query(query, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := []models.VehicleDevice{}
+	for rows.Next() {
+		var device models.VehicleDevice
+		if err := rows.Scan(&device.ID, &device.VehicleID, &device.Name, &device.IsActive, &device.LastSeenAt, &device.CreatedAt); err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+
+	return devices, nil
+}
+
+// DeactivateVehicleDevice revokes a device's token
+func DeactivateVehicleDevice(db DBInterface, vehicleID, deviceID int) error {
+	query := `UPDATE vehicle_devices SET is_active = false WHERE id = $1 AND vehicle_id = $2`
+
+	result, err := db.Exec(query, deviceID, vehicleID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func TouchVehicleDevice(db DBInterface, deviceID int) error {
+	query := `UPDATE vehicle_devices SET last_seen_at = NOW() WHERE id = $1`
+	_, err := db.Exec(query, deviceID)
+	return err
+}
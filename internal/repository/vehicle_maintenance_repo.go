@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+func CreateMaintenanceWindow(db DBInterface, window *models.MaintenanceWindow) error {
+	query := `
+		INSERT INTO vehicle_maintenance_windows (vehicle_id, starts_at, ends_at, reason, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at`
+
+	return db.QueryRow(query, window.VehicleID, window.StartsAt, window.EndsAt, window.Reason).Scan(&window.ID, &window.CreatedAt)
+}
+
+// GetMaintenanceWindows returns the maintenance windows of a vehicle that overlap a period
+func GetMaintenanceWindows(db DBInterface, vehicleID int, from, to time.Time) ([]models.MaintenanceWindow, error) {
+	query := `
+		SELECT id, vehicle_id, starts_at, ends_at, reason, created_at
+		FROM vehicle_maintenance_windows
+		WHERE vehicle_id = $1 AND starts_at < $3 AND ends_at > $2
+		ORDER BY starts_at`
+
+	rows, err := db.Query(query, vehicleID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []models.MaintenanceWindow{}
+	for rows.Next() {
+		var window models.MaintenanceWindow
+		if err := rows.Scan(&window.ID, &window.VehicleID, &window.StartsAt, &window.EndsAt, &window.Reason, &window.CreatedAt); err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+
+	return windows, nil
+}
+
+func DeleteMaintenanceWindow(db DBInterface, vehicleID, windowID int) error {
+	query := `DELETE FROM vehicle_maintenance_windows WHERE id = $1 AND vehicle_id = $2`
+
+	result, err := db.Exec(query, windowID, vehicleID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+func CreateVehiclePosition(db DBInterface, position *models.VehiclePosition) error {
+	query := `
+		INSERT INTO vehicle_positions (vehicle_id, trip_id, latitude, longitude, bearing, speed_kmh, recorded_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING id, created_at`
+
+	return db.QueryRow(query, position.VehicleID, position.TripID, position.Latitude, position.Longitude, position.Bearing, position.SpeedKmh, position.RecordedAt).Scan(&position.ID, &position.CreatedAt)
+}
+
+// GetLatestTripPosition returns the last position reported for a trip
+func GetLatestTripPosition(db DBInterface, tripID int) (*models.VehiclePosition, error) {
+	var position models.VehiclePosition
+	query := `
+		SELECT id, vehicle_id, trip_id, latitude, longitude, bearing, speed_kmh, recorded_at, created_at
+		FROM vehicle_positions
+		WHERE trip_id = $1
+		ORDER BY recorded_at DESC
+		LIMIT 1`
+
+	err := db.QueryRow(query, tripID).Scan(
+		&position.ID, &position.VehicleID, &position.TripID, &position.Latitude, &position.Longitude, &position.Bearing, &position.SpeedKmh, &position.RecordedAt, &position.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &position, nil
+}
+
+// GetTripPositions returns the positions reported for a trip since the given time, oldest first
+func GetTripPositions(db DBInterface, tripID int, since time.Time) ([]models.VehiclePosition, error) {
+	query := `
+		SELECT id, vehicle_id, trip_id, latitude, longitude, bearing, speed_kmh, recorded_at, created_at
+		FROM vehicle_positions
+		WHERE trip_id = $1 AND recorded_at >= $2
+		ORDER BY recorded_at`
+
+	return queryVehiclePositions(db, query, tripID, since)
+}
+
+// GetLatestTripPositions returns the last position of each trip reported since the given time
+func GetLatestTripPositions(db DBInterface, since time.Time) ([]models.VehiclePosition, error) {
+	query := `
+		SELECT DISTINCT ON (trip_id) id, vehicle_id, trip_id, latitude, longitude, bearing, speed_kmh, recorded_at, created_at
+		FROM vehicle_positions
+		WHERE trip_id IS NOT NULL AND recorded_at >= $1
+		ORDER BY trip_id, recorded_at DESC`
+
+	return queryVehiclePositions(db, query, since)
+}
+
+func queryVehiclePositions(db DBInterface, query string, args ...interface{}) ([]models.VehiclePosition, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions := []models.VehiclePosition{}
+	for rows.Next() {
+		var position models.VehiclePosition
+		err := rows.Scan(
+			&position.ID, &position.VehicleID, &position.TripID, &position.Latitude, &position.Longitude, &position.Bearing, &position.SpeedKmh, &position.RecordedAt, &position.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, position)
+	}
+
+	return positions, nil
+}
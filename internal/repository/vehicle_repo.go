@@ -19,7 +19,7 @@ func CreateVehicle(db *sql.DB, vehicle *models.Vehicle) error {
 	return db.QueryRow(query, vehicle.CompanyID, vehicle.LicensePlate, vehicle.VehicleType, vehicle.Brand, vehicle.Model, vehicle.Year, vehicle.TotalSeats, seatLayoutJSON, amenitiesJSON, vehicle.IsActive).Scan(&vehicle.ID)
 }
 
-func GetVehicleByID(db *sql.DB, id int) (*models.Vehicle, error) {
+func GetVehicleByID(db DBInterface, id int) (*models.Vehicle, error) {
 	var vehicle models.Vehicle
 	var seatLayoutJSON, amenitiesJSON []byte
 
@@ -38,6 +38,13 @@ func GetVehicleByID(db *sql.DB, id int) (*models.Vehicle, error) {
 	return &vehicle, nil
 }
 
+// LockVehicle locks a vehicle until the end of the transaction, so that concurrent assignments
+// of the vehicle are checked for overlaps one after the other
+func LockVehicle(db DBInterface, id int) error {
+	query := `SELECT id FROM vehicles WHERE id = $1 FOR UPDATE`
+	return db.QueryRow(query, id).Scan(&id)
+}
+
 func GetAllVehicles(db *sql.DB) ([]models.Vehicle, error) {
 	query := `SELECT id, company_id, license_plate, vehicle_type, brand, model, year, total_seats, seat_layout, amenities, is_active, created_at, updated_at FROM vehicles ORDER BY license_plate`
 
@@ -65,6 +72,42 @@ func GetAllVehicles(db *sql.DB) ([]models.Vehicle, error) {
 	return vehicles, nil
 }
 
+func GetVehiclesByCompanyID(db DBInterface, companyID int) ([]models.Vehicle, error) {
+	query := `SELECT id, company_id, license_plate, vehicle_type, brand, model, year, total_seats, seat_layout, amenities, is_active, created_at, updated_at FROM vehicles WHERE company_id = $1 ORDER BY license_plate`
+
+	rows, err := db.Query(query, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vehicles []models.Vehicle
+	for rows.Next() {
+		var vehicle models.Vehicle
+		var seatLayoutJSON, amenitiesJSON []byte
+		err := rows.Scan(
+			&vehicle.ID, &vehicle.CompanyID, &vehicle.LicensePlate, &vehicle.VehicleType, &vehicle.Brand, &vehicle.Model, &vehicle.Year, &vehicle.TotalSeats, &seatLayoutJSON, &amenitiesJSON, &vehicle.IsActive, &vehicle.CreatedAt, &vehicle.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		json.Unmarshal(seatLayoutJSON, &vehicle.SeatLayout)
+		json.Unmarshal(amenitiesJSON, &vehicle.Amenities)
+		vehicles = append(vehicles, vehicle)
+	}
+
+	return vehicles, nil
+}
+
+// GetFirstActiveVehicleID returns the oldest active vehicle of a company
+func GetFirstActiveVehicleID(db DBInterface, companyID int) (int, error) {
+	var id int
+	query := `SELECT id FROM vehicles WHERE company_id = $1 AND is_active = true ORDER BY id LIMIT 1`
+
+	err := db.QueryRow(query, companyID).Scan(&id)
+	return id, err
+}
+
 func UpdateVehicle(db *sql.DB, vehicle *models.Vehicle) error {
 	seatLayoutJSON, _ := json.Marshal(vehicle.SeatLayout)
 	amenitiesJSON, _ := json.Marshal(vehicle.Amenities)
@@ -82,4 +125,4 @@ func DeleteVehicle(db *sql.DB, id int) error {
 	query := `DELETE FROM vehicles WHERE id = $1`
 	_, err := db.Exec(query, id)
 	return err
-}
\ No newline at end of file
+}
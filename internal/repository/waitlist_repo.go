@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+const waitlistColumns = `id, trip_id, user_id, seat_type, origin_stop_sequence, destination_stop_sequence, passenger_name, passenger_document, passenger_phone, status, offered_seat_id, offered_at, offer_expires_at, booking_id, created_at, updated_at`
+
+func scanWaitlistEntry(row rowScanner) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := row.Scan(
+		&entry.ID, &entry.TripID, &entry.UserID, &entry.SeatType, &entry.OriginStopSequence, &entry.DestinationStopSequence, &entry.PassengerName, &entry.PassengerDocument, &entry.PassengerPhone,
+		&entry.Status, &entry.OfferedSeatID, &entry.OfferedAt, &entry.OfferExpiresAt, &entry.BookingID, &entry.CreatedAt, &entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func scanWaitlistEntries(rows *sql.Rows) ([]models.WaitlistEntry, error) {
+	defer rows.Close()
+
+	entries := []models.WaitlistEntry{}
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, nil
+}
+
+func CreateWaitlistEntry(db DBInterface, entry *models.WaitlistEntry) error {
+	query := `
+		INSERT INTO waitlist_entries (trip_id, user_id, seat_type, origin_stop_sequence, destination_stop_sequence, passenger_name, passenger_document, passenger_phone, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, entry.TripID, entry.UserID, entry.SeatType, entry.OriginStopSequence, entry.DestinationStopSequence, entry.PassengerName, entry.PassengerDocument, entry.PassengerPhone, entry.Status).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+}
+
+func GetWaitlistEntryByID(db DBInterface, id int) (*models.WaitlistEntry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist_entries WHERE id = $1`
+	return scanWaitlistEntry(db.QueryRow(query, id))
+}
+
+// GetWaitlistEntryByTokenHash returns the entry whose offer carries the claim token with the given hash
+func GetWaitlistEntryByTokenHash(db DBInterface, tokenHash string) (*models.WaitlistEntry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist_entries WHERE claim_token_hash = $1`
+	return scanWaitlistEntry(db.QueryRow(query, tokenHash))
+}
+
+// GetWaitlistEntriesByUserID returns the entries of a passenger, newest first
+func GetWaitlistEntriesByUserID(db DBInterface, userID int) ([]models.WaitlistEntry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist_entries WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWaitlistEntries(rows)
+}
+
+// GetTripWaitlist returns every entry of a trip in the order passengers joined
+func GetTripWaitlist(db DBInterface, tripID int) ([]models.WaitlistEntry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist_entries WHERE trip_id = $1 ORDER BY created_at, id`
+
+	rows, err := db.Query(query, tripID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWaitlistEntries(rows)
+}
+
+// LockWaitingEntries returns the entries of a trip still waiting for a seat, in order, locking them
+// so that concurrent runs do not offer the same seat twice
+func LockWaitingEntries(db DBInterface, tripID int) ([]models.WaitlistEntry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist_entries WHERE trip_id = $1 AND status = 'waiting' ORDER BY created_at, id FOR UPDATE`
+
+	rows, err := db.Query(query, tripID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWaitlistEntries(rows)
+}
+
+// CountWaitingAhead returns how many entries of the trip joined before the given one and are still waiting
+func CountWaitingAhead(db DBInterface, entry *models.WaitlistEntry) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM waitlist_entries
+		WHERE trip_id = $1 AND status = 'waiting' AND (created_at, id) < ($2, $3)`
+
+	var count int
+	err := db.QueryRow(query, entry.TripID, entry.CreatedAt, entry.ID).Scan(&count)
+	return count, err
+}
+
+// GetWaitlistedTripIDs returns the trips with passengers waiting for a seat
+func GetWaitlistedTripIDs(db DBInterface) ([]int, error) {
+	rows, err := db.Query(`SELECT DISTINCT trip_id FROM waitlist_entries WHERE status = 'waiting' ORDER BY trip_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tripIDs []int
+	for rows.Next() {
+		var tripID int
+		if err := rows.Scan(&tripID); err != nil {
+			return nil, err
+		}
+		tripIDs = append(tripIDs, tripID)
+	}
+
+	return tripIDs, nil
+}
+
+// OfferWaitlistSeat holds a seat for a waiting entry until the offer expires
+func OfferWaitlistSeat(db DBInterface, entry *models.WaitlistEntry, tokenHash string) error {
+	query := `
+		UPDATE waitlist_entries
+		SET status = 'offered', offered_seat_id = $2, claim_token_hash = $3, offered_at = $4, offer_expires_at = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	return db.QueryRow(query, entry.ID, entry.OfferedSeatID, tokenHash, entry.OfferedAt, entry.OfferExpiresAt).Scan(&entry.UpdatedAt)
+}
+
+// MoveWaitlistOffer moves the seat held for an offer to another seat
+func MoveWaitlistOffer(db DBInterface, entryID, seatID int) error {
+	_, err := db.Exec(`UPDATE waitlist_entries SET offered_seat_id = $2, updated_at = NOW() WHERE id = $1`, entryID, seatID)
+	return err
+}
+
+// WithdrawWaitlistOffer puts an offered entry back to waiting, releasing its seat and invalidating its claim token
+func WithdrawWaitlistOffer(db DBInterface, entryID int) error {
+	query := `
+		UPDATE waitlist_entries
+		SET status = 'waiting', offered_seat_id = NULL, claim_token_hash = NULL, offered_at = NULL, offer_expires_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'offered'`
+
+	_, err := db.Exec(query, entryID)
+	return err
+}
+
+// ClaimWaitlistOffer marks an offer claimed, releasing its hold. It returns sql.ErrNoRows
+// when the entry has no offer running at the given time.
+func ClaimWaitlistOffer(db DBInterface, entryID int, at time.Time) error {
+	query := `
+		UPDATE waitlist_entries
+		SET status = 'claimed', updated_at = NOW()
+		WHERE id = $1 AND status = 'offered' AND offer_expires_at > $2
+		RETURNING id`
+
+	return db.QueryRow(query, entryID, at).Scan(&entryID)
+}
+
+// SetWaitlistBooking links a claimed entry to the booking made for it
+func SetWaitlistBooking(db DBInterface, entryID, bookingID int) error {
+	_, err := db.Exec(`UPDATE waitlist_entries SET booking_id = $2, updated_at = NOW() WHERE id = $1`, entryID, bookingID)
+	return err
+}
+
+// CancelWaitlistEntry takes a waiting or offered entry off the waitlist, releasing any seat held for it.
+// It returns sql.ErrNoRows when the entry is no longer active.
+func CancelWaitlistEntry(db DBInterface, entry *models.WaitlistEntry) error {
+	query := `
+		UPDATE waitlist_entries
+		SET status = 'cancelled', updated_at = NOW()
+		WHERE id = $1 AND status IN ('waiting', 'offered')
+		RETURNING status, updated_at`
+
+	return db.QueryRow(query, entry.ID).Scan(&entry.Status, &entry.UpdatedAt)
+}
+
+// ExpireWaitlistEntries expires the offers not claimed in time and the entries of trips that were
+// cancelled or have departed. It returns the number of entries expired.
+func ExpireWaitlistEntries(db DBInterface, at time.Time) (int64, error) {
+	query := `
+		UPDATE waitlist_entries w
+		SET status = 'expired', updated_at = NOW()
+		FROM trips t
+		WHERE w.trip_id = t.id
+		AND w.status IN ('waiting', 'offered')
+		AND ((w.status = 'offered' AND w.offer_expires_at <= $1) OR t.status = 'cancelled' OR t.departure_datetime <= $1)`
+
+	result, err := db.Exec(query, at)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+const yieldRuleColumns = `id, route_id, name, min_load_percent, max_load_percent, min_days_before, max_days_before, price_percent, priority, is_active, created_at, updated_at`
+
+func scanYieldRule(row rowScanner, rule *models.YieldRule) error {
+	return row.Scan(
+		&rule.ID, &rule.RouteID, &rule.Name, &rule.MinLoadPercent, &rule.MaxLoadPercent, &rule.MinDaysBefore, &rule.MaxDaysBefore, &rule.PricePercent, &rule.Priority, &rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+}
+
+func CreateYieldRule(db DBInterface, rule *models.YieldRule) error {
+	query := `
+		INSERT INTO yield_rules (route_id, name, min_load_percent, max_load_percent, min_days_before, max_days_before, price_percent, priority, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, rule.RouteID, rule.Name, rule.MinLoadPercent, rule.MaxLoadPercent, rule.MinDaysBefore, rule.MaxDaysBefore, rule.PricePercent, rule.Priority, rule.IsActive).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+func GetYieldRuleByID(db DBInterface, id int) (*models.YieldRule, error) {
+	var rule models.YieldRule
+	query := `SELECT ` + yieldRuleColumns + ` FROM yield_rules WHERE id = $1`
+
+	if err := scanYieldRule(db.QueryRow(query, id), &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetYieldRulesByRouteID lists the yield rules of a route in evaluation order, only the active ones when asked
+func GetYieldRulesByRouteID(db DBInterface, routeID int, activeOnly bool) ([]models.YieldRule, error) {
+	query := `SELECT ` + yieldRuleColumns + ` FROM yield_rules WHERE route_id = $1 AND (is_active OR NOT $2) ORDER BY priority, id`
+
+	rows, err := db.Query(query, routeID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.YieldRule{}
+	for rows.Next() {
+		var rule models.YieldRule
+		if err := scanYieldRule(rows, &rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func UpdateYieldRule(db DBInterface, rule *models.YieldRule) error {
+	query := `
+		UPDATE yield_rules
+		SET name = $2, min_load_percent = $3, max_load_percent = $4, min_days_before = $5, max_days_before = $6, price_percent = $7, priority = $8, is_active = $9, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	return db.QueryRow(query, rule.ID, rule.Name, rule.MinLoadPercent, rule.MaxLoadPercent, rule.MinDaysBefore, rule.MaxDaysBefore, rule.PricePercent, rule.Priority, rule.IsActive).Scan(&rule.UpdatedAt)
+}
+
+func DeleteYieldRule(db DBInterface, id int) error {
+	_, err := db.Exec(`DELETE FROM yield_rules WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+)
+
+var (
+	ErrInvalidAncillary       = errors.New("invalid ancillary")
+	ErrAncillaryNotFound      = errors.New("ancillary not found")
+	ErrAncillarySoldOut       = errors.New("not enough units of the ancillary are left on this departure")
+	ErrAncillariesUnavailable = errors.New("ancillaries can only be added to confirmed, paid bookings")
+)
+
+// AncillaryRequest asks for a number of units of an ancillary on a booking
+type AncillaryRequest struct {
+	AncillaryID int `json:"ancillary_id"`
+	Quantity    int `json:"quantity"`
+}
+
+// AncillaryPurchase is the result of adding ancillaries to an existing booking
+type AncillaryPurchase struct {
+	Booking     models.Booking            `json:"booking"`
+	Ancillaries []models.BookingAncillary `json:"ancillaries"`
+	Payment     models.Payment            `json:"payment"`
+}
+
+// ValidateAncillary checks the category, price and capacity of an ancillary
+func ValidateAncillary(ancillary *models.Ancillary) error {
+	switch ancillary.Category {
+	case models.AncillaryCategoryExtraBag, models.AncillaryCategoryOversizeLuggage, models.AncillaryCategoryBicycle,
+		models.AncillaryCategoryPet, models.AncillaryCategoryInsurance:
+	default:
+		return fmt.Errorf("%w: category must be extra_bag, oversize_luggage, bicycle, pet or insurance", ErrInvalidAncillary)
+	}
+
+	switch {
+	case strings.TrimSpace(ancillary.Name) == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidAncillary)
+	case ancillary.Price < 0:
+		return fmt.Errorf("%w: price cannot be negative", ErrInvalidAncillary)
+	case ancillary.FarePercent < 0 || ancillary.FarePercent > 100:
+		return fmt.Errorf("%w: fare_percent must be between 0 and 100", ErrInvalidAncillary)
+	case ancillary.CapacityPerTrip != nil && *ancillary.CapacityPerTrip < 0:
+		return fmt.Errorf("%w: capacity_per_trip cannot be negative", ErrInvalidAncillary)
+	}
+	return nil
+}
+
+// AncillaryRemaining returns the units of an ancillary left once some are sold, or nil when it has no capacity limit
+func AncillaryRemaining(capacity *int, sold int) *int {
+	if capacity == nil {
+		return nil
+	}
+	remaining := *capacity - sold
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &remaining
+}
+
+// AncillarySummary describes the ancillaries of a booking in one line, such as "2 x Extra bag, 1 x Bicycle"
+func AncillarySummary(items []models.BookingAncillary) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		parts = append(parts, fmt.Sprintf("%d x %s", item.Quantity, item.Name))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// AncillaryOffers lists the active ancillaries sold on a departure of a schedule, priced for the segment
+// and with the units left on it. Without stop sequences the segment is the whole route.
+func AncillaryOffers(db repository.DBInterface, scheduleID int, travelDate time.Time, originStopSequence, destinationStopSequence *int) ([]models.AncillaryOffer, error) {
+	schedule, err := repository.GetScheduleByID(db, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	route, err := GetRouteWithStops(db, schedule.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	origin, destination, err := ResolveSegment(route.Stops, originStopSequence, destinationStopSequence)
+	if err != nil {
+		return nil, err
+	}
+	seatFare := SegmentFare(route.BasePrice, route.Stops, route.Fares, origin, destination)
+
+	ancillaries, err := repository.GetAncillariesByCompanyID(db, route.CompanyID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	offers := make([]models.AncillaryOffer, 0, len(ancillaries))
+	for _, ancillary := range ancillaries {
+		offer := models.AncillaryOffer{Ancillary: ancillary, UnitPrice: AncillaryPrice(ancillary, seatFare)}
+		if ancillary.CapacityPerTrip != nil {
+			sold, err := repository.CountSoldAncillaries(db, ancillary.ID, scheduleID, travelDate, origin.StopSequence, destination.StopSequence, 0)
+			if err != nil {
+				return nil, err
+			}
+			offer.Remaining = AncillaryRemaining(ancillary.CapacityPerTrip, sold)
+		}
+		offers = append(offers, offer)
+	}
+	return offers, nil
+}
+
+// sellAncillaries prices the requested ancillaries of a company on a segment of a departure, once the
+// capacity left is checked. Included units, given by ancillary id, come free with the fare but take
+// capacity all the same. Units already held by the given booking are not counted against it.
+func sellAncillaries(db repository.DBInterface, companyID, scheduleID int, travelDate time.Time, origin, destination models.RouteStop, seatFare float64, requests []AncillaryRequest, included map[int]int, bookingID int) ([]models.BookingAncillary, error) {
+	purchased := make(map[int]int)
+	for _, request := range requests {
+		if request.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidAncillary)
+		}
+		purchased[request.AncillaryID] += request.Quantity
+	}
+	quantities := make(map[int]int)
+	for id, quantity := range purchased {
+		quantities[id] += quantity
+	}
+	for id, quantity := range included {
+		quantities[id] += quantity
+	}
+
+	items := []models.BookingAncillary{}
+	for _, id := range lockOrder(quantities) {
+		ancillary, err := repository.LockAncillary(db, id)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && (ancillary.CompanyID != companyID || !ancillary.IsActive)) {
+			return nil, ErrAncillaryNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkAncillaryCapacity(db, ancillary, quantities[id], scheduleID, travelDate, origin, destination, bookingID); err != nil {
+			return nil, err
+		}
+
+		item := models.BookingAncillary{AncillaryID: ancillary.ID, Category: ancillary.Category, Name: ancillary.Name}
+		if quantity := included[id]; quantity > 0 {
+			item.Quantity = quantity
+			items = append(items, item)
+		}
+		if quantity := purchased[id]; quantity > 0 {
+			item.Quantity = quantity
+			item.UnitPrice = AncillaryPrice(*ancillary, seatFare)
+			item.TotalPrice = roundPrice(item.UnitPrice * float64(quantity))
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// legAncillaries sells the ancillaries requested on a booking leg along with those its fare family
+// includes with each seat
+func legAncillaries(db repository.DBInterface, leg preparedLeg) ([]models.BookingAncillary, error) {
+	included := make(map[int]int)
+	if leg.fareFamily != nil {
+		for _, item := range leg.fareFamily.IncludedAncillaries {
+			included[item.AncillaryID] += item.Quantity * len(leg.seatIDs)
+		}
+	}
+	return sellAncillaries(db, leg.companyID, leg.booking.ScheduleID, leg.booking.TravelDate, leg.originStop, leg.destinationStop, leg.seatFare, leg.ancillaryRequests, included, 0)
+}
+
+// keepAncillaries checks that the ancillaries of a booking still fit on the segment of the departure it moves to.
+// They keep the price they were sold for, even when no longer on sale.
+func keepAncillaries(db repository.DBInterface, items []models.BookingAncillary, scheduleID int, travelDate time.Time, origin, destination models.RouteStop, bookingID int) error {
+	quantities := make(map[int]int)
+	for _, item := range items {
+		quantities[item.AncillaryID] += item.Quantity
+	}
+
+	for _, id := range lockOrder(quantities) {
+		ancillary, err := repository.LockAncillary(db, id)
+		if err != nil {
+			return err
+		}
+		if err := checkAncillaryCapacity(db, ancillary, quantities[id], scheduleID, travelDate, origin, destination, bookingID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockOrder returns the ids of ancillaries in the order they are locked, so that concurrent sales cannot deadlock
+func lockOrder(quantities map[int]int) []int {
+	ids := make([]int, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// checkAncillaryCapacity checks that units of an ancillary are left on the segment of a departure,
+// not counting those held by the given booking
+func checkAncillaryCapacity(db repository.DBInterface, ancillary *models.Ancillary, quantity, scheduleID int, travelDate time.Time, origin, destination models.RouteStop, bookingID int) error {
+	if ancillary.CapacityPerTrip == nil {
+		return nil
+	}
+	sold, err := repository.CountSoldAncillaries(db, ancillary.ID, scheduleID, travelDate, origin.StopSequence, destination.StopSequence, bookingID)
+	if err != nil {
+		return err
+	}
+	if quantity > *AncillaryRemaining(ancillary.CapacityPerTrip, sold) {
+		return ErrAncillarySoldOut
+	}
+	return nil
+}
+
+// ancillaryAmount adds up the price of ancillaries
+func ancillaryAmount(items []models.BookingAncillary) float64 {
+	total := 0.0
+	for _, item := range items {
+		total += item.TotalPrice
+	}
+	return roundPrice(total)
+}
+
+// AddBookingAncillaries adds ancillaries to a confirmed, paid booking of a passenger before departure
+// and charges them with the booking's payment method
+func AddBookingAncillaries(db *sql.DB, bookingID, userID int, requests []AncillaryRequest) (*AncillaryPurchase, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("%w: at least one ancillary is required", ErrInvalidAncillary)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking, err := repository.GetBookingByID(tx, bookingID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && booking.UserID != userID) {
+		return nil, ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if booking.BookingStatus == "cancelled" {
+		return nil, ErrBookingCancelled
+	}
+	if booking.BookingStatus != "confirmed" || booking.PaymentStatus != "paid" {
+		return nil, ErrAncillariesUnavailable
+	}
+	if !booking.DepartureDatetime.After(time.Now()) {
+		return nil, ErrBookingDeparted
+	}
+
+	schedule, err := repository.GetScheduleByID(tx, booking.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	route, err := GetRouteWithStops(tx, schedule.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	origin, destination, err := ResolveSegment(route.Stops, booking.OriginStopSequence, booking.DestinationStopSequence)
+	if err != nil {
+		return nil, err
+	}
+	seatFare := SegmentFare(route.BasePrice, route.Stops, route.Fares, origin, destination)
+
+	items, err := sellAncillaries(tx, route.CompanyID, booking.ScheduleID, booking.TravelDate, origin, destination, seatFare, requests, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].BookingID = booking.ID
+		if err := repository.CreateBookingAncillary(tx, &items[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	amount := ancillaryAmount(items)
+	if err := repository.AddBookingAncillaryAmount(tx, booking, amount); err != nil {
+		return nil, err
+	}
+	if booking.JourneyID != nil {
+		if err := repository.AddJourneyAmount(tx, *booking.JourneyID, amount); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	payment := models.Payment{
+		BookingID:      booking.ID,
+		JourneyID:      booking.JourneyID,
+		Amount:         amount,
+		PaymentMethod:  booking.PaymentMethod,
+		PaymentStatus:  "paid",
+		TransactionID:  "AN" + booking.BookingCode + "-" + strconv.Itoa(items[0].ID),
+		PaymentGateway: "simulated",
+		PaidAt:         &now,
+	}
+	if err := repository.CreatePayment(tx, &payment); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &AncillaryPurchase{Booking: *booking, Ancillaries: items, Payment: payment}, nil
+}
@@ -0,0 +1,421 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+const (
+	// AssignmentHorizonDays is how far ahead schedule assignments are checked for conflicts
+	AssignmentHorizonDays = 90
+
+	ConflictOverlap         = "overlap"
+	ConflictCompanyMismatch = "company_mismatch"
+	ConflictMaintenance     = "maintenance"
+)
+
+var (
+	ErrVehicleConflict        = errors.New("the vehicle is already assigned to an overlapping departure")
+	ErrVehicleCompanyMismatch = errors.New("the vehicle must belong to the company operating the route")
+)
+
+// Assignment is a departure a vehicle is assigned to, materialised as a trip or projected from its schedule
+type Assignment struct {
+	VehicleID         int       `json:"vehicle_id"`
+	ScheduleID        int       `json:"schedule_id"`
+	RouteID           int       `json:"route_id"`
+	TripID            *int      `json:"trip_id"`
+	TravelDate        string    `json:"travel_date"`
+	DepartureDatetime time.Time `json:"departure_datetime"`
+	ArrivalDatetime   time.Time `json:"arrival_datetime"` // including any delay
+	Status            string    `json:"status"`
+}
+
+// AssignmentConflict is an assignment that overlaps another one of the same vehicle or one of its
+// maintenance windows, or whose vehicle belongs to another company than its route
+type AssignmentConflict struct {
+	Type              string                    `json:"type"`
+	VehicleID         int                       `json:"vehicle_id"`
+	Assignment        Assignment                `json:"assignment"`
+	ConflictsWith     *Assignment               `json:"conflicts_with,omitempty"`
+	MaintenanceWindow *models.MaintenanceWindow `json:"maintenance_window,omitempty"`
+}
+
+// ScheduleDepartures projects the departures of a schedule that overlap a period
+func ScheduleDepartures(schedule *models.Schedule, stops []models.RouteStop, exceptions []models.CalendarException, from, to time.Time) ([]Assignment, error) {
+	byDate := make(map[string]*models.CalendarException)
+	for i := range exceptions {
+		byDate[exceptions[i].ExceptionDate.Format(utils.DateLayout)] = &exceptions[i]
+	}
+
+	var assignments []Assignment
+	// Start early enough to catch overnight departures still running at the start of the period
+	date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -2)
+	for ; date.Before(to); date = date.AddDate(0, 0, 1) {
+		key := date.Format(utils.DateLayout)
+		if !RunsOn(schedule, byDate[key], date) {
+			continue
+		}
+
+		departure, arrival, err := scheduleDatetimes(schedule, stops, date)
+		if err != nil {
+			return nil, err
+		}
+		if !departure.Before(to) || !arrival.After(from) {
+			continue
+		}
+
+		assignments = append(assignments, Assignment{
+			VehicleID:         schedule.VehicleID,
+			ScheduleID:        schedule.ID,
+			RouteID:           schedule.RouteID,
+			TravelDate:        key,
+			DepartureDatetime: departure,
+			ArrivalDatetime:   arrival,
+			Status:            models.TripStatusScheduled,
+		})
+	}
+
+	return assignments, nil
+}
+
+// FindOverlaps returns the pairs of assignments whose times overlap
+func FindOverlaps(assignments []Assignment) []AssignmentConflict {
+	sorted := append([]Assignment(nil), assignments...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].DepartureDatetime.Before(sorted[j].DepartureDatetime)
+	})
+
+	conflicts := []AssignmentConflict{}
+	for i := range sorted {
+		for j := i + 1; j < len(sorted) && sorted[j].DepartureDatetime.Before(sorted[i].ArrivalDatetime); j++ {
+			other := sorted[i]
+			conflicts = append(conflicts, AssignmentConflict{
+				Type:          ConflictOverlap,
+				VehicleID:     sorted[j].VehicleID,
+				Assignment:    sorted[j],
+				ConflictsWith: &other,
+			})
+		}
+	}
+	return conflicts
+}
+
+// assignmentLoader caches the stops and calendar exceptions used to project schedules
+type assignmentLoader struct {
+	db         repository.DBInterface
+	stops      map[int][]models.RouteStop
+	exceptions map[int][]models.CalendarException
+}
+
+func newAssignmentLoader(db repository.DBInterface) *assignmentLoader {
+	return &assignmentLoader{db: db, stops: make(map[int][]models.RouteStop), exceptions: make(map[int][]models.CalendarException)}
+}
+
+func (l *assignmentLoader) departures(schedule *models.Schedule, from, to time.Time) ([]Assignment, error) {
+	stops, ok := l.stops[schedule.RouteID]
+	if !ok {
+		var err error
+		if stops, err = repository.GetRouteStopsByRouteID(l.db, schedule.RouteID); err != nil {
+			return nil, err
+		}
+		l.stops[schedule.RouteID] = stops
+	}
+
+	var exceptions []models.CalendarException
+	if schedule.CalendarID != nil {
+		if exceptions, ok = l.exceptions[*schedule.CalendarID]; !ok {
+			var err error
+			if exceptions, err = repository.GetCalendarExceptions(l.db, *schedule.CalendarID); err != nil {
+				return nil, err
+			}
+			l.exceptions[*schedule.CalendarID] = exceptions
+		}
+	}
+
+	return ScheduleDepartures(schedule, stops, exceptions, from, to)
+}
+
+// VehicleTimeline lists the departures a vehicle is assigned to during a period: its trips, and the
+// departures of its schedules that have no trip yet. Cancelled and reassigned trips are left out.
+func VehicleTimeline(db repository.DBInterface, vehicleID int, from, to time.Time) ([]Assignment, error) {
+	trips, err := repository.GetVehicleTripsBetween(db, vehicleID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make(map[int]int)
+	materialised := make(map[string]bool)
+	timeline := []Assignment{}
+	for i := range trips {
+		trip := trips[i]
+		materialised[fmt.Sprintf("%d|%s", trip.ScheduleID, trip.TravelDate.Format(utils.DateLayout))] = true
+		if trip.VehicleID != vehicleID || trip.Status == models.TripStatusCancelled {
+			continue
+		}
+
+		routeID, ok := routes[trip.ScheduleID]
+		if !ok {
+			schedule, err := repository.GetScheduleByID(db, trip.ScheduleID)
+			if err != nil {
+				return nil, err
+			}
+			routeID = schedule.RouteID
+			routes[trip.ScheduleID] = routeID
+		}
+		timeline = append(timeline, Assignment{
+			VehicleID:         vehicleID,
+			ScheduleID:        trip.ScheduleID,
+			RouteID:           routeID,
+			TripID:            &trip.ID,
+			TravelDate:        trip.TravelDate.Format(utils.DateLayout),
+			DepartureDatetime: trip.DepartureDatetime,
+			ArrivalDatetime:   trip.ArrivalDatetime.Add(time.Duration(trip.DelayMinutes) * time.Minute),
+			Status:            trip.Status,
+		})
+	}
+
+	schedules, err := repository.GetActiveSchedulesByVehicleID(db, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	loader := newAssignmentLoader(db)
+	for i := range schedules {
+		departures, err := loader.departures(&schedules[i], from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, departure := range departures {
+			if !materialised[fmt.Sprintf("%d|%s", departure.ScheduleID, departure.TravelDate)] {
+				timeline = append(timeline, departure)
+			}
+		}
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].DepartureDatetime.Before(timeline[j].DepartureDatetime)
+	})
+	return timeline, nil
+}
+
+// checkVehicleCompany fails when the vehicle does not belong to the company operating the route
+func checkVehicleCompany(db repository.DBInterface, vehicleID, routeID int) error {
+	vehicle, err := repository.GetVehicleByID(db, vehicleID)
+	if err != nil {
+		return err
+	}
+	route, err := repository.GetRouteByID(db, routeID)
+	if err != nil {
+		return err
+	}
+	if vehicle.CompanyID != route.CompanyID {
+		return ErrVehicleCompanyMismatch
+	}
+	return nil
+}
+
+// conflictError describes the first conflict of a candidate assignment
+func conflictError(conflicts []AssignmentConflict, isCandidate func(Assignment) bool) error {
+	for _, conflict := range conflicts {
+		candidate, other := conflict.Assignment, conflict.ConflictsWith
+		if isCandidate(*other) {
+			candidate, other = *other, &conflict.Assignment
+		} else if !isCandidate(candidate) {
+			continue
+		}
+		if isCandidate(*other) {
+			continue
+		}
+		return fmt.Errorf("%w: the departure of %s overlaps schedule %d on %s", ErrVehicleConflict,
+			candidate.TravelDate, other.ScheduleID, other.TravelDate)
+	}
+	return nil
+}
+
+// CheckScheduleAssignment checks that a schedule's vehicle belongs to the route's company and is free for
+// its departures over the next AssignmentHorizonDays
+func CheckScheduleAssignment(db repository.DBInterface, schedule *models.Schedule) error {
+	if err := checkVehicleCompany(db, schedule.VehicleID, schedule.RouteID); err != nil {
+		return err
+	}
+	if !schedule.IsActive {
+		return nil
+	}
+
+	from := time.Now()
+	if schedule.ValidFrom.After(from) {
+		from = schedule.ValidFrom
+	}
+	to := from.AddDate(0, 0, AssignmentHorizonDays)
+	if schedule.ValidUntil != nil && schedule.ValidUntil.AddDate(0, 0, 2).Before(to) {
+		to = schedule.ValidUntil.AddDate(0, 0, 2)
+	}
+	if !from.Before(to) {
+		return nil
+	}
+
+	candidate, err := newAssignmentLoader(db).departures(schedule, from, to)
+	if err != nil {
+		return err
+	}
+	timeline, err := VehicleTimeline(db, schedule.VehicleID, from, to)
+	if err != nil {
+		return err
+	}
+
+	assignments := candidate
+	for _, assignment := range timeline {
+		// An updated schedule replaces its own departures
+		if assignment.ScheduleID != schedule.ID || schedule.ID == 0 {
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	return conflictError(FindOverlaps(assignments), func(assignment Assignment) bool {
+		return assignment.ScheduleID == schedule.ID && assignment.TripID == nil
+	})
+}
+
+// CheckTripAssignment checks that a vehicle can run a trip: it belongs to the route's company,
+// is not in maintenance and has no other departure overlapping the trip
+func CheckTripAssignment(db repository.DBInterface, trip *models.Trip, vehicleID int) error {
+	schedule, err := repository.GetScheduleByID(db, trip.ScheduleID)
+	if err != nil {
+		return err
+	}
+	if err := checkVehicleCompany(db, vehicleID, schedule.RouteID); err != nil {
+		return err
+	}
+
+	candidate := Assignment{
+		VehicleID:         vehicleID,
+		ScheduleID:        trip.ScheduleID,
+		TravelDate:        trip.TravelDate.Format(utils.DateLayout),
+		DepartureDatetime: trip.DepartureDatetime,
+		ArrivalDatetime:   trip.ArrivalDatetime.Add(time.Duration(trip.DelayMinutes) * time.Minute),
+	}
+	if err := checkMaintenance(db, vehicleID, candidate.DepartureDatetime, candidate.ArrivalDatetime); err != nil {
+		return err
+	}
+	timeline, err := VehicleTimeline(db, vehicleID, candidate.DepartureDatetime, candidate.ArrivalDatetime)
+	if err != nil {
+		return err
+	}
+
+	isCandidate := func(assignment Assignment) bool {
+		return assignment.ScheduleID == candidate.ScheduleID && assignment.TravelDate == candidate.TravelDate
+	}
+	assignments := []Assignment{candidate}
+	for _, assignment := range timeline {
+		if !isCandidate(assignment) {
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	return conflictError(FindOverlaps(assignments), isCandidate)
+}
+
+// OpenTrip returns the trip of a schedule on a date, creating it after checking that the vehicle is free for it.
+// A vehicleID of 0 runs the trip with the schedule's vehicle; with another vehicle, seats already booked are
+// moved to it and ErrSeatsUnmatched is returned when some have no equivalent.
+func OpenTrip(db *sql.DB, scheduleID int, travelDate time.Time, vehicleID int) (*models.Trip, error) {
+	trip, err := repository.GetTripByScheduleAndDate(db, scheduleID, travelDate)
+	if err == nil {
+		return trip, LocalizeTrip(db, trip)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	schedule, err := repository.GetScheduleByID(db, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	stops, err := repository.GetRouteStopsByRouteID(db, schedule.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	departure, arrival, err := scheduleDatetimes(schedule, stops, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	if vehicleID == 0 {
+		vehicleID = schedule.VehicleID
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The vehicle stays locked until the trip is stored, so that no other departure takes it meanwhile
+	if err := repository.LockVehicle(tx, vehicleID); err != nil {
+		return nil, err
+	}
+	candidate := &models.Trip{ScheduleID: scheduleID, TravelDate: travelDate, DepartureDatetime: departure, ArrivalDatetime: arrival}
+	if err := CheckTripAssignment(tx, candidate, vehicleID); err != nil {
+		return nil, err
+	}
+
+	trip, err = MaterializeTrip(tx, scheduleID, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := swapTripVehicle(tx, trip, vehicleID, false); err != nil {
+		return nil, err
+	}
+
+	return trip, tx.Commit()
+}
+
+// AssignmentConflicts reports the overlapping departures of a company's vehicles during a period,
+// the departures falling inside a maintenance window and the schedules assigned to a vehicle of another company
+func AssignmentConflicts(db repository.DBInterface, companyID int, from, to time.Time) ([]AssignmentConflict, error) {
+	vehicles, err := repository.GetVehiclesByCompanyID(db, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []AssignmentConflict{}
+	for _, vehicle := range vehicles {
+		timeline, err := VehicleTimeline(db, vehicle.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, FindOverlaps(timeline)...)
+
+		windows, err := repository.GetMaintenanceWindows(db, vehicle.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, maintenanceConflicts(timeline, windows)...)
+	}
+
+	schedules, err := repository.GetCrossCompanySchedules(db, companyID)
+	if err != nil {
+		return nil, err
+	}
+	loader := newAssignmentLoader(db)
+	for i := range schedules {
+		schedule := &schedules[i]
+		assignment := Assignment{VehicleID: schedule.VehicleID, ScheduleID: schedule.ID, RouteID: schedule.RouteID, Status: models.TripStatusScheduled}
+		departures, err := loader.departures(schedule, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if len(departures) > 0 {
+			assignment = departures[0]
+		}
+		conflicts = append(conflicts, AssignmentConflict{Type: ConflictCompanyMismatch, VehicleID: schedule.VehicleID, Assignment: assignment})
+	}
+
+	return conflicts, nil
+}
@@ -18,6 +18,10 @@ func RegisterUser(db *sql.DB, user *models.User) error {
 	}
 	user.Password = string(hashedPassword)
 
+	// Self-registered accounts are always passengers; operators are created by an admin
+	user.Role = models.RolePassenger
+	user.CompanyID = nil
+
 	return repository.CreateUser(db, user)
 }
 
@@ -38,4 +42,4 @@ func AuthenticateUser(db *sql.DB, email, password, jwtSecret string) (string, er
 	}
 
 	return token, nil
-}
\ No newline at end of file
+}
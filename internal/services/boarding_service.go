@@ -0,0 +1,154 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+// Reasons given for scans that did not board a passenger
+const (
+	BoardingReasonInvalidTicket    = "invalid_ticket"
+	BoardingReasonNotYetValid      = "ticket_not_yet_valid"
+	BoardingReasonExpired          = "ticket_expired"
+	BoardingReasonTripCancelled    = "trip_cancelled"
+	BoardingReasonWrongTrip        = "wrong_trip"
+	BoardingReasonTicketNotFound   = "ticket_not_found"
+	BoardingReasonBookingCancelled = "booking_cancelled"
+	BoardingReasonAlreadyBoarded   = "already_boarded"
+)
+
+// TicketScan is a ticket read by a scanner. ScanID is chosen by the scanner and makes uploads safe to retry.
+type TicketScan struct {
+	ScanID    string
+	Payload   string
+	ScannedAt time.Time
+}
+
+// Scanner identifies who scanned: an operator account or an on-board device
+type Scanner struct {
+	UserID   *int
+	DeviceID *int
+}
+
+// BoardingOutcome decides what a verified ticket scanned for a trip does. Seat is the booked seat the ticket
+// was issued for, nil when it no longer exists.
+func BoardingOutcome(trip *models.Trip, claims *TicketClaims, seat *models.BoardingSeat) (string, string) {
+	travelDate := trip.TravelDate.Format(utils.DateLayout)
+	switch {
+	case trip.Status == models.TripStatusCancelled:
+		return models.BoardingResultRejected, BoardingReasonTripCancelled
+	case claims.ScheduleID != trip.ScheduleID || claims.TravelDate != travelDate:
+		return models.BoardingResultRejected, BoardingReasonWrongTrip
+	case seat == nil || seat.BookingCode != claims.BookingCode:
+		return models.BoardingResultRejected, BoardingReasonTicketNotFound
+	case seat.ScheduleID != trip.ScheduleID || seat.TravelDate.Format(utils.DateLayout) != travelDate:
+		return models.BoardingResultRejected, BoardingReasonWrongTrip
+	case seat.BookingStatus == "cancelled":
+		return models.BoardingResultRejected, BoardingReasonBookingCancelled
+	case seat.BoardedAt != nil:
+		return models.BoardingResultDuplicate, BoardingReasonAlreadyBoarded
+	}
+	return models.BoardingResultBoarded, ""
+}
+
+// ScanTickets validates tickets scanned for a trip and boards their seats. Scans are processed in the
+// order they were made, so a passenger boards at their first scan even when devices upload late;
+// other scans of the seat are duplicates. Scans already uploaded with the same ScanID are returned
+// as stored. Results are in the order of the scans given.
+func ScanTickets(db *sql.DB, publicKey ed25519.PublicKey, tripID int, scans []TicketScan, scanner Scanner) ([]models.BoardingScan, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	trip, err := repository.GetTripByID(tx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]int, len(scans))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scans[order[a]].ScannedAt.Before(scans[order[b]].ScannedAt) })
+
+	results := make([]models.BoardingScan, len(scans))
+	for _, i := range order {
+		result, err := scanTicket(tx, publicKey, trip, scans[i], scanner)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = *result
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func scanTicket(db repository.DBInterface, publicKey ed25519.PublicKey, trip *models.Trip, ticket TicketScan, scanner Scanner) (*models.BoardingScan, error) {
+	if ticket.ScanID != "" {
+		existing, err := repository.GetBoardingScanByScanID(db, trip.ID, ticket.ScanID)
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+
+	scan := &models.BoardingScan{
+		TripID:    trip.ID,
+		DeviceID:  scanner.DeviceID,
+		ScannedBy: scanner.UserID,
+		ScanID:    ticket.ScanID,
+		ScannedAt: ticket.ScannedAt,
+		Result:    models.BoardingResultRejected,
+	}
+
+	claims, err := VerifyTicket(publicKey, ticket.Payload, ticket.ScannedAt)
+	if claims != nil {
+		scan.BookingCode = claims.BookingCode
+		scan.SeatNumber = claims.SeatNumber
+		scan.PassengerName = claims.PassengerName
+	}
+	switch {
+	case errors.Is(err, ErrInvalidTicket):
+		scan.Reason = BoardingReasonInvalidTicket
+	case errors.Is(err, ErrTicketNotYetValid):
+		scan.Reason = BoardingReasonNotYetValid
+	case errors.Is(err, ErrTicketExpired):
+		scan.Reason = BoardingReasonExpired
+	case err != nil:
+		return nil, err
+	default:
+		seat, err := repository.LockBoardingSeat(db, claims.BookingSeatID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		scan.Result, scan.Reason = BoardingOutcome(trip, claims, seat)
+		if seat != nil && scan.Reason != BoardingReasonTicketNotFound {
+			scan.BookingSeatID = &seat.BookingSeatID
+			scan.SeatNumber = seat.SeatNumber
+		}
+		if scan.Result != models.BoardingResultRejected {
+			if err := repository.MarkSeatBoarded(db, seat.BookingSeatID, ticket.ScannedAt); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := repository.CreateBoardingScan(db, scan); err != nil {
+		return nil, err
+	}
+	return scan, nil
+}
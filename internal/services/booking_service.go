@@ -0,0 +1,566 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+var (
+	ErrNoLegs                = errors.New("at least one leg is required")
+	ErrInvalidTravelDate     = errors.New("invalid travel date format, use YYYY-MM-DD")
+	ErrDepartureCancelled    = errors.New("this departure has been cancelled")
+	ErrSeatsUnavailable      = errors.New("one or more selected seats are not available")
+	ErrInvalidConnection     = errors.New("legs do not connect at a shared city or terminal")
+	ErrInvalidConnectionTime = errors.New("connection time is outside the allowed window")
+	ErrRoundTripLegs         = errors.New("a round trip has exactly one outbound leg")
+	ErrInvalidReturn         = errors.New("the return leg must go back from the destination to the origin after arrival")
+	ErrBookingNotFound       = errors.New("booking not found")
+	ErrBookingCancelled      = errors.New("booking is already cancelled")
+	ErrBookingDeparted       = errors.New("booking has already departed")
+	ErrBookingClosed         = errors.New("booking has already travelled or been closed as a no-show")
+)
+
+// BookingLeg is one departure of a booking request, with the seats taken on it
+type BookingLeg struct {
+	ScheduleID              int    `json:"schedule_id"`
+	TravelDate              string `json:"travel_date"`
+	OriginStopSequence      *int   `json:"origin_stop_sequence"`
+	DestinationStopSequence *int   `json:"destination_stop_sequence"`
+	SeatIDs                 []int  `json:"seat_ids"`
+	// Fare family the seats are sold under; the route's plain fare when omitted
+	FareFamilyID *int `json:"fare_family_id"`
+	// Ancillaries bought for the leg, such as luggage or insurance
+	Ancillaries []AncillaryRequest `json:"ancillaries"`
+
+	// Set by exchanges keeping the departure and segment: seats are priced at the booking's locked yield
+	keepYield    bool
+	yieldPercent *float64
+}
+
+// NewBooking holds the passenger and payment details shared by every leg of a booking request
+type NewBooking struct {
+	UserID int
+	Legs   []BookingLeg
+	// Return makes the booking a round trip; it requires a single outbound leg
+	Return            *BookingLeg
+	PassengerName     string
+	PassengerDocument string
+	PassengerPhone    string
+	PaymentMethod     string
+	Notes             string
+}
+
+// ConnectionWindow bounds the time between arriving with one leg and leaving with the next
+type ConnectionWindow struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// preparedLeg is a validated and priced leg, ready to be stored
+type preparedLeg struct {
+	booking           models.Booking
+	seatIDs           []int
+	seatFare          float64
+	fareFamily        *models.FareFamily
+	ancillaryRequests []AncillaryRequest
+	ancillaries       []models.BookingAncillary
+	companyID         int
+	originStop        models.RouteStop
+	destinationStop   models.RouteStop
+	arrival           time.Time
+}
+
+// BookingCancellation is the result of a passenger cancelling a booking
+type BookingCancellation struct {
+	CancelledBookings []models.Booking `json:"cancelled_bookings"`
+	RefundAmount      float64          `json:"refund_amount"`
+	// Part of the fares kept under the bookings' fare family rules
+	CancellationFee float64 `json:"cancellation_fee"`
+}
+
+// CreateBookings books every leg of a request in one transaction. A single leg gives a plain booking;
+// several legs must connect within the window and are grouped into a journey. With a return leg the
+// outbound and return bookings are grouped into a round trip, discounted when one company operates both.
+// Ancillaries, with those included in each leg's fare family, are added to its total after any discount.
+func CreateBookings(db *sql.DB, request NewBooking, window ConnectionWindow) ([]models.Booking, *models.Journey, error) {
+	if len(request.Legs) == 0 {
+		return nil, nil, ErrNoLegs
+	}
+	if request.Return != nil && len(request.Legs) != 1 {
+		return nil, nil, ErrRoundTripLegs
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	legs := make([]preparedLeg, 0, len(request.Legs))
+	for _, leg := range request.Legs {
+		prepared, err := prepareLeg(tx, request, leg)
+		if err != nil {
+			return nil, nil, err
+		}
+		legs = append(legs, *prepared)
+	}
+
+	for i := 1; i < len(legs); i++ {
+		if err := checkConnection(legs[i-1], legs[i], window); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	journeyType := models.JourneyTypeConnection
+	if request.Return != nil {
+		inbound, err := prepareLeg(tx, request, *request.Return)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkReturn(legs[0], *inbound); err != nil {
+			return nil, nil, err
+		}
+		legs = append(legs, *inbound)
+		journeyType = models.JourneyTypeRoundTrip
+
+		if err := applyRoundTripDiscount(tx, legs); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for i := range legs {
+		if legs[i].ancillaries, err = legAncillaries(tx, legs[i]); err != nil {
+			return nil, nil, err
+		}
+		legs[i].booking.AncillaryAmount = ancillaryAmount(legs[i].ancillaries)
+		legs[i].booking.TotalAmount = roundPrice(legs[i].booking.TotalAmount + legs[i].booking.AncillaryAmount)
+	}
+
+	var journey *models.Journey
+	if len(legs) > 1 {
+		journeyCode, err := utils.GenerateCode("JR")
+		if err != nil {
+			return nil, nil, err
+		}
+		journey = &models.Journey{
+			UserID:      request.UserID,
+			JourneyCode: journeyCode,
+			JourneyType: journeyType,
+		}
+		for _, leg := range legs {
+			journey.TotalAmount += leg.booking.TotalAmount
+		}
+		journey.TotalAmount = roundPrice(journey.TotalAmount)
+		if err := repository.CreateJourney(tx, journey); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	bookings := make([]models.Booking, 0, len(legs))
+	for i := range legs {
+		booking := legs[i].booking
+		if booking.BookingCode, err = utils.GenerateCode("BK"); err != nil {
+			return nil, nil, err
+		}
+		if journey != nil {
+			sequence := i + 1
+			booking.JourneyID = &journey.ID
+			booking.LegSequence = &sequence
+		}
+
+		if err := repository.CreateBooking(tx, &booking); err != nil {
+			return nil, nil, err
+		}
+
+		// Seat inventory is derived from booking_seats per date and segment, so the seat itself stays available
+		for _, seatID := range legs[i].seatIDs {
+			bookingSeat := models.BookingSeat{BookingID: booking.ID, SeatID: seatID}
+			if err := repository.CreateBookingSeat(tx, &bookingSeat); err != nil {
+				return nil, nil, err
+			}
+		}
+		for _, item := range legs[i].ancillaries {
+			item.BookingID = booking.ID
+			if err := repository.CreateBookingAncillary(tx, &item); err != nil {
+				return nil, nil, err
+			}
+			booking.Ancillaries = append(booking.Ancillaries, item)
+		}
+		bookings = append(bookings, booking)
+	}
+
+	// A journey is paid once, for all of its legs
+	if journey != nil {
+		now := time.Now()
+		payment := models.Payment{
+			BookingID:      bookings[0].ID,
+			JourneyID:      &journey.ID,
+			Amount:         journey.TotalAmount,
+			PaymentMethod:  request.PaymentMethod,
+			PaymentStatus:  "paid",
+			TransactionID:  "PY" + journey.JourneyCode,
+			PaymentGateway: "simulated",
+			PaidAt:         &now,
+		}
+		if err := repository.CreatePayment(tx, &payment); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	if journey != nil {
+		journey.Bookings = bookings
+	}
+	return bookings, journey, nil
+}
+
+// prepareLeg checks that a leg can be booked and prices it
+func prepareLeg(db repository.DBInterface, request NewBooking, leg BookingLeg) (*preparedLeg, error) {
+	travelDate, err := time.Parse("2006-01-02", leg.TravelDate)
+	if err != nil {
+		return nil, ErrInvalidTravelDate
+	}
+	if len(leg.SeatIDs) == 0 {
+		return nil, ErrSeatsUnavailable
+	}
+
+	// Reject bookings on departures that have been cancelled
+	if trip, err := repository.GetTripByScheduleAndDate(db, leg.ScheduleID, travelDate); err == nil && trip.Status == models.TripStatusCancelled {
+		return nil, ErrDepartureCancelled
+	}
+
+	schedule, err := repository.GetScheduleByID(db, leg.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := ScheduleRunsOn(db, schedule, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	if !schedule.IsActive || !runs {
+		return nil, ErrScheduleNotRunning
+	}
+
+	route, err := GetRouteWithStops(db, schedule.RouteID)
+	if err != nil {
+		return nil, err
+	}
+
+	originStop, destinationStop, err := ResolveSegment(route.Stops, leg.OriginStopSequence, leg.DestinationStopSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.LockDeparture(db, leg.ScheduleID, leg.TravelDate); err != nil {
+		return nil, err
+	}
+	availableSeats, err := repository.GetAvailableSeatsForSegment(db, leg.ScheduleID, leg.TravelDate, originStop.StopSequence, destinationStop.StopSequence)
+	if err != nil {
+		return nil, err
+	}
+	availableSeatMap := make(map[int]bool)
+	for _, seat := range availableSeats {
+		availableSeatMap[seat.ID] = true
+	}
+	for _, seatID := range leg.SeatIDs {
+		if !availableSeatMap[seatID] {
+			return nil, ErrSeatsUnavailable
+		}
+		// The same seat cannot be taken twice in one request
+		availableSeatMap[seatID] = false
+	}
+
+	departure, _, err := scheduleDatetimes(schedule, route.Stops, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	// Passengers boarding at an intermediate stop leave later than the route origin
+	boarding, err := stopDatetime(departure, originStop)
+	if err != nil {
+		return nil, err
+	}
+	alighting, err := stopDatetime(departure, destinationStop)
+	if err != nil {
+		return nil, err
+	}
+
+	var fareFamily *models.FareFamily
+	if leg.FareFamilyID != nil {
+		fareFamily, err = LoadFareFamily(db, *leg.FareFamilyID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && (fareFamily.RouteID != route.ID || !fareFamily.IsActive)) {
+			return nil, ErrFareFamilyNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Yield rules price the segment by load factor and booking horizon; the percentage is locked into the booking
+	segmentFare := SegmentFare(route.BasePrice, route.Stops, route.Fares, originStop, destinationStop)
+	yieldPercent := leg.yieldPercent
+	if !leg.keepYield {
+		rules, err := repository.GetYieldRulesByRouteID(db, route.ID, true)
+		if err != nil {
+			return nil, err
+		}
+		quote, err := DepartureYield(db, route, rules, leg.ScheduleID, leg.TravelDate, originStop, destinationStop, boarding, segmentFare, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if quote != nil {
+			yieldPercent = &quote.PricePercent
+		}
+	}
+	if yieldPercent != nil {
+		segmentFare = YieldFare(segmentFare, *yieldPercent)
+	}
+
+	// In a real app, you'd calculate this based on seat types and modifiers
+	seatFare := FamilyFare(segmentFare, fareFamily)
+	totalAmount := seatFare * float64(len(leg.SeatIDs))
+
+	// The booking keeps the rules it was sold under, whatever later becomes of the fare family
+	var fareRules *models.FareRules
+	if fareFamily != nil {
+		rules := fareFamily.FareRules
+		fareRules = &rules
+	}
+
+	return &preparedLeg{
+		booking: models.Booking{
+			UserID:                  request.UserID,
+			ScheduleID:              leg.ScheduleID,
+			FareFamilyID:            leg.FareFamilyID,
+			YieldPercent:            yieldPercent,
+			FareRules:               fareRules,
+			TravelDate:              travelDate,
+			OriginStopSequence:      &originStop.StopSequence,
+			DestinationStopSequence: &destinationStop.StopSequence,
+			DepartureDatetime:       boarding,
+			PassengerName:           request.PassengerName,
+			PassengerDocument:       request.PassengerDocument,
+			PassengerPhone:          request.PassengerPhone,
+			TotalAmount:             roundPrice(totalAmount),
+			PaymentStatus:           "paid", // Simulate payment success
+			BookingStatus:           "confirmed",
+			PaymentMethod:           request.PaymentMethod,
+			Notes:                   request.Notes,
+		},
+		seatIDs:           leg.SeatIDs,
+		seatFare:          seatFare,
+		fareFamily:        fareFamily,
+		ancillaryRequests: leg.Ancillaries,
+		companyID:         route.CompanyID,
+		originStop:        originStop,
+		destinationStop:   destinationStop,
+		arrival:           alighting,
+	}, nil
+}
+
+// checkConnection verifies that the passenger can change from one leg to the next
+func checkConnection(arriving, departing preparedLeg, window ConnectionWindow) error {
+	if !legsConnect(
+		models.ItineraryLeg{DestinationCity: arriving.destinationStop.City, DestinationTerminal: arriving.destinationStop.Terminal},
+		models.ItineraryLeg{OriginCity: departing.originStop.City, OriginTerminal: departing.originStop.Terminal},
+	) {
+		return ErrInvalidConnection
+	}
+
+	connection := departing.booking.DepartureDatetime.Sub(arriving.arrival)
+	if connection < window.Min || connection > window.Max {
+		return ErrInvalidConnectionTime
+	}
+	return nil
+}
+
+// checkReturn verifies that the return leg goes back from the outbound destination to its origin, after arrival
+func checkReturn(outbound, inbound preparedLeg) error {
+	goesBack := legsConnect(
+		models.ItineraryLeg{DestinationCity: outbound.destinationStop.City, DestinationTerminal: outbound.destinationStop.Terminal},
+		models.ItineraryLeg{OriginCity: inbound.originStop.City, OriginTerminal: inbound.originStop.Terminal},
+	)
+	returnsHome := legsConnect(
+		models.ItineraryLeg{DestinationCity: inbound.destinationStop.City, DestinationTerminal: inbound.destinationStop.Terminal},
+		models.ItineraryLeg{OriginCity: outbound.originStop.City, OriginTerminal: outbound.originStop.Terminal},
+	)
+	if !goesBack || !returnsHome || !inbound.booking.DepartureDatetime.After(outbound.arrival) {
+		return ErrInvalidReturn
+	}
+	return nil
+}
+
+// applyRoundTripDiscount discounts both legs of a round trip when a single company operates them
+func applyRoundTripDiscount(db repository.DBInterface, legs []preparedLeg) error {
+	if legs[0].companyID != legs[1].companyID {
+		return nil
+	}
+
+	company, err := repository.GetCompanyByID(db, legs[0].companyID)
+	if err != nil {
+		return err
+	}
+
+	for i := range legs {
+		discount := RoundTripDiscount(legs[i].booking.TotalAmount, company.RoundTripDiscountPercent)
+		legs[i].booking.DiscountAmount = discount
+		legs[i].booking.TotalAmount = roundPrice(legs[i].booking.TotalAmount - discount)
+	}
+	return nil
+}
+
+// CancelBooking cancels a booking of a passenger and refunds it. Connecting journeys are cancelled as a
+// whole. Cancelling the outbound leg of a round trip cancels the return too; cancelling only the return
+// refunds it minus the round-trip discount granted on the outbound leg. The fare rules a booking was sold
+// under keep their cancellation fee, or the whole fare when it is not refundable; ancillaries bought are
+// always refunded.
+func CancelBooking(db *sql.DB, bookingID, userID int) (*BookingCancellation, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking, err := repository.GetBookingByID(tx, bookingID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && booking.UserID != userID) {
+		return nil, ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckCancellable(booking, time.Now()); err != nil {
+		return nil, err
+	}
+	// The group's payments cover its bookings, which are kept under the group's deposit terms
+	member, err := repository.IsGroupMemberBooking(tx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	if member {
+		return nil, ErrGroupMemberBooking
+	}
+
+	// Work out which bookings go and how much each one gets back
+	toCancel := []models.Booking{*booking}
+	refunds := []float64{booking.TotalAmount}
+	if booking.JourneyID != nil {
+		journey, err := repository.GetJourneyByID(tx, *booking.JourneyID)
+		if err != nil {
+			return nil, err
+		}
+		legs, err := repository.GetBookingsByJourneyID(tx, journey.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		isReturn := journey.JourneyType == models.JourneyTypeRoundTrip && booking.LegSequence != nil && *booking.LegSequence > 1
+		if isReturn {
+			// The outbound leg keeps running but no longer qualifies for the round-trip price
+			for _, leg := range legs {
+				if leg.ID != booking.ID {
+					refunds[0] = math.Max(0, roundPrice(booking.TotalAmount-leg.DiscountAmount))
+				}
+			}
+		} else {
+			toCancel, refunds = nil, nil
+			for _, leg := range legs {
+				if leg.BookingStatus != "cancelled" {
+					toCancel = append(toCancel, leg)
+					refunds = append(refunds, leg.TotalAmount)
+				}
+			}
+		}
+	}
+
+	now := time.Now()
+	for i := range toCancel {
+		if err := CheckCancellable(&toCancel[i], now); err != nil {
+			return nil, err
+		}
+	}
+
+	cancellation := &BookingCancellation{CancelledBookings: []models.Booking{}}
+	for i := range toCancel {
+		leg := &toCancel[i]
+		fee := 0.0
+		switch {
+		case leg.DelayCompensation:
+			// Long delays are refunded in full, without fare rules or lost discounts
+			refunds[i] = leg.TotalAmount
+		case leg.PaymentStatus == "paid":
+			fee = math.Min(refunds[i], CancellationFee(roundPrice(leg.TotalAmount-leg.AncillaryAmount), leg.FareRules))
+		}
+
+		refundAmount, err := refundBooking(tx, leg, roundPrice(refunds[i]-fee))
+		if err != nil {
+			return nil, err
+		}
+		if refundAmount > 0 {
+			leg.PaymentStatus = "refunded"
+		}
+		leg.BookingStatus = "cancelled"
+		if err := repository.UpdateBookingStatus(tx, leg.ID, leg.BookingStatus, leg.PaymentStatus); err != nil {
+			return nil, err
+		}
+
+		cancellation.CancelledBookings = append(cancellation.CancelledBookings, *leg)
+		cancellation.RefundAmount += refundAmount
+		cancellation.CancellationFee += fee
+	}
+	cancellation.RefundAmount = roundPrice(cancellation.RefundAmount)
+	cancellation.CancellationFee = roundPrice(cancellation.CancellationFee)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return cancellation, nil
+}
+
+// CheckCancellable reports why a booking can no longer be cancelled, if at all. Passengers of a long-delayed
+// trip can still claim their refund after the scheduled departure, until the no-show run closes the booking.
+func CheckCancellable(booking *models.Booking, now time.Time) error {
+	switch booking.BookingStatus {
+	case "cancelled":
+		return ErrBookingCancelled
+	case "completed", "no_show":
+		return ErrBookingClosed
+	}
+	if !booking.DepartureDatetime.After(now) && !booking.DelayCompensation {
+		return ErrBookingDeparted
+	}
+	return nil
+}
+
+// refundBooking records a refund payment for the given amount and returns it.
+// Bookings that were never paid are not refunded.
+func refundBooking(db repository.DBInterface, booking *models.Booking, amount float64) (float64, error) {
+	if booking.PaymentStatus != "paid" || amount <= 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	refund := models.Payment{
+		BookingID:      booking.ID,
+		JourneyID:      booking.JourneyID,
+		Amount:         -amount,
+		PaymentMethod:  booking.PaymentMethod,
+		PaymentStatus:  "refunded",
+		TransactionID:  "RF" + booking.BookingCode,
+		PaymentGateway: "simulated",
+		PaidAt:         &now,
+	}
+	if err := repository.CreatePayment(db, &refund); err != nil {
+		return 0, err
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+var (
+	ErrInvalidException   = errors.New("exception type must be 'added' or 'removed'")
+	ErrScheduleNotRunning = errors.New("schedule does not run on this date")
+)
+
+// RunsOn reports whether a schedule runs on a date. A calendar exception on that date wins;
+// otherwise the date must be within the validity period and on one of the schedule's days of week.
+func RunsOn(schedule *models.Schedule, exception *models.CalendarException, date time.Time) bool {
+	if exception != nil {
+		return exception.ExceptionType == models.ExceptionTypeAdded
+	}
+
+	day := date.Format(utils.DateLayout)
+	if day < schedule.ValidFrom.Format(utils.DateLayout) {
+		return false
+	}
+	if schedule.ValidUntil != nil && day > schedule.ValidUntil.Format(utils.DateLayout) {
+		return false
+	}
+
+	weekday := int64(date.Weekday())
+	if weekday == 0 {
+		weekday = 7 // ISO Sunday
+	}
+	for _, scheduled := range schedule.DaysOfWeek {
+		if scheduled == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// ScheduleRunsOn reports whether a schedule runs on a date, taking its calendar exceptions into account
+func ScheduleRunsOn(db repository.DBInterface, schedule *models.Schedule, date time.Time) (bool, error) {
+	var exception *models.CalendarException
+	if schedule.CalendarID != nil {
+		found, err := repository.GetCalendarExceptionByDate(db, *schedule.CalendarID, date)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+		exception = found
+	}
+
+	return RunsOn(schedule, exception, date), nil
+}
+
+// GetCalendarWithExceptions returns a calendar with its exception dates
+func GetCalendarWithExceptions(db repository.DBInterface, id int) (*models.ServiceCalendar, error) {
+	calendar, err := repository.GetCalendarByID(db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar.Exceptions, err = repository.GetCalendarExceptions(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if calendar.Exceptions == nil {
+		calendar.Exceptions = []models.CalendarException{}
+	}
+
+	return calendar, nil
+}
+
+// AddCalendarException adds or replaces the exception of a calendar on a date
+func AddCalendarException(db repository.DBInterface, exception *models.CalendarException) error {
+	if exception.ExceptionType != models.ExceptionTypeAdded && exception.ExceptionType != models.ExceptionTypeRemoved {
+		return ErrInvalidException
+	}
+
+	return repository.CreateCalendarException(db, exception)
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+var (
+	ErrCheckInClosed            = errors.New("check-in is not open")
+	ErrAlreadyCheckedIn         = errors.New("booking is already checked in")
+	ErrInvalidPassengerDocument = errors.New("invalid passenger document")
+	ErrDocumentExpired          = errors.New("the passenger document expires before the travel date")
+)
+
+// Document types accepted at check-in
+const (
+	DocumentTypePassport = "passport"
+	DocumentTypeIDCard   = "id_card"
+)
+
+var nationalityPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// CheckInWindow is when online check-in is open, relative to the boarding time of a booking
+type CheckInWindow struct {
+	OpensBefore  time.Duration
+	ClosesBefore time.Duration
+}
+
+// CheckInStatus tells a passenger whether they can check in
+type CheckInStatus struct {
+	BookingID   int        `json:"booking_id"`
+	OpensAt     time.Time  `json:"opens_at"`
+	ClosesAt    time.Time  `json:"closes_at"`
+	IsOpen      bool       `json:"is_open"`
+	CheckedInAt *time.Time `json:"checked_in_at"`
+}
+
+// CheckInDetails is the passenger data collected at check-in. An empty document number keeps the one given at booking.
+type CheckInDetails struct {
+	DocumentType   string
+	DocumentNumber string
+	DocumentExpiry time.Time
+	Nationality    string
+}
+
+// CheckInResult is a checked-in booking with its boarding passes
+type CheckInResult struct {
+	Booking *models.Booking `json:"booking"`
+	Tickets []Ticket        `json:"tickets"`
+}
+
+// ValidateCheckInDetails checks the passenger data given at check-in for a travel date
+func ValidateCheckInDetails(details CheckInDetails, travelDate time.Time) error {
+	if details.DocumentType != DocumentTypePassport && details.DocumentType != DocumentTypeIDCard {
+		return fmt.Errorf("%w: document type must be %s or %s", ErrInvalidPassengerDocument, DocumentTypePassport, DocumentTypeIDCard)
+	}
+	if len(details.DocumentNumber) > 20 {
+		return fmt.Errorf("%w: document number is too long", ErrInvalidPassengerDocument)
+	}
+	if !nationalityPattern.MatchString(details.Nationality) {
+		return fmt.Errorf("%w: nationality must be an ISO 3166-1 alpha-2 code", ErrInvalidPassengerDocument)
+	}
+	if details.DocumentExpiry.Format(utils.DateLayout) < travelDate.Format(utils.DateLayout) {
+		return ErrDocumentExpired
+	}
+	return nil
+}
+
+// BookingCheckInStatus returns when check-in opens and closes for a booking
+func BookingCheckInStatus(db repository.DBInterface, window CheckInWindow, booking *models.Booking, now time.Time) (*CheckInStatus, error) {
+	boarding, err := bookingBoardingTime(db, booking)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &CheckInStatus{
+		BookingID:   booking.ID,
+		OpensAt:     boarding.Add(-window.OpensBefore),
+		ClosesAt:    boarding.Add(-window.ClosesBefore),
+		CheckedInAt: booking.CheckedInAt,
+	}
+	status.IsOpen = booking.CheckedInAt == nil && !now.Before(status.OpensAt) && now.Before(status.ClosesAt)
+	return status, nil
+}
+
+// CheckIn records the passenger data of a confirmed, paid booking within the check-in window
+// and issues its boarding passes
+func CheckIn(db *sql.DB, key *utils.TicketKey, window CheckInWindow, booking *models.Booking, details CheckInDetails, now time.Time) (*CheckInResult, error) {
+	if booking.BookingStatus == "cancelled" {
+		return nil, ErrBookingCancelled
+	}
+	if booking.BookingStatus != "confirmed" || booking.PaymentStatus != "paid" {
+		return nil, ErrTicketsUnavailable
+	}
+	if booking.CheckedInAt != nil {
+		return nil, ErrAlreadyCheckedIn
+	}
+
+	status, err := BookingCheckInStatus(db, window, booking, now)
+	if err != nil {
+		return nil, err
+	}
+	if !status.IsOpen {
+		return nil, fmt.Errorf("%w: check-in is open from %s to %s", ErrCheckInClosed,
+			status.OpensAt.Format(time.RFC3339), status.ClosesAt.Format(time.RFC3339))
+	}
+
+	if details.DocumentNumber == "" {
+		details.DocumentNumber = booking.PassengerDocument
+	}
+	if err := ValidateCheckInDetails(details, booking.TravelDate); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking.PassengerDocument = details.DocumentNumber
+	booking.PassengerDocumentType = details.DocumentType
+	booking.PassengerDocumentExpiry = &details.DocumentExpiry
+	booking.PassengerNationality = details.Nationality
+	if err := repository.CheckInBooking(tx, booking); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAlreadyCheckedIn
+		}
+		return nil, err
+	}
+
+	tickets, err := BookingTickets(tx, key, booking)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &CheckInResult{Booking: booking, Tickets: tickets}, nil
+}
+
+// bookingBoardingTime returns when a booking boards at its origin stop, following the trip when it exists
+func bookingBoardingTime(db repository.DBInterface, booking *models.Booking) (time.Time, error) {
+	schedule, err := repository.GetScheduleByID(db, booking.ScheduleID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	stops, err := repository.GetRouteStopsByRouteID(db, schedule.RouteID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	origin, destination, err := ResolveSegment(stops, booking.OriginStopSequence, booking.DestinationStopSequence)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	trip, err := repository.GetTripByScheduleAndDate(db, booking.ScheduleID, booking.TravelDate)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, err
+	}
+	if trip != nil {
+		if trip.Status == models.TripStatusCancelled {
+			return time.Time{}, ErrTripCancelled
+		}
+		return stopDatetime(trip.DepartureDatetime, origin)
+	}
+
+	boarding, _, err := SegmentDatetimes(schedule.DepartureTime, stops, origin, destination, booking.TravelDate)
+	return boarding, err
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+// DrivingWindow is the rolling period over which a driver's driving time is limited
+const DrivingWindow = 24 * time.Hour
+
+var (
+	ErrInvalidCrewMember   = errors.New("crew members need a first and last name and a role of driver or attendant")
+	ErrLicenceRequired     = errors.New("drivers need a licence number and expiry date")
+	ErrInvalidDuty         = errors.New("duty must be driver or attendant, and only drivers may drive")
+	ErrCrewInactive        = errors.New("the crew member is not active")
+	ErrCrewCompanyMismatch = errors.New("the crew member must belong to the company operating the trip")
+	ErrCrewAssigned        = errors.New("the crew member is already assigned to this trip")
+	ErrLicenceExpired      = errors.New("the driver's licence expires before the trip")
+	ErrDutyConflict        = errors.New("the crew member already has an overlapping duty")
+	ErrDrivingTimeExceeded = errors.New("the duty exceeds the maximum driving time")
+)
+
+// ValidateCrewMember checks a crew member's role and that drivers hold a licence
+func ValidateCrewMember(member *models.CrewMember) error {
+	if member.FirstName == "" || member.LastName == "" {
+		return ErrInvalidCrewMember
+	}
+	switch member.Role {
+	case models.CrewRoleDriver:
+		if member.LicenceNumber == "" || member.LicenceExpiry == nil {
+			return ErrLicenceRequired
+		}
+	case models.CrewRoleAttendant:
+	default:
+		return ErrInvalidCrewMember
+	}
+	return nil
+}
+
+// dutyEnd returns when a duty ends, including the trip's delay
+func dutyEnd(duty models.CrewDuty) time.Time {
+	return duty.ArrivalDatetime.Add(time.Duration(duty.DelayMinutes) * time.Minute)
+}
+
+// overlapMinutes returns how long a duty runs within a period
+func overlapMinutes(duty models.CrewDuty, from, to time.Time) float64 {
+	start, end := duty.DepartureDatetime, dutyEnd(duty)
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Minutes()
+}
+
+// MaxDrivingMinutes returns the longest driving time within a DrivingWindow that includes the candidate duty
+func MaxDrivingMinutes(duties []models.CrewDuty, candidate models.CrewDuty) int {
+	driving := []models.CrewDuty{candidate}
+	for _, duty := range duties {
+		if duty.Duty == models.CrewRoleDriver && duty.TripID != candidate.TripID {
+			driving = append(driving, duty)
+		}
+	}
+
+	// The busiest window starts when a duty starts or ends when a duty ends
+	var windows []time.Time
+	for _, duty := range driving {
+		windows = append(windows, duty.DepartureDatetime, dutyEnd(duty).Add(-DrivingWindow))
+	}
+
+	longest := 0.0
+	for _, from := range windows {
+		to := from.Add(DrivingWindow)
+		if overlapMinutes(candidate, from, to) == 0 {
+			continue
+		}
+		total := 0.0
+		for _, duty := range driving {
+			total += overlapMinutes(duty, from, to)
+		}
+		if total > longest {
+			longest = total
+		}
+	}
+	return int(longest)
+}
+
+// CheckDuty checks that a crew member can take a duty next to the duties they already have:
+// duties may not overlap, and drivers may not drive more than maxDrivingMinutes within a DrivingWindow
+func CheckDuty(duties []models.CrewDuty, candidate models.CrewDuty, maxDrivingMinutes int) error {
+	for _, duty := range duties {
+		if duty.TripID == candidate.TripID {
+			return ErrCrewAssigned
+		}
+		if duty.DepartureDatetime.Before(dutyEnd(candidate)) && candidate.DepartureDatetime.Before(dutyEnd(duty)) {
+			return fmt.Errorf("%w: trip %d from %s to %s", ErrDutyConflict, duty.TripID, duty.OriginCity, duty.DestinationCity)
+		}
+	}
+
+	if candidate.Duty == models.CrewRoleDriver {
+		if driving := MaxDrivingMinutes(duties, candidate); driving > maxDrivingMinutes {
+			return fmt.Errorf("%w: %d minutes within %d hours, at most %d allowed", ErrDrivingTimeExceeded,
+				driving, int(DrivingWindow.Hours()), maxDrivingMinutes)
+		}
+	}
+	return nil
+}
+
+// AssignCrew assigns a crew member to a trip after checking their licence, their other duties and their driving time
+func AssignCrew(db *sql.DB, tripID, crewMemberID int, duty string, maxDrivingMinutes int) (*models.TripCrew, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	trip, err := repository.GetTripByID(tx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.Status == models.TripStatusCancelled {
+		return nil, ErrTripCancelled
+	}
+	companyID, err := repository.GetTripCompanyID(tx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := repository.LockCrewMember(tx, crewMemberID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case !member.IsActive:
+		return nil, ErrCrewInactive
+	case member.CompanyID != companyID:
+		return nil, ErrCrewCompanyMismatch
+	case duty != models.CrewRoleDriver && duty != models.CrewRoleAttendant,
+		duty == models.CrewRoleDriver && member.Role != models.CrewRoleDriver:
+		return nil, ErrInvalidDuty
+	}
+
+	candidate := models.CrewDuty{
+		TripID:            trip.ID,
+		Duty:              duty,
+		DepartureDatetime: trip.DepartureDatetime,
+		ArrivalDatetime:   trip.ArrivalDatetime,
+		DelayMinutes:      trip.DelayMinutes,
+	}
+	if duty == models.CrewRoleDriver &&
+		(member.LicenceExpiry == nil || member.LicenceExpiry.Format(utils.DateLayout) < dutyEnd(candidate).UTC().Format(utils.DateLayout)) {
+		return nil, ErrLicenceExpired
+	}
+
+	duties, err := repository.GetCrewDuties(tx, member.ID, candidate.DepartureDatetime.Add(-DrivingWindow), dutyEnd(candidate).Add(DrivingWindow))
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckDuty(duties, candidate, maxDrivingMinutes); err != nil {
+		return nil, err
+	}
+
+	assignment := &models.TripCrew{TripID: trip.ID, CrewMemberID: member.ID, Duty: duty}
+	if err := repository.CreateTripCrew(tx, assignment); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	assignment.CrewMember = member
+	return assignment, nil
+}
+
+// CrewDuties lists the duties of a crew member during a period, with times in the zones of the route's stops
+func CrewDuties(db repository.DBInterface, crewMemberID int, from, to time.Time) ([]models.CrewDuty, error) {
+	duties, err := repository.GetCrewDuties(db, crewMemberID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stopsByRoute := make(map[int][]models.RouteStop)
+	for i := range duties {
+		duty := &duties[i]
+		stops, ok := stopsByRoute[duty.RouteID]
+		if !ok {
+			if stops, err = repository.GetRouteStopsByRouteID(db, duty.RouteID); err != nil {
+				return nil, err
+			}
+			stopsByRoute[duty.RouteID] = stops
+		}
+		if len(stops) < 2 {
+			continue
+		}
+
+		departureLoc, err := utils.LoadLocation(stops[0].TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		arrivalLoc, err := utils.LoadLocation(stops[len(stops)-1].TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		duty.DepartureDatetime = duty.DepartureDatetime.In(departureLoc)
+		duty.ArrivalDatetime = duty.ArrivalDatetime.In(arrivalLoc)
+	}
+
+	return duties, nil
+}
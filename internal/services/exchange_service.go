@@ -0,0 +1,303 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+)
+
+var (
+	ErrExchangeUnavailable = errors.New("only confirmed and paid bookings can be exchanged")
+	ErrExchangeJourney     = errors.New("bookings of a journey cannot be exchanged")
+	ErrExchangeRoute       = errors.New("the new departure must go between the same cities")
+	ErrExchangeSeats       = errors.New("an exchange must keep the number of seats")
+)
+
+// BookingExchangeResult is a quoted or completed exchange. The booking and the payment
+// are only set once the exchange is made.
+type BookingExchangeResult struct {
+	Exchange models.BookingExchange `json:"exchange"`
+	Booking  *models.Booking        `json:"booking,omitempty"`
+	Payment  *models.Payment        `json:"payment,omitempty"`
+}
+
+// ExchangeAmountDue returns what the passenger pays for an exchange, or gets back when negative
+func ExchangeAmountDue(previousFare, newFare, changeFee float64) float64 {
+	return roundPrice(newFare + changeFee - previousFare)
+}
+
+// ExchangeTerms prices an exchange: the fare paid for the booking without ancillaries, the fare of the new
+// departure, the change fee and what the passenger pays, or gets back when negative
+type ExchangeTerms struct {
+	PreviousFare float64
+	NewFare      float64
+	ChangeFee    float64
+	AmountDue    float64
+}
+
+// CheckExchangeable reports why a booking cannot be exchanged for the given number of seats, if at all.
+// Bookings of a long-delayed trip can still be exchanged once it has departed.
+func CheckExchangeable(booking *models.Booking, previousSeats, seats int, now time.Time) error {
+	if booking.BookingStatus == "cancelled" {
+		return ErrBookingCancelled
+	}
+	if booking.BookingStatus != "confirmed" || booking.PaymentStatus != "paid" {
+		return ErrExchangeUnavailable
+	}
+	// Journey fares and connections depend on every leg
+	if booking.JourneyID != nil {
+		return ErrExchangeJourney
+	}
+	if !booking.DepartureDatetime.After(now) && !booking.DelayCompensation {
+		return ErrBookingDeparted
+	}
+	if seats != previousSeats {
+		return ErrExchangeSeats
+	}
+	return nil
+}
+
+// QuoteExchange prices the exchange of a booking for a new fare. Changing only the seats of the same
+// departure is free; otherwise the booking's fare rules must allow changes and their change fee, or the
+// company's, is charged. Passengers of a long-delayed trip rebook free of charge, at no more than they paid.
+func QuoteExchange(booking *models.Booking, newFare float64, sameDeparture bool, companyChangeFee float64, rules *models.FareRules) (ExchangeTerms, error) {
+	terms := ExchangeTerms{PreviousFare: roundPrice(booking.TotalAmount - booking.AncillaryAmount), NewFare: newFare}
+	if booking.DelayCompensation {
+		terms.NewFare = math.Min(newFare, terms.PreviousFare)
+	} else if !sameDeparture {
+		if rules != nil && !rules.AllowChanges {
+			return ExchangeTerms{}, ErrFareChangesNotAllowed
+		}
+		terms.ChangeFee = FareChangeFee(companyChangeFee, rules)
+	}
+	terms.AmountDue = ExchangeAmountDue(terms.PreviousFare, terms.NewFare, terms.ChangeFee)
+	return terms, nil
+}
+
+// ExchangeBooking moves a booking of a passenger to another departure, segment or seats. Fields left
+// empty in the leg keep the booking's own. The change fee of the operating company is waived when only
+// the seats change. Without commit the exchange is only quoted; otherwise the old seats are released,
+// the new ones reserved, the fare difference charged or refunded and the original booking kept for audit.
+// Ancillaries move with the booking at the price paid, provided they fit on the new departure. The fare
+// family is kept on its own route unless another is chosen. The fare rules the booking was sold under
+// decide whether the departure may change and for which fee, and stay with it unless another family is chosen.
+func ExchangeBooking(db *sql.DB, bookingID, userID int, leg BookingLeg, commit bool) (*BookingExchangeResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking, err := repository.GetBookingByID(tx, bookingID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && booking.UserID != userID) {
+		return nil, ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	previousSeats, err := repository.GetBookedSeatsByBookingID(tx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sameSchedule := leg.ScheduleID == 0 || leg.ScheduleID == booking.ScheduleID
+	if leg.ScheduleID == 0 {
+		leg.ScheduleID = booking.ScheduleID
+	}
+	if leg.TravelDate == "" {
+		leg.TravelDate = booking.TravelDate.Format("2006-01-02")
+	}
+	if sameSchedule && leg.OriginStopSequence == nil && leg.DestinationStopSequence == nil {
+		leg.OriginStopSequence = booking.OriginStopSequence
+		leg.DestinationStopSequence = booking.DestinationStopSequence
+	}
+	// Seats changed within the same departure and segment keep the price locked at booking time
+	if leg.ScheduleID == booking.ScheduleID && leg.TravelDate == booking.TravelDate.Format("2006-01-02") &&
+		sameStop(leg.OriginStopSequence, booking.OriginStopSequence) && sameStop(leg.DestinationStopSequence, booking.DestinationStopSequence) {
+		leg.keepYield = true
+		leg.yieldPercent = booking.YieldPercent
+	}
+	if len(leg.SeatIDs) == 0 {
+		for _, seat := range previousSeats {
+			leg.SeatIDs = append(leg.SeatIDs, seat.SeatID)
+		}
+	}
+	if err := CheckExchangeable(booking, len(previousSeats), len(leg.SeatIDs), time.Now()); err != nil {
+		return nil, err
+	}
+
+	previousOrigin, previousDestination, err := bookingStops(tx, booking)
+	if err != nil {
+		return nil, err
+	}
+
+	// The booking's own seats are released first so that they can be kept
+	if err := repository.DeleteBookingSeats(tx, booking.ID); err != nil {
+		return nil, err
+	}
+
+	fareFamily, err := bookingFareFamily(tx, booking)
+	if err != nil {
+		return nil, err
+	}
+	// Passengers keep the fare rules they bought unless they pick another fare family
+	keepFareRules := leg.FareFamilyID == nil || sameFareFamily(booking.FareFamilyID, leg.FareFamilyID)
+	if leg.FareFamilyID == nil && fareFamily != nil && fareFamily.IsActive {
+		schedule, err := repository.GetScheduleByID(tx, leg.ScheduleID)
+		if err != nil {
+			return nil, err
+		}
+		if schedule.RouteID == fareFamily.RouteID {
+			leg.FareFamilyID = &fareFamily.ID
+		}
+	}
+
+	prepared, err := prepareLeg(tx, NewBooking{
+		UserID:            booking.UserID,
+		PassengerName:     booking.PassengerName,
+		PassengerDocument: booking.PassengerDocument,
+		PassengerPhone:    booking.PassengerPhone,
+		PaymentMethod:     booking.PaymentMethod,
+		Notes:             booking.Notes,
+	}, leg)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(prepared.originStop.City, previousOrigin.City) || !strings.EqualFold(prepared.destinationStop.City, previousDestination.City) {
+		return nil, ErrExchangeRoute
+	}
+
+	ancillaries, err := repository.GetBookingAncillaries(tx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := keepAncillaries(tx, ancillaries, prepared.booking.ScheduleID, prepared.booking.TravelDate, prepared.originStop, prepared.destinationStop, booking.ID); err != nil {
+		return nil, err
+	}
+
+	company, err := repository.GetCompanyByID(tx, prepared.companyID)
+	if err != nil {
+		return nil, err
+	}
+	sameDeparture := prepared.booking.ScheduleID == booking.ScheduleID &&
+		prepared.booking.TravelDate.Equal(booking.TravelDate) &&
+		prepared.booking.DepartureDatetime.Equal(booking.DepartureDatetime)
+	terms, err := QuoteExchange(booking, prepared.booking.TotalAmount, sameDeparture, company.ChangeFee, booking.FareRules)
+	if err != nil {
+		return nil, err
+	}
+	prepared.booking.TotalAmount = terms.NewFare
+
+	result := &BookingExchangeResult{Exchange: models.BookingExchange{
+		BookingID:       booking.ID,
+		PreviousBooking: models.PreviousBooking{Booking: *booking, Seats: previousSeats},
+		ScheduleID:      prepared.booking.ScheduleID,
+		TravelDate:      prepared.booking.TravelDate,
+		PreviousFare:    terms.PreviousFare,
+		NewFare:         terms.NewFare,
+		ChangeFee:       terms.ChangeFee,
+		AmountDue:       terms.AmountDue,
+	}}
+	if !commit {
+		return result, nil
+	}
+
+	exchanged := *booking
+	exchanged.ScheduleID = prepared.booking.ScheduleID
+	exchanged.TravelDate = prepared.booking.TravelDate
+	exchanged.OriginStopSequence = prepared.booking.OriginStopSequence
+	exchanged.DestinationStopSequence = prepared.booking.DestinationStopSequence
+	exchanged.DepartureDatetime = prepared.booking.DepartureDatetime
+	exchanged.FareFamilyID = prepared.booking.FareFamilyID
+	exchanged.YieldPercent = prepared.booking.YieldPercent
+	if !keepFareRules {
+		exchanged.FareRules = prepared.booking.FareRules
+	}
+	// Rebooking on another departure uses up a delay compensation; changing seats does not
+	if !sameDeparture {
+		exchanged.DelayCompensation = false
+	}
+	exchanged.TotalAmount = roundPrice(prepared.booking.TotalAmount + booking.AncillaryAmount)
+	if err := repository.ExchangeBooking(tx, &exchanged); err != nil {
+		return nil, err
+	}
+	for _, seatID := range prepared.seatIDs {
+		bookingSeat := models.BookingSeat{BookingID: exchanged.ID, SeatID: seatID}
+		if err := repository.CreateBookingSeat(tx, &bookingSeat); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Exchange.AmountDue != 0 {
+		previousExchanges, err := repository.GetBookingExchanges(tx, booking.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		payment := models.Payment{
+			BookingID:      exchanged.ID,
+			Amount:         result.Exchange.AmountDue,
+			PaymentMethod:  exchanged.PaymentMethod,
+			PaymentStatus:  "paid",
+			TransactionID:  "EX" + exchanged.BookingCode + "-" + strconv.Itoa(len(previousExchanges)+1),
+			PaymentGateway: "simulated",
+			PaidAt:         &now,
+		}
+		if payment.Amount < 0 {
+			payment.PaymentStatus = "refunded"
+		}
+		if err := repository.CreatePayment(tx, &payment); err != nil {
+			return nil, err
+		}
+		result.Exchange.PaymentID = &payment.ID
+		result.Payment = &payment
+	}
+
+	if err := repository.CreateBookingExchange(tx, &result.Exchange); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	result.Booking = &exchanged
+	return result, nil
+}
+
+// bookingStops returns the boarding and alighting stops of a booking
+func bookingStops(db repository.DBInterface, booking *models.Booking) (models.RouteStop, models.RouteStop, error) {
+	schedule, err := repository.GetScheduleByID(db, booking.ScheduleID)
+	if err != nil {
+		return models.RouteStop{}, models.RouteStop{}, err
+	}
+	route, err := GetRouteWithStops(db, schedule.RouteID)
+	if err != nil {
+		return models.RouteStop{}, models.RouteStop{}, err
+	}
+	return ResolveSegment(route.Stops, booking.OriginStopSequence, booking.DestinationStopSequence)
+}
+
+// sameStop reports whether two optional stop sequences are the same
+func sameStop(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// sameFareFamily reports whether two bookings are sold under the same fare family, or both at the plain fare
+func sameFareFamily(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+)
+
+var (
+	ErrInvalidFareFamily     = errors.New("invalid fare family")
+	ErrFareFamilyNotFound    = errors.New("fare family not found on this route")
+	ErrFareChangesNotAllowed = errors.New("the fare of this booking does not allow changes")
+)
+
+// ValidateFareFamily checks the price and flexibility rules of a fare family and its included ancillaries
+func ValidateFareFamily(family *models.FareFamily) error {
+	switch {
+	case strings.TrimSpace(family.Name) == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidFareFamily)
+	case family.PricePercent <= 0:
+		return fmt.Errorf("%w: price_percent must be positive", ErrInvalidFareFamily)
+	case family.Surcharge < 0:
+		return fmt.Errorf("%w: surcharge cannot be negative", ErrInvalidFareFamily)
+	case family.ChangeFee != nil && *family.ChangeFee < 0:
+		return fmt.Errorf("%w: change_fee cannot be negative", ErrInvalidFareFamily)
+	case family.CancellationFeePercent < 0 || family.CancellationFeePercent > 100:
+		return fmt.Errorf("%w: cancellation_fee_percent must be between 0 and 100", ErrInvalidFareFamily)
+	}
+
+	seen := make(map[int]bool)
+	for _, item := range family.IncludedAncillaries {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: included ancillary quantities must be positive", ErrInvalidFareFamily)
+		}
+		if seen[item.AncillaryID] {
+			return fmt.Errorf("%w: ancillary %d is included twice", ErrInvalidFareFamily, item.AncillaryID)
+		}
+		seen[item.AncillaryID] = true
+	}
+	return nil
+}
+
+// FareOptions prices the fare families of a route for a segment
+func FareOptions(families []models.FareFamily, segmentFare float64) []models.FareOption {
+	options := make([]models.FareOption, 0, len(families))
+	for _, family := range families {
+		options = append(options, models.FareOption{FareFamily: family, Price: FamilyFare(segmentFare, &family)})
+	}
+	return options
+}
+
+// SaveFareFamily creates or updates a fare family of a route operated by the given company, with the
+// ancillaries included with each seat. Included ancillaries must belong to the company.
+func SaveFareFamily(db *sql.DB, family *models.FareFamily, companyID int) error {
+	if err := ValidateFareFamily(family); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, item := range family.IncludedAncillaries {
+		ancillary, err := repository.GetAncillaryByID(tx, item.AncillaryID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && ancillary.CompanyID != companyID) {
+			return fmt.Errorf("%w: ancillary %d not found", ErrInvalidFareFamily, item.AncillaryID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if family.ID == 0 {
+		err = repository.CreateFareFamily(tx, family)
+	} else {
+		err = repository.UpdateFareFamily(tx, family)
+	}
+	if err != nil {
+		return err
+	}
+	if err := repository.ReplaceFareFamilyAncillaries(tx, family.ID, family.IncludedAncillaries); err != nil {
+		return err
+	}
+	if family.IncludedAncillaries, err = repository.GetFareFamilyAncillaries(tx, family.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LoadFareFamily loads a fare family with its included ancillaries
+func LoadFareFamily(db repository.DBInterface, id int) (*models.FareFamily, error) {
+	family, err := repository.GetFareFamilyByID(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if family.IncludedAncillaries, err = repository.GetFareFamilyAncillaries(db, family.ID); err != nil {
+		return nil, err
+	}
+	return family, nil
+}
+
+// RouteFareFamilies lists the fare families of a route with their included ancillaries, only the active ones when asked
+func RouteFareFamilies(db repository.DBInterface, routeID int, activeOnly bool) ([]models.FareFamily, error) {
+	families, err := repository.GetFareFamiliesByRouteID(db, routeID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	for i := range families {
+		if families[i].IncludedAncillaries, err = repository.GetFareFamilyAncillaries(db, families[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return families, nil
+}
+
+// bookingFareFamily loads the fare family a booking was sold under, or nil for the plain fare
+func bookingFareFamily(db repository.DBInterface, booking *models.Booking) (*models.FareFamily, error) {
+	if booking.FareFamilyID == nil {
+		return nil, nil
+	}
+	return repository.GetFareFamilyByID(db, *booking.FareFamilyID)
+}
@@ -0,0 +1,601 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+// GroupBookingMinSeats is the smallest group handled through quotes; smaller parties book normally
+const GroupBookingMinSeats = 10
+
+var (
+	ErrGroupTooSmall          = fmt.Errorf("a group booking needs at least %d seats", GroupBookingMinSeats)
+	ErrGroupBookingNotFound   = errors.New("group booking not found")
+	ErrGroupBookingStatus     = errors.New("the group booking cannot be changed in its current status")
+	ErrInvalidGroupQuote      = errors.New("invalid group quote")
+	ErrGroupBookingExpired    = errors.New("the group booking deadline has passed")
+	ErrGroupSeatNotInBlock    = errors.New("seat is not part of the group's block")
+	ErrGroupPassengersMissing = errors.New("every seat of the group needs a passenger before the balance is paid")
+	ErrGroupMemberBooking     = errors.New("bookings of a group follow the group's terms and cannot be cancelled on their own")
+)
+
+// groupFareRules are the terms of the bookings issued for a group: paid through the group, they are
+// neither refunded nor changed on their own
+var groupFareRules = models.FareRules{AllowChanges: false, AllowRefunds: false}
+
+// GroupBookingRequest is a group asking for a quote on a departure
+type GroupBookingRequest struct {
+	UserID                  int
+	ScheduleID              int
+	TravelDate              string
+	OriginStopSequence      *int
+	DestinationStopSequence *int
+	SeatCount               int
+	GroupName               string
+	ContactName             string
+	ContactPhone            string
+	ContactEmail            string
+	Notes                   string
+}
+
+// GroupQuote is the operator's offer for a group: a custom price per seat, a deposit and the deadlines.
+// The block is made of the given seats, or of the first free ones when none are given.
+type GroupQuote struct {
+	PricePerSeat      float64   `json:"price_per_seat"`
+	DepositPercent    float64   `json:"deposit_percent"`
+	SeatIDs           []int     `json:"seat_ids"`
+	QuoteExpiresAt    time.Time `json:"quote_expires_at"`
+	PassengerDeadline time.Time `json:"passenger_deadline"`
+	BalanceDeadline   time.Time `json:"balance_deadline"`
+}
+
+// GroupPassenger is the passenger travelling on a seat of a group's block
+type GroupPassenger struct {
+	SeatID            int    `json:"seat_id" binding:"required"`
+	PassengerName     string `json:"passenger_name" binding:"required"`
+	PassengerDocument string `json:"passenger_document" binding:"required"`
+	PassengerPhone    string `json:"passenger_phone"`
+}
+
+// GroupBookingRun summarizes a pass over the group bookings' deadlines
+type GroupBookingRun struct {
+	Expired       int   `json:"expired"`
+	SeatsReleased int64 `json:"seats_released"`
+}
+
+// GroupDeposit returns the deposit due on a group's total for a deposit percentage, clamped to 0-100
+func GroupDeposit(total, percent float64) float64 {
+	percent = math.Max(0, math.Min(100, percent))
+	return roundPrice(total * percent / 100)
+}
+
+// ValidateGroupQuote checks a quote's price and that its deadlines follow each other before departure
+func ValidateGroupQuote(quote GroupQuote, now, departure time.Time) error {
+	switch {
+	case quote.PricePerSeat <= 0:
+		return fmt.Errorf("%w: price_per_seat must be positive", ErrInvalidGroupQuote)
+	case quote.DepositPercent < 0 || quote.DepositPercent > 100:
+		return fmt.Errorf("%w: deposit_percent must be between 0 and 100", ErrInvalidGroupQuote)
+	case !quote.QuoteExpiresAt.After(now):
+		return fmt.Errorf("%w: quote_expires_at must be in the future", ErrInvalidGroupQuote)
+	case quote.PassengerDeadline.Before(quote.QuoteExpiresAt) || quote.BalanceDeadline.Before(quote.QuoteExpiresAt):
+		return fmt.Errorf("%w: passenger and balance deadlines cannot come before the quote expires", ErrInvalidGroupQuote)
+	case quote.PassengerDeadline.After(departure) || quote.BalanceDeadline.After(departure):
+		return fmt.Errorf("%w: deadlines must be before departure", ErrInvalidGroupQuote)
+	}
+	return nil
+}
+
+// RequestGroupBooking records a group's request for a quote on a departure
+func RequestGroupBooking(db *sql.DB, request GroupBookingRequest, now time.Time) (*models.GroupBooking, error) {
+	if request.SeatCount < GroupBookingMinSeats {
+		return nil, ErrGroupTooSmall
+	}
+	travelDate, err := time.Parse("2006-01-02", request.TravelDate)
+	if err != nil {
+		return nil, ErrInvalidTravelDate
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	trip, err := repository.GetTripByScheduleAndDate(tx, request.ScheduleID, travelDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		trip, err = MaterializeTrip(tx, request.ScheduleID, travelDate)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if trip.Status == models.TripStatusCancelled {
+		return nil, ErrDepartureCancelled
+	}
+
+	schedule, err := repository.GetScheduleByID(tx, trip.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	stops, err := repository.GetRouteStopsByRouteID(tx, schedule.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	origin, destination, err := ResolveSegment(stops, request.OriginStopSequence, request.DestinationStopSequence)
+	if err != nil {
+		return nil, err
+	}
+	departure, _, err := scheduleDatetimes(schedule, stops, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	boarding, err := stopDatetime(departure, origin)
+	if err != nil {
+		return nil, err
+	}
+	if !boarding.After(now) {
+		return nil, ErrBookingDeparted
+	}
+
+	group := models.GroupBooking{
+		UserID:                  request.UserID,
+		TripID:                  trip.ID,
+		OriginStopSequence:      origin.StopSequence,
+		DestinationStopSequence: destination.StopSequence,
+		DepartureDatetime:       boarding,
+		GroupName:               request.GroupName,
+		ContactName:             request.ContactName,
+		ContactPhone:            request.ContactPhone,
+		ContactEmail:            request.ContactEmail,
+		SeatCount:               request.SeatCount,
+		Notes:                   request.Notes,
+		Status:                  models.GroupBookingStatusRequested,
+	}
+	if err := repository.CreateGroupBooking(tx, &group); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// GetGroupBooking returns a group booking with its seat block
+func GetGroupBooking(db repository.DBInterface, id int) (*models.GroupBooking, error) {
+	group, err := repository.GetGroupBookingByID(db, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGroupBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if group.Seats, err = repository.GetGroupBookingSeats(db, group.ID); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// lockGroupBooking locks a group booking for a change. Groups of other customers are not found,
+// unless userID is 0 for operators.
+func lockGroupBooking(db repository.DBInterface, id, userID int) (*models.GroupBooking, error) {
+	group, err := repository.LockGroupBooking(db, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && userID != 0 && group.UserID != userID) {
+		return nil, ErrGroupBookingNotFound
+	}
+	return group, err
+}
+
+// QuoteGroupBooking prices a requested group and holds its seat block until the quote expires.
+// Quoting again replaces the previous quote and block.
+func QuoteGroupBooking(db *sql.DB, groupID, operatorID int, quote GroupQuote, now time.Time) (*models.GroupBooking, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	group, err := lockGroupBooking(tx, groupID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if group.Status != models.GroupBookingStatusRequested && group.Status != models.GroupBookingStatusQuoted {
+		return nil, ErrGroupBookingStatus
+	}
+	if err := ValidateGroupQuote(quote, now, group.DepartureDatetime); err != nil {
+		return nil, err
+	}
+
+	trip, err := repository.GetTripByID(tx, group.TripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.Status == models.TripStatusCancelled {
+		return nil, ErrDepartureCancelled
+	}
+
+	// The previous block is released first so that its seats can be quoted again
+	if err := repository.DeleteGroupBookingSeats(tx, group.ID); err != nil {
+		return nil, err
+	}
+	travelDate := trip.TravelDate.Format("2006-01-02")
+	if err := repository.LockDeparture(tx, trip.ScheduleID, travelDate); err != nil {
+		return nil, err
+	}
+	available, err := repository.GetAvailableSeatsForSegment(tx, trip.ScheduleID, travelDate, group.OriginStopSequence, group.DestinationStopSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	seatIDs := quote.SeatIDs
+	if len(seatIDs) == 0 {
+		if len(available) < group.SeatCount {
+			return nil, ErrSeatsUnavailable
+		}
+		for _, seat := range available[:group.SeatCount] {
+			seatIDs = append(seatIDs, seat.ID)
+		}
+	}
+	if len(seatIDs) < GroupBookingMinSeats {
+		return nil, ErrGroupTooSmall
+	}
+	availableSeatMap := make(map[int]bool)
+	for _, seat := range available {
+		availableSeatMap[seat.ID] = true
+	}
+	for _, seatID := range seatIDs {
+		if !availableSeatMap[seatID] {
+			return nil, ErrSeatsUnavailable
+		}
+		availableSeatMap[seatID] = false
+
+		seat := models.GroupBookingSeat{GroupBookingID: group.ID, SeatID: seatID}
+		if err := repository.CreateGroupBookingSeat(tx, &seat); err != nil {
+			return nil, err
+		}
+	}
+
+	total := roundPrice(quote.PricePerSeat * float64(len(seatIDs)))
+	deposit := GroupDeposit(total, quote.DepositPercent)
+	group.Status = models.GroupBookingStatusQuoted
+	group.SeatCount = len(seatIDs)
+	group.PricePerSeat = &quote.PricePerSeat
+	group.TotalAmount = &total
+	group.DepositAmount = &deposit
+	group.QuoteExpiresAt = &quote.QuoteExpiresAt
+	group.PassengerDeadline = &quote.PassengerDeadline
+	group.BalanceDeadline = &quote.BalanceDeadline
+	group.QuotedBy = &operatorID
+	if err := repository.QuoteGroupBooking(tx, group); err != nil {
+		return nil, err
+	}
+
+	if group.Seats, err = repository.GetGroupBookingSeats(tx, group.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// DeclineGroupBooking turns down a group's request, releasing any quoted block
+func DeclineGroupBooking(db *sql.DB, groupID int) (*models.GroupBooking, error) {
+	return closeGroupBooking(db, groupID, 0, models.GroupBookingStatusDeclined)
+}
+
+// CancelGroupBooking withdraws a customer's group booking, releasing its block. A deposit already paid is kept.
+func CancelGroupBooking(db *sql.DB, groupID, userID int) (*models.GroupBooking, error) {
+	return closeGroupBooking(db, groupID, userID, models.GroupBookingStatusCancelled)
+}
+
+func closeGroupBooking(db *sql.DB, groupID, userID int, status string) (*models.GroupBooking, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	group, err := lockGroupBooking(tx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	closable := group.Status == models.GroupBookingStatusRequested || group.Status == models.GroupBookingStatusQuoted ||
+		(status == models.GroupBookingStatusCancelled && group.Status == models.GroupBookingStatusConfirmed)
+	if !closable {
+		return nil, ErrGroupBookingStatus
+	}
+
+	group.Status = status
+	if err := repository.UpdateGroupBookingStatus(tx, group); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// ConfirmGroupBooking accepts a quote by paying its deposit, securing the block until the balance deadline
+func ConfirmGroupBooking(db *sql.DB, groupID, userID int, paymentMethod string, now time.Time) (*models.GroupBooking, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	group, err := lockGroupBooking(tx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if group.Status != models.GroupBookingStatusQuoted {
+		return nil, ErrGroupBookingStatus
+	}
+	if !group.QuoteExpiresAt.After(now) {
+		return nil, ErrGroupBookingExpired
+	}
+
+	if err := payGroupBooking(tx, group, *group.DepositAmount, paymentMethod, "GD"); err != nil {
+		return nil, err
+	}
+	group.Status = models.GroupBookingStatusConfirmed
+	group.PaymentMethod = paymentMethod
+	group.ConfirmedAt = &now
+	if err := repository.UpdateGroupBookingPayment(tx, group); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// SetGroupPassengers records who travels on seats of a confirmed group's block, until the passenger deadline
+func SetGroupPassengers(db *sql.DB, groupID, userID int, passengers []GroupPassenger, now time.Time) (*models.GroupBooking, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	group, err := lockGroupBooking(tx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if group.Status != models.GroupBookingStatusConfirmed {
+		return nil, ErrGroupBookingStatus
+	}
+	if !group.PassengerDeadline.After(now) {
+		return nil, ErrGroupBookingExpired
+	}
+
+	seats, err := repository.GetGroupBookingSeats(tx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	seatMap := make(map[int]*models.GroupBookingSeat)
+	for i := range seats {
+		seatMap[seats[i].SeatID] = &seats[i]
+	}
+	for _, passenger := range passengers {
+		seat, ok := seatMap[passenger.SeatID]
+		if !ok {
+			return nil, ErrGroupSeatNotInBlock
+		}
+		seat.PassengerName = passenger.PassengerName
+		seat.PassengerDocument = passenger.PassengerDocument
+		seat.PassengerPhone = passenger.PassengerPhone
+		if err := repository.UpdateGroupBookingPassenger(tx, seat); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	group.Seats = seats
+	return group, nil
+}
+
+// PayGroupBalance pays what remains of a group's total and issues a confirmed booking for each passenger.
+// The bookings are covered by the group's payments and keep its terms: no refunds and no changes.
+func PayGroupBalance(db *sql.DB, groupID, userID int, paymentMethod string, now time.Time) (*models.GroupBooking, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	group, err := lockGroupBooking(tx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if group.Status != models.GroupBookingStatusConfirmed {
+		return nil, ErrGroupBookingStatus
+	}
+	if !group.BalanceDeadline.After(now) {
+		return nil, ErrGroupBookingExpired
+	}
+
+	seats, err := repository.GetGroupBookingSeats(tx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, seat := range seats {
+		if seat.PassengerName == "" {
+			return nil, ErrGroupPassengersMissing
+		}
+	}
+
+	trip, err := repository.GetTripByID(tx, group.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Seats released after the passenger deadline lower the total, but the deposit is not refunded
+	balance := math.Max(0, roundPrice(*group.TotalAmount-group.AmountPaid))
+	if err := payGroupBooking(tx, group, balance, paymentMethod, "GB"); err != nil {
+		return nil, err
+	}
+
+	for i := range seats {
+		code, err := utils.GenerateCode("GB")
+		if err != nil {
+			return nil, err
+		}
+		rules := groupFareRules
+		booking := models.Booking{
+			UserID:                  group.UserID,
+			ScheduleID:              trip.ScheduleID,
+			FareRules:               &rules,
+			BookingCode:             code,
+			TravelDate:              trip.TravelDate,
+			OriginStopSequence:      &group.OriginStopSequence,
+			DestinationStopSequence: &group.DestinationStopSequence,
+			DepartureDatetime:       group.DepartureDatetime,
+			PassengerName:           seats[i].PassengerName,
+			PassengerDocument:       seats[i].PassengerDocument,
+			PassengerPhone:          seats[i].PassengerPhone,
+			TotalAmount:             *group.PricePerSeat,
+			PaymentStatus:           "paid",
+			BookingStatus:           "confirmed",
+			PaymentMethod:           paymentMethod,
+			Notes:                   "Group booking: " + group.GroupName,
+		}
+		if err := repository.CreateBooking(tx, &booking); err != nil {
+			return nil, err
+		}
+		bookingSeat := models.BookingSeat{BookingID: booking.ID, SeatID: seats[i].SeatID}
+		if err := repository.CreateBookingSeat(tx, &bookingSeat); err != nil {
+			return nil, err
+		}
+		if err := repository.SetGroupSeatBooking(tx, seats[i].ID, booking.ID); err != nil {
+			return nil, err
+		}
+		seats[i].BookingID = &booking.ID
+	}
+
+	group.Status = models.GroupBookingStatusCompleted
+	group.PaymentMethod = paymentMethod
+	group.CompletedAt = &now
+	if err := repository.UpdateGroupBookingPayment(tx, group); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	group.Seats = seats
+	return group, nil
+}
+
+// payGroupBooking records a payment of a group booking, before its bookings exist
+func payGroupBooking(db repository.DBInterface, group *models.GroupBooking, amount float64, paymentMethod, prefix string) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	payment := models.Payment{
+		GroupBookingID: &group.ID,
+		Amount:         amount,
+		PaymentMethod:  paymentMethod,
+		PaymentStatus:  "paid",
+		TransactionID:  prefix + strconv.Itoa(group.ID),
+		PaymentGateway: "simulated",
+		PaidAt:         &now,
+	}
+	if err := repository.CreatePayment(db, &payment); err != nil {
+		return err
+	}
+
+	group.AmountPaid = roundPrice(group.AmountPaid + amount)
+	return nil
+}
+
+// ProcessGroupBookings expires quotes not confirmed in time and groups whose balance is overdue, and
+// releases the seats left without a passenger after the passenger deadline. Released seats are offered
+// to the waitlist.
+func ProcessGroupBookings(db *sql.DB, claimPeriod time.Duration, now time.Time) (*GroupBookingRun, error) {
+	run := &GroupBookingRun{}
+	releasedTrips := map[int]bool{}
+
+	expired, err := repository.ExpireGroupBookings(db, now)
+	if err != nil {
+		return nil, err
+	}
+	run.Expired = len(expired)
+	for _, group := range expired {
+		releasedTrips[group.TripID] = true
+	}
+
+	overdue, err := repository.GetGroupBookingsPastPassengerDeadline(db, now)
+	if err != nil {
+		return run, err
+	}
+	for i := range overdue {
+		released, err := releaseUnnamedGroupSeats(db, &overdue[i])
+		if err != nil {
+			return run, err
+		}
+		run.SeatsReleased += released
+		releasedTrips[overdue[i].TripID] = true
+	}
+
+	for tripID := range releasedTrips {
+		if _, err := OfferWaitlistSeats(db, tripID, claimPeriod, now); err != nil {
+			return run, err
+		}
+	}
+	return run, nil
+}
+
+// releaseUnnamedGroupSeats releases the seats of a group without a passenger and reprices the group on the
+// seats left. A group left without seats expires.
+func releaseUnnamedGroupSeats(db *sql.DB, overdue *models.GroupBooking) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	group, err := repository.LockGroupBooking(tx, overdue.ID)
+	if err != nil {
+		return 0, err
+	}
+	if group.Status != models.GroupBookingStatusConfirmed {
+		return 0, nil
+	}
+
+	released, err := repository.ReleaseUnnamedGroupSeats(tx, group.ID)
+	if err != nil {
+		return 0, err
+	}
+	group.SeatCount -= int(released)
+	total := roundPrice(*group.PricePerSeat * float64(group.SeatCount))
+	group.TotalAmount = &total
+	if err := repository.UpdateGroupBookingSize(tx, group); err != nil {
+		return 0, err
+	}
+	if group.SeatCount <= 0 {
+		group.Status = models.GroupBookingStatusExpired
+		if err := repository.UpdateGroupBookingStatus(tx, group); err != nil {
+			return 0, err
+		}
+	}
+
+	return released, tx.Commit()
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+)
+
+// maxItineraries caps the number of itineraries returned by a search
+const maxItineraries = 50
+
+// ItineraryOptions controls how legs may be combined into an itinerary
+type ItineraryOptions struct {
+	MaxTransfers  int
+	MinConnection time.Duration
+	MaxConnection time.Duration
+	Passengers    int
+}
+
+// legsConnect reports whether a passenger arriving with one leg can board the next one:
+// both legs must meet at the same city or terminal.
+func legsConnect(arriving, departing models.ItineraryLeg) bool {
+	if strings.EqualFold(arriving.DestinationCity, departing.OriginCity) {
+		return true
+	}
+	return arriving.DestinationTerminal != "" && strings.EqualFold(arriving.DestinationTerminal, departing.OriginTerminal)
+}
+
+// matchesCity mirrors the ILIKE matching used by the travel search
+func matchesCity(city, query string) bool {
+	return strings.Contains(strings.ToLower(city), strings.ToLower(query))
+}
+
+// BuildItineraries combines legs into itineraries from origin to destination, with at most
+// opts.MaxTransfers changes and a connection time between opts.MinConnection and opts.MaxConnection.
+// Itineraries are ranked by total duration, then total price.
+func BuildItineraries(legs []models.ItineraryLeg, origin, destination string, opts ItineraryOptions) []models.Itinerary {
+	itineraries := []models.Itinerary{}
+
+	// Index legs by boarding city and terminal so connections are looked up rather than scanned
+	byCity := make(map[string][]models.ItineraryLeg)
+	byTerminal := make(map[string][]models.ItineraryLeg)
+	for _, leg := range legs {
+		byCity[strings.ToLower(leg.OriginCity)] = append(byCity[strings.ToLower(leg.OriginCity)], leg)
+		if leg.OriginTerminal != "" {
+			byTerminal[strings.ToLower(leg.OriginTerminal)] = append(byTerminal[strings.ToLower(leg.OriginTerminal)], leg)
+		}
+	}
+
+	var extend func(path []models.ItineraryLeg)
+	extend = func(path []models.ItineraryLeg) {
+		last := path[len(path)-1]
+		if matchesCity(last.DestinationCity, destination) {
+			itineraries = append(itineraries, newItinerary(path))
+			return
+		}
+		if len(path) > opts.MaxTransfers {
+			return
+		}
+
+		candidates := byCity[strings.ToLower(last.DestinationCity)]
+		if last.DestinationTerminal != "" {
+			for _, leg := range byTerminal[strings.ToLower(last.DestinationTerminal)] {
+				// Legs leaving from the same city are already candidates
+				if !strings.EqualFold(leg.OriginCity, last.DestinationCity) {
+					candidates = append(candidates, leg)
+				}
+			}
+		}
+
+		for _, next := range candidates {
+			if !legsConnect(last, next) || visits(path, next) {
+				continue
+			}
+			connection := next.DepartureDatetime.Sub(last.ArrivalDatetime)
+			if connection < opts.MinConnection || connection > opts.MaxConnection {
+				continue
+			}
+
+			extended := make([]models.ItineraryLeg, len(path), len(path)+1)
+			copy(extended, path)
+			extend(append(extended, next))
+		}
+	}
+
+	for _, leg := range legs {
+		if matchesCity(leg.OriginCity, origin) {
+			extend([]models.ItineraryLeg{leg})
+		}
+	}
+
+	sort.SliceStable(itineraries, func(i, j int) bool {
+		if itineraries[i].TotalDurationMinutes != itineraries[j].TotalDurationMinutes {
+			return itineraries[i].TotalDurationMinutes < itineraries[j].TotalDurationMinutes
+		}
+		if itineraries[i].TotalPrice != itineraries[j].TotalPrice {
+			return itineraries[i].TotalPrice < itineraries[j].TotalPrice
+		}
+		return itineraries[i].DepartureDatetime.Before(itineraries[j].DepartureDatetime)
+	})
+
+	return itineraries
+}
+
+// visits reports whether adding the leg would reuse a departure or loop back to a city already passed
+func visits(path []models.ItineraryLeg, leg models.ItineraryLeg) bool {
+	for _, previous := range path {
+		if previous.ScheduleID == leg.ScheduleID && previous.TravelDate == leg.TravelDate {
+			return true
+		}
+		if strings.EqualFold(previous.OriginCity, leg.DestinationCity) {
+			return true
+		}
+	}
+	return false
+}
+
+func newItinerary(path []models.ItineraryLeg) models.Itinerary {
+	itinerary := models.Itinerary{
+		Legs:              path,
+		Transfers:         len(path) - 1,
+		DepartureDatetime: path[0].DepartureDatetime,
+		ArrivalDatetime:   path[len(path)-1].ArrivalDatetime,
+	}
+	itinerary.TotalDurationMinutes = int(itinerary.ArrivalDatetime.Sub(itinerary.DepartureDatetime).Minutes())
+	for _, leg := range path {
+		itinerary.TotalPrice += leg.Price
+	}
+	itinerary.TotalPrice = roundPrice(itinerary.TotalPrice)
+
+	return itinerary
+}
+
+// SearchItineraries finds direct and connecting itineraries leaving on a travel date.
+// Connections may continue on the following day; itineraries without enough free seats on every leg are dropped.
+func SearchItineraries(db repository.DBInterface, origin, destination string, travelDate time.Time, opts ItineraryOptions) ([]models.Itinerary, error) {
+	var legs []models.ItineraryLeg
+	for day := 0; day <= 1; day++ {
+		dayLegs, err := repository.GetScheduledSegments(db, travelDate.AddDate(0, 0, day))
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, dayLegs...)
+	}
+
+	// Price every leg for its segment, loading stops and fares once per route
+	routes := make(map[int]*models.Route)
+	for i := range legs {
+		leg := &legs[i]
+		route, ok := routes[leg.RouteID]
+		if !ok {
+			var err error
+			route, err = GetRouteWithStops(db, leg.RouteID)
+			if err != nil {
+				return nil, err
+			}
+			routes[leg.RouteID] = route
+		}
+
+		originStop, destinationStop, err := ResolveSegment(route.Stops, &leg.OriginStopSequence, &leg.DestinationStopSequence)
+		if err != nil {
+			return nil, err
+		}
+		leg.Price = SegmentFare(route.BasePrice, route.Stops, route.Fares, originStop, destinationStop)
+	}
+
+	// Only the first leg has to leave on the requested date
+	firstDay := travelDate.Format("2006-01-02")
+	candidates := BuildItineraries(legs, origin, destination, opts)
+
+	itineraries := []models.Itinerary{}
+	type segmentKey struct {
+		scheduleID, origin, destination int
+		travelDate                      string
+	}
+	seatCounts := make(map[segmentKey]int)
+	for _, itinerary := range candidates {
+		if itinerary.Legs[0].TravelDate != firstDay {
+			continue
+		}
+
+		available := true
+		for i := range itinerary.Legs {
+			leg := &itinerary.Legs[i]
+			key := segmentKey{leg.ScheduleID, leg.OriginStopSequence, leg.DestinationStopSequence, leg.TravelDate}
+			count, ok := seatCounts[key]
+			if !ok {
+				seats, err := repository.GetAvailableSeatsForSegment(db, leg.ScheduleID, leg.TravelDate, leg.OriginStopSequence, leg.DestinationStopSequence)
+				if err != nil {
+					return nil, err
+				}
+				count = len(seats)
+				seatCounts[key] = count
+			}
+			leg.AvailableSeats = count
+			if count < opts.Passengers {
+				available = false
+			}
+		}
+
+		if available {
+			itineraries = append(itineraries, itinerary)
+		}
+		if len(itineraries) == maxItineraries {
+			break
+		}
+	}
+
+	return itineraries, nil
+}
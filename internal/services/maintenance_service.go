@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+)
+
+var (
+	ErrInvalidMaintenanceWindow = errors.New("maintenance windows must end after they start")
+	ErrVehicleInMaintenance     = errors.New("the vehicle is in maintenance during the departure")
+)
+
+// MaintenanceWarning is a departure that falls inside a maintenance window of its vehicle,
+// with the vehicles that could run it instead
+type MaintenanceWarning struct {
+	Window       models.MaintenanceWindow `json:"window"`
+	Assignment   Assignment               `json:"assignment"`
+	Alternatives []models.Vehicle         `json:"alternatives"`
+}
+
+// MaintenanceAt returns the first maintenance window overlapping a period, or nil
+func MaintenanceAt(windows []models.MaintenanceWindow, from, to time.Time) *models.MaintenanceWindow {
+	for i := range windows {
+		if windows[i].StartsAt.Before(to) && from.Before(windows[i].EndsAt) {
+			return &windows[i]
+		}
+	}
+	return nil
+}
+
+// CompatibleSeats reports whether a vehicle can take over the passengers of another:
+// it must have every seat of the original vehicle, with the same number and type.
+// Vehicles without a seat map are compared on their number of seats.
+func CompatibleSeats(original *models.Vehicle, originalSeats []models.Seat, candidate *models.Vehicle, candidateSeats []models.Seat) bool {
+	if len(originalSeats) == 0 {
+		return candidate.TotalSeats >= original.TotalSeats
+	}
+
+	types := make(map[string]string, len(candidateSeats))
+	for _, seat := range candidateSeats {
+		types[seat.SeatNumber] = seat.SeatType
+	}
+	for _, seat := range originalSeats {
+		if seatType, ok := types[seat.SeatNumber]; !ok || seatType != seat.SeatType {
+			return false
+		}
+	}
+	return true
+}
+
+// checkMaintenance fails when a vehicle is in maintenance during a period
+func checkMaintenance(db repository.DBInterface, vehicleID int, from, to time.Time) error {
+	windows, err := repository.GetMaintenanceWindows(db, vehicleID, from, to)
+	if err != nil {
+		return err
+	}
+	if window := MaintenanceAt(windows, from, to); window != nil {
+		return fmt.Errorf("%w: %s from %s to %s", ErrVehicleInMaintenance, window.Reason,
+			window.StartsAt.UTC().Format(time.RFC3339), window.EndsAt.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// AlternativeVehicles lists the active vehicles of the same company with compatible seats
+// that are free and out of maintenance during a departure
+func AlternativeVehicles(db repository.DBInterface, vehicleID int, assignment Assignment) ([]models.Vehicle, error) {
+	original, err := repository.GetVehicleByID(db, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	originalSeats, err := repository.GetSeatsByVehicleID(db, original.ID)
+	if err != nil {
+		return nil, err
+	}
+	vehicles, err := repository.GetVehiclesByCompanyID(db, original.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+
+	alternatives := []models.Vehicle{}
+	for i := range vehicles {
+		candidate := &vehicles[i]
+		if candidate.ID == original.ID || !candidate.IsActive {
+			continue
+		}
+
+		seats, err := repository.GetSeatsByVehicleID(db, candidate.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !CompatibleSeats(original, originalSeats, candidate, seats) {
+			continue
+		}
+
+		err = checkMaintenance(db, candidate.ID, assignment.DepartureDatetime, assignment.ArrivalDatetime)
+		if errors.Is(err, ErrVehicleInMaintenance) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		timeline, err := VehicleTimeline(db, candidate.ID, assignment.DepartureDatetime, assignment.ArrivalDatetime)
+		if err != nil {
+			return nil, err
+		}
+		if len(timeline) > 0 {
+			continue
+		}
+
+		alternatives = append(alternatives, *candidate)
+	}
+
+	return alternatives, nil
+}
+
+// MaintenanceWarnings lists the departures of a vehicle that fall inside its maintenance windows,
+// with alternative vehicles for each
+func MaintenanceWarnings(db repository.DBInterface, vehicleID int, windows []models.MaintenanceWindow) ([]MaintenanceWarning, error) {
+	warnings := []MaintenanceWarning{}
+	for _, window := range windows {
+		timeline, err := VehicleTimeline(db, vehicleID, window.StartsAt, window.EndsAt)
+		if err != nil {
+			return nil, err
+		}
+		for _, assignment := range timeline {
+			alternatives, err := AlternativeVehicles(db, vehicleID, assignment)
+			if err != nil {
+				return nil, err
+			}
+			warnings = append(warnings, MaintenanceWarning{Window: window, Assignment: assignment, Alternatives: alternatives})
+		}
+	}
+	return warnings, nil
+}
+
+// ScheduleMaintenance stores a maintenance window and returns the departures it affects
+func ScheduleMaintenance(db repository.DBInterface, window *models.MaintenanceWindow) ([]MaintenanceWarning, error) {
+	if !window.EndsAt.After(window.StartsAt) {
+		return nil, ErrInvalidMaintenanceWindow
+	}
+
+	if err := repository.CreateMaintenanceWindow(db, window); err != nil {
+		return nil, err
+	}
+	return MaintenanceWarnings(db, window.VehicleID, []models.MaintenanceWindow{*window})
+}
+
+// maintenanceConflicts reports the departures of a timeline that fall inside a maintenance window
+func maintenanceConflicts(timeline []Assignment, windows []models.MaintenanceWindow) []AssignmentConflict {
+	conflicts := []AssignmentConflict{}
+	for _, assignment := range timeline {
+		if window := MaintenanceAt(windows, assignment.DepartureDatetime, assignment.ArrivalDatetime); window != nil {
+			conflicts = append(conflicts, AssignmentConflict{
+				Type:              ConflictMaintenance,
+				VehicleID:         assignment.VehicleID,
+				Assignment:        assignment,
+				MaintenanceWindow: window,
+			})
+		}
+	}
+	return conflicts
+}
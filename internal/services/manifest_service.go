@@ -0,0 +1,100 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+)
+
+// Manifest is the list of passengers booked on a trip
+type Manifest struct {
+	Trip            *models.Trip           `json:"trip"`
+	OriginCity      string                 `json:"origin_city"`
+	DestinationCity string                 `json:"destination_city"`
+	LicensePlate    string                 `json:"license_plate"`
+	Passengers      []models.ManifestEntry `json:"passengers"`
+	GeneratedAt     time.Time              `json:"generated_at"`
+}
+
+// TripManifest lists the passengers of a trip in seat order with the stops where they board and alight
+// and the ancillaries they travel with
+func TripManifest(db repository.DBInterface, tripID int) (*Manifest, error) {
+	trip, err := repository.GetTripByID(db, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if err := LocalizeTrip(db, trip); err != nil {
+		return nil, err
+	}
+	schedule, err := repository.GetScheduleByID(db, trip.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	stops, err := repository.GetRouteStopsByRouteID(db, schedule.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stops) < 2 {
+		return nil, ErrInvalidStops
+	}
+	vehicle, err := repository.GetVehicleByID(db, trip.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := repository.GetManifestEntries(db, trip.ScheduleID, trip.TravelDate)
+	if err != nil {
+		return nil, err
+	}
+	FillManifestStops(entries, stops)
+
+	ancillaries, err := repository.GetTripAncillaries(db, trip.ScheduleID, trip.TravelDate)
+	if err != nil {
+		return nil, err
+	}
+	FillManifestAncillaries(entries, ancillaries)
+
+	return &Manifest{
+		Trip:            trip,
+		OriginCity:      stops[0].City,
+		DestinationCity: stops[len(stops)-1].City,
+		LicensePlate:    vehicle.LicensePlate,
+		Passengers:      entries,
+		GeneratedAt:     time.Now().UTC(),
+	}, nil
+}
+
+// FillManifestStops names the boarding and alighting stops of manifest entries.
+// Bookings without a segment travel from the first to the last stop.
+func FillManifestStops(entries []models.ManifestEntry, stops []models.RouteStop) {
+	bySequence := make(map[int]models.RouteStop, len(stops))
+	for _, stop := range stops {
+		bySequence[stop.StopSequence] = stop
+	}
+	for i := range entries {
+		entry := &entries[i]
+		boarding, alighting := stops[0], stops[len(stops)-1]
+		if entry.OriginStopSequence != nil {
+			boarding = bySequence[*entry.OriginStopSequence]
+		}
+		if entry.DestinationStopSequence != nil {
+			alighting = bySequence[*entry.DestinationStopSequence]
+		}
+		entry.BoardingStopSequence = boarding.StopSequence
+		entry.BoardingStop = stopName(boarding)
+		entry.AlightingStopSequence = alighting.StopSequence
+		entry.AlightingStop = stopName(alighting)
+	}
+}
+
+// FillManifestAncillaries summarizes the ancillaries of each booking on its manifest entries
+func FillManifestAncillaries(entries []models.ManifestEntry, ancillaries []models.BookingAncillary) {
+	byBooking := make(map[int][]models.BookingAncillary)
+	for _, item := range ancillaries {
+		byBooking[item.BookingID] = append(byBooking[item.BookingID], item)
+	}
+	for i := range entries {
+		entries[i].Ancillaries = AncillarySummary(byBooking[entries[i].BookingID])
+	}
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+// NoShowRun summarises a pass of no-show processing
+type NoShowRun struct {
+	Completed int     `json:"completed"`
+	NoShows   int     `json:"no_shows"`
+	Fees      float64 `json:"fees"`
+	Refunds   float64 `json:"refunds"`
+}
+
+// AttendanceReport counts completed and no-show bookings of a company's departures
+type AttendanceReport struct {
+	CompanyID  int                          `json:"company_id"`
+	Departures []models.DepartureAttendance `json:"departures"`
+	Completed  int                          `json:"completed"`
+	NoShows    int                          `json:"no_shows"`
+	NoShowFees float64                      `json:"no_show_fees"`
+	// Share of departed bookings that did not show up, in percent
+	NoShowRate float64 `json:"no_show_rate"`
+}
+
+// NoShowFee returns the part of a fare kept for a no-show, given the company's fee percentage
+func NoShowFee(amount, feePercent float64) float64 {
+	feePercent = math.Min(100, math.Max(0, feePercent))
+	return roundPrice(amount * feePercent / 100)
+}
+
+// AttendanceOutcome decides the final status of a departed booking. Passengers count as no-shows only
+// when the trip was scanned at boarding and none of their seats boarded; otherwise they travelled.
+func AttendanceOutcome(tracked bool, boardedSeats int) string {
+	if tracked && boardedSeats == 0 {
+		return "no_show"
+	}
+	return "completed"
+}
+
+// NoShowOutcome is how a departed booking is closed: its final status, the fee kept and the amount refunded
+type NoShowOutcome struct {
+	Status string
+	Fee    float64
+	Refund float64
+}
+
+// DecideNoShow closes a departed booking given whether its trip was scanned at boarding, how many of its
+// seats boarded and the company's no-show fee percentage. Checking in does not count as boarding; only
+// paid no-shows are charged and refunded the rest. The fee is taken from the fare without ancillaries and
+// is never less than cancelling under the booking's fare rules would cost, so non-refundable fares keep it all.
+func DecideNoShow(booking *models.Booking, tracked bool, boardedSeats int, feePercent float64) NoShowOutcome {
+	outcome := NoShowOutcome{Status: AttendanceOutcome(tracked, boardedSeats)}
+	if outcome.Status == "no_show" && booking.PaymentStatus == "paid" {
+		fare := roundPrice(booking.TotalAmount - booking.AncillaryAmount)
+		outcome.Fee = math.Max(NoShowFee(fare, feePercent), CancellationFee(fare, booking.FareRules))
+		outcome.Refund = roundPrice(booking.TotalAmount - outcome.Fee)
+	}
+	return outcome
+}
+
+// ProcessNoShows closes the confirmed bookings whose boarding closed, grace after their (delayed) departure.
+// Bookings become completed or no_show; no-shows of paid bookings are refunded minus their no-show fee
+// and their passengers are notified. Each departure is processed in its own transaction.
+func ProcessNoShows(db *sql.DB, grace time.Duration, now time.Time) (*NoShowRun, error) {
+	bookings, err := repository.GetDepartedBookings(db, now.Add(-grace))
+	if err != nil {
+		return nil, err
+	}
+
+	type departure struct {
+		scheduleID int
+		travelDate string
+	}
+	var order []departure
+	byDeparture := make(map[departure][]models.Booking)
+	for _, booking := range bookings {
+		key := departure{booking.ScheduleID, booking.TravelDate.Format(utils.DateLayout)}
+		if _, ok := byDeparture[key]; !ok {
+			order = append(order, key)
+		}
+		byDeparture[key] = append(byDeparture[key], booking)
+	}
+
+	run := &NoShowRun{}
+	for _, key := range order {
+		if err := closeDeparture(db, byDeparture[key], grace, now, run); err != nil {
+			return run, fmt.Errorf("schedule %d on %s: %w", key.scheduleID, key.travelDate, err)
+		}
+	}
+	run.Fees = roundPrice(run.Fees)
+	run.Refunds = roundPrice(run.Refunds)
+	return run, nil
+}
+
+func closeDeparture(db *sql.DB, bookings []models.Booking, grace time.Duration, now time.Time, run *NoShowRun) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	first := bookings[0]
+	trip, err := repository.GetTripByScheduleAndDate(tx, first.ScheduleID, first.TravelDate)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if trip != nil && trip.Status == models.TripStatusCancelled {
+		// Cancelling a trip cancels its bookings; whatever is left is not ours to close
+		return nil
+	}
+
+	delay := time.Duration(0)
+	tracked := false
+	if trip != nil {
+		delay = time.Duration(trip.DelayMinutes) * time.Minute
+		scans, err := repository.CountTripBoardingScans(tx, trip.ID)
+		if err != nil {
+			return err
+		}
+		tracked = scans > 0
+	}
+
+	companyID, err := repository.GetScheduleCompanyID(tx, first.ScheduleID)
+	if err != nil {
+		return err
+	}
+	company, err := repository.GetCompanyByID(tx, companyID)
+	if err != nil {
+		return err
+	}
+
+	for i := range bookings {
+		booking := &bookings[i]
+		if now.Before(booking.DepartureDatetime.Add(delay + grace)) {
+			continue
+		}
+
+		boarded, err := repository.CountBoardedSeats(tx, booking.ID)
+		if err != nil {
+			return err
+		}
+		original := *booking
+		outcome := DecideNoShow(booking, tracked, boarded, company.NoShowFeePercent)
+		refund := outcome.Refund
+		booking.BookingStatus = outcome.Status
+		booking.NoShowFee = outcome.Fee
+		if refund > 0 {
+			booking.PaymentStatus = "refunded"
+		}
+
+		if err := repository.SetBookingAttendance(tx, booking); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return err
+		}
+		// The refund is recorded against the booking as it was paid
+		if _, err := refundBooking(tx, &original, refund); err != nil {
+			return err
+		}
+
+		if booking.BookingStatus == "completed" {
+			run.Completed++
+			continue
+		}
+		run.NoShows++
+		run.Fees += booking.NoShowFee
+		run.Refunds += refund
+		if err := notifyNoShow(tx, booking, refund); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func notifyNoShow(db repository.DBInterface, booking *models.Booking, refund float64) error {
+	message := fmt.Sprintf("You did not board your trip of %s (booking %s).", booking.DepartureDatetime.Format("2006-01-02 15:04"), booking.BookingCode)
+	if refund > 0 {
+		message += fmt.Sprintf(" %.2f has been refunded after a no-show fee of %.2f.", refund, booking.NoShowFee)
+	}
+
+	notification := models.Notification{
+		UserID:           booking.UserID,
+		BookingID:        &booking.ID,
+		NotificationType: "no_show",
+		Title:            "Missed trip",
+		Message:          message,
+		Data: map[string]interface{}{
+			"no_show_fee":   booking.NoShowFee,
+			"refund_amount": refund,
+		},
+	}
+	return repository.CreateNotification(db, &notification)
+}
+
+// DepartureAttendanceReport counts the completed and no-show bookings of a company's departures with a travel date in [from, to)
+func DepartureAttendanceReport(db repository.DBInterface, companyID int, from, to time.Time) (*AttendanceReport, error) {
+	departures, err := repository.GetDepartureAttendance(db, companyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AttendanceReport{CompanyID: companyID, Departures: departures}
+	for _, departure := range departures {
+		report.Completed += departure.Completed
+		report.NoShows += departure.NoShows
+		report.NoShowFees += departure.NoShowFees
+	}
+	report.NoShowFees = roundPrice(report.NoShowFees)
+	if total := report.Completed + report.NoShows; total > 0 {
+		report.NoShowRate = roundPrice(float64(report.NoShows) * 100 / float64(total))
+	}
+	return report, nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+)
+
+// SegmentFare returns the price of one seat between two stops of a route.
+// An explicit stop-pair fare wins; otherwise the base price is pro-rated by distance,
+// or by travel time when the route has no distances.
+func SegmentFare(basePrice float64, stops []models.RouteStop, fares []models.RouteFare, origin, destination models.RouteStop) float64 {
+	for _, fare := range fares {
+		if fare.OriginStopSequence == origin.StopSequence && fare.DestinationStopSequence == destination.StopSequence {
+			return fare.Price
+		}
+	}
+
+	if len(stops) == 0 {
+		return basePrice
+	}
+	last := stops[len(stops)-1]
+
+	var share float64
+	switch {
+	case last.DistanceKm > 0:
+		share = float64(destination.DistanceKm-origin.DistanceKm) / float64(last.DistanceKm)
+	case last.OffsetMinutes > 0:
+		share = float64(destination.OffsetMinutes-origin.OffsetMinutes) / float64(last.OffsetMinutes)
+	default:
+		return basePrice
+	}
+
+	return roundPrice(basePrice * share)
+}
+
+// roundPrice rounds an amount to cents
+func roundPrice(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// RoundTripDiscount returns the discount granted on a round-trip leg, given the company's discount percentage
+func RoundTripDiscount(amount, discountPercent float64) float64 {
+	if discountPercent <= 0 {
+		return 0
+	}
+	if discountPercent > 100 {
+		discountPercent = 100
+	}
+	return roundPrice(amount * discountPercent / 100)
+}
+
+// AncillaryPrice returns the price of one unit of an ancillary on a leg: its own price plus its share of the seat fare
+func AncillaryPrice(ancillary models.Ancillary, seatFare float64) float64 {
+	return roundPrice(ancillary.Price + seatFare*ancillary.FarePercent/100)
+}
+
+// FamilyFare returns the price of one seat sold under a fare family, given the segment fare.
+// Without a fare family the segment fare applies.
+func FamilyFare(segmentFare float64, family *models.FareFamily) float64 {
+	if family == nil {
+		return segmentFare
+	}
+	return roundPrice(segmentFare*family.PricePercent/100 + family.Surcharge)
+}
+
+// CancellationFee returns the part of a fare kept when the passenger cancels a booking sold under fare rules
+func CancellationFee(fare float64, rules *models.FareRules) float64 {
+	if rules == nil || fare <= 0 {
+		return 0
+	}
+	if !rules.AllowRefunds {
+		return fare
+	}
+	return roundPrice(fare * math.Min(math.Max(rules.CancellationFeePercent, 0), 100) / 100)
+}
+
+// FareChangeFee returns the fee to move a booking to another departure: the fare rules' own, or the company's
+func FareChangeFee(companyFee float64, rules *models.FareRules) float64 {
+	if rules == nil || rules.ChangeFee == nil {
+		return roundPrice(companyFee)
+	}
+	return roundPrice(*rules.ChangeFee)
+}
+
+// LoadPercent returns the share of a departure's seats already sold
+func LoadPercent(sold, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return roundPrice(float64(sold) * 100 / float64(total))
+}
+
+// DaysBefore returns the whole days left until a departure
+func DaysBefore(departure, now time.Time) int {
+	if !departure.After(now) {
+		return 0
+	}
+	return int(departure.Sub(now).Hours() / 24)
+}
+
+// YieldPercent returns the share of the segment fare charged for a departure with the given load factor and days
+// left: that of the first matching rule, in the order given, or 100 when none matches, within the route's caps
+func YieldPercent(rules []models.YieldRule, loadPercent float64, daysBefore int, minPercent, maxPercent *float64) (float64, *models.YieldRule) {
+	percent := 100.0
+	var applied *models.YieldRule
+	for i := range rules {
+		if yieldRuleMatches(rules[i], loadPercent, daysBefore) {
+			percent = rules[i].PricePercent
+			applied = &rules[i]
+			break
+		}
+	}
+
+	if minPercent != nil && percent < *minPercent {
+		percent = *minPercent
+	}
+	if maxPercent != nil && percent > *maxPercent {
+		percent = *maxPercent
+	}
+	return percent, applied
+}
+
+func yieldRuleMatches(rule models.YieldRule, loadPercent float64, daysBefore int) bool {
+	return rule.IsActive &&
+		loadPercent >= rule.MinLoadPercent && loadPercent <= rule.MaxLoadPercent &&
+		(rule.MinDaysBefore == nil || daysBefore >= *rule.MinDaysBefore) &&
+		(rule.MaxDaysBefore == nil || daysBefore <= *rule.MaxDaysBefore)
+}
+
+// YieldFare returns the segment fare charged at a yield percentage
+func YieldFare(segmentFare, yieldPercent float64) float64 {
+	return roundPrice(segmentFare * yieldPercent / 100)
+}
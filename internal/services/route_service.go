@@ -0,0 +1,286 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+var (
+	ErrInvalidStops    = errors.New("a route needs at least two stops with increasing offsets and distances")
+	ErrInvalidFare     = errors.New("fares must go forward between existing stops and have a positive price")
+	ErrInvalidSegment  = errors.New("origin and destination stops must exist on the route and be in travel order")
+	ErrInvalidTimeZone = errors.New("stop time zones must be valid IANA names, e.g. Europe/Rome")
+	ErrRouteStopsInUse = errors.New("stops cannot change while the route has upcoming bookings")
+)
+
+// NormalizeRouteStops validates the stops of a route, numbers them in travel order and
+// keeps the route origin, destination, distance and duration in sync with them.
+// Routes submitted without stops get their origin and destination as the only two stops.
+func NormalizeRouteStops(route *models.Route) error {
+	if len(route.Stops) == 0 {
+		route.Stops = []models.RouteStop{
+			{City: route.OriginCity, Terminal: route.OriginTerminal},
+			{City: route.DestinationCity, Terminal: route.DestinationTerminal, OffsetMinutes: route.EstimatedDurationMinutes, DistanceKm: route.DistanceKm},
+		}
+	}
+	if len(route.Stops) < 2 {
+		return ErrInvalidStops
+	}
+
+	for i := range route.Stops {
+		stop := &route.Stops[i]
+		stop.RouteID = route.ID
+		stop.StopSequence = i + 1
+
+		if stop.City == "" {
+			return ErrInvalidStops
+		}
+
+		// Stops without a zone share the zone of the previous stop
+		if stop.TimeZone == "" && i > 0 {
+			stop.TimeZone = route.Stops[i-1].TimeZone
+		}
+		if stop.TimeZone == "" {
+			stop.TimeZone = "UTC"
+		}
+		if _, err := utils.LoadLocation(stop.TimeZone); err != nil {
+			return ErrInvalidTimeZone
+		}
+		if i == 0 {
+			if stop.OffsetMinutes != 0 || stop.DistanceKm != 0 {
+				return ErrInvalidStops
+			}
+			continue
+		}
+
+		previous := route.Stops[i-1]
+		if stop.OffsetMinutes <= previous.OffsetMinutes || stop.DistanceKm < previous.DistanceKm {
+			return ErrInvalidStops
+		}
+	}
+
+	first := route.Stops[0]
+	last := route.Stops[len(route.Stops)-1]
+	route.OriginCity = first.City
+	route.OriginTerminal = first.Terminal
+	route.DestinationCity = last.City
+	route.DestinationTerminal = last.Terminal
+	route.DistanceKm = last.DistanceKm
+	route.EstimatedDurationMinutes = last.OffsetMinutes
+
+	for i := range route.Fares {
+		fare := &route.Fares[i]
+		fare.RouteID = route.ID
+		if fare.OriginStopSequence < 1 || fare.DestinationStopSequence > len(route.Stops) ||
+			fare.OriginStopSequence >= fare.DestinationStopSequence || fare.Price <= 0 {
+			return ErrInvalidFare
+		}
+	}
+
+	return nil
+}
+
+// ResolveSegment returns the boarding and alighting stops for the requested stop sequences.
+// Missing sequences default to the first and last stop of the route.
+func ResolveSegment(stops []models.RouteStop, originStopSequence, destinationStopSequence *int) (models.RouteStop, models.RouteStop, error) {
+	if len(stops) < 2 {
+		return models.RouteStop{}, models.RouteStop{}, ErrInvalidSegment
+	}
+
+	origin := stops[0]
+	destination := stops[len(stops)-1]
+	found := 0
+	for _, stop := range stops {
+		if originStopSequence != nil && stop.StopSequence == *originStopSequence {
+			origin = stop
+			found++
+		}
+		if destinationStopSequence != nil && stop.StopSequence == *destinationStopSequence {
+			destination = stop
+			found++
+		}
+	}
+
+	expected := 0
+	if originStopSequence != nil {
+		expected++
+	}
+	if destinationStopSequence != nil {
+		expected++
+	}
+	if found != expected || origin.StopSequence >= destination.StopSequence {
+		return models.RouteStop{}, models.RouteStop{}, ErrInvalidSegment
+	}
+
+	return origin, destination, nil
+}
+
+// GetRouteWithStops loads a route together with its stops and stop-pair fares
+func GetRouteWithStops(db repository.DBInterface, id int) (*models.Route, error) {
+	route, err := repository.GetRouteByID(db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	route.Stops, err = repository.GetRouteStopsByRouteID(db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	route.Fares, err = repository.GetRouteFaresByRouteID(db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return route, nil
+}
+
+// CreateRoute stores a route with its stops and fares
+func CreateRoute(db *sql.DB, route *models.Route) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := StoreRoute(tx, route); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateRoute updates a route and replaces its stops and fares
+func UpdateRoute(db *sql.DB, route *models.Route) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := ReplaceRoute(tx, route); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// StoreRoute stores a route with its stops and fares within the caller's transaction
+func StoreRoute(db repository.DBInterface, route *models.Route) error {
+	if err := NormalizeRouteStops(route); err != nil {
+		return err
+	}
+	if err := ValidateYieldCaps(route.MinYieldPercent, route.MaxYieldPercent); err != nil {
+		return err
+	}
+
+	if err := repository.CreateRoute(db, route); err != nil {
+		return err
+	}
+	return saveRouteStops(db, route)
+}
+
+// ReplaceRoute updates a route within the caller's transaction. Routes given without stops keep their
+// stops, and without fares keep their fares unless the stops change. Stops can only be changed while no
+// upcoming booking depends on their sequence; otherwise only their coordinates and GTFS ids are updated.
+func ReplaceRoute(db repository.DBInterface, route *models.Route) error {
+	current, err := repository.GetRouteStopsByRouteID(db, route.ID)
+	if err != nil {
+		return err
+	}
+	keepStops := len(route.Stops) == 0
+	if keepStops {
+		route.Stops = current
+	}
+	if err := NormalizeRouteStops(route); err != nil {
+		return err
+	}
+	if err := ValidateYieldCaps(route.MinYieldPercent, route.MaxYieldPercent); err != nil {
+		return err
+	}
+
+	stopsChanged := !keepStops && StopsChanged(current, route.Stops)
+	if stopsChanged {
+		inUse, err := repository.RouteHasUpcomingBookings(db, route.ID, time.Now())
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return ErrRouteStopsInUse
+		}
+	}
+
+	if err := repository.UpdateRoute(db, route); err != nil {
+		return err
+	}
+
+	keepFares := route.Fares == nil && !stopsChanged
+	if !keepFares {
+		if err := repository.DeleteRouteFaresByRouteID(db, route.ID); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case stopsChanged:
+		if err := repository.DeleteRouteStopsByRouteID(db, route.ID); err != nil {
+			return err
+		}
+		return saveRouteStops(db, route)
+	case !keepStops:
+		for i := range route.Stops {
+			route.Stops[i].ID = current[i].ID
+			if err := repository.UpdateRouteStopDetails(db, &route.Stops[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if keepFares {
+		route.Fares, err = repository.GetRouteFaresByRouteID(db, route.ID)
+		return err
+	}
+	return saveRouteFares(db, route)
+}
+
+// StopsChanged reports whether updated stops differ from the current ones in anything bookings depend on:
+// their number, places, time zones, offsets or distances
+func StopsChanged(current, updated []models.RouteStop) bool {
+	if len(current) != len(updated) {
+		return true
+	}
+	for i, stop := range updated {
+		existing := current[i]
+		if existing.City != stop.City || existing.Terminal != stop.Terminal || existing.TimeZone != stop.TimeZone ||
+			existing.OffsetMinutes != stop.OffsetMinutes || existing.DistanceKm != stop.DistanceKm {
+			return true
+		}
+	}
+	return false
+}
+
+func saveRouteStops(db repository.DBInterface, route *models.Route) error {
+	for i := range route.Stops {
+		route.Stops[i].RouteID = route.ID
+		if err := repository.CreateRouteStop(db, &route.Stops[i]); err != nil {
+			return err
+		}
+	}
+	return saveRouteFares(db, route)
+}
+
+func saveRouteFares(db repository.DBInterface, route *models.Route) error {
+	for i := range route.Fares {
+		route.Fares[i].RouteID = route.ID
+		if err := repository.CreateRouteFare(db, &route.Fares[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
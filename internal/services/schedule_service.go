@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+var (
+	ErrInvalidSchedule  = errors.New("schedules need valid times, ISO days of week (1-7) and a validity period that does not end before it starts")
+	ErrCalendarMismatch = errors.New("the calendar must belong to the company operating the route")
+)
+
+// validateSchedule checks a schedule before it is stored. Its calendar, if any, must belong to the route's company.
+func validateSchedule(db repository.DBInterface, schedule *models.Schedule) error {
+	if _, err := utils.ParseClock(schedule.DepartureTime); err != nil {
+		return ErrInvalidSchedule
+	}
+	if _, err := utils.ParseClock(schedule.ArrivalTime); err != nil {
+		return ErrInvalidSchedule
+	}
+	if schedule.ValidUntil != nil && schedule.ValidUntil.Before(schedule.ValidFrom) {
+		return ErrInvalidSchedule
+	}
+	for _, day := range schedule.DaysOfWeek {
+		if day < 1 || day > 7 {
+			return ErrInvalidSchedule
+		}
+	}
+	// Calendar-only schedules run on their added dates alone
+	if len(schedule.DaysOfWeek) == 0 && schedule.CalendarID == nil {
+		return ErrInvalidSchedule
+	}
+
+	if schedule.CalendarID != nil {
+		route, err := repository.GetRouteByID(db, schedule.RouteID)
+		if err != nil {
+			return err
+		}
+		calendar, err := repository.GetCalendarByID(db, *schedule.CalendarID)
+		if err != nil {
+			return err
+		}
+		if calendar.CompanyID != route.CompanyID {
+			return ErrCalendarMismatch
+		}
+	}
+
+	return nil
+}
+
+// CreateSchedule validates and stores a new schedule whose vehicle is free for its departures
+func CreateSchedule(db repository.DBInterface, schedule *models.Schedule) error {
+	if err := validateSchedule(db, schedule); err != nil {
+		return err
+	}
+	if err := CheckScheduleAssignment(db, schedule); err != nil {
+		return err
+	}
+	return StoreSchedule(db, schedule)
+}
+
+// UpdateSchedule validates and stores changes to a schedule whose vehicle is free for its departures
+func UpdateSchedule(db repository.DBInterface, schedule *models.Schedule) error {
+	if err := validateSchedule(db, schedule); err != nil {
+		return err
+	}
+	if err := CheckScheduleAssignment(db, schedule); err != nil {
+		return err
+	}
+	return ReplaceSchedule(db, schedule)
+}
+
+// StoreSchedule validates and stores a new schedule without checking its vehicle's other assignments,
+// for bulk changes that check them once all schedules are stored
+func StoreSchedule(db repository.DBInterface, schedule *models.Schedule) error {
+	if err := validateSchedule(db, schedule); err != nil {
+		return err
+	}
+	if schedule.DaysOfWeek == nil {
+		schedule.DaysOfWeek = []int64{}
+	}
+	return repository.CreateSchedule(db, schedule)
+}
+
+// ReplaceSchedule validates and stores changes to a schedule without checking its vehicle's other assignments
+func ReplaceSchedule(db repository.DBInterface, schedule *models.Schedule) error {
+	if err := validateSchedule(db, schedule); err != nil {
+		return err
+	}
+	if schedule.DaysOfWeek == nil {
+		schedule.DaysOfWeek = []int64{}
+	}
+	return repository.UpdateSchedule(db, schedule)
+}
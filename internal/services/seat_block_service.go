@@ -0,0 +1,139 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+)
+
+var (
+	ErrInvalidSeatBlock  = errors.New("invalid seat block")
+	ErrSeatBlockNotFound = errors.New("seat block not found")
+)
+
+// SeatBlockRequest asks to block specific seats or a number of seats
+type SeatBlockRequest struct {
+	SeatIDs              []int
+	SeatCount            int
+	Reason               string
+	Note                 string
+	ReleaseMinutesBefore *int
+	CreatedBy            int
+}
+
+// ValidateSeatBlockRequest checks that a request blocks either seats or a number of seats, for a known reason
+func ValidateSeatBlockRequest(request SeatBlockRequest) error {
+	switch {
+	case (len(request.SeatIDs) == 0) == (request.SeatCount == 0):
+		return fmt.Errorf("%w: give either seat_ids or seat_count", ErrInvalidSeatBlock)
+	case request.SeatCount < 0:
+		return fmt.Errorf("%w: seat_count must be positive", ErrInvalidSeatBlock)
+	case request.ReleaseMinutesBefore != nil && *request.ReleaseMinutesBefore < 0:
+		return fmt.Errorf("%w: release_minutes_before cannot be negative", ErrInvalidSeatBlock)
+	}
+
+	switch request.Reason {
+	case models.SeatBlockReasonStaff, models.SeatBlockReasonEscort, models.SeatBlockReasonAllotment, models.SeatBlockReasonOther:
+		return nil
+	}
+	return fmt.Errorf("%w: reason must be staff, escort, allotment or other", ErrInvalidSeatBlock)
+}
+
+// BlockSeats keeps seats off sale on the trip of a schedule on a travel date, or on every trip of the
+// schedule when no date is given. Seats of a trip must still be free; seats of the schedule need only
+// belong to its vehicle, bookings already made keeping theirs.
+func BlockSeats(db *sql.DB, scheduleID int, travelDate *time.Time, request SeatBlockRequest) ([]models.SeatBlock, error) {
+	if err := ValidateSeatBlockRequest(request); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	schedule, err := repository.GetScheduleByID(tx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	var seats []models.Seat
+	if travelDate != nil {
+		if err := repository.LockDeparture(tx, scheduleID, travelDate.Format("2006-01-02")); err != nil {
+			return nil, err
+		}
+		seats, err = repository.GetAvailableSeatsForSchedule(tx, scheduleID, travelDate.Format("2006-01-02"))
+	} else {
+		seats, err = repository.GetSeatsByVehicleID(tx, schedule.VehicleID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if request.SeatCount > len(seats) {
+		return nil, ErrSeatsUnavailable
+	}
+	seatMap := make(map[int]bool)
+	for _, seat := range seats {
+		seatMap[seat.ID] = true
+	}
+	for _, seatID := range request.SeatIDs {
+		if !seatMap[seatID] {
+			return nil, ErrSeatsUnavailable
+		}
+		seatMap[seatID] = false
+	}
+
+	block := models.SeatBlock{
+		ScheduleID:           scheduleID,
+		TravelDate:           travelDate,
+		Reason:               request.Reason,
+		Note:                 request.Note,
+		ReleaseMinutesBefore: request.ReleaseMinutesBefore,
+		CreatedBy:            &request.CreatedBy,
+	}
+	blocks := []models.SeatBlock{}
+	if request.SeatCount > 0 {
+		block.SeatCount = &request.SeatCount
+		if err := repository.CreateSeatBlock(tx, &block); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	for _, seatID := range request.SeatIDs {
+		seatBlock := block
+		seatBlock.SeatID = &seatID
+		if err := repository.CreateSeatBlock(tx, &seatBlock); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, seatBlock)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// ReleaseSeatBlock lifts a block of a schedule. With a travel date, only blocks of the trip of that date
+// may be lifted.
+func ReleaseSeatBlock(db repository.DBInterface, scheduleID int, travelDate *time.Time, blockID int) error {
+	block, err := repository.GetSeatBlockByID(db, blockID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSeatBlockNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	sameDeparture := travelDate == nil || (block.TravelDate != nil && block.TravelDate.Format("2006-01-02") == travelDate.Format("2006-01-02"))
+	if block.ScheduleID != scheduleID || !sameDeparture {
+		return ErrSeatBlockNotFound
+	}
+	return repository.DeleteSeatBlock(db, block.ID)
+}
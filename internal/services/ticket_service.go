@@ -0,0 +1,166 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+var (
+	ErrTicketsUnavailable = errors.New("tickets are only issued for confirmed, paid bookings")
+	ErrInvalidTicket      = errors.New("ticket is not valid")
+	ErrTicketNotYetValid  = errors.New("ticket is not valid yet")
+	ErrTicketExpired      = errors.New("ticket has expired")
+)
+
+// A ticket can be scanned from this long before boarding until this long after the scheduled
+// arrival, so that delayed departures are still covered
+const (
+	TicketValidBefore = 24 * time.Hour
+	TicketValidAfter  = 12 * time.Hour
+)
+
+// TicketClaims is the signed content of a ticket, one per booked seat
+type TicketClaims struct {
+	BookingCode             string `json:"booking_code"`
+	BookingSeatID           int    `json:"booking_seat_id"`
+	ScheduleID              int    `json:"schedule_id"`
+	TravelDate              string `json:"travel_date"`
+	SeatNumber              string `json:"seat_number"`
+	PassengerName           string `json:"passenger_name"`
+	OriginStopSequence      int    `json:"origin_stop_sequence"`
+	DestinationStopSequence int    `json:"destination_stop_sequence"`
+	ValidFrom               int64  `json:"valid_from"`
+	ValidUntil              int64  `json:"valid_until"`
+}
+
+// Ticket is a signed ticket with the journey details printed next to its QR code
+type Ticket struct {
+	Claims            TicketClaims `json:"claims"`
+	Payload           string       `json:"payload"`
+	OriginStop        string       `json:"origin_stop"`
+	DestinationStop   string       `json:"destination_stop"`
+	DepartureDatetime time.Time    `json:"departure_datetime"`
+	ArrivalDatetime   time.Time    `json:"arrival_datetime"`
+	// Ancillaries of the booking, printed on each of its tickets
+	Ancillaries []models.BookingAncillary `json:"ancillaries"`
+}
+
+// SignTicket serializes and signs ticket claims
+func SignTicket(key *utils.TicketKey, claims TicketClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	return key.SignTicket(payload), nil
+}
+
+// VerifyTicket checks the signature and validity period of a ticket, as scanners do offline
+func VerifyTicket(publicKey ed25519.PublicKey, payload string, now time.Time) (*TicketClaims, error) {
+	content, err := utils.OpenTicket(publicKey, payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTicket, err)
+	}
+	var claims TicketClaims
+	if err := json.Unmarshal(content, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTicket, err)
+	}
+
+	if now.Unix() < claims.ValidFrom {
+		return &claims, ErrTicketNotYetValid
+	}
+	if now.Unix() > claims.ValidUntil {
+		return &claims, ErrTicketExpired
+	}
+	return &claims, nil
+}
+
+// BookingTickets issues a signed ticket for each seat of a booking
+func BookingTickets(db repository.DBInterface, key *utils.TicketKey, booking *models.Booking) ([]Ticket, error) {
+	if booking.BookingStatus == "cancelled" {
+		return nil, ErrBookingCancelled
+	}
+	if booking.BookingStatus != "confirmed" || booking.PaymentStatus != "paid" {
+		return nil, ErrTicketsUnavailable
+	}
+
+	schedule, err := repository.GetScheduleByID(db, booking.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	stops, err := repository.GetRouteStopsByRouteID(db, schedule.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	origin, destination, err := ResolveSegment(stops, booking.OriginStopSequence, booking.DestinationStopSequence)
+	if err != nil {
+		return nil, err
+	}
+	boarding, alighting, err := SegmentDatetimes(schedule.DepartureTime, stops, origin, destination, booking.TravelDate)
+	if err != nil {
+		return nil, err
+	}
+
+	trip, err := repository.GetTripByScheduleAndDate(db, booking.ScheduleID, booking.TravelDate)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if trip != nil && trip.Status == models.TripStatusCancelled {
+		return nil, ErrTripCancelled
+	}
+
+	seats, err := repository.GetBookedSeatsByBookingID(db, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	ancillaries, err := repository.GetBookingAncillaries(db, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tickets := []Ticket{}
+	for _, seat := range seats {
+		claims := TicketClaims{
+			BookingCode:             booking.BookingCode,
+			BookingSeatID:           seat.BookingSeatID,
+			ScheduleID:              booking.ScheduleID,
+			TravelDate:              booking.TravelDate.Format(utils.DateLayout),
+			SeatNumber:              seat.SeatNumber,
+			PassengerName:           booking.PassengerName,
+			OriginStopSequence:      origin.StopSequence,
+			DestinationStopSequence: destination.StopSequence,
+			ValidFrom:               boarding.Add(-TicketValidBefore).Unix(),
+			ValidUntil:              alighting.Add(TicketValidAfter).Unix(),
+		}
+		payload, err := SignTicket(key, claims)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, Ticket{
+			Claims:            claims,
+			Payload:           payload,
+			OriginStop:        stopName(origin),
+			DestinationStop:   stopName(destination),
+			DepartureDatetime: boarding,
+			ArrivalDatetime:   alighting,
+			Ancillaries:       ancillaries,
+		})
+	}
+
+	return tickets, nil
+}
+
+// stopName names a stop by its city and terminal
+func stopName(stop models.RouteStop) string {
+	if stop.Terminal == "" {
+		return stop.City
+	}
+	return stop.City + " - " + stop.Terminal
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+const (
+	// TripPositionMargin is how long before departure and after arrival positions still belong to a trip
+	TripPositionMargin = 30 * time.Minute
+	// PositionMaxAge is how old a position may be before it is no longer used for live information
+	PositionMaxAge = 30 * time.Minute
+	// maxOffRouteKm is how far from the route a position may be and still be matched to it
+	maxOffRouteKm = 5.0
+)
+
+var (
+	ErrInvalidPosition  = errors.New("positions need a latitude between -90 and 90 and a longitude between -180 and 180")
+	ErrTrackingFinished = errors.New("the booking's trip has already arrived")
+)
+
+// StopEstimate is the scheduled and estimated time of a trip at one stop
+type StopEstimate struct {
+	StopSequence int       `json:"stop_sequence"`
+	City         string    `json:"city"`
+	Terminal     string    `json:"terminal"`
+	ScheduledAt  time.Time `json:"scheduled_at"`
+	EstimatedAt  time.Time `json:"estimated_at"`
+	Passed       bool      `json:"passed"`
+}
+
+// TripProgress is where a trip stands along its stops
+type TripProgress struct {
+	DelayMinutes         int            `json:"delay_minutes"`
+	NextStopSequence     *int           `json:"next_stop_sequence"`
+	DistanceToNextStopKm *float64       `json:"distance_to_next_stop_km"`
+	Stops                []StopEstimate `json:"stops"`
+}
+
+// BookingTracking is the live view of a booking's trip for its passenger
+type BookingTracking struct {
+	BookingID   int                     `json:"booking_id"`
+	TripID      *int                    `json:"trip_id"`
+	TripStatus  string                  `json:"trip_status"`
+	Position    *models.VehiclePosition `json:"position"`
+	Origin      StopEstimate            `json:"origin"`
+	Destination StopEstimate            `json:"destination"`
+	Progress    TripProgress            `json:"progress"`
+}
+
+// RecordVehiclePositions stores positions reported by a vehicle, linking each one to the trip
+// the vehicle was running at that time, if any
+func RecordVehiclePositions(db *sql.DB, vehicleID int, positions []models.VehiclePosition) ([]models.VehiclePosition, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var trip *models.Trip
+	for i := range positions {
+		position := &positions[i]
+		if position.Latitude < -90 || position.Latitude > 90 || position.Longitude < -180 || position.Longitude > 180 {
+			return nil, ErrInvalidPosition
+		}
+		position.VehicleID = vehicleID
+
+		// Batches usually belong to one trip, so the last match is tried first
+		if trip == nil || position.RecordedAt.Before(trip.DepartureDatetime.Add(-TripPositionMargin)) ||
+			position.RecordedAt.After(trip.ArrivalDatetime.Add(time.Duration(trip.DelayMinutes)*time.Minute+TripPositionMargin)) {
+			trip, err = repository.GetVehicleTripAt(tx, vehicleID, position.RecordedAt, TripPositionMargin)
+			if err == sql.ErrNoRows {
+				trip, err = nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if trip != nil {
+			position.TripID = &trip.ID
+		}
+
+		if err := repository.CreateVehiclePosition(tx, position); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// EstimateProgress estimates a trip's times at its stops. With a position, the vehicle is placed on the
+// closest stretch between two stops and the delay is how late it is there; running early counts as on time,
+// since buses wait at stops. Without one, the reported delay is used.
+func EstimateProgress(stops []models.RouteStop, departure time.Time, reportedDelayMinutes int, position *models.VehiclePosition, now time.Time) TripProgress {
+	progress := TripProgress{DelayMinutes: reportedDelayMinutes, Stops: make([]StopEstimate, len(stops))}
+	scheduled := make([]time.Time, len(stops))
+	for i, stop := range stops {
+		scheduled[i] = departure.Add(time.Duration(stop.OffsetMinutes) * time.Minute)
+	}
+
+	// Index of the last passed stop, -1 before departure
+	lastPassed := -1
+	located := false
+	if position != nil {
+		best := math.Inf(1)
+		for i := 0; i+1 < len(stops); i++ {
+			from, to := stops[i], stops[i+1]
+			if from.Latitude == nil || from.Longitude == nil || to.Latitude == nil || to.Longitude == nil {
+				continue
+			}
+			fraction, distance := utils.ProjectOnSegment(position.Latitude, position.Longitude, *from.Latitude, *from.Longitude, *to.Latitude, *to.Longitude)
+			if distance >= best || distance > maxOffRouteKm {
+				continue
+			}
+			best = distance
+			located = true
+
+			expected := scheduled[i].Add(time.Duration(fraction * float64(scheduled[i+1].Sub(scheduled[i]))))
+			progress.DelayMinutes = int(math.Max(0, math.Round(position.RecordedAt.Sub(expected).Minutes())))
+
+			lastPassed = i
+			if fraction == 0 && !position.RecordedAt.After(scheduled[i]) {
+				lastPassed = i - 1
+			}
+			distanceToNext := utils.HaversineKm(position.Latitude, position.Longitude, *to.Latitude, *to.Longitude)
+			progress.DistanceToNextStopKm = &distanceToNext
+		}
+	}
+
+	delay := time.Duration(progress.DelayMinutes) * time.Minute
+	for i, stop := range stops {
+		estimate := StopEstimate{
+			StopSequence: stop.StopSequence,
+			City:         stop.City,
+			Terminal:     stop.Terminal,
+			ScheduledAt:  scheduled[i],
+			EstimatedAt:  scheduled[i].Add(delay),
+		}
+		if loc, err := utils.LoadLocation(stop.TimeZone); err == nil {
+			estimate.ScheduledAt = estimate.ScheduledAt.In(loc)
+			estimate.EstimatedAt = estimate.EstimatedAt.In(loc)
+		}
+
+		if located {
+			estimate.Passed = i <= lastPassed
+		} else {
+			estimate.Passed = !estimate.EstimatedAt.After(now)
+		}
+		if !estimate.Passed && progress.NextStopSequence == nil {
+			sequence := stop.StopSequence
+			progress.NextStopSequence = &sequence
+		}
+		progress.Stops[i] = estimate
+	}
+	if !located {
+		progress.DistanceToNextStopKm = nil
+	}
+
+	return progress
+}
+
+// TrackBooking returns the live position and estimated times of a booking's trip. Positions older than
+// PositionMaxAge are shown but not used for estimates.
+func TrackBooking(db *sql.DB, booking *models.Booking, now time.Time) (*BookingTracking, error) {
+	schedule, err := repository.GetScheduleByID(db, booking.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	stops, err := repository.GetRouteStopsByRouteID(db, schedule.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	origin, destination, err := ResolveSegment(stops, booking.OriginStopSequence, booking.DestinationStopSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	tracking := &BookingTracking{BookingID: booking.ID, TripStatus: models.TripStatusScheduled}
+	departure, _, err := scheduleDatetimes(schedule, stops, booking.TravelDate)
+	if err != nil {
+		return nil, err
+	}
+
+	delayMinutes := 0
+	var livePosition *models.VehiclePosition
+	trip, err := repository.GetTripByScheduleAndDate(db, booking.ScheduleID, booking.TravelDate)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if trip != nil {
+		if trip.Status == models.TripStatusCancelled {
+			return nil, ErrTripCancelled
+		}
+		tracking.TripID = &trip.ID
+		tracking.TripStatus = trip.Status
+		departure = trip.DepartureDatetime
+		delayMinutes = trip.DelayMinutes
+
+		tracking.Position, err = repository.GetLatestTripPosition(db, trip.ID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if tracking.Position != nil && now.Sub(tracking.Position.RecordedAt) <= PositionMaxAge {
+			livePosition = tracking.Position
+		}
+	}
+
+	tracking.Progress = EstimateProgress(stops, departure, delayMinutes, livePosition, now)
+	for _, estimate := range tracking.Progress.Stops {
+		switch estimate.StopSequence {
+		case origin.StopSequence:
+			tracking.Origin = estimate
+		case destination.StopSequence:
+			tracking.Destination = estimate
+		}
+	}
+	if tracking.Destination.Passed && now.Sub(tracking.Destination.EstimatedAt) > TripPositionMargin {
+		return nil, ErrTrackingFinished
+	}
+
+	return tracking, nil
+}
@@ -0,0 +1,418 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+var (
+	ErrTripCancelled    = errors.New("trip is already cancelled")
+	ErrInvalidDelay     = errors.New("delay must be greater than zero")
+	ErrReasonRequired   = errors.New("a reason is required")
+	ErrScheduleInactive = errors.New("schedule is not active")
+)
+
+// rebookSearchDays is how many days after the disrupted trip we look for an alternative departure
+const rebookSearchDays = 2
+
+// RebookOffer is an alternative departure with enough free seats for an affected booking
+type RebookOffer struct {
+	ScheduleID        int       `json:"schedule_id"`
+	TravelDate        string    `json:"travel_date"`
+	DepartureDatetime time.Time `json:"departure_datetime"`
+	AvailableSeats    int       `json:"available_seats"`
+}
+
+// BookingImpact describes what happened to a booking as a consequence of a trip disruption
+type BookingImpact struct {
+	BookingID      int          `json:"booking_id"`
+	BookingCode    string       `json:"booking_code"`
+	UserID         int          `json:"user_id"`
+	BookingStatus  string       `json:"booking_status"`
+	PaymentStatus  string       `json:"payment_status"`
+	RefundAmount   float64      `json:"refund_amount"`
+	RebookOffer    *RebookOffer `json:"rebook_offer"`
+	NotificationID int          `json:"notification_id"`
+	// The passenger may cancel for a full refund or exchange free of charge
+	DelayCompensation bool `json:"delay_compensation"`
+}
+
+// TripDisruption is the result of cancelling or delaying a trip
+type TripDisruption struct {
+	Trip             *models.Trip    `json:"trip"`
+	AffectedBookings []BookingImpact `json:"affected_bookings"`
+}
+
+// scheduleDatetimes combines a travel date with the schedule departure and arrival times, which are local
+// to the first and last stop of the route. Overnight arrivals are placed on the following day.
+func scheduleDatetimes(schedule *models.Schedule, stops []models.RouteStop, travelDate time.Time) (time.Time, time.Time, error) {
+	if len(stops) < 2 {
+		return time.Time{}, time.Time{}, ErrInvalidStops
+	}
+
+	departureLoc, err := utils.LoadLocation(stops[0].TimeZone)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	arrivalLoc, err := utils.LoadLocation(stops[len(stops)-1].TimeZone)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return utils.DepartureArrival(travelDate, schedule.DepartureTime, departureLoc, schedule.ArrivalTime, arrivalLoc)
+}
+
+// stopDatetime returns when a departure reaches a stop, in the time zone of the stop
+func stopDatetime(departure time.Time, stop models.RouteStop) (time.Time, error) {
+	loc, err := utils.LoadLocation(stop.TimeZone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return departure.Add(time.Duration(stop.OffsetMinutes) * time.Minute).In(loc), nil
+}
+
+// SegmentDatetimes returns when a departure leaving the first stop at a local clock time on a date
+// reaches the origin and destination stops of a segment, each in the time zone of its stop
+func SegmentDatetimes(departureClock string, stops []models.RouteStop, origin, destination models.RouteStop, travelDate time.Time) (time.Time, time.Time, error) {
+	if len(stops) == 0 {
+		return time.Time{}, time.Time{}, ErrInvalidStops
+	}
+	loc, err := utils.LoadLocation(stops[0].TimeZone)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	departure, err := utils.LocalDatetime(travelDate, departureClock, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	boarding, err := stopDatetime(departure, origin)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	alighting, err := stopDatetime(departure, destination)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return boarding, alighting, nil
+}
+
+// LocalizeTrip expresses the departure and arrival of a trip in the time zones of its first and last stop
+func LocalizeTrip(db repository.DBInterface, trip *models.Trip) error {
+	schedule, err := repository.GetScheduleByID(db, trip.ScheduleID)
+	if err != nil {
+		return err
+	}
+	stops, err := repository.GetRouteStopsByRouteID(db, schedule.RouteID)
+	if err != nil {
+		return err
+	}
+	if len(stops) < 2 {
+		return ErrInvalidStops
+	}
+
+	departureLoc, err := utils.LoadLocation(stops[0].TimeZone)
+	if err != nil {
+		return err
+	}
+	arrivalLoc, err := utils.LoadLocation(stops[len(stops)-1].TimeZone)
+	if err != nil {
+		return err
+	}
+	trip.DepartureDatetime = trip.DepartureDatetime.In(departureLoc)
+	trip.ArrivalDatetime = trip.ArrivalDatetime.In(arrivalLoc)
+	return nil
+}
+
+// MaterializeTrip returns the trip of a schedule on a travel date, creating it if needed
+func MaterializeTrip(db repository.DBInterface, scheduleID int, travelDate time.Time) (*models.Trip, error) {
+	schedule, err := repository.GetScheduleByID(db, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if !schedule.IsActive {
+		return nil, ErrScheduleInactive
+	}
+	runs, err := ScheduleRunsOn(db, schedule, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	if !runs {
+		return nil, ErrScheduleNotRunning
+	}
+
+	stops, err := repository.GetRouteStopsByRouteID(db, schedule.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	departure, arrival, err := scheduleDatetimes(schedule, stops, travelDate)
+	if err != nil {
+		return nil, err
+	}
+
+	trip := models.Trip{
+		ScheduleID:        schedule.ID,
+		VehicleID:         schedule.VehicleID,
+		TravelDate:        travelDate,
+		DepartureDatetime: departure,
+		ArrivalDatetime:   arrival,
+	}
+	if err := repository.CreateTrip(db, &trip); err != nil {
+		return nil, err
+	}
+	if err := LocalizeTrip(db, &trip); err != nil {
+		return nil, err
+	}
+
+	return &trip, nil
+}
+
+// CancelTrip cancels a trip, cancels and refunds every active booking on it,
+// offers an alternative departure and notifies the passengers.
+func CancelTrip(db *sql.DB, tripID int, reason string) (*TripDisruption, error) {
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	trip, err := repository.GetTripByID(tx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.Status == models.TripStatusCancelled {
+		return nil, ErrTripCancelled
+	}
+	if err := LocalizeTrip(tx, trip); err != nil {
+		return nil, err
+	}
+
+	trip.Status = models.TripStatusCancelled
+	trip.StatusReason = reason
+	if err := repository.UpdateTripStatus(tx, trip); err != nil {
+		return nil, err
+	}
+
+	bookings, err := repository.GetActiveBookingsForTrip(tx, trip.ScheduleID, trip.TravelDate)
+	if err != nil {
+		return nil, err
+	}
+
+	disruption := &TripDisruption{Trip: trip, AffectedBookings: []BookingImpact{}}
+	for i := range bookings {
+		booking := &bookings[i]
+
+		refundAmount, err := refundBooking(tx, booking, booking.TotalAmount)
+		if err != nil {
+			return nil, err
+		}
+		paymentStatus := booking.PaymentStatus
+		if refundAmount > 0 {
+			paymentStatus = "refunded"
+		}
+		if err := repository.UpdateBookingStatus(tx, booking.ID, "cancelled", paymentStatus); err != nil {
+			return nil, err
+		}
+
+		offer, err := findRebookOffer(tx, trip, booking.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		message := fmt.Sprintf("Your trip on %s has been cancelled: %s.", trip.DepartureDatetime.Format("2006-01-02 15:04"), reason)
+		if refundAmount > 0 {
+			message += fmt.Sprintf(" A full refund of %.2f has been issued.", refundAmount)
+		}
+		if offer != nil {
+			message += fmt.Sprintf(" You can rebook free of charge on the departure of %s.", offer.DepartureDatetime.Format("2006-01-02 15:04"))
+		}
+
+		notification := models.Notification{
+			UserID:           booking.UserID,
+			BookingID:        &booking.ID,
+			NotificationType: "trip_cancelled",
+			Title:            "Trip cancelled",
+			Message:          message,
+			Data: map[string]interface{}{
+				"trip_id":       trip.ID,
+				"reason":        reason,
+				"refund_amount": refundAmount,
+				"rebook_offer":  offer,
+			},
+		}
+		if err := repository.CreateNotification(tx, &notification); err != nil {
+			return nil, err
+		}
+
+		disruption.AffectedBookings = append(disruption.AffectedBookings, BookingImpact{
+			BookingID:      booking.ID,
+			BookingCode:    booking.BookingCode,
+			UserID:         booking.UserID,
+			BookingStatus:  "cancelled",
+			PaymentStatus:  paymentStatus,
+			RefundAmount:   refundAmount,
+			RebookOffer:    offer,
+			NotificationID: notification.ID,
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return disruption, nil
+}
+
+// DelayTrip records a delay on a trip and notifies the passengers. Delays of at least
+// compensationMinutes entitle the bookings to a full refund or a free rebooking, which
+// the passenger claims by cancelling or exchanging the booking.
+func DelayTrip(db *sql.DB, tripID int, delayMinutes int, reason string, compensationMinutes int) (*TripDisruption, error) {
+	if delayMinutes <= 0 {
+		return nil, ErrInvalidDelay
+	}
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	trip, err := repository.GetTripByID(tx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.Status == models.TripStatusCancelled {
+		return nil, ErrTripCancelled
+	}
+	if err := LocalizeTrip(tx, trip); err != nil {
+		return nil, err
+	}
+
+	trip.Status = models.TripStatusDelayed
+	trip.DelayMinutes = delayMinutes
+	trip.StatusReason = reason
+	if err := repository.UpdateTripStatus(tx, trip); err != nil {
+		return nil, err
+	}
+
+	bookings, err := repository.GetActiveBookingsForTrip(tx, trip.ScheduleID, trip.TravelDate)
+	if err != nil {
+		return nil, err
+	}
+
+	compensated := delayMinutes >= compensationMinutes
+	expectedDeparture := trip.DepartureDatetime.Add(time.Duration(delayMinutes) * time.Minute)
+
+	disruption := &TripDisruption{Trip: trip, AffectedBookings: []BookingImpact{}}
+	for i := range bookings {
+		booking := &bookings[i]
+
+		var offer *RebookOffer
+		message := fmt.Sprintf("Your trip on %s is delayed by %d minutes (%s). Expected departure: %s.",
+			trip.DepartureDatetime.Format("2006-01-02 15:04"), delayMinutes, reason, expectedDeparture.Format("2006-01-02 15:04"))
+		if compensated {
+			if err := repository.SetDelayCompensation(tx, booking.ID); err != nil {
+				return nil, err
+			}
+			booking.DelayCompensation = true
+
+			offer, err = findRebookOffer(tx, trip, booking.ID)
+			if err != nil {
+				return nil, err
+			}
+			message += " You are entitled to a full refund by cancelling your booking, or to rebook free of charge by exchanging it"
+			if offer != nil {
+				message += fmt.Sprintf(", e.g. on the departure of %s", offer.DepartureDatetime.Format("2006-01-02 15:04"))
+			}
+			message += "."
+		}
+
+		notification := models.Notification{
+			UserID:           booking.UserID,
+			BookingID:        &booking.ID,
+			NotificationType: "trip_delayed",
+			Title:            "Trip delayed",
+			Message:          message,
+			Data: map[string]interface{}{
+				"trip_id":            trip.ID,
+				"reason":             reason,
+				"delay_minutes":      delayMinutes,
+				"expected_departure": expectedDeparture,
+				"refund_eligible":    compensated,
+				"rebook_offer":       offer,
+			},
+		}
+		if err := repository.CreateNotification(tx, &notification); err != nil {
+			return nil, err
+		}
+
+		disruption.AffectedBookings = append(disruption.AffectedBookings, BookingImpact{
+			BookingID:         booking.ID,
+			BookingCode:       booking.BookingCode,
+			UserID:            booking.UserID,
+			BookingStatus:     booking.BookingStatus,
+			PaymentStatus:     booking.PaymentStatus,
+			RebookOffer:       offer,
+			NotificationID:    notification.ID,
+			DelayCompensation: booking.DelayCompensation,
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return disruption, nil
+}
+
+// findRebookOffer looks for the first later departure of the same route with enough free seats for the booking
+func findRebookOffer(db repository.DBInterface, trip *models.Trip, bookingID int) (*RebookOffer, error) {
+	schedule, err := repository.GetScheduleByID(db, trip.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookingSeats, err := repository.GetBookingSeatsByBookingID(db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	departures, err := repository.GetAlternativeDepartures(db, schedule.RouteID, trip.DepartureDatetime, rebookSearchDays, trip.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Offers are shown in the local time of the departure, like the trip itself
+	departureLoc := trip.DepartureDatetime.Location()
+
+	for _, departure := range departures {
+		travelDate := departure.TravelDate.Format("2006-01-02")
+		seats, err := repository.GetAvailableSeatsForSchedule(db, departure.ScheduleID, travelDate)
+		if err != nil {
+			return nil, err
+		}
+		if len(seats) >= len(bookingSeats) {
+			return &RebookOffer{
+				ScheduleID:        departure.ScheduleID,
+				TravelDate:        travelDate,
+				DepartureDatetime: departure.DepartureDatetime.In(departureLoc),
+				AvailableSeats:    len(seats),
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
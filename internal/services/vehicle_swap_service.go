@@ -0,0 +1,342 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+)
+
+const (
+	SeatMatchNumber = "number"
+	SeatMatchType   = "type"
+	SeatMatchAny    = "any"
+)
+
+var (
+	ErrSeatsUnmatched      = errors.New("some booked seats have no equivalent on the new vehicle")
+	ErrGroupSeatsUnmatched = errors.New("some seats held for a group have no equivalent on the new vehicle")
+)
+
+// SeatChange is where a booked seat goes when its trip changes vehicle.
+// Match tells how the new seat was chosen and is empty when no seat was left.
+type SeatChange struct {
+	models.BookedSeat
+	ToSeatID       *int   `json:"to_seat_id"`
+	ToSeatNumber   string `json:"to_seat_number,omitempty"`
+	Match          string `json:"match,omitempty"`
+	NotificationID *int   `json:"notification_id,omitempty"`
+}
+
+// HeldSeatChange is where a seat held without a booking goes when its trip changes vehicle
+type HeldSeatChange struct {
+	models.HeldSeat
+	ToSeatID     *int   `json:"to_seat_id"`
+	ToSeatNumber string `json:"to_seat_number,omitempty"`
+	Match        string `json:"match,omitempty"`
+}
+
+// VehicleSwap is the result of moving a trip to another vehicle
+type VehicleSwap struct {
+	Trip          *models.Trip `json:"trip"`
+	FromVehicleID int          `json:"from_vehicle_id"`
+	ToVehicleID   int          `json:"to_vehicle_id"`
+	Seats         []SeatChange `json:"seats"`
+	Unmatched     []SeatChange `json:"unmatched"`
+	// Seats held without a booking, for groups, seat blocks and waitlist offers
+	Holds          []HeldSeatChange `json:"holds"`
+	UnmatchedHolds []HeldSeatChange `json:"unmatched_holds"`
+}
+
+// bookedSegment returns the stops a booked seat is held between; bookings without a segment cover the whole route
+func bookedSegment(seat models.BookedSeat) (int, int) {
+	origin, destination := 0, math.MaxInt32
+	if seat.OriginStopSequence != nil {
+		origin = *seat.OriginStopSequence
+	}
+	if seat.DestinationStopSequence != nil {
+		destination = *seat.DestinationStopSequence
+	}
+	return origin, destination
+}
+
+// MapSeats finds a seat of the new vehicle for each booked seat: the seat with the same number,
+// then a seat of the same type, then any seat. Exact matches are served first, and a seat is shared
+// by bookings only when their segments do not overlap. Seats that are not available are skipped.
+func MapSeats(booked []models.BookedSeat, seats []models.Seat) []SeatChange {
+	changes := make([]SeatChange, len(booked))
+	for i := range booked {
+		changes[i] = SeatChange{BookedSeat: booked[i]}
+	}
+
+	taken := make(map[int][][2]int)
+	isFree := func(seat models.Seat, booked models.BookedSeat) bool {
+		if !seat.IsAvailable {
+			return false
+		}
+		origin, destination := bookedSegment(booked)
+		for _, segment := range taken[seat.ID] {
+			if segment[0] < destination && origin < segment[1] {
+				return false
+			}
+		}
+		return true
+	}
+
+	matches := []struct {
+		name    string
+		matches func(seat models.Seat, booked models.BookedSeat) bool
+	}{
+		{SeatMatchNumber, func(seat models.Seat, booked models.BookedSeat) bool { return seat.SeatNumber == booked.SeatNumber }},
+		{SeatMatchType, func(seat models.Seat, booked models.BookedSeat) bool { return seat.SeatType == booked.SeatType }},
+		{SeatMatchAny, func(models.Seat, models.BookedSeat) bool { return true }},
+	}
+	for _, match := range matches {
+		for i := range changes {
+			change := &changes[i]
+			if change.ToSeatID != nil {
+				continue
+			}
+			for _, seat := range seats {
+				if !match.matches(seat, change.BookedSeat) || !isFree(seat, change.BookedSeat) {
+					continue
+				}
+				origin, destination := bookedSegment(change.BookedSeat)
+				taken[seat.ID] = append(taken[seat.ID], [2]int{origin, destination})
+				seatID := seat.ID
+				change.ToSeatID = &seatID
+				change.ToSeatNumber = seat.SeatNumber
+				change.Match = match.name
+				break
+			}
+		}
+	}
+
+	return changes
+}
+
+// MapTripSeats maps the booked seats and then the held seats of a trip to the seats of a new vehicle,
+// as MapSeats does, so that bookings and holds never share a seat on overlapping segments
+func MapTripSeats(booked []models.BookedSeat, held []models.HeldSeat, seats []models.Seat) ([]SeatChange, []HeldSeatChange) {
+	all := append([]models.BookedSeat(nil), booked...)
+	for _, hold := range held {
+		all = append(all, models.BookedSeat{
+			SeatID:                  hold.SeatID,
+			SeatNumber:              hold.SeatNumber,
+			SeatType:                hold.SeatType,
+			OriginStopSequence:      hold.OriginStopSequence,
+			DestinationStopSequence: hold.DestinationStopSequence,
+		})
+	}
+
+	changes := MapSeats(all, seats)
+	holds := make([]HeldSeatChange, len(held))
+	for i, hold := range held {
+		change := changes[len(booked)+i]
+		holds[i] = HeldSeatChange{HeldSeat: hold, ToSeatID: change.ToSeatID, ToSeatNumber: change.ToSeatNumber, Match: change.Match}
+	}
+	return changes[:len(booked)], holds
+}
+
+// SwapTripVehicle moves a trip to another vehicle, moves its booked seats and the seats held for groups,
+// seat blocks and waitlist offers to equivalent seats of the new vehicle and notifies the passengers whose
+// seat number changed. Unless allowUnmatched is set, nothing is changed when a booked or held seat has no
+// equivalent, and the swap is returned with ErrSeatsUnmatched; when it is, unmatched blocks of the trip are
+// lifted and unmatched waitlist offers withdrawn. Groups must keep all their seats: the swap is always
+// refused with ErrGroupSeatsUnmatched otherwise.
+func SwapTripVehicle(db *sql.DB, tripID, vehicleID int, allowUnmatched bool) (*VehicleSwap, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	trip, err := repository.GetTripByID(tx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	swap, err := swapTripVehicle(tx, trip, vehicleID, allowUnmatched)
+	if err != nil {
+		return swap, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return swap, nil
+}
+
+func swapTripVehicle(db repository.DBInterface, trip *models.Trip, vehicleID int, allowUnmatched bool) (*VehicleSwap, error) {
+	if trip.Status == models.TripStatusCancelled {
+		return nil, ErrTripCancelled
+	}
+	if err := LocalizeTrip(db, trip); err != nil {
+		return nil, err
+	}
+
+	swap := &VehicleSwap{
+		Trip: trip, FromVehicleID: trip.VehicleID, ToVehicleID: vehicleID,
+		Seats: []SeatChange{}, Unmatched: []SeatChange{}, Holds: []HeldSeatChange{}, UnmatchedHolds: []HeldSeatChange{},
+	}
+	if trip.VehicleID == vehicleID {
+		return swap, nil
+	}
+	if err := repository.LockVehicle(db, vehicleID); err != nil {
+		return nil, err
+	}
+	if err := CheckTripAssignment(db, trip, vehicleID); err != nil {
+		return nil, err
+	}
+	// Seats sold or held meanwhile would stay on the old vehicle
+	if err := repository.LockDeparture(db, trip.ScheduleID, trip.TravelDate.Format("2006-01-02")); err != nil {
+		return nil, err
+	}
+
+	seats, err := repository.GetSeatsByVehicleID(db, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	booked, err := repository.GetBookedSeats(db, trip.ScheduleID, trip.TravelDate)
+	if err != nil {
+		return nil, err
+	}
+	held, err := repository.GetHeldSeats(db, trip)
+	if err != nil {
+		return nil, err
+	}
+	seatChanges, holdChanges := MapTripSeats(booked, held, seats)
+	for _, change := range seatChanges {
+		if change.ToSeatID == nil {
+			swap.Unmatched = append(swap.Unmatched, change)
+		} else {
+			swap.Seats = append(swap.Seats, change)
+		}
+	}
+	for _, change := range holdChanges {
+		if change.ToSeatID == nil {
+			swap.UnmatchedHolds = append(swap.UnmatchedHolds, change)
+		} else {
+			swap.Holds = append(swap.Holds, change)
+		}
+	}
+	for _, hold := range swap.UnmatchedHolds {
+		if hold.Kind == models.SeatHoldGroup {
+			return swap, ErrGroupSeatsUnmatched
+		}
+	}
+	if (len(swap.Unmatched) > 0 || len(swap.UnmatchedHolds) > 0) && !allowUnmatched {
+		return swap, ErrSeatsUnmatched
+	}
+
+	for _, change := range swap.Seats {
+		if err := repository.MoveBookingSeat(db, change.BookingSeatID, *change.ToSeatID); err != nil {
+			return nil, err
+		}
+	}
+	for _, change := range swap.Holds {
+		if err := moveHeldSeat(db, trip, change); err != nil {
+			return nil, err
+		}
+	}
+	for _, hold := range swap.UnmatchedHolds {
+		if err := releaseHeldSeat(db, hold); err != nil {
+			return nil, err
+		}
+	}
+	if err := notifySeatChanges(db, trip, swap.Seats); err != nil {
+		return nil, err
+	}
+
+	if err := repository.UpdateTripVehicle(db, trip.ID, vehicleID); err != nil {
+		return nil, err
+	}
+	trip.VehicleID = vehicleID
+	return swap, nil
+}
+
+// moveHeldSeat moves a held seat to its seat on the new vehicle. Blocks of every trip of the schedule
+// stay on the schedule's vehicle and are copied to the new seat for this trip only.
+func moveHeldSeat(db repository.DBInterface, trip *models.Trip, change HeldSeatChange) error {
+	switch change.Kind {
+	case models.SeatHoldGroup:
+		return repository.MoveGroupBookingSeat(db, change.ID, *change.ToSeatID)
+	case models.SeatHoldWaitlist:
+		return repository.MoveWaitlistOffer(db, change.ID, *change.ToSeatID)
+	case models.SeatHoldBlock:
+		if !change.EveryTrip {
+			return repository.MoveSeatBlock(db, change.ID, *change.ToSeatID)
+		}
+		block, err := repository.GetSeatBlockByID(db, change.ID)
+		if err != nil {
+			return err
+		}
+		travelDate := trip.TravelDate
+		block.TravelDate = &travelDate
+		block.SeatID = change.ToSeatID
+		return repository.CreateSeatBlock(db, block)
+	}
+	return nil
+}
+
+// releaseHeldSeat lets go of a held seat left without a seat on the new vehicle: blocks of the trip are
+// lifted and waitlist offers go back to waiting, to be offered another seat
+func releaseHeldSeat(db repository.DBInterface, hold HeldSeatChange) error {
+	switch {
+	case hold.Kind == models.SeatHoldWaitlist:
+		return repository.WithdrawWaitlistOffer(db, hold.ID)
+	case hold.Kind == models.SeatHoldBlock && !hold.EveryTrip:
+		return repository.DeleteSeatBlock(db, hold.ID)
+	}
+	return nil
+}
+
+// notifySeatChanges sends one notification per booking whose seat numbers changed
+func notifySeatChanges(db repository.DBInterface, trip *models.Trip, changes []SeatChange) error {
+	byBooking := make(map[int][]int)
+	var bookingIDs []int
+	for i, change := range changes {
+		if change.ToSeatNumber == change.SeatNumber {
+			continue
+		}
+		if _, ok := byBooking[change.BookingID]; !ok {
+			bookingIDs = append(bookingIDs, change.BookingID)
+		}
+		byBooking[change.BookingID] = append(byBooking[change.BookingID], i)
+	}
+
+	for _, bookingID := range bookingIDs {
+		indexes := byBooking[bookingID]
+		first := changes[indexes[0]]
+
+		var moves []string
+		var seats []map[string]interface{}
+		for _, i := range indexes {
+			moves = append(moves, fmt.Sprintf("%s to %s", changes[i].SeatNumber, changes[i].ToSeatNumber))
+			seats = append(seats, map[string]interface{}{"from": changes[i].SeatNumber, "to": changes[i].ToSeatNumber})
+		}
+
+		notification := models.Notification{
+			UserID:           first.UserID,
+			BookingID:        &bookingID,
+			NotificationType: "seat_changed",
+			Title:            "Seat changed",
+			Message: fmt.Sprintf("Your trip on %s will run with another vehicle. Your seat has changed from %s.",
+				trip.DepartureDatetime.Format("2006-01-02 15:04"), strings.Join(moves, ", ")),
+			Data: map[string]interface{}{
+				"trip_id": trip.ID,
+				"seats":   seats,
+			},
+		}
+		if err := repository.CreateNotification(db, &notification); err != nil {
+			return err
+		}
+		for _, i := range indexes {
+			changes[i].NotificationID = &notification.ID
+		}
+	}
+	return nil
+}
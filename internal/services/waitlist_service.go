@@ -0,0 +1,401 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+)
+
+var (
+	ErrWaitlistSeatsAvailable = errors.New("seats are still available on this departure")
+	ErrWaitlistClosed         = errors.New("this departure has been cancelled or has already left")
+	ErrAlreadyWaitlisted      = errors.New("already on the waitlist of this departure")
+	ErrWaitlistEntryNotFound  = errors.New("waitlist entry not found")
+	ErrWaitlistOfferExpired   = errors.New("the seat offer has expired or was already claimed")
+)
+
+// claimTokenBytes is the size of the random token claiming a waitlist offer
+const claimTokenBytes = 24
+
+// WaitlistRequest holds the departure a passenger waits for and the details needed to book it
+type WaitlistRequest struct {
+	UserID                  int
+	ScheduleID              int
+	TravelDate              string
+	SeatType                *string
+	OriginStopSequence      *int
+	DestinationStopSequence *int
+	PassengerName           string
+	PassengerDocument       string
+	PassengerPhone          string
+}
+
+// WaitlistPosition is a waitlist entry with the number of passengers waiting ahead of it
+type WaitlistPosition struct {
+	models.WaitlistEntry
+	Ahead int `json:"ahead"`
+}
+
+// WaitlistRun summarizes a pass over the waitlists
+type WaitlistRun struct {
+	Expired int64 `json:"expired"`
+	Offered int   `json:"offered"`
+}
+
+// PickWaitlistSeat returns the first seat of the given type, or the first seat when no type is wanted
+func PickWaitlistSeat(seats []models.Seat, seatType *string) *models.Seat {
+	for i := range seats {
+		if seatType == nil || seats[i].SeatType == *seatType {
+			return &seats[i]
+		}
+	}
+	return nil
+}
+
+// WaitlistOfferExpiry returns when an offer made at the given time lapses: after the claim period,
+// or at departure if sooner
+func WaitlistOfferExpiry(offeredAt time.Time, claimPeriod time.Duration, departure time.Time) time.Time {
+	expiry := offeredAt.Add(claimPeriod)
+	if departure.Before(expiry) {
+		return departure
+	}
+	return expiry
+}
+
+// JoinWaitlist puts a passenger on the waitlist of a departure. Passengers may only wait when no seat
+// of the wanted type is free on their segment.
+func JoinWaitlist(db *sql.DB, request WaitlistRequest, now time.Time) (*WaitlistPosition, error) {
+	travelDate, err := time.Parse("2006-01-02", request.TravelDate)
+	if err != nil {
+		return nil, ErrInvalidTravelDate
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	trip, err := repository.GetTripByScheduleAndDate(tx, request.ScheduleID, travelDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		trip, err = MaterializeTrip(tx, request.ScheduleID, travelDate)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if trip.Status == models.TripStatusCancelled || !trip.DepartureDatetime.After(now) {
+		return nil, ErrWaitlistClosed
+	}
+
+	schedule, err := repository.GetScheduleByID(tx, trip.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	stops, err := repository.GetRouteStopsByRouteID(tx, schedule.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	origin, destination, err := ResolveSegment(stops, request.OriginStopSequence, request.DestinationStopSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.LockDeparture(tx, trip.ScheduleID, request.TravelDate); err != nil {
+		return nil, err
+	}
+	seats, err := repository.GetAvailableSeatsForSegment(tx, trip.ScheduleID, request.TravelDate, origin.StopSequence, destination.StopSequence)
+	if err != nil {
+		return nil, err
+	}
+	if PickWaitlistSeat(seats, request.SeatType) != nil {
+		return nil, ErrWaitlistSeatsAvailable
+	}
+
+	entries, err := repository.GetTripWaitlist(tx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.UserID == request.UserID && (entry.Status == models.WaitlistStatusWaiting || entry.Status == models.WaitlistStatusOffered) {
+			return nil, ErrAlreadyWaitlisted
+		}
+	}
+
+	entry := models.WaitlistEntry{
+		TripID:                  trip.ID,
+		UserID:                  request.UserID,
+		SeatType:                request.SeatType,
+		OriginStopSequence:      origin.StopSequence,
+		DestinationStopSequence: destination.StopSequence,
+		PassengerName:           request.PassengerName,
+		PassengerDocument:       request.PassengerDocument,
+		PassengerPhone:          request.PassengerPhone,
+		Status:                  models.WaitlistStatusWaiting,
+	}
+	if err := repository.CreateWaitlistEntry(tx, &entry); err != nil {
+		return nil, err
+	}
+	ahead, err := repository.CountWaitingAhead(tx, &entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &WaitlistPosition{WaitlistEntry: entry, Ahead: ahead}, nil
+}
+
+// UserWaitlist returns the waitlist entries of a passenger with their place in the queue
+func UserWaitlist(db repository.DBInterface, userID int) ([]WaitlistPosition, error) {
+	entries, err := repository.GetWaitlistEntriesByUserID(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]WaitlistPosition, 0, len(entries))
+	for _, entry := range entries {
+		position := WaitlistPosition{WaitlistEntry: entry}
+		if entry.Status == models.WaitlistStatusWaiting {
+			if position.Ahead, err = repository.CountWaitingAhead(db, &entry); err != nil {
+				return nil, err
+			}
+		}
+		positions = append(positions, position)
+	}
+
+	return positions, nil
+}
+
+// LeaveWaitlist takes a passenger's entry off the waitlist. A seat held for it is offered to the next passenger.
+func LeaveWaitlist(db *sql.DB, entryID, userID int, claimPeriod time.Duration, now time.Time) (*models.WaitlistEntry, error) {
+	entry, err := repository.GetWaitlistEntryByID(db, entryID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && entry.UserID != userID) {
+		return nil, ErrWaitlistEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	wasOffered := entry.Status == models.WaitlistStatusOffered
+	if err := repository.CancelWaitlistEntry(db, entry); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entry, nil
+		}
+		return nil, err
+	}
+
+	if wasOffered {
+		if _, err := OfferWaitlistSeats(db, entry.TripID, claimPeriod, now); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+// OfferWaitlistSeats offers the seats free on a trip to its waiting passengers in the order they joined.
+// Each offered seat is held for the passenger, who is notified with a claim token valid for the claim period.
+// Passengers whose segment or seat type has nothing free keep their place.
+func OfferWaitlistSeats(db *sql.DB, tripID int, claimPeriod time.Duration, now time.Time) ([]models.WaitlistEntry, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	trip, err := repository.GetTripByID(tx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.Status == models.TripStatusCancelled || !trip.DepartureDatetime.After(now) {
+		return nil, nil
+	}
+
+	entries, err := repository.LockWaitingEntries(tx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	travelDate := trip.TravelDate.Format("2006-01-02")
+	if err := repository.LockDeparture(tx, trip.ScheduleID, travelDate); err != nil {
+		return nil, err
+	}
+	offered := []models.WaitlistEntry{}
+	for i := range entries {
+		entry := &entries[i]
+		// Seats already held in this pass are excluded, as the offers are visible within the transaction
+		seats, err := repository.GetAvailableSeatsForSegment(tx, trip.ScheduleID, travelDate, entry.OriginStopSequence, entry.DestinationStopSequence)
+		if err != nil {
+			return nil, err
+		}
+		seat := PickWaitlistSeat(seats, entry.SeatType)
+		if seat == nil {
+			continue
+		}
+
+		token, err := utils.GenerateToken(claimTokenBytes)
+		if err != nil {
+			return nil, err
+		}
+		expiresAt := WaitlistOfferExpiry(now, claimPeriod, trip.DepartureDatetime)
+		entry.Status = models.WaitlistStatusOffered
+		entry.OfferedSeatID = &seat.ID
+		entry.OfferedAt = &now
+		entry.OfferExpiresAt = &expiresAt
+		if err := repository.OfferWaitlistSeat(tx, entry, utils.HashToken(token)); err != nil {
+			return nil, err
+		}
+		if err := notifyWaitlistOffer(tx, trip, entry, seat, token); err != nil {
+			return nil, err
+		}
+		offered = append(offered, *entry)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return offered, nil
+}
+
+func notifyWaitlistOffer(db repository.DBInterface, trip *models.Trip, entry *models.WaitlistEntry, seat *models.Seat, token string) error {
+	notification := models.Notification{
+		UserID:           entry.UserID,
+		NotificationType: "waitlist_offer",
+		Title:            "A seat is available",
+		Message: fmt.Sprintf("Seat %s is available on your waitlisted trip of %s. Claim it before %s.",
+			seat.SeatNumber, trip.DepartureDatetime.Format("2006-01-02 15:04"), entry.OfferExpiresAt.Format("2006-01-02 15:04")),
+		Data: map[string]interface{}{
+			"waitlist_entry_id": entry.ID,
+			"trip_id":           trip.ID,
+			"seat_id":           seat.ID,
+			"seat_number":       seat.SeatNumber,
+			"claim_token":       token,
+			"claim_method":      "POST",
+			"claim_url":         "/api/v1/waitlist/claim",
+			"expires_at":        entry.OfferExpiresAt,
+		},
+	}
+	return repository.CreateNotification(db, &notification)
+}
+
+// ClaimWaitlistOffer books the seat offered to a passenger with a claim token, if the offer is still running
+func ClaimWaitlistOffer(db *sql.DB, token string, userID int, paymentMethod string, now time.Time) (*models.Booking, error) {
+	entry, err := repository.GetWaitlistEntryByTokenHash(db, utils.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && entry.UserID != userID) {
+		return nil, ErrWaitlistEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Claiming releases the hold, so the seat shows as available to the booking below
+	if err := repository.ClaimWaitlistOffer(tx, entry.ID, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWaitlistOfferExpired
+		}
+		return nil, err
+	}
+
+	trip, err := repository.GetTripByID(tx, entry.TripID)
+	if err != nil {
+		return nil, err
+	}
+	prepared, err := prepareLeg(tx, NewBooking{
+		UserID:            entry.UserID,
+		PassengerName:     entry.PassengerName,
+		PassengerDocument: entry.PassengerDocument,
+		PassengerPhone:    entry.PassengerPhone,
+		PaymentMethod:     paymentMethod,
+	}, BookingLeg{
+		ScheduleID:              trip.ScheduleID,
+		TravelDate:              trip.TravelDate.Format("2006-01-02"),
+		OriginStopSequence:      &entry.OriginStopSequence,
+		DestinationStopSequence: &entry.DestinationStopSequence,
+		SeatIDs:                 []int{*entry.OfferedSeatID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	booking := prepared.booking
+	if booking.BookingCode, err = utils.GenerateCode("BK"); err != nil {
+		return nil, err
+	}
+	if err := repository.CreateBooking(tx, &booking); err != nil {
+		return nil, err
+	}
+	bookingSeat := models.BookingSeat{BookingID: booking.ID, SeatID: *entry.OfferedSeatID}
+	if err := repository.CreateBookingSeat(tx, &bookingSeat); err != nil {
+		return nil, err
+	}
+	if err := repository.SetWaitlistBooking(tx, entry.ID, booking.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &booking, nil
+}
+
+// OfferFreedSeats offers the seats released by the given bookings to the waitlists of their departures
+func OfferFreedSeats(db *sql.DB, bookings []models.Booking, claimPeriod time.Duration, now time.Time) error {
+	offeredTrips := map[int]bool{}
+	for _, booking := range bookings {
+		trip, err := repository.GetTripByScheduleAndDate(db, booking.ScheduleID, booking.TravelDate)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Joining a waitlist creates the trip, so nobody waits for this departure
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if offeredTrips[trip.ID] {
+			continue
+		}
+		offeredTrips[trip.ID] = true
+
+		if _, err := OfferWaitlistSeats(db, trip.ID, claimPeriod, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProcessWaitlists expires lapsed offers and the entries of departures that are gone, then offers
+// the free seats of every trip with passengers waiting
+func ProcessWaitlists(db *sql.DB, claimPeriod time.Duration, now time.Time) (*WaitlistRun, error) {
+	expired, err := repository.ExpireWaitlistEntries(db, now)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &WaitlistRun{Expired: expired}
+	tripIDs, err := repository.GetWaitlistedTripIDs(db)
+	if err != nil {
+		return run, err
+	}
+	for _, tripID := range tripIDs {
+		offered, err := OfferWaitlistSeats(db, tripID, claimPeriod, now)
+		if err != nil {
+			return run, err
+		}
+		run.Offered += len(offered)
+	}
+
+	return run, nil
+}
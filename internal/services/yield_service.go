@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/repository"
+)
+
+var ErrInvalidYieldRule = errors.New("invalid yield rule")
+
+// Load factors and booking horizons previewed by a simulation when none are given
+var (
+	DefaultSimulationLoads = []float64{0, 25, 50, 75, 90, 100}
+	DefaultSimulationDays  = []int{0, 1, 3, 7, 14, 30, 60}
+)
+
+// ValidateYieldRule checks the bands and price of a yield rule
+func ValidateYieldRule(rule *models.YieldRule) error {
+	switch {
+	case strings.TrimSpace(rule.Name) == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidYieldRule)
+	case rule.MinLoadPercent < 0 || rule.MaxLoadPercent > 100 || rule.MinLoadPercent > rule.MaxLoadPercent:
+		return fmt.Errorf("%w: load percentages must be between 0 and 100, minimum first", ErrInvalidYieldRule)
+	case (rule.MinDaysBefore != nil && *rule.MinDaysBefore < 0) || (rule.MaxDaysBefore != nil && *rule.MaxDaysBefore < 0):
+		return fmt.Errorf("%w: days before departure cannot be negative", ErrInvalidYieldRule)
+	case rule.MinDaysBefore != nil && rule.MaxDaysBefore != nil && *rule.MinDaysBefore > *rule.MaxDaysBefore:
+		return fmt.Errorf("%w: min_days_before cannot exceed max_days_before", ErrInvalidYieldRule)
+	case rule.PricePercent <= 0:
+		return fmt.Errorf("%w: price_percent must be positive", ErrInvalidYieldRule)
+	}
+	return nil
+}
+
+// ValidateYieldCaps checks the caps a route puts on the share of the segment fare charged by yield rules
+func ValidateYieldCaps(minPercent, maxPercent *float64) error {
+	switch {
+	case minPercent != nil && *minPercent <= 0, maxPercent != nil && *maxPercent <= 0:
+		return fmt.Errorf("%w: yield caps must be positive", ErrInvalidYieldRule)
+	case minPercent != nil && maxPercent != nil && *minPercent > *maxPercent:
+		return fmt.Errorf("%w: min_yield_percent cannot exceed max_yield_percent", ErrInvalidYieldRule)
+	}
+	return nil
+}
+
+// SaveYieldRule creates or updates a yield rule
+func SaveYieldRule(db repository.DBInterface, rule *models.YieldRule) error {
+	if err := ValidateYieldRule(rule); err != nil {
+		return err
+	}
+	if rule.ID == 0 {
+		return repository.CreateYieldRule(db, rule)
+	}
+	return repository.UpdateYieldRule(db, rule)
+}
+
+// QuoteYield prices a segment fare with the yield rules of a route, in evaluation order, for a load factor and
+// days left before departure
+func QuoteYield(route *models.Route, rules []models.YieldRule, loadPercent float64, daysBefore int, segmentFare float64) models.YieldQuote {
+	percent, rule := YieldPercent(rules, loadPercent, daysBefore, route.MinYieldPercent, route.MaxYieldPercent)
+	quote := models.YieldQuote{
+		LoadPercent:  loadPercent,
+		DaysBefore:   daysBefore,
+		PricePercent: percent,
+		Price:        YieldFare(segmentFare, percent),
+	}
+	if rule != nil {
+		quote.RuleID = &rule.ID
+		quote.RuleName = rule.Name
+	}
+	return quote
+}
+
+// DepartureYield prices a segment of a departure with the active yield rules of its route, from the seats sold on
+// the segment and the time left before boarding. It returns nil when the route has no rules and keeps its static fare.
+func DepartureYield(db repository.DBInterface, route *models.Route, rules []models.YieldRule, scheduleID int, travelDate string, originStop, destinationStop models.RouteStop, boarding time.Time, segmentFare float64, now time.Time) (*models.YieldQuote, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	total, err := repository.CountDepartureSeats(db, scheduleID, travelDate)
+	if err != nil {
+		return nil, err
+	}
+	sold, err := repository.CountSoldSeatsForSegment(db, scheduleID, travelDate, originStop.StopSequence, destinationStop.StopSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := QuoteYield(route, rules, LoadPercent(sold, total), DaysBefore(boarding, now), segmentFare)
+	return &quote, nil
+}
+
+// SimulateYield previews the price of a whole-route seat under the given rules at each combination of load factor
+// and days before departure
+func SimulateYield(route *models.Route, rules []models.YieldRule, loads []float64, days []int) []models.YieldQuote {
+	if len(loads) == 0 {
+		loads = DefaultSimulationLoads
+	}
+	if len(days) == 0 {
+		days = DefaultSimulationDays
+	}
+
+	// Rules are evaluated in priority order, as when they are stored
+	rules = append([]models.YieldRule(nil), rules...)
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Priority < rules[j].Priority })
+
+	fare := RouteFare(route)
+	quotes := make([]models.YieldQuote, 0, len(loads)*len(days))
+	for _, daysBefore := range days {
+		for _, load := range loads {
+			quotes = append(quotes, QuoteYield(route, rules, load, daysBefore, fare))
+		}
+	}
+	return quotes
+}
+
+// RouteFare returns the static fare of a seat from the first to the last stop of a route
+func RouteFare(route *models.Route) float64 {
+	if len(route.Stops) == 0 {
+		return route.BasePrice
+	}
+	return SegmentFare(route.BasePrice, route.Stops, route.Fares, route.Stops[0], route.Stops[len(route.Stops)-1])
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"sync"
+	"time"
+
+	// Embedded zone database, so IANA zones resolve on hosts without one
+	_ "time/tzdata"
+)
+
+const (
+	DateLayout  = "2006-01-02"
+	ClockLayout = "15:04:05"
+)
+
+var locations sync.Map
+
+// LoadLocation returns the IANA time zone with the given name. An empty name is UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// ParseClock parses a wall clock time such as "08:00:00" or "08:00"
+func ParseClock(clock string) (time.Time, error) {
+	parsed, err := time.Parse(ClockLayout, clock)
+	if err != nil {
+		return time.Parse("15:04", clock)
+	}
+	return parsed, nil
+}
+
+// LocalDatetime returns the instant at which a wall clock time happens on a date in a time zone.
+// Wall clock times skipped by a daylight saving change are moved forward.
+func LocalDatetime(date time.Time, clock string, loc *time.Location) (time.Time, error) {
+	parsed, err := ParseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Date(
+		date.Year(), date.Month(), date.Day(),
+		parsed.Hour(), parsed.Minute(), parsed.Second(),
+		0, loc,
+	), nil
+}
+
+// DepartureArrival returns the departure and arrival instants of a service leaving at a local time in one
+// zone and arriving at a local time in another. Arrivals falling before the departure are overnight
+// arrivals and are moved onto the following day.
+func DepartureArrival(date time.Time, departureClock string, departureLoc *time.Location, arrivalClock string, arrivalLoc *time.Location) (time.Time, time.Time, error) {
+	departure, err := LocalDatetime(date, departureClock, departureLoc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	arrival, err := LocalDatetime(date, arrivalClock, arrivalLoc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	// Zones far apart may need more than one day to catch up
+	for days := 1; arrival.Before(departure); days++ {
+		arrival, err = LocalDatetime(date.AddDate(0, 0, days), arrivalClock, arrivalLoc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	return departure, arrival, nil
+}
@@ -0,0 +1,35 @@
+package utils
+
+import "math"
+
+const earthRadiusKm = 6371.0
+
+// HaversineKm returns the great-circle distance between two coordinates in kilometres
+func HaversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// ProjectOnSegment locates a point relative to the segment between two coordinates. It returns how far along
+// the segment the closest point lies, from 0 to 1, and the distance to it in kilometres. A local flat
+// projection is used, which is accurate enough between consecutive stops.
+func ProjectOnSegment(lat, lon, lat1, lon1, lat2, lon2 float64) (float64, float64) {
+	kmPerDegreeLon := 111.32 * math.Cos(lat1*math.Pi/180)
+	const kmPerDegreeLat = 110.57
+
+	x, y := (lon-lon1)*kmPerDegreeLon, (lat-lat1)*kmPerDegreeLat
+	dx, dy := (lon2-lon1)*kmPerDegreeLon, (lat2-lat1)*kmPerDegreeLat
+
+	fraction := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		fraction = math.Max(0, math.Min(1, (x*dx+y*dy)/length))
+	}
+
+	return fraction, math.Hypot(x-fraction*dx, y-fraction*dy)
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// GenerateToken returns a random hex token of the given number of bytes
+func GenerateToken(size int) (string, error) {
+	token := make([]byte, size)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// codeAlphabet leaves out characters that are easily confused when read out, such as 0/O and 1/I
+const codeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// CodeLength is the number of random characters after the prefix of a generated code
+const CodeLength = 10
+
+// GenerateCode returns the prefix followed by CodeLength random characters, for booking and journey
+// codes that fit their columns and do not depend on who booked or when
+func GenerateCode(prefix string) (string, error) {
+	random := make([]byte, CodeLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	code := make([]byte, CodeLength)
+	for i, b := range random {
+		code[i] = codeAlphabet[int(b)%len(codeAlphabet)]
+	}
+	return prefix + string(code), nil
+}
+
+// HashToken returns the SHA-256 hex digest under which a token is stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
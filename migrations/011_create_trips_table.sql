@@ -0,0 +1,21 @@
+-- Create trips table (a schedule materialised for a specific travel date)
+CREATE TABLE IF NOT EXISTS trips (
+    id SERIAL PRIMARY KEY,
+    schedule_id INTEGER REFERENCES schedules(id),
+    vehicle_id INTEGER REFERENCES vehicles(id),
+    travel_date DATE NOT NULL,
+    departure_datetime TIMESTAMP NOT NULL,
+    arrival_datetime TIMESTAMP NOT NULL,
+    status VARCHAR(20) DEFAULT 'scheduled', -- 'scheduled', 'delayed', 'cancelled'
+    delay_minutes INTEGER DEFAULT 0,
+    status_reason TEXT,
+    status_changed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(schedule_id, travel_date)
+);
+
+-- Create indexes for trips
+CREATE INDEX IF NOT EXISTS idx_trips_schedule_id ON trips(schedule_id);
+CREATE INDEX IF NOT EXISTS idx_trips_travel_date ON trips(travel_date);
+CREATE INDEX IF NOT EXISTS idx_trips_status ON trips(status);
@@ -0,0 +1,16 @@
+-- Create notifications table
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id),
+    booking_id INTEGER REFERENCES bookings(id),
+    notification_type VARCHAR(50) NOT NULL, -- 'trip_cancelled', 'trip_delayed'
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    data JSONB, -- Datos adicionales (reembolso, oferta de cambio, etc.)
+    is_read BOOLEAN DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for notifications
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_booking_id ON notifications(booking_id);
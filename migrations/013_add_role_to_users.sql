@@ -0,0 +1,5 @@
+-- Add role and operating company to users
+ALTER TABLE users ADD COLUMN role VARCHAR(20) DEFAULT 'passenger'; -- 'passenger', 'operator', 'admin'
+ALTER TABLE users ADD COLUMN company_id INTEGER REFERENCES companies(id);
+
+CREATE INDEX IF NOT EXISTS idx_users_company_id ON users(company_id);
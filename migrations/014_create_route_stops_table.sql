@@ -0,0 +1,25 @@
+-- Create route_stops table (ordered stops of a route, origin and destination included)
+CREATE TABLE IF NOT EXISTS route_stops (
+    id SERIAL PRIMARY KEY,
+    route_id INTEGER REFERENCES routes(id) ON DELETE CASCADE,
+    stop_sequence INTEGER NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    terminal VARCHAR(255),
+    offset_minutes INTEGER NOT NULL DEFAULT 0, -- Minutos desde la salida del origen
+    distance_km INTEGER NOT NULL DEFAULT 0, -- Distancia acumulada desde el origen
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(route_id, stop_sequence)
+);
+
+-- Create indexes for route_stops
+CREATE INDEX IF NOT EXISTS idx_route_stops_route_id ON route_stops(route_id);
+CREATE INDEX IF NOT EXISTS idx_route_stops_city ON route_stops(city);
+
+-- Existing routes become two-stop routes
+INSERT INTO route_stops (route_id, stop_sequence, city, terminal, offset_minutes, distance_km)
+SELECT id, 1, origin_city, origin_terminal, 0, 0 FROM routes
+ON CONFLICT (route_id, stop_sequence) DO NOTHING;
+
+INSERT INTO route_stops (route_id, stop_sequence, city, terminal, offset_minutes, distance_km)
+SELECT id, 2, destination_city, destination_terminal, COALESCE(estimated_duration_minutes, 0), COALESCE(distance_km, 0) FROM routes
+ON CONFLICT (route_id, stop_sequence) DO NOTHING;
@@ -0,0 +1,13 @@
+-- Create route_fares table (explicit prices between two stops of a route)
+CREATE TABLE IF NOT EXISTS route_fares (
+    id SERIAL PRIMARY KEY,
+    route_id INTEGER REFERENCES routes(id) ON DELETE CASCADE,
+    origin_stop_sequence INTEGER NOT NULL,
+    destination_stop_sequence INTEGER NOT NULL,
+    price DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(route_id, origin_stop_sequence, destination_stop_sequence)
+);
+
+-- Create indexes for route_fares
+CREATE INDEX IF NOT EXISTS idx_route_fares_route_id ON route_fares(route_id);
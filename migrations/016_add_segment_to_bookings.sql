@@ -0,0 +1,3 @@
+-- Add the travelled segment to bookings (NULL means the whole route)
+ALTER TABLE bookings ADD COLUMN origin_stop_sequence INTEGER;
+ALTER TABLE bookings ADD COLUMN destination_stop_sequence INTEGER;
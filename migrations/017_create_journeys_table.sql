@@ -0,0 +1,13 @@
+-- Create journeys table (several bookings sold together as one trip)
+CREATE TABLE IF NOT EXISTS journeys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id),
+    journey_code VARCHAR(20) UNIQUE NOT NULL,
+    journey_type VARCHAR(20) NOT NULL DEFAULT 'connection', -- 'connection'
+    total_amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for journeys
+CREATE INDEX IF NOT EXISTS idx_journeys_user_id ON journeys(user_id);
@@ -0,0 +1,5 @@
+-- Link bookings to the journey they belong to
+ALTER TABLE bookings ADD COLUMN journey_id INTEGER REFERENCES journeys(id);
+ALTER TABLE bookings ADD COLUMN leg_sequence INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_journey_id ON bookings(journey_id);
@@ -0,0 +1,10 @@
+-- Round-trip discount offered by each company when outbound and return are booked together
+ALTER TABLE companies ADD COLUMN round_trip_discount_percent DECIMAL(5,2) NOT NULL DEFAULT 0;
+
+-- Discount granted on a booking because it was sold as part of a round trip
+ALTER TABLE bookings ADD COLUMN discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+-- A journey is paid once; the payment is attached to its first booking and to the journey
+ALTER TABLE payments ADD COLUMN journey_id INTEGER REFERENCES journeys(id);
+
+CREATE INDEX IF NOT EXISTS idx_payments_journey_id ON payments(journey_id);
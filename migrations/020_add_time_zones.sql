@@ -0,0 +1,8 @@
+-- IANA time zone of each stop; schedule times are local to the stop they refer to
+ALTER TABLE route_stops ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Store departure and arrival instants with their offset.
+-- Existing values were written as UTC wall clock times and every stop starts in UTC, so they are kept as is.
+ALTER TABLE bookings ALTER COLUMN departure_datetime TYPE TIMESTAMPTZ USING departure_datetime AT TIME ZONE 'UTC';
+ALTER TABLE trips ALTER COLUMN departure_datetime TYPE TIMESTAMPTZ USING departure_datetime AT TIME ZONE 'UTC';
+ALTER TABLE trips ALTER COLUMN arrival_datetime TYPE TIMESTAMPTZ USING arrival_datetime AT TIME ZONE 'UTC';
@@ -0,0 +1,27 @@
+-- Create service calendars table (reusable operating calendars of a company)
+CREATE TABLE IF NOT EXISTS service_calendars (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER REFERENCES companies(id) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create calendar exceptions table (dates with added or removed service)
+CREATE TABLE IF NOT EXISTS calendar_exceptions (
+    id SERIAL PRIMARY KEY,
+    calendar_id INTEGER REFERENCES service_calendars(id) ON DELETE CASCADE NOT NULL,
+    exception_date DATE NOT NULL,
+    exception_type VARCHAR(10) NOT NULL, -- 'added', 'removed'
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (calendar_id, exception_date)
+);
+
+-- Schedules may follow a calendar on top of their days of week
+ALTER TABLE schedules ADD COLUMN calendar_id INTEGER REFERENCES service_calendars(id);
+
+-- Create indexes for calendars
+CREATE INDEX IF NOT EXISTS idx_service_calendars_company_id ON service_calendars(company_id);
+CREATE INDEX IF NOT EXISTS idx_calendar_exceptions_date ON calendar_exceptions(exception_date);
+CREATE INDEX IF NOT EXISTS idx_schedules_calendar_id ON schedules(calendar_id);
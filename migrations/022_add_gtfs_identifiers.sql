@@ -0,0 +1,14 @@
+-- GTFS identifiers, so feeds can be re-imported and exported with stable ids
+ALTER TABLE companies ADD COLUMN gtfs_id VARCHAR(100);
+ALTER TABLE routes ADD COLUMN gtfs_id VARCHAR(255);
+ALTER TABLE schedules ADD COLUMN gtfs_id VARCHAR(255);
+ALTER TABLE service_calendars ADD COLUMN gtfs_id VARCHAR(255);
+
+-- Stop identifiers and coordinates (GTFS stops need a position)
+ALTER TABLE route_stops ADD COLUMN gtfs_stop_id VARCHAR(255);
+ALTER TABLE route_stops ADD COLUMN latitude DECIMAL(9,6);
+ALTER TABLE route_stops ADD COLUMN longitude DECIMAL(9,6);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_routes_company_gtfs_id ON routes(company_id, gtfs_id) WHERE gtfs_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_calendars_company_gtfs_id ON service_calendars(company_id, gtfs_id) WHERE gtfs_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_schedules_gtfs_id ON schedules(gtfs_id);
@@ -0,0 +1,16 @@
+-- Create vehicle positions table (reported locations of vehicles, by trip when on service)
+CREATE TABLE IF NOT EXISTS vehicle_positions (
+    id BIGSERIAL PRIMARY KEY,
+    vehicle_id INTEGER REFERENCES vehicles(id) NOT NULL,
+    trip_id INTEGER REFERENCES trips(id),
+    latitude DECIMAL(9,6) NOT NULL,
+    longitude DECIMAL(9,6) NOT NULL,
+    bearing DECIMAL(5,2), -- degrees clockwise from north
+    speed_kmh DECIMAL(5,1),
+    recorded_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for vehicle positions
+CREATE INDEX IF NOT EXISTS idx_vehicle_positions_vehicle_recorded ON vehicle_positions(vehicle_id, recorded_at DESC);
+CREATE INDEX IF NOT EXISTS idx_vehicle_positions_trip_recorded ON vehicle_positions(trip_id, recorded_at DESC);
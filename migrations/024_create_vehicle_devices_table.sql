@@ -0,0 +1,13 @@
+-- Create vehicle devices table (on-board units allowed to report positions for a vehicle)
+CREATE TABLE IF NOT EXISTS vehicle_devices (
+    id SERIAL PRIMARY KEY,
+    vehicle_id INTEGER REFERENCES vehicles(id) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL, -- SHA-256 of the device token, which is only shown once
+    is_active BOOLEAN DEFAULT true,
+    last_seen_at TIMESTAMPTZ,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for vehicle devices
+CREATE INDEX IF NOT EXISTS idx_vehicle_devices_vehicle_id ON vehicle_devices(vehicle_id);
@@ -0,0 +1,28 @@
+-- Create crew members table (drivers and attendants employed by a company)
+CREATE TABLE IF NOT EXISTS crew_members (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER REFERENCES companies(id) NOT NULL,
+    user_id INTEGER REFERENCES users(id) UNIQUE, -- account used to see their duties
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    crew_role VARCHAR(20) NOT NULL, -- 'driver', 'attendant'
+    licence_number VARCHAR(50),
+    licence_expiry DATE,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create trip crew table (duties of crew members on trips)
+CREATE TABLE IF NOT EXISTS trip_crew (
+    id SERIAL PRIMARY KEY,
+    trip_id INTEGER REFERENCES trips(id) ON DELETE CASCADE NOT NULL,
+    crew_member_id INTEGER REFERENCES crew_members(id) NOT NULL,
+    duty VARCHAR(20) NOT NULL, -- 'driver', 'attendant'
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (trip_id, crew_member_id)
+);
+
+-- Create indexes for crew
+CREATE INDEX IF NOT EXISTS idx_crew_members_company_id ON crew_members(company_id);
+CREATE INDEX IF NOT EXISTS idx_trip_crew_crew_member_id ON trip_crew(crew_member_id);
@@ -0,0 +1,13 @@
+-- Create vehicle maintenance windows table (periods during which a vehicle cannot run trips)
+CREATE TABLE IF NOT EXISTS vehicle_maintenance_windows (
+    id SERIAL PRIMARY KEY,
+    vehicle_id INTEGER REFERENCES vehicles(id) NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+-- Create indexes for vehicle maintenance windows
+CREATE INDEX IF NOT EXISTS idx_vehicle_maintenance_vehicle_period ON vehicle_maintenance_windows(vehicle_id, starts_at, ends_at);
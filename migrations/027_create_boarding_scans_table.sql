@@ -0,0 +1,23 @@
+-- Track when each booked seat boarded
+ALTER TABLE booking_seats ADD COLUMN boarded_at TIMESTAMPTZ;
+
+-- Create boarding scans table (every ticket scanned for a trip, accepted or not)
+CREATE TABLE IF NOT EXISTS boarding_scans (
+    id SERIAL PRIMARY KEY,
+    trip_id INTEGER REFERENCES trips(id) ON DELETE CASCADE NOT NULL,
+    booking_seat_id INTEGER REFERENCES booking_seats(id) ON DELETE SET NULL,
+    device_id INTEGER REFERENCES vehicle_devices(id),
+    scanned_by INTEGER REFERENCES users(id),
+    scan_id VARCHAR(64), -- generated by the scanner so that uploads can be retried
+    booking_code VARCHAR(20),
+    seat_number VARCHAR(10),
+    passenger_name VARCHAR(255),
+    result VARCHAR(20) NOT NULL, -- 'boarded', 'duplicate', 'rejected'
+    reason VARCHAR(50),
+    scanned_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (trip_id, scan_id)
+);
+
+-- Create indexes for boarding scans
+CREATE INDEX IF NOT EXISTS idx_boarding_scans_booking_seat_id ON boarding_scans(booking_seat_id);
@@ -0,0 +1,5 @@
+-- Add online check-in to bookings, with the passenger data collected at check-in
+ALTER TABLE bookings ADD COLUMN passenger_document_type VARCHAR(20); -- 'passport', 'id_card'
+ALTER TABLE bookings ADD COLUMN passenger_document_expiry DATE;
+ALTER TABLE bookings ADD COLUMN passenger_nationality VARCHAR(2); -- ISO 3166-1 alpha-2
+ALTER TABLE bookings ADD COLUMN checked_in_at TIMESTAMPTZ;
@@ -0,0 +1,7 @@
+-- Share of the fare each company keeps when a passenger does not show up; the rest is refunded
+ALTER TABLE companies ADD COLUMN no_show_fee_percent DECIMAL(5,2) NOT NULL DEFAULT 0;
+
+-- Fee kept on a booking marked as a no-show. Departed bookings become 'completed' or 'no_show'
+ALTER TABLE bookings ADD COLUMN no_show_fee DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_status_departure ON bookings(booking_status, departure_datetime);
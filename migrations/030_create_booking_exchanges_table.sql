@@ -0,0 +1,20 @@
+-- Fee each company charges to move a booking to another departure
+ALTER TABLE companies ADD COLUMN change_fee DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+-- Create booking exchanges table (audit of every change of date, time or seat of a booking)
+CREATE TABLE IF NOT EXISTS booking_exchanges (
+    id SERIAL PRIMARY KEY,
+    booking_id INTEGER REFERENCES bookings(id) ON DELETE CASCADE NOT NULL,
+    previous_booking JSONB NOT NULL, -- the booking and its seats as they were before the change
+    schedule_id INTEGER REFERENCES schedules(id) NOT NULL,
+    travel_date DATE NOT NULL,
+    previous_fare DECIMAL(10,2) NOT NULL,
+    new_fare DECIMAL(10,2) NOT NULL,
+    change_fee DECIMAL(10,2) NOT NULL DEFAULT 0,
+    amount_due DECIMAL(10,2) NOT NULL, -- charged when positive, refunded when negative
+    payment_id INTEGER REFERENCES payments(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for booking exchanges
+CREATE INDEX IF NOT EXISTS idx_booking_exchanges_booking_id ON booking_exchanges(booking_id);
@@ -0,0 +1,27 @@
+-- Create waitlist table (passengers waiting for a seat on a sold-out departure, served in order)
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id SERIAL PRIMARY KEY,
+    trip_id INTEGER REFERENCES trips(id) ON DELETE CASCADE NOT NULL,
+    user_id INTEGER REFERENCES users(id) NOT NULL,
+    seat_type VARCHAR(20), -- any seat type when NULL
+    origin_stop_sequence INTEGER NOT NULL,
+    destination_stop_sequence INTEGER NOT NULL,
+    passenger_name VARCHAR(255) NOT NULL,
+    passenger_document VARCHAR(50) NOT NULL,
+    passenger_phone VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting', -- 'waiting', 'offered', 'claimed', 'expired', 'cancelled'
+    offered_seat_id INTEGER REFERENCES seats(id), -- seat held for the passenger while the offer lasts
+    claim_token_hash VARCHAR(64) UNIQUE,
+    offered_at TIMESTAMP,
+    offer_expires_at TIMESTAMP,
+    booking_id INTEGER REFERENCES bookings(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A passenger waits at most once per departure
+CREATE UNIQUE INDEX IF NOT EXISTS idx_waitlist_entries_active_user ON waitlist_entries(trip_id, user_id) WHERE status IN ('waiting', 'offered');
+
+-- Create indexes for waitlist entries
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_trip_status ON waitlist_entries(trip_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_user_id ON waitlist_entries(user_id);
@@ -0,0 +1,52 @@
+-- Create group bookings table (blocks of seats quoted by the operator and paid with a deposit and a balance)
+CREATE TABLE IF NOT EXISTS group_bookings (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) NOT NULL,
+    trip_id INTEGER REFERENCES trips(id) ON DELETE CASCADE NOT NULL,
+    origin_stop_sequence INTEGER NOT NULL,
+    destination_stop_sequence INTEGER NOT NULL,
+    departure_datetime TIMESTAMPTZ NOT NULL, -- boarding time at the origin stop
+    group_name VARCHAR(255) NOT NULL,
+    contact_name VARCHAR(255) NOT NULL,
+    contact_phone VARCHAR(20) NOT NULL,
+    contact_email VARCHAR(255),
+    seat_count INTEGER NOT NULL,
+    notes TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested', -- 'requested', 'quoted', 'confirmed', 'completed', 'declined', 'expired', 'cancelled'
+    price_per_seat DECIMAL(10,2),
+    total_amount DECIMAL(10,2),
+    deposit_amount DECIMAL(10,2),
+    amount_paid DECIMAL(10,2) NOT NULL DEFAULT 0,
+    quote_expires_at TIMESTAMPTZ,
+    passenger_deadline TIMESTAMPTZ, -- seats without a passenger are released after it
+    balance_deadline TIMESTAMPTZ, -- the group expires unless the balance is paid before it
+    quoted_by INTEGER REFERENCES users(id),
+    payment_method VARCHAR(20),
+    confirmed_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create group booking seats table (the seat block, with the passenger of each seat once supplied)
+CREATE TABLE IF NOT EXISTS group_booking_seats (
+    id SERIAL PRIMARY KEY,
+    group_booking_id INTEGER REFERENCES group_bookings(id) ON DELETE CASCADE NOT NULL,
+    seat_id INTEGER REFERENCES seats(id) NOT NULL,
+    passenger_name VARCHAR(255),
+    passenger_document VARCHAR(50),
+    passenger_phone VARCHAR(20),
+    booking_id INTEGER REFERENCES bookings(id), -- the booking issued for the seat once the balance is paid
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(group_booking_id, seat_id)
+);
+
+-- Deposits and balances are paid before the group's bookings exist
+ALTER TABLE payments ADD COLUMN group_booking_id INTEGER REFERENCES group_bookings(id);
+
+-- Create indexes for group bookings
+CREATE INDEX IF NOT EXISTS idx_group_bookings_user_id ON group_bookings(user_id);
+CREATE INDEX IF NOT EXISTS idx_group_bookings_trip_id ON group_bookings(trip_id);
+CREATE INDEX IF NOT EXISTS idx_group_bookings_status ON group_bookings(status);
+CREATE INDEX IF NOT EXISTS idx_group_booking_seats_seat_id ON group_booking_seats(seat_id);
+CREATE INDEX IF NOT EXISTS idx_payments_group_booking_id ON payments(group_booking_id);
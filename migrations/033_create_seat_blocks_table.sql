@@ -0,0 +1,19 @@
+-- Create seat blocks table (seats operators keep off sale for staff, escorts or partner allotments)
+CREATE TABLE IF NOT EXISTS seat_blocks (
+    id SERIAL PRIMARY KEY,
+    schedule_id INTEGER REFERENCES schedules(id) ON DELETE CASCADE NOT NULL,
+    travel_date DATE, -- every trip of the schedule when NULL
+    seat_id INTEGER REFERENCES seats(id) ON DELETE CASCADE, -- a specific seat, or
+    seat_count INTEGER, -- a number of seats, taken from the back of the vehicle
+    reason VARCHAR(20) NOT NULL, -- 'staff', 'escort', 'allotment', 'other'
+    note TEXT,
+    release_minutes_before INTEGER, -- the block is lifted this many minutes before departure; kept until departure when NULL
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((seat_id IS NULL) <> (seat_count IS NULL)),
+    CHECK (seat_count IS NULL OR seat_count > 0),
+    CHECK (release_minutes_before IS NULL OR release_minutes_before >= 0)
+);
+
+-- Create indexes for seat blocks
+CREATE INDEX IF NOT EXISTS idx_seat_blocks_schedule_date ON seat_blocks(schedule_id, travel_date);
@@ -0,0 +1,33 @@
+-- Create ancillaries table (extras each company sells next to seats)
+CREATE TABLE IF NOT EXISTS ancillaries (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER REFERENCES companies(id) NOT NULL,
+    category VARCHAR(20) NOT NULL, -- 'extra_bag', 'oversize_luggage', 'bicycle', 'pet', 'insurance'
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    price DECIMAL(10,2) NOT NULL DEFAULT 0, -- per unit and leg
+    fare_percent DECIMAL(5,2) NOT NULL DEFAULT 0, -- share of the seat fare added to the price
+    capacity_per_trip INTEGER CHECK (capacity_per_trip >= 0), -- NULL for no limit
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create booking ancillaries table (extras added to a booking, priced when added)
+CREATE TABLE IF NOT EXISTS booking_ancillaries (
+    id SERIAL PRIMARY KEY,
+    booking_id INTEGER REFERENCES bookings(id) ON DELETE CASCADE NOT NULL,
+    ancillary_id INTEGER REFERENCES ancillaries(id) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(10,2) NOT NULL,
+    total_price DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Part of the booking total paid for ancillaries
+ALTER TABLE bookings ADD COLUMN ancillary_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+-- Create indexes for ancillaries
+CREATE INDEX IF NOT EXISTS idx_ancillaries_company_id ON ancillaries(company_id);
+CREATE INDEX IF NOT EXISTS idx_booking_ancillaries_booking_id ON booking_ancillaries(booking_id);
+CREATE INDEX IF NOT EXISTS idx_booking_ancillaries_ancillary_id ON booking_ancillaries(ancillary_id);
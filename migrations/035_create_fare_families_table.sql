@@ -0,0 +1,32 @@
+-- Create fare families table (fares of a route with their own price and flexibility rules)
+CREATE TABLE IF NOT EXISTS fare_families (
+    id SERIAL PRIMARY KEY,
+    route_id INTEGER REFERENCES routes(id) ON DELETE CASCADE NOT NULL,
+    name VARCHAR(50) NOT NULL, -- e.g. 'Basic', 'Flex', 'Premium'
+    description TEXT,
+    price_percent DECIMAL(6,2) NOT NULL DEFAULT 100, -- share of the segment fare charged
+    surcharge DECIMAL(10,2) NOT NULL DEFAULT 0, -- added per seat
+    allow_changes BOOLEAN NOT NULL DEFAULT true,
+    change_fee DECIMAL(10,2), -- NULL for the company's change fee
+    allow_refunds BOOLEAN NOT NULL DEFAULT true,
+    cancellation_fee_percent DECIMAL(5,2) NOT NULL DEFAULT 0, -- share of the fare kept on cancellation
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(route_id, name)
+);
+
+-- Create fare family ancillaries table (ancillaries included with each seat of a fare family)
+CREATE TABLE IF NOT EXISTS fare_family_ancillaries (
+    fare_family_id INTEGER REFERENCES fare_families(id) ON DELETE CASCADE NOT NULL,
+    ancillary_id INTEGER REFERENCES ancillaries(id) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (fare_family_id, ancillary_id)
+);
+
+-- Fare family a booking was sold under; NULL for the route's plain fare
+ALTER TABLE bookings ADD COLUMN fare_family_id INTEGER REFERENCES fare_families(id);
+
+-- Create indexes for fare families
+CREATE INDEX IF NOT EXISTS idx_fare_families_route_id ON fare_families(route_id);
@@ -0,0 +1,27 @@
+-- Create yield rules table (price bands of a route by load factor and booking horizon)
+CREATE TABLE IF NOT EXISTS yield_rules (
+    id SERIAL PRIMARY KEY,
+    route_id INTEGER REFERENCES routes(id) ON DELETE CASCADE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    min_load_percent DECIMAL(5,2) NOT NULL DEFAULT 0, -- share of seats sold on the segment
+    max_load_percent DECIMAL(5,2) NOT NULL DEFAULT 100,
+    min_days_before INTEGER, -- NULL for no lower bound
+    max_days_before INTEGER, -- NULL for no upper bound
+    price_percent DECIMAL(6,2) NOT NULL, -- share of the segment fare charged
+    priority INTEGER NOT NULL DEFAULT 0, -- lowest first; the first matching rule applies
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (min_load_percent <= max_load_percent),
+    CHECK (min_days_before IS NULL OR max_days_before IS NULL OR min_days_before <= max_days_before)
+);
+
+-- Caps on the share of the segment fare charged by yield rules; NULL for no cap
+ALTER TABLE routes ADD COLUMN min_yield_percent DECIMAL(6,2);
+ALTER TABLE routes ADD COLUMN max_yield_percent DECIMAL(6,2);
+
+-- Share of the segment fare charged when the booking was sold; NULL for the static fare
+ALTER TABLE bookings ADD COLUMN yield_percent DECIMAL(6,2);
+
+-- Create indexes for yield rules
+CREATE INDEX IF NOT EXISTS idx_yield_rules_route_id ON yield_rules(route_id);
@@ -0,0 +1,2 @@
+-- Set when a long delay entitles the passenger to a fee-free refund or rebooking
+ALTER TABLE bookings ADD COLUMN delay_compensation BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,11 @@
+-- Change and cancellation rules of the fare family when the booking was sold; NULL for the plain fare
+ALTER TABLE bookings ADD COLUMN fare_allow_changes BOOLEAN;
+ALTER TABLE bookings ADD COLUMN fare_change_fee DECIMAL(10,2);
+ALTER TABLE bookings ADD COLUMN fare_allow_refunds BOOLEAN;
+ALTER TABLE bookings ADD COLUMN fare_cancellation_fee_percent DECIMAL(5,2);
+
+-- Bookings sold before keep the rules their fare family has now
+UPDATE bookings b
+SET fare_allow_changes = f.allow_changes, fare_change_fee = f.change_fee, fare_allow_refunds = f.allow_refunds, fare_cancellation_fee_percent = f.cancellation_fee_percent
+FROM fare_families f
+WHERE b.fare_family_id = f.id;
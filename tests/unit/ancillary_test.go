@@ -0,0 +1,49 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAncillaryPrice(t *testing.T) {
+	bag := models.Ancillary{Category: models.AncillaryCategoryExtraBag, Price: 8}
+	insurance := models.Ancillary{Category: models.AncillaryCategoryInsurance, Price: 1.5, FarePercent: 7}
+
+	assert.Equal(t, 8.0, services.AncillaryPrice(bag, 45.5))
+	assert.Equal(t, 4.69, services.AncillaryPrice(insurance, 45.5))
+}
+
+func TestValidateAncillary(t *testing.T) {
+	capacity := 4
+	negative := -1
+
+	assert.NoError(t, services.ValidateAncillary(&models.Ancillary{Category: models.AncillaryCategoryBicycle, Name: "Bicycle", Price: 10, CapacityPerTrip: &capacity}))
+	assert.ErrorIs(t, services.ValidateAncillary(&models.Ancillary{Category: "surfboard", Name: "Surfboard"}), services.ErrInvalidAncillary)
+	assert.ErrorIs(t, services.ValidateAncillary(&models.Ancillary{Category: models.AncillaryCategoryPet, Name: " "}), services.ErrInvalidAncillary)
+	assert.ErrorIs(t, services.ValidateAncillary(&models.Ancillary{Category: models.AncillaryCategoryInsurance, Name: "Insurance", FarePercent: 120}), services.ErrInvalidAncillary)
+	assert.ErrorIs(t, services.ValidateAncillary(&models.Ancillary{Category: models.AncillaryCategoryBicycle, Name: "Bicycle", CapacityPerTrip: &negative}), services.ErrInvalidAncillary)
+}
+
+func TestAncillaryRemaining(t *testing.T) {
+	capacity := 3
+
+	assert.Nil(t, services.AncillaryRemaining(nil, 10))
+	assert.Equal(t, 1, *services.AncillaryRemaining(&capacity, 2))
+	// Capacity lowered below what is already sold leaves nothing rather than a negative count
+	assert.Equal(t, 0, *services.AncillaryRemaining(&capacity, 5))
+}
+
+func TestFillManifestAncillaries(t *testing.T) {
+	entries := []models.ManifestEntry{{BookingID: 1, SeatNumber: "1A"}, {BookingID: 1, SeatNumber: "1B"}, {BookingID: 2, SeatNumber: "2A"}}
+	services.FillManifestAncillaries(entries, []models.BookingAncillary{
+		{BookingID: 1, Name: "Extra bag", Quantity: 2},
+		{BookingID: 1, Name: "Bicycle", Quantity: 1},
+	})
+
+	assert.Equal(t, "2 x Extra bag, 1 x Bicycle", entries[0].Ancillaries)
+	assert.Equal(t, "2 x Extra bag, 1 x Bicycle", entries[1].Ancillaries)
+	assert.Equal(t, "", entries[2].Ancillaries)
+}
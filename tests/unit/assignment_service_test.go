@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleDepartures(t *testing.T) {
+	stops := []models.RouteStop{
+		{StopSequence: 1, City: "Milano", TimeZone: "UTC"},
+		{StopSequence: 2, City: "Roma", TimeZone: "UTC", OffsetMinutes: 240},
+	}
+	// Overnight departure on weekdays
+	schedule := &models.Schedule{
+		ID:            1,
+		RouteID:       2,
+		VehicleID:     3,
+		DepartureTime: "22:00:00",
+		ArrivalTime:   "02:00:00",
+		DaysOfWeek:    []int64{1, 2, 3, 4, 5},
+		ValidFrom:     time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC),
+		IsActive:      true,
+	}
+	from := time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC) // Tuesday
+	to := from.AddDate(0, 0, 1)
+
+	t.Run("includes departures still running at the start of the period", func(t *testing.T) {
+		departures, err := services.ScheduleDepartures(schedule, stops, nil, from, to)
+		require.NoError(t, err)
+
+		require.Len(t, departures, 2)
+		assert.Equal(t, "2025-03-10", departures[0].TravelDate)
+		assert.True(t, time.Date(2025, 3, 11, 2, 0, 0, 0, time.UTC).Equal(departures[0].ArrivalDatetime))
+		assert.Equal(t, "2025-03-11", departures[1].TravelDate)
+		assert.Equal(t, 3, departures[1].VehicleID)
+	})
+
+	t.Run("honours calendar exceptions", func(t *testing.T) {
+		exceptions := []models.CalendarException{
+			{ExceptionDate: time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), ExceptionType: models.ExceptionTypeRemoved},
+		}
+		departures, err := services.ScheduleDepartures(schedule, stops, exceptions, from, to)
+		require.NoError(t, err)
+
+		require.Len(t, departures, 1)
+		assert.Equal(t, "2025-03-10", departures[0].TravelDate)
+	})
+}
+
+func TestFindOverlaps(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, 3, 10, hour, 0, 0, 0, time.UTC) }
+	morning := services.Assignment{VehicleID: 1, ScheduleID: 1, DepartureDatetime: at(8), ArrivalDatetime: at(12)}
+	noon := services.Assignment{VehicleID: 1, ScheduleID: 2, DepartureDatetime: at(11), ArrivalDatetime: at(14)}
+	afternoon := services.Assignment{VehicleID: 1, ScheduleID: 3, DepartureDatetime: at(14), ArrivalDatetime: at(18)}
+
+	conflicts := services.FindOverlaps([]services.Assignment{afternoon, noon, morning})
+
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, services.ConflictOverlap, conflicts[0].Type)
+	assert.Equal(t, 2, conflicts[0].Assignment.ScheduleID)
+	require.NotNil(t, conflicts[0].ConflictsWith)
+	assert.Equal(t, 1, conflicts[0].ConflictsWith.ScheduleID)
+
+	assert.Empty(t, services.FindOverlaps([]services.Assignment{morning, afternoon}))
+}
@@ -0,0 +1,49 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoardingOutcome(t *testing.T) {
+	travelDate := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	trip := &models.Trip{ID: 1, ScheduleID: 3, TravelDate: travelDate, Status: models.TripStatusScheduled}
+	claims := &services.TicketClaims{BookingCode: "ABC123", BookingSeatID: 7, ScheduleID: 3, TravelDate: "2025-03-10", SeatNumber: "1A"}
+	seat := func() *models.BoardingSeat {
+		return &models.BoardingSeat{BookingSeatID: 7, BookingCode: "ABC123", ScheduleID: 3, TravelDate: travelDate, BookingStatus: "confirmed", SeatNumber: "2C"}
+	}
+
+	result, reason := services.BoardingOutcome(trip, claims, seat())
+	assert.Equal(t, models.BoardingResultBoarded, result)
+	assert.Empty(t, reason)
+
+	boarded := seat()
+	boardedAt := travelDate.Add(8 * time.Hour)
+	boarded.BoardedAt = &boardedAt
+	result, reason = services.BoardingOutcome(trip, claims, boarded)
+	assert.Equal(t, models.BoardingResultDuplicate, result)
+	assert.Equal(t, services.BoardingReasonAlreadyBoarded, reason)
+
+	otherDay := *claims
+	otherDay.TravelDate = "2025-03-11"
+	_, reason = services.BoardingOutcome(trip, &otherDay, seat())
+	assert.Equal(t, services.BoardingReasonWrongTrip, reason)
+
+	_, reason = services.BoardingOutcome(trip, claims, nil)
+	assert.Equal(t, services.BoardingReasonTicketNotFound, reason)
+
+	cancelled := seat()
+	cancelled.BookingStatus = "cancelled"
+	result, reason = services.BoardingOutcome(trip, claims, cancelled)
+	assert.Equal(t, models.BoardingResultRejected, result)
+	assert.Equal(t, services.BoardingReasonBookingCancelled, reason)
+
+	cancelledTrip := *trip
+	cancelledTrip.Status = models.TripStatusCancelled
+	_, reason = services.BoardingOutcome(&cancelledTrip, claims, seat())
+	assert.Equal(t, services.BoardingReasonTripCancelled, reason)
+}
@@ -0,0 +1,39 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunsOn(t *testing.T) {
+	validUntil := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	// Weekdays only, for 2025
+	schedule := &models.Schedule{
+		DaysOfWeek: []int64{1, 2, 3, 4, 5},
+		ValidFrom:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		ValidUntil: &validUntil,
+	}
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("follows days of week within the validity period", func(t *testing.T) {
+		assert.True(t, services.RunsOn(schedule, nil, day(time.March, 10)))  // Monday
+		assert.False(t, services.RunsOn(schedule, nil, day(time.March, 16))) // Sunday
+		assert.False(t, services.RunsOn(schedule, nil, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("removed dates have no service", func(t *testing.T) {
+		christmas := &models.CalendarException{ExceptionType: models.ExceptionTypeRemoved}
+		assert.False(t, services.RunsOn(schedule, christmas, day(time.December, 25))) // Thursday
+	})
+
+	t.Run("added dates run whatever the day of week", func(t *testing.T) {
+		ferragosto := &models.CalendarException{ExceptionType: models.ExceptionTypeAdded}
+		assert.True(t, services.RunsOn(schedule, ferragosto, day(time.August, 17))) // Sunday
+	})
+}
@@ -0,0 +1,38 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCancellable(t *testing.T) {
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	booking := func(status string, departure time.Time, delayed bool) *models.Booking {
+		return &models.Booking{BookingStatus: status, PaymentStatus: "paid", DepartureDatetime: departure, DelayCompensation: delayed}
+	}
+
+	t.Run("upcoming bookings can be cancelled", func(t *testing.T) {
+		assert.NoError(t, services.CheckCancellable(booking("confirmed", now.Add(time.Hour), false), now))
+	})
+
+	t.Run("departed bookings cannot be cancelled", func(t *testing.T) {
+		assert.ErrorIs(t, services.CheckCancellable(booking("confirmed", now.Add(-time.Hour), false), now), services.ErrBookingDeparted)
+	})
+
+	t.Run("long-delayed bookings can be refunded after the scheduled departure", func(t *testing.T) {
+		assert.NoError(t, services.CheckCancellable(booking("confirmed", now.Add(-time.Hour), true), now))
+	})
+
+	t.Run("delayed bookings closed by the no-show run cannot be refunded", func(t *testing.T) {
+		assert.ErrorIs(t, services.CheckCancellable(booking("completed", now.Add(-time.Hour), true), now), services.ErrBookingClosed)
+		assert.ErrorIs(t, services.CheckCancellable(booking("no_show", now.Add(-time.Hour), true), now), services.ErrBookingClosed)
+	})
+
+	t.Run("cancelled bookings cannot be cancelled again", func(t *testing.T) {
+		assert.ErrorIs(t, services.CheckCancellable(booking("cancelled", now.Add(time.Hour), false), now), services.ErrBookingCancelled)
+	})
+}
@@ -0,0 +1,32 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCheckInDetails(t *testing.T) {
+	travelDate := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	details := services.CheckInDetails{
+		DocumentType:   services.DocumentTypePassport,
+		DocumentNumber: "YA1234567",
+		DocumentExpiry: travelDate,
+		Nationality:    "IT",
+	}
+	assert.NoError(t, services.ValidateCheckInDetails(details, travelDate))
+
+	expired := details
+	expired.DocumentExpiry = travelDate.AddDate(0, 0, -1)
+	assert.ErrorIs(t, services.ValidateCheckInDetails(expired, travelDate), services.ErrDocumentExpired)
+
+	licence := details
+	licence.DocumentType = "driving_licence"
+	assert.ErrorIs(t, services.ValidateCheckInDetails(licence, travelDate), services.ErrInvalidPassengerDocument)
+
+	nationality := details
+	nationality.Nationality = "ita"
+	assert.ErrorIs(t, services.ValidateCheckInDetails(nationality, travelDate), services.ErrInvalidPassengerDocument)
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCrewMember(t *testing.T) {
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, services.ValidateCrewMember(&models.CrewMember{FirstName: "Anna", LastName: "Bianchi", Role: models.CrewRoleAttendant}))
+	assert.NoError(t, services.ValidateCrewMember(&models.CrewMember{FirstName: "Marco", LastName: "Rossi", Role: models.CrewRoleDriver, LicenceNumber: "MI1234567", LicenceExpiry: &expiry}))
+	assert.ErrorIs(t, services.ValidateCrewMember(&models.CrewMember{FirstName: "Marco", LastName: "Rossi", Role: models.CrewRoleDriver}), services.ErrLicenceRequired)
+	assert.ErrorIs(t, services.ValidateCrewMember(&models.CrewMember{FirstName: "Marco", LastName: "Rossi", Role: "conductor"}), services.ErrInvalidCrewMember)
+}
+
+func TestCheckDuty(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC) }
+	duty := func(tripID int, role string, from, to time.Time) models.CrewDuty {
+		return models.CrewDuty{TripID: tripID, Duty: role, DepartureDatetime: from, ArrivalDatetime: to}
+	}
+	const maxDriving = 9 * 60
+
+	t.Run("rejects overlapping duties", func(t *testing.T) {
+		duties := []models.CrewDuty{duty(1, models.CrewRoleAttendant, at(10, 8), at(10, 12))}
+		err := services.CheckDuty(duties, duty(2, models.CrewRoleAttendant, at(10, 11), at(10, 14)), maxDriving)
+		assert.ErrorIs(t, err, services.ErrDutyConflict)
+
+		assert.NoError(t, services.CheckDuty(duties, duty(2, models.CrewRoleAttendant, at(10, 12), at(10, 14)), maxDriving))
+	})
+
+	t.Run("counts delays in the duty time", func(t *testing.T) {
+		delayed := duty(1, models.CrewRoleAttendant, at(10, 8), at(10, 12))
+		delayed.DelayMinutes = 90
+		err := services.CheckDuty([]models.CrewDuty{delayed}, duty(2, models.CrewRoleAttendant, at(10, 13), at(10, 15)), maxDriving)
+		assert.ErrorIs(t, err, services.ErrDutyConflict)
+	})
+
+	t.Run("rejects a duty already held on the trip", func(t *testing.T) {
+		duties := []models.CrewDuty{duty(1, models.CrewRoleDriver, at(10, 8), at(10, 12))}
+		assert.ErrorIs(t, services.CheckDuty(duties, duty(1, models.CrewRoleDriver, at(10, 8), at(10, 12)), maxDriving), services.ErrCrewAssigned)
+	})
+
+	t.Run("limits driving time within 24 hours", func(t *testing.T) {
+		duties := []models.CrewDuty{
+			duty(1, models.CrewRoleDriver, at(10, 6), at(10, 10)),
+			duty(2, models.CrewRoleDriver, at(10, 11), at(10, 15)),
+		}
+		assert.Equal(t, 9*60, services.MaxDrivingMinutes(duties, duty(3, models.CrewRoleDriver, at(10, 16), at(10, 17))))
+		assert.NoError(t, services.CheckDuty(duties, duty(3, models.CrewRoleDriver, at(10, 16), at(10, 17)), maxDriving))
+
+		err := services.CheckDuty(duties, duty(3, models.CrewRoleDriver, at(10, 16), at(10, 18)), maxDriving)
+		assert.ErrorIs(t, err, services.ErrDrivingTimeExceeded)
+
+		// Working as attendant is not driving, and the next morning is outside the window
+		assert.NoError(t, services.CheckDuty(duties, duty(3, models.CrewRoleAttendant, at(10, 16), at(10, 20)), maxDriving))
+		assert.NoError(t, services.CheckDuty(duties, duty(3, models.CrewRoleDriver, at(11, 10), at(11, 14)), maxDriving))
+	})
+}
@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalDatetime(t *testing.T) {
+	rome, err := utils.LoadLocation("Europe/Rome")
+	require.NoError(t, err)
+
+	t.Run("keeps the local wall clock with the zone offset", func(t *testing.T) {
+		winter, err := utils.LocalDatetime(time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), "08:00:00", rome)
+		require.NoError(t, err)
+		assert.Equal(t, "2025-01-15T08:00:00+01:00", winter.Format(time.RFC3339))
+
+		summer, err := utils.LocalDatetime(time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC), "08:00", rome)
+		require.NoError(t, err)
+		assert.Equal(t, "2025-07-15T06:00:00Z", summer.UTC().Format(time.RFC3339))
+	})
+
+	t.Run("rejects invalid clocks and zones", func(t *testing.T) {
+		_, err := utils.LocalDatetime(time.Now(), "25:00:00", rome)
+		assert.Error(t, err)
+
+		_, err = utils.LoadLocation("Mars/Olympus")
+		assert.Error(t, err)
+	})
+}
+
+func TestDepartureArrival(t *testing.T) {
+	rome, err := utils.LoadLocation("Europe/Rome")
+	require.NoError(t, err)
+	london, err := utils.LoadLocation("Europe/London")
+	require.NoError(t, err)
+	date := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("same day arrival", func(t *testing.T) {
+		departure, arrival, err := utils.DepartureArrival(date, "08:00:00", rome, "15:00:00", rome)
+		require.NoError(t, err)
+		assert.Equal(t, 7*time.Hour, arrival.Sub(departure))
+	})
+
+	t.Run("overnight arrival moves to the next day", func(t *testing.T) {
+		departure, arrival, err := utils.DepartureArrival(date, "22:30:00", rome, "06:15:00", rome)
+		require.NoError(t, err)
+		assert.Equal(t, "2025-03-11T06:15:00+01:00", arrival.Format(time.RFC3339))
+		assert.Equal(t, 7*time.Hour+45*time.Minute, arrival.Sub(departure))
+	})
+
+	t.Run("arrival read in the destination zone", func(t *testing.T) {
+		// 23:00 in Rome is 22:00 in London, so a 22:30 London arrival is the same evening
+		departure, arrival, err := utils.DepartureArrival(date, "23:00:00", rome, "22:30:00", london)
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Minute, arrival.Sub(departure))
+	})
+
+	t.Run("overnight across the daylight saving change", func(t *testing.T) {
+		// Clocks go forward at 02:00 on 30 March 2025 in Rome
+		departure, arrival, err := utils.DepartureArrival(time.Date(2025, 3, 29, 0, 0, 0, 0, time.UTC), "23:00:00", rome, "05:00:00", rome)
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Hour, arrival.Sub(departure))
+	})
+}
@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeAmountDue(t *testing.T) {
+	// A dearer departure costs the difference plus the fee
+	assert.Equal(t, 25.5, services.ExchangeAmountDue(40, 60.5, 5))
+	// A cheaper one is refunded, minus the fee
+	assert.Equal(t, -15.0, services.ExchangeAmountDue(60, 40, 5))
+	assert.Equal(t, 0.0, services.ExchangeAmountDue(40, 40, 0))
+	assert.Equal(t, 0.1, services.ExchangeAmountDue(0.2, 0.3, 0))
+}
+
+func exchangeableBooking(now time.Time) *models.Booking {
+	return &models.Booking{
+		BookingStatus:     "confirmed",
+		PaymentStatus:     "paid",
+		DepartureDatetime: now.Add(24 * time.Hour),
+		TotalAmount:       52,
+		AncillaryAmount:   12,
+	}
+}
+
+func TestCheckExchangeable(t *testing.T) {
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("confirmed and paid bookings keeping their seat count can be exchanged", func(t *testing.T) {
+		assert.NoError(t, services.CheckExchangeable(exchangeableBooking(now), 2, 2, now))
+	})
+
+	t.Run("journey legs cannot be exchanged on their own", func(t *testing.T) {
+		booking := exchangeableBooking(now)
+		booking.JourneyID = intPtr(7)
+		assert.ErrorIs(t, services.CheckExchangeable(booking, 1, 1, now), services.ErrExchangeJourney)
+	})
+
+	t.Run("the number of seats must not change", func(t *testing.T) {
+		booking := exchangeableBooking(now)
+		assert.ErrorIs(t, services.CheckExchangeable(booking, 2, 1, now), services.ErrExchangeSeats)
+		assert.ErrorIs(t, services.CheckExchangeable(booking, 1, 2, now), services.ErrExchangeSeats)
+	})
+
+	t.Run("departed bookings are only exchanged after a long delay", func(t *testing.T) {
+		booking := exchangeableBooking(now)
+		booking.DepartureDatetime = now
+		assert.ErrorIs(t, services.CheckExchangeable(booking, 1, 1, now), services.ErrBookingDeparted)
+
+		booking.DelayCompensation = true
+		assert.NoError(t, services.CheckExchangeable(booking, 1, 1, now))
+	})
+
+	t.Run("unpaid or cancelled bookings cannot be exchanged", func(t *testing.T) {
+		booking := exchangeableBooking(now)
+		booking.PaymentStatus = "pending"
+		assert.ErrorIs(t, services.CheckExchangeable(booking, 1, 1, now), services.ErrExchangeUnavailable)
+
+		booking.BookingStatus = "cancelled"
+		assert.ErrorIs(t, services.CheckExchangeable(booking, 1, 1, now), services.ErrBookingCancelled)
+	})
+}
+
+func TestQuoteExchange(t *testing.T) {
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("the previous fare leaves out ancillaries", func(t *testing.T) {
+		terms, err := services.QuoteExchange(exchangeableBooking(now), 45, false, 5, nil)
+		require.NoError(t, err)
+		assert.Equal(t, services.ExchangeTerms{PreviousFare: 40, NewFare: 45, ChangeFee: 5, AmountDue: 10}, terms)
+	})
+
+	t.Run("changing only the seats is free", func(t *testing.T) {
+		locked := &models.FareRules{AllowChanges: false}
+		terms, err := services.QuoteExchange(exchangeableBooking(now), 40, true, 5, locked)
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, terms.ChangeFee)
+		assert.Equal(t, 0.0, terms.AmountDue)
+	})
+
+	t.Run("fare families decide on other departures", func(t *testing.T) {
+		_, err := services.QuoteExchange(exchangeableBooking(now), 40, false, 5, &models.FareRules{AllowChanges: false})
+		assert.ErrorIs(t, err, services.ErrFareChangesNotAllowed)
+
+		fee := 0.0
+		terms, err := services.QuoteExchange(exchangeableBooking(now), 40, false, 5, &models.FareRules{AllowChanges: true, ChangeFee: &fee})
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, terms.ChangeFee)
+	})
+
+	t.Run("long-delayed bookings rebook free at no more than they paid", func(t *testing.T) {
+		booking := exchangeableBooking(now)
+		booking.DelayCompensation = true
+
+		terms, err := services.QuoteExchange(booking, 55, false, 5, &models.FareRules{AllowChanges: false})
+		require.NoError(t, err)
+		assert.Equal(t, services.ExchangeTerms{PreviousFare: 40, NewFare: 40}, terms)
+
+		terms, err = services.QuoteExchange(booking, 30, false, 5, nil)
+		require.NoError(t, err)
+		assert.Equal(t, -10.0, terms.AmountDue)
+	})
+}
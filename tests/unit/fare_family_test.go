@@ -0,0 +1,53 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFamilyFare(t *testing.T) {
+	assert.Equal(t, 40.0, services.FamilyFare(40, nil))
+	assert.Equal(t, 34.0, services.FamilyFare(40, &models.FareFamily{PricePercent: 85}))
+	assert.Equal(t, 55.5, services.FamilyFare(40, &models.FareFamily{PricePercent: 120, Surcharge: 7.5}))
+}
+
+func TestCancellationFee(t *testing.T) {
+	assert.Equal(t, 0.0, services.CancellationFee(40, nil))
+	assert.Equal(t, 10.0, services.CancellationFee(40, &models.FareRules{AllowRefunds: true, CancellationFeePercent: 25}))
+	// Non-refundable fares keep the whole fare
+	assert.Equal(t, 40.0, services.CancellationFee(40, &models.FareRules{AllowRefunds: false}))
+	assert.Equal(t, 0.0, services.CancellationFee(0, &models.FareRules{AllowRefunds: false}))
+}
+
+func TestFareChangeFee(t *testing.T) {
+	fee := 3.0
+	assert.Equal(t, 10.0, services.FareChangeFee(10, nil))
+	assert.Equal(t, 10.0, services.FareChangeFee(10, &models.FareRules{}))
+	assert.Equal(t, 3.0, services.FareChangeFee(10, &models.FareRules{ChangeFee: &fee}))
+}
+
+func TestValidateFareFamily(t *testing.T) {
+	valid := models.FareFamily{Name: "Flex", PricePercent: 120, FareRules: models.FareRules{CancellationFeePercent: 10},
+		IncludedAncillaries: []models.FareFamilyAncillary{{AncillaryID: 1, Quantity: 1}}}
+	assert.NoError(t, services.ValidateFareFamily(&valid))
+
+	cases := []func(f *models.FareFamily){
+		func(f *models.FareFamily) { f.Name = " " },
+		func(f *models.FareFamily) { f.PricePercent = 0 },
+		func(f *models.FareFamily) { f.Surcharge = -1 },
+		func(f *models.FareFamily) { f.CancellationFeePercent = 120 },
+		func(f *models.FareFamily) {
+			f.IncludedAncillaries = []models.FareFamilyAncillary{{AncillaryID: 1, Quantity: 1}, {AncillaryID: 1, Quantity: 2}}
+		},
+	}
+	for _, mutate := range cases {
+		family := valid
+		mutate(&family)
+		err := services.ValidateFareFamily(&family)
+		assert.True(t, errors.Is(err, services.ErrInvalidFareFamily))
+	}
+}
@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupDeposit(t *testing.T) {
+	assert.Equal(t, 300.0, services.GroupDeposit(1000, 30))
+	assert.Equal(t, 0.0, services.GroupDeposit(1000, 0))
+	assert.Equal(t, 1000.0, services.GroupDeposit(1000, 120))
+	assert.Equal(t, 33.33, services.GroupDeposit(333.33, 10))
+}
+
+func TestValidateGroupQuote(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	departure := now.AddDate(0, 0, 30)
+	quote := services.GroupQuote{
+		PricePerSeat:      25,
+		DepositPercent:    20,
+		QuoteExpiresAt:    now.AddDate(0, 0, 3),
+		PassengerDeadline: departure.AddDate(0, 0, -7),
+		BalanceDeadline:   departure.AddDate(0, 0, -3),
+	}
+	assert.NoError(t, services.ValidateGroupQuote(quote, now, departure))
+
+	free := quote
+	free.PricePerSeat = 0
+	assert.ErrorIs(t, services.ValidateGroupQuote(free, now, departure), services.ErrInvalidGroupQuote)
+
+	lapsed := quote
+	lapsed.QuoteExpiresAt = now
+	assert.ErrorIs(t, services.ValidateGroupQuote(lapsed, now, departure), services.ErrInvalidGroupQuote)
+
+	early := quote
+	early.BalanceDeadline = now.AddDate(0, 0, 1)
+	assert.ErrorIs(t, services.ValidateGroupQuote(early, now, departure), services.ErrInvalidGroupQuote)
+
+	late := quote
+	late.PassengerDeadline = departure.Add(time.Hour)
+	assert.ErrorIs(t, services.ValidateGroupQuote(late, now, departure), services.ErrInvalidGroupQuote)
+}
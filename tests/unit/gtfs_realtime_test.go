@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoFields decodes one level of a protobuf message into its fields by number
+func protoFields(t *testing.T, message []byte) map[protowire.Number][]interface{} {
+	fields := make(map[protowire.Number][]interface{})
+	for len(message) > 0 {
+		number, wireType, n := protowire.ConsumeTag(message)
+		require.GreaterOrEqual(t, n, 0)
+		message = message[n:]
+
+		var value interface{}
+		switch wireType {
+		case protowire.VarintType:
+			value, n = protowire.ConsumeVarint(message)
+		case protowire.Fixed32Type:
+			value, n = protowire.ConsumeFixed32(message)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(message)
+		default:
+			t.Fatalf("unexpected wire type %d", wireType)
+		}
+		require.GreaterOrEqual(t, n, 0)
+		message = message[n:]
+		fields[number] = append(fields[number], value)
+	}
+	return fields
+}
+
+func TestFeedMessageMarshalProto(t *testing.T) {
+	delay := int32(-120)
+	feed := &gtfs.FeedMessage{
+		Header: gtfs.FeedHeader{GTFSRealtimeVersion: gtfs.RealtimeVersion, Incrementality: "FULL_DATASET", Timestamp: 1735725600},
+		Entity: []gtfs.FeedEntity{
+			{ID: "trip-1", TripUpdate: &gtfs.TripUpdate{
+				Trip:  gtfs.TripDescriptor{TripID: "T1", StartDate: "20250101", ScheduleRelationship: gtfs.TripScheduled},
+				Delay: &delay,
+			}},
+			{ID: "trip-2", TripUpdate: &gtfs.TripUpdate{
+				Trip: gtfs.TripDescriptor{TripID: "T2", StartDate: "20250101", ScheduleRelationship: gtfs.TripCanceled},
+			}},
+		},
+	}
+
+	message := protoFields(t, feed.MarshalProto())
+	header := protoFields(t, message[1][0].([]byte))
+	assert.Equal(t, "2.0", string(header[1][0].([]byte)))
+	assert.Equal(t, uint64(1735725600), header[3][0])
+	require.Len(t, message[2], 2)
+
+	t.Run("encodes delays as signed int32", func(t *testing.T) {
+		entity := protoFields(t, message[2][0].([]byte))
+		assert.Equal(t, "trip-1", string(entity[1][0].([]byte)))
+		update := protoFields(t, entity[3][0].([]byte))
+		assert.Equal(t, int32(-120), int32(update[5][0].(uint64)))
+
+		trip := protoFields(t, update[1][0].([]byte))
+		assert.Equal(t, "T1", string(trip[1][0].([]byte)))
+		assert.Equal(t, "20250101", string(trip[3][0].([]byte)))
+		assert.NotContains(t, trip, protowire.Number(4)) // SCHEDULED is the default
+	})
+
+	t.Run("marks cancelled trips", func(t *testing.T) {
+		entity := protoFields(t, message[2][1].([]byte))
+		update := protoFields(t, entity[3][0].([]byte))
+		trip := protoFields(t, update[1][0].([]byte))
+		assert.Equal(t, uint64(3), trip[4][0])
+	})
+}
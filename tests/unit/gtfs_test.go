@@ -0,0 +1,212 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/gtfs"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sampleFeed = map[string]string{
+	"agency.txt": "\xef\xbb\xbfagency_id,agency_name,agency_url,agency_timezone,agency_phone\n" +
+		"FB,FlixBus Italia,https://flixbus.it,Europe/Rome,+39 02 123\n",
+	"stops.txt": "stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station\n" +
+		"MI,Milano,45.4642,9.1900,1,\n" +
+		"MI_L,Milano Lampugnano,45.4890,9.1060,0,MI\n" +
+		"BG,Bergamo,45.6983,9.6773,0,\n" +
+		"VR,Verona,45.4384,10.9916,0,\n",
+	"routes.txt": "route_id,agency_id,route_short_name,route_long_name,route_type\n" +
+		"R1,FB,,Milano - Verona,3\n",
+	"trips.txt": "route_id,service_id,trip_id\n" +
+		"R1,WD,T1\n" +
+		"R1,WD,T2\n" +
+		"R1,HOL,T3\n" +
+		"R1,WD,T4\n",
+	"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+		"T1,08:00:00,08:00:00,MI_L,1\nT1,08:50:00,08:55:00,BG,2\nT1,10:30:00,10:30:00,VR,3\n" +
+		"T2,17:00:00,17:00:00,MI_L,1\nT2,17:50:00,17:55:00,BG,2\nT2,19:30:00,19:30:00,VR,3\n" +
+		// Express without the Bergamo stop
+		"T3,09:00:00,09:00:00,MI_L,1\nT3,10:40:00,10:40:00,VR,3\n" +
+		// Leaves after midnight of its service day
+		"T4,24:30:00,24:30:00,MI_L,1\nT4,26:00:00,26:00:00,VR,2\n",
+	"calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n" +
+		"WD,1,1,1,1,1,0,0,20250101,20251231\n",
+	"calendar_dates.txt": "service_id,date,exception_type\n" +
+		"WD,20251225,2\n" +
+		"HOL,20250815,1\nHOL,20250817,1\n",
+	"fare_attributes.txt": "fare_id,price,currency_type,payment_method,transfers\n" +
+		"F1,19.90,EUR,1,0\n",
+	"fare_rules.txt": "fare_id,route_id\nF1,R1\n",
+}
+
+func readSampleFeed(t *testing.T) *gtfs.Feed {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for name, content := range sampleFeed {
+		file, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = file.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	feed, err := gtfs.ReadFeed(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	require.NoError(t, err)
+	return feed
+}
+
+func TestReadFeed(t *testing.T) {
+	feed := readSampleFeed(t)
+
+	require.Len(t, feed.Agencies, 1)
+	assert.Equal(t, "FB", feed.Agencies[0].AgencyID) // byte order mark stripped
+	assert.Len(t, feed.Stops, 4)
+	assert.Len(t, feed.StopTimes, 10)
+
+	t.Run("rejects feeds without required files", func(t *testing.T) {
+		var archive bytes.Buffer
+		writer := zip.NewWriter(&archive)
+		file, _ := writer.Create("agency.txt")
+		file.Write([]byte(sampleFeed["agency.txt"]))
+		writer.Close()
+
+		_, err := gtfs.ReadFeed(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+		assert.ErrorIs(t, err, gtfs.ErrMissingFile)
+	})
+}
+
+func TestGTFSTimes(t *testing.T) {
+	seconds, err := gtfs.ParseTime("25:05:30")
+	require.NoError(t, err)
+	assert.Equal(t, 25*3600+5*60+30, seconds)
+	assert.Equal(t, "25:05:30", gtfs.FormatTime(seconds))
+
+	_, err = gtfs.ParseTime("8:5:00")
+	assert.ErrorIs(t, err, gtfs.ErrInvalidTime)
+}
+
+func TestBuildTimetable(t *testing.T) {
+	timetable, err := gtfs.BuildTimetable(readSampleFeed(t), "")
+	require.NoError(t, err)
+
+	t.Run("splits routes by stop pattern", func(t *testing.T) {
+		require.Len(t, timetable.Routes, 2)
+		assert.Equal(t, "R1~1", timetable.Routes[0].GTFSID)
+		assert.Equal(t, "R1~2", timetable.Routes[1].GTFSID)
+
+		stops := timetable.Routes[0].Route.Stops
+		require.Len(t, stops, 3)
+		assert.Equal(t, "Milano", stops[0].City) // parent station
+		assert.Equal(t, "Milano Lampugnano", stops[0].Terminal)
+		assert.Equal(t, "Europe/Rome", stops[0].TimeZone)
+		assert.Equal(t, 50, stops[1].OffsetMinutes)
+		assert.Equal(t, 150, stops[2].OffsetMinutes)
+		assert.InDelta(t, 160, stops[2].DistanceKm, 5)
+		assert.Equal(t, 19.90, timetable.Routes[0].Route.BasePrice)
+	})
+
+	t.Run("turns trips into schedules", func(t *testing.T) {
+		require.Len(t, timetable.Schedules, 3)
+		first := timetable.Schedules[0]
+		assert.Equal(t, "T1", first.GTFSID)
+		assert.Equal(t, "R1~1", first.RouteGTFSID)
+		assert.Equal(t, "08:00:00", first.Schedule.DepartureTime)
+		assert.Equal(t, "10:30:00", first.Schedule.ArrivalTime)
+		assert.Equal(t, []int64{1, 2, 3, 4, 5}, first.Schedule.DaysOfWeek)
+
+		holiday := timetable.Schedules[2]
+		assert.Empty(t, holiday.Schedule.DaysOfWeek)
+		assert.Equal(t, time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC), holiday.Schedule.ValidFrom)
+		assert.Equal(t, time.Date(2025, 8, 17, 0, 0, 0, 0, time.UTC), *holiday.Schedule.ValidUntil)
+	})
+
+	t.Run("keeps calendar dates and reports skipped trips", func(t *testing.T) {
+		require.Len(t, timetable.Calendars, 2)
+		assert.Equal(t, "HOL", timetable.Calendars[0].ServiceID)
+		assert.Equal(t, models.ExceptionTypeRemoved, timetable.Calendars[1].Exceptions[0].ExceptionType)
+		assert.Len(t, timetable.Warnings, 1)
+		assert.Contains(t, timetable.Warnings[0], "T4")
+	})
+
+	t.Run("needs an agency when the feed has several", func(t *testing.T) {
+		feed := readSampleFeed(t)
+		feed.Agencies = append(feed.Agencies, gtfs.Agency{AgencyID: "OT", AgencyTimezone: "Europe/Rome"})
+		_, err := gtfs.BuildTimetable(feed, "")
+		assert.ErrorIs(t, err, gtfs.ErrAgencyRequired)
+	})
+}
+
+func TestBuildFeedRoundTrip(t *testing.T) {
+	timetable, err := gtfs.BuildTimetable(readSampleFeed(t), "")
+	require.NoError(t, err)
+
+	// Load the timetable as the importer would store it
+	data := gtfs.ExportData{
+		Company:    models.Company{ID: 7, Name: "FlixBus Italia"},
+		AgencyID:   "FB",
+		Exceptions: make(map[int][]models.CalendarException),
+	}
+	calendarIDs := make(map[string]int)
+	for i, calendar := range timetable.Calendars {
+		calendarIDs[calendar.ServiceID] = i + 1
+		data.Exceptions[i+1] = calendar.Exceptions
+	}
+	for i, timetableRoute := range timetable.Routes {
+		route := timetableRoute.Route
+		route.ID = i + 1
+		for j := range route.Stops {
+			route.Stops[j].StopSequence = j + 1
+		}
+		route.OriginCity = route.Stops[0].City
+		route.DestinationCity = route.Stops[len(route.Stops)-1].City
+		exportRoute := gtfs.ExportRoute{GTFSID: timetableRoute.GTFSID, Route: route}
+		for _, timetableSchedule := range timetable.Schedules {
+			if timetableSchedule.RouteGTFSID != timetableRoute.GTFSID {
+				continue
+			}
+			schedule := timetableSchedule.Schedule
+			if calendarID, ok := calendarIDs[timetableSchedule.ServiceID]; ok {
+				schedule.CalendarID = &calendarID
+			}
+			exportRoute.Schedules = append(exportRoute.Schedules, gtfs.ExportSchedule{GTFSID: timetableSchedule.GTFSID, Schedule: schedule})
+		}
+		data.Routes = append(data.Routes, exportRoute)
+	}
+
+	_, _, err = gtfs.BuildFeed(data, gtfs.ExportOptions{})
+	assert.ErrorIs(t, err, gtfs.ErrAgencyURLRequired)
+
+	feed, warnings, err := gtfs.BuildFeed(data, gtfs.ExportOptions{AgencyURL: "https://flixbus.it", Currency: "EUR"})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "Europe/Rome", feed.Agencies[0].AgencyTimezone)
+	assert.Len(t, feed.Stops, 4) // Lampugnano, its parent station, Bergamo and Verona
+
+	var archive bytes.Buffer
+	require.NoError(t, gtfs.WriteFeed(&archive, feed))
+	reread, err := gtfs.ReadFeed(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	require.NoError(t, err)
+
+	again, err := gtfs.BuildTimetable(reread, "")
+	require.NoError(t, err)
+	require.Len(t, again.Routes, len(timetable.Routes))
+	require.Len(t, again.Schedules, len(timetable.Schedules))
+	for i := range timetable.Routes {
+		assert.Equal(t, timetable.Routes[i].GTFSID, again.Routes[i].GTFSID)
+		assert.Equal(t, timetable.Routes[i].Route.BasePrice, again.Routes[i].Route.BasePrice)
+		for j, stop := range timetable.Routes[i].Route.Stops {
+			assert.Equal(t, stop.City, again.Routes[i].Route.Stops[j].City)
+			assert.Equal(t, stop.OffsetMinutes, again.Routes[i].Route.Stops[j].OffsetMinutes)
+		}
+	}
+	for i, schedule := range timetable.Schedules {
+		assert.Equal(t, schedule.GTFSID, again.Schedules[i].GTFSID)
+		assert.Equal(t, schedule.Schedule.DepartureTime, again.Schedules[i].Schedule.DepartureTime)
+		assert.Equal(t, schedule.Schedule.DaysOfWeek, again.Schedules[i].Schedule.DaysOfWeek)
+	}
+}
@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func leg(scheduleID int, from, to string, departure, arrival string, price float64) models.ItineraryLeg {
+	parse := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			panic(err)
+		}
+		return parsed
+	}
+	return models.ItineraryLeg{
+		ScheduleID:        scheduleID,
+		TravelDate:        departure[:10],
+		OriginCity:        from,
+		DestinationCity:   to,
+		DepartureDatetime: parse(departure),
+		ArrivalDatetime:   parse(arrival),
+		Price:             price,
+	}
+}
+
+func TestBuildItineraries(t *testing.T) {
+	opts := services.ItineraryOptions{MaxTransfers: 1, MinConnection: 30 * time.Minute, MaxConnection: 4 * time.Hour, Passengers: 1}
+	legs := []models.ItineraryLeg{
+		leg(1, "Roma", "Napoli", "2025-06-01 08:00", "2025-06-01 14:00", 30),
+		leg(2, "Roma", "Firenze", "2025-06-01 07:00", "2025-06-01 10:00", 20),
+		leg(3, "Firenze", "Napoli", "2025-06-01 10:15", "2025-06-01 13:00", 15),
+		leg(4, "Firenze", "Napoli", "2025-06-01 11:00", "2025-06-01 15:30", 12),
+		leg(5, "Firenze", "Napoli", "2025-06-01 16:00", "2025-06-01 19:00", 10),
+		leg(6, "Napoli", "Roma", "2025-06-01 15:00", "2025-06-01 18:00", 25),
+	}
+
+	t.Run("combines legs within the connection window and ranks by duration", func(t *testing.T) {
+		itineraries := services.BuildItineraries(legs, "Roma", "Napoli", opts)
+
+		// Leg 3 leaves too soon after arriving in Firenze and leg 5 too late
+		require.Len(t, itineraries, 2)
+		assert.Equal(t, 0, itineraries[0].Transfers)
+		assert.Equal(t, 360, itineraries[0].TotalDurationMinutes)
+		assert.Equal(t, 1, itineraries[1].Transfers)
+		assert.Equal(t, 2, itineraries[1].Legs[0].ScheduleID)
+		assert.Equal(t, 4, itineraries[1].Legs[1].ScheduleID)
+		assert.Equal(t, 32.0, itineraries[1].TotalPrice)
+	})
+
+	t.Run("ties on duration are broken by price", func(t *testing.T) {
+		cheaper := leg(7, "Roma", "Napoli", "2025-06-01 09:00", "2025-06-01 15:00", 18)
+		itineraries := services.BuildItineraries(append(legs, cheaper), "Roma", "Napoli", opts)
+
+		require.Len(t, itineraries, 3)
+		assert.Equal(t, 7, itineraries[0].Legs[0].ScheduleID)
+	})
+
+	t.Run("transfers are limited", func(t *testing.T) {
+		direct := opts
+		direct.MaxTransfers = 0
+		itineraries := services.BuildItineraries(legs, "Roma", "Napoli", direct)
+
+		require.Len(t, itineraries, 1)
+		assert.Equal(t, 1, itineraries[0].Legs[0].ScheduleID)
+	})
+
+	t.Run("legs sharing only a terminal connect", func(t *testing.T) {
+		arriving := leg(8, "Milano", "Sesto San Giovanni", "2025-06-01 08:00", "2025-06-01 08:30", 5)
+		arriving.DestinationTerminal = "Autostazione Nord"
+		departing := leg(9, "Cologno Monzese", "Bergamo", "2025-06-01 09:30", "2025-06-01 10:30", 8)
+		departing.OriginTerminal = "Autostazione Nord"
+
+		itineraries := services.BuildItineraries([]models.ItineraryLeg{arriving, departing}, "Milano", "Bergamo", opts)
+		assert.Len(t, itineraries, 1)
+	})
+}
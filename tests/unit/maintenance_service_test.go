@@ -0,0 +1,43 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceAt(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, 3, 10, hour, 0, 0, 0, time.UTC) }
+	windows := []models.MaintenanceWindow{{ID: 1, StartsAt: at(9), EndsAt: at(13), Reason: "Brake inspection"}}
+
+	window := services.MaintenanceAt(windows, at(12), at(15))
+	require.NotNil(t, window)
+	assert.Equal(t, 1, window.ID)
+
+	assert.Nil(t, services.MaintenanceAt(windows, at(6), at(9)))
+	assert.Nil(t, services.MaintenanceAt(windows, at(13), at(17)))
+}
+
+func TestCompatibleSeats(t *testing.T) {
+	seats := func(types ...string) []models.Seat {
+		var result []models.Seat
+		for i, seatType := range types {
+			result = append(result, models.Seat{SeatNumber: string(rune('1' + i)), SeatType: seatType})
+		}
+		return result
+	}
+	original := &models.Vehicle{TotalSeats: 3}
+	candidate := &models.Vehicle{TotalSeats: 4}
+
+	assert.True(t, services.CompatibleSeats(original, seats("premium", "standard", "standard"), candidate, seats("premium", "standard", "standard", "standard")))
+	assert.False(t, services.CompatibleSeats(original, seats("premium", "standard", "standard"), candidate, seats("standard", "standard", "standard", "standard")))
+	assert.False(t, services.CompatibleSeats(original, seats("premium", "standard", "standard"), candidate, seats("premium", "standard")))
+
+	// Without a seat map only the capacity counts
+	assert.True(t, services.CompatibleSeats(original, nil, candidate, nil))
+	assert.False(t, services.CompatibleSeats(candidate, nil, original, nil))
+}
@@ -0,0 +1,49 @@
+package unit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/documents"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFillManifestStops(t *testing.T) {
+	stops := []models.RouteStop{
+		{StopSequence: 1, City: "Milano", Terminal: "Lampugnano"},
+		{StopSequence: 2, City: "Bologna"},
+		{StopSequence: 3, City: "Roma", Terminal: "Tiburtina"},
+	}
+	origin, destination := 2, 3
+	entries := []models.ManifestEntry{
+		{BookingCode: "ABC123"},
+		{BookingCode: "DEF456", OriginStopSequence: &origin, DestinationStopSequence: &destination},
+	}
+
+	services.FillManifestStops(entries, stops)
+
+	assert.Equal(t, 1, entries[0].BoardingStopSequence)
+	assert.Equal(t, "Milano - Lampugnano", entries[0].BoardingStop)
+	assert.Equal(t, "Roma - Tiburtina", entries[0].AlightingStop)
+	assert.Equal(t, 2, entries[1].BoardingStopSequence)
+	assert.Equal(t, "Bologna", entries[1].BoardingStop)
+	assert.Equal(t, 3, entries[1].AlightingStopSequence)
+}
+
+func TestManifestCSV(t *testing.T) {
+	manifest := &services.Manifest{Passengers: []models.ManifestEntry{{
+		SeatNumber: "1A", BookingCode: "ABC123", PassengerName: "Rossi, Marco", PassengerDocument: "AB1234567",
+		BoardingStopSequence: 1, BoardingStop: "Milano", AlightingStopSequence: 3, AlightingStop: "Roma",
+		BookingStatus: "confirmed", PaymentStatus: "paid", Ancillaries: "1 x Bicycle",
+	}}}
+
+	var out bytes.Buffer
+	require.NoError(t, documents.ManifestCSV(&out, manifest))
+	assert.Equal(t,
+		"seat,booking_code,passenger_name,passenger_document,passenger_phone,boarding_stop_sequence,boarding_stop,alighting_stop_sequence,alighting_stop,booking_status,payment_status,checked_in_at,boarded_at,ancillaries\n"+
+			"1A,ABC123,\"Rossi, Marco\",AB1234567,,1,Milano,3,Roma,confirmed,paid,,,1 x Bicycle\n",
+		out.String())
+}
@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoShowFee(t *testing.T) {
+	assert.Equal(t, 45.5, services.NoShowFee(45.5, 100))
+	assert.Equal(t, 11.38, services.NoShowFee(45.5, 25))
+	assert.Equal(t, 0.0, services.NoShowFee(45.5, 0))
+	assert.Equal(t, 45.5, services.NoShowFee(45.5, 150))
+}
+
+func TestAttendanceOutcome(t *testing.T) {
+	assert.Equal(t, "no_show", services.AttendanceOutcome(true, 0))
+	assert.Equal(t, "completed", services.AttendanceOutcome(true, 1))
+	// Without boarding scans nobody can be told apart, so everyone travelled
+	assert.Equal(t, "completed", services.AttendanceOutcome(false, 0))
+}
+
+func TestDecideNoShow(t *testing.T) {
+	paid := func() *models.Booking {
+		return &models.Booking{TotalAmount: 40, PaymentStatus: "paid"}
+	}
+
+	t.Run("boarded passengers complete their trip", func(t *testing.T) {
+		outcome := services.DecideNoShow(paid(), true, 1, 25)
+		assert.Equal(t, services.NoShowOutcome{Status: "completed"}, outcome)
+	})
+
+	t.Run("checking in is not boarding", func(t *testing.T) {
+		booking := paid()
+		checkedIn := time.Now()
+		booking.CheckedInAt = &checkedIn
+
+		outcome := services.DecideNoShow(booking, true, 0, 25)
+		assert.Equal(t, services.NoShowOutcome{Status: "no_show", Fee: 10, Refund: 30}, outcome)
+	})
+
+	t.Run("a zero fee refunds the whole fare", func(t *testing.T) {
+		outcome := services.DecideNoShow(paid(), true, 0, 0)
+		assert.Equal(t, services.NoShowOutcome{Status: "no_show", Fee: 0, Refund: 40}, outcome)
+	})
+
+	t.Run("a full fee refunds nothing", func(t *testing.T) {
+		outcome := services.DecideNoShow(paid(), true, 0, 100)
+		assert.Equal(t, services.NoShowOutcome{Status: "no_show", Fee: 40, Refund: 0}, outcome)
+	})
+
+	t.Run("unpaid no-shows are neither charged nor refunded", func(t *testing.T) {
+		booking := paid()
+		booking.PaymentStatus = "pending"
+
+		outcome := services.DecideNoShow(booking, true, 0, 25)
+		assert.Equal(t, services.NoShowOutcome{Status: "no_show"}, outcome)
+	})
+
+	t.Run("the fee is charged on the fare, ancillaries are refunded", func(t *testing.T) {
+		booking := paid()
+		booking.AncillaryAmount = 8
+
+		outcome := services.DecideNoShow(booking, true, 0, 25)
+		assert.Equal(t, services.NoShowOutcome{Status: "no_show", Fee: 8, Refund: 32}, outcome)
+	})
+
+	t.Run("non-refundable fares keep the whole fare", func(t *testing.T) {
+		booking := paid()
+		booking.AncillaryAmount = 8
+		booking.FareRules = &models.FareRules{AllowRefunds: false}
+
+		outcome := services.DecideNoShow(booking, true, 0, 25)
+		assert.Equal(t, services.NoShowOutcome{Status: "no_show", Fee: 32, Refund: 8}, outcome)
+	})
+
+	t.Run("a higher cancellation fee of the fare rules applies", func(t *testing.T) {
+		booking := paid()
+		booking.FareRules = &models.FareRules{AllowRefunds: true, CancellationFeePercent: 50}
+
+		outcome := services.DecideNoShow(booking, true, 0, 25)
+		assert.Equal(t, services.NoShowOutcome{Status: "no_show", Fee: 20, Refund: 20}, outcome)
+	})
+
+	t.Run("untracked trips complete every booking", func(t *testing.T) {
+		outcome := services.DecideNoShow(paid(), false, 0, 25)
+		assert.Equal(t, services.NoShowOutcome{Status: "completed"}, outcome)
+	})
+}
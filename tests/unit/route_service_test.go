@@ -0,0 +1,156 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func romaMilanoRoute() models.Route {
+	return models.Route{
+		BasePrice: 45.00,
+		Stops: []models.RouteStop{
+			{City: "Roma", Terminal: "Stazione Tiburtina"},
+			{City: "Firenze", Terminal: "Stazione Santa Maria Novella", OffsetMinutes: 180, DistanceKm: 275},
+			{City: "Bologna", Terminal: "Autostazione", OffsetMinutes: 270, DistanceKm: 375},
+			{City: "Milano", Terminal: "Stazione Centrale", OffsetMinutes: 420, DistanceKm: 570},
+		},
+	}
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestNormalizeRouteStops(t *testing.T) {
+	t.Run("numbers stops and syncs the route endpoints", func(t *testing.T) {
+		route := romaMilanoRoute()
+		require.NoError(t, services.NormalizeRouteStops(&route))
+
+		for i, stop := range route.Stops {
+			assert.Equal(t, i+1, stop.StopSequence)
+		}
+		assert.Equal(t, "Roma", route.OriginCity)
+		assert.Equal(t, "Milano", route.DestinationCity)
+		assert.Equal(t, 570, route.DistanceKm)
+		assert.Equal(t, 420, route.EstimatedDurationMinutes)
+	})
+
+	t.Run("builds origin and destination stops when none are given", func(t *testing.T) {
+		route := models.Route{OriginCity: "Torino", DestinationCity: "Genova", DistanceKm: 170, EstimatedDurationMinutes: 150}
+		require.NoError(t, services.NormalizeRouteStops(&route))
+
+		require.Len(t, route.Stops, 2)
+		assert.Equal(t, "Genova", route.Stops[1].City)
+		assert.Equal(t, 150, route.Stops[1].OffsetMinutes)
+	})
+
+	t.Run("rejects stops going back in time", func(t *testing.T) {
+		route := romaMilanoRoute()
+		route.Stops[2].OffsetMinutes = 100
+		assert.ErrorIs(t, services.NormalizeRouteStops(&route), services.ErrInvalidStops)
+	})
+
+	t.Run("stops inherit the time zone of the previous stop", func(t *testing.T) {
+		route := romaMilanoRoute()
+		route.Stops[0].TimeZone = "Europe/Rome"
+		require.NoError(t, services.NormalizeRouteStops(&route))
+		assert.Equal(t, "Europe/Rome", route.Stops[3].TimeZone)
+	})
+
+	t.Run("rejects unknown time zones", func(t *testing.T) {
+		route := romaMilanoRoute()
+		route.Stops[1].TimeZone = "Europe/Atlantis"
+		assert.ErrorIs(t, services.NormalizeRouteStops(&route), services.ErrInvalidTimeZone)
+	})
+
+	t.Run("rejects backward fares", func(t *testing.T) {
+		route := romaMilanoRoute()
+		route.Fares = []models.RouteFare{{OriginStopSequence: 3, DestinationStopSequence: 2, Price: 10}}
+		assert.ErrorIs(t, services.NormalizeRouteStops(&route), services.ErrInvalidFare)
+	})
+}
+
+func TestStopsChanged(t *testing.T) {
+	current := romaMilanoRoute().Stops
+
+	t.Run("coordinates and GTFS ids do not change the stops", func(t *testing.T) {
+		updated := romaMilanoRoute().Stops
+		latitude := 43.77
+		updated[1].Latitude = &latitude
+		updated[1].GTFSStopID = "FI-SMN"
+		assert.False(t, services.StopsChanged(current, updated))
+	})
+
+	t.Run("moved or renamed stops change the stops", func(t *testing.T) {
+		updated := romaMilanoRoute().Stops
+		updated[2].OffsetMinutes = 280
+		assert.True(t, services.StopsChanged(current, updated))
+
+		updated = romaMilanoRoute().Stops
+		updated[1].Terminal = "Villa Costanza"
+		assert.True(t, services.StopsChanged(current, updated))
+	})
+
+	t.Run("added or removed stops change the stops", func(t *testing.T) {
+		updated := romaMilanoRoute().Stops
+		assert.True(t, services.StopsChanged(current, append(updated[:1], updated[2:]...)))
+	})
+}
+
+func TestResolveSegment(t *testing.T) {
+	route := romaMilanoRoute()
+	require.NoError(t, services.NormalizeRouteStops(&route))
+
+	t.Run("defaults to the whole route", func(t *testing.T) {
+		origin, destination, err := services.ResolveSegment(route.Stops, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Roma", origin.City)
+		assert.Equal(t, "Milano", destination.City)
+	})
+
+	t.Run("resolves intermediate stops", func(t *testing.T) {
+		origin, destination, err := services.ResolveSegment(route.Stops, intPtr(2), intPtr(3))
+		require.NoError(t, err)
+		assert.Equal(t, "Firenze", origin.City)
+		assert.Equal(t, "Bologna", destination.City)
+	})
+
+	t.Run("rejects unknown or reversed stops", func(t *testing.T) {
+		_, _, err := services.ResolveSegment(route.Stops, intPtr(3), intPtr(2))
+		assert.ErrorIs(t, err, services.ErrInvalidSegment)
+
+		_, _, err = services.ResolveSegment(route.Stops, intPtr(1), intPtr(9))
+		assert.ErrorIs(t, err, services.ErrInvalidSegment)
+	})
+}
+
+func TestSegmentFare(t *testing.T) {
+	route := romaMilanoRoute()
+	require.NoError(t, services.NormalizeRouteStops(&route))
+	stops := route.Stops
+
+	t.Run("whole route costs the base price", func(t *testing.T) {
+		assert.Equal(t, 45.00, services.SegmentFare(route.BasePrice, stops, nil, stops[0], stops[3]))
+	})
+
+	t.Run("segments are pro-rated by distance", func(t *testing.T) {
+		// Firenze-Milano is 295 of 570 km
+		assert.Equal(t, 23.29, services.SegmentFare(route.BasePrice, stops, nil, stops[1], stops[3]))
+	})
+
+	t.Run("explicit stop-pair fares win", func(t *testing.T) {
+		fares := []models.RouteFare{{OriginStopSequence: 1, DestinationStopSequence: 2, Price: 19.90}}
+		assert.Equal(t, 19.90, services.SegmentFare(route.BasePrice, stops, fares, stops[0], stops[1]))
+	})
+}
+
+func TestRoundTripDiscount(t *testing.T) {
+	assert.Equal(t, 4.5, services.RoundTripDiscount(45.00, 10))
+	assert.Equal(t, 2.33, services.RoundTripDiscount(23.29, 10))
+	assert.Equal(t, 0.0, services.RoundTripDiscount(45.00, 0))
+	assert.Equal(t, 45.00, services.RoundTripDiscount(45.00, 150))
+}
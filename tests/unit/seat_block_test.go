@@ -0,0 +1,25 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSeatBlockRequest(t *testing.T) {
+	negative := -10
+	release := 60
+
+	assert.NoError(t, services.ValidateSeatBlockRequest(services.SeatBlockRequest{SeatIDs: []int{1, 2}, Reason: models.SeatBlockReasonStaff}))
+	assert.NoError(t, services.ValidateSeatBlockRequest(services.SeatBlockRequest{SeatCount: 5, Reason: models.SeatBlockReasonAllotment, ReleaseMinutesBefore: &release}))
+
+	// Either seats or a number of seats, never both nor none
+	assert.ErrorIs(t, services.ValidateSeatBlockRequest(services.SeatBlockRequest{SeatIDs: []int{1}, SeatCount: 2, Reason: models.SeatBlockReasonOther}), services.ErrInvalidSeatBlock)
+	assert.ErrorIs(t, services.ValidateSeatBlockRequest(services.SeatBlockRequest{Reason: models.SeatBlockReasonOther}), services.ErrInvalidSeatBlock)
+	assert.ErrorIs(t, services.ValidateSeatBlockRequest(services.SeatBlockRequest{SeatCount: -1, Reason: models.SeatBlockReasonOther}), services.ErrInvalidSeatBlock)
+
+	assert.ErrorIs(t, services.ValidateSeatBlockRequest(services.SeatBlockRequest{SeatCount: 2, Reason: "vip"}), services.ErrInvalidSeatBlock)
+	assert.ErrorIs(t, services.ValidateSeatBlockRequest(services.SeatBlockRequest{SeatCount: 2, Reason: models.SeatBlockReasonEscort, ReleaseMinutesBefore: &negative}), services.ErrInvalidSeatBlock)
+}
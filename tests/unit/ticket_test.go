@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyTicket(t *testing.T) {
+	key, err := utils.LoadTicketKey("", "test-secret")
+	require.NoError(t, err)
+
+	boarding := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	claims := services.TicketClaims{
+		BookingCode:   "ABC123",
+		BookingSeatID: 7,
+		ScheduleID:    3,
+		TravelDate:    "2025-03-10",
+		SeatNumber:    "1A",
+		PassengerName: "Marco Rossi",
+		ValidFrom:     boarding.Add(-services.TicketValidBefore).Unix(),
+		ValidUntil:    boarding.Add(4*time.Hour + services.TicketValidAfter).Unix(),
+	}
+	payload, err := services.SignTicket(key, claims)
+	require.NoError(t, err)
+
+	verified, err := services.VerifyTicket(key.PublicKey(), payload, boarding)
+	require.NoError(t, err)
+	assert.Equal(t, claims, *verified)
+
+	_, err = services.VerifyTicket(key.PublicKey(), payload, boarding.Add(-25*time.Hour))
+	assert.ErrorIs(t, err, services.ErrTicketNotYetValid)
+	_, err = services.VerifyTicket(key.PublicKey(), payload, boarding.Add(17*time.Hour))
+	assert.ErrorIs(t, err, services.ErrTicketExpired)
+
+	t.Run("rejects tampered payloads", func(t *testing.T) {
+		parts := strings.Split(payload, ".")
+		forged := claims
+		forged.SeatNumber = "1B"
+		forgedPayload, err := services.SignTicket(key, forged)
+		require.NoError(t, err)
+		tampered := parts[0] + "." + strings.Split(forgedPayload, ".")[1] + "." + parts[2]
+
+		_, err = services.VerifyTicket(key.PublicKey(), tampered, boarding)
+		assert.ErrorIs(t, err, services.ErrInvalidTicket)
+	})
+
+	t.Run("rejects other keys", func(t *testing.T) {
+		other, err := utils.LoadTicketKey("", "other-secret")
+		require.NoError(t, err)
+		_, err = services.VerifyTicket(other.PublicKey(), payload, boarding)
+		assert.ErrorIs(t, err, services.ErrInvalidTicket)
+	})
+}
+
+func TestLoadTicketKey(t *testing.T) {
+	_, err := utils.LoadTicketKey("dG9vLXNob3J0", "")
+	assert.Error(t, err)
+
+	key, err := utils.LoadTicketKey("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", "")
+	require.NoError(t, err)
+	assert.Len(t, key.ID, 16)
+}
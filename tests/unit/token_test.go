@@ -0,0 +1,30 @@
+package unit
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCode(t *testing.T) {
+	t.Run("codes have a fixed length that fits the code columns", func(t *testing.T) {
+		code, err := utils.GenerateCode("BK")
+		require.NoError(t, err)
+		assert.Len(t, code, 2+utils.CodeLength)
+		assert.LessOrEqual(t, len(code), 20)
+		assert.Regexp(t, regexp.MustCompile(`^BK[2-9A-HJ-NP-Z]+$`), code)
+	})
+
+	t.Run("codes do not repeat", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for i := 0; i < 1000; i++ {
+			code, err := utils.GenerateCode("BK")
+			require.NoError(t, err)
+			assert.False(t, seen[code], code)
+			seen[code] = true
+		}
+	})
+}
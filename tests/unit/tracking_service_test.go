@@ -0,0 +1,58 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHaversineKm(t *testing.T) {
+	assert.InDelta(t, 141, utils.HaversineKm(45.4642, 9.1900, 45.4384, 10.9916), 1) // Milano - Verona
+	assert.Zero(t, utils.HaversineKm(45.4642, 9.1900, 45.4642, 9.1900))
+}
+
+func TestEstimateProgress(t *testing.T) {
+	coordinate := func(value float64) *float64 { return &value }
+	// Milano, Brescia, Verona along the A4
+	stops := []models.RouteStop{
+		{StopSequence: 1, City: "Milano", TimeZone: "Europe/Rome", Latitude: coordinate(45.4642), Longitude: coordinate(9.1900)},
+		{StopSequence: 2, City: "Brescia", TimeZone: "Europe/Rome", OffsetMinutes: 60, Latitude: coordinate(45.5416), Longitude: coordinate(10.2118)},
+		{StopSequence: 3, City: "Verona", TimeZone: "Europe/Rome", OffsetMinutes: 120, Latitude: coordinate(45.4384), Longitude: coordinate(10.9916)},
+	}
+	departure := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+
+	t.Run("estimates from the vehicle position", func(t *testing.T) {
+		// Halfway to Brescia, due at 08:30 but seen at 08:40
+		position := &models.VehiclePosition{Latitude: 45.5029, Longitude: 9.7009, RecordedAt: departure.Add(40 * time.Minute)}
+		progress := services.EstimateProgress(stops, departure, 0, position, position.RecordedAt)
+
+		assert.Equal(t, 10, progress.DelayMinutes)
+		require.NotNil(t, progress.NextStopSequence)
+		assert.Equal(t, 2, *progress.NextStopSequence)
+		assert.InDelta(t, 40, *progress.DistanceToNextStopKm, 1)
+		assert.True(t, progress.Stops[0].Passed)
+		assert.False(t, progress.Stops[1].Passed)
+		assert.True(t, departure.Add(130*time.Minute).Equal(progress.Stops[2].EstimatedAt))
+	})
+
+	t.Run("running early counts as on time", func(t *testing.T) {
+		position := &models.VehiclePosition{Latitude: 45.5029, Longitude: 9.7009, RecordedAt: departure.Add(20 * time.Minute)}
+		progress := services.EstimateProgress(stops, departure, 0, position, position.RecordedAt)
+		assert.Zero(t, progress.DelayMinutes)
+	})
+
+	t.Run("falls back to the reported delay", func(t *testing.T) {
+		progress := services.EstimateProgress(stops, departure, 15, nil, departure.Add(70*time.Minute))
+
+		assert.Equal(t, 15, progress.DelayMinutes)
+		assert.Nil(t, progress.DistanceToNextStopKm)
+		assert.True(t, progress.Stops[0].Passed)
+		assert.False(t, progress.Stops[1].Passed) // due at 09:15 with the delay
+		assert.Equal(t, 2, *progress.NextStopSequence)
+	})
+}
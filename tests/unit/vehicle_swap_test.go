@@ -0,0 +1,125 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapSeats(t *testing.T) {
+	sequence := func(value int) *int { return &value }
+	booked := []models.BookedSeat{
+		{BookingID: 1, SeatID: 101, SeatNumber: "1A", SeatType: "premium"},
+		{BookingID: 2, SeatID: 102, SeatNumber: "9C", SeatType: "disabled"},
+		{BookingID: 3, SeatID: 103, SeatNumber: "9D", SeatType: "standard"},
+		{BookingID: 4, SeatID: 104, SeatNumber: "2B", SeatType: "standard", OriginStopSequence: sequence(1), DestinationStopSequence: sequence(2)},
+		{BookingID: 5, SeatID: 105, SeatNumber: "2C", SeatType: "standard", OriginStopSequence: sequence(2), DestinationStopSequence: sequence(3)},
+	}
+	seats := []models.Seat{
+		{ID: 201, SeatNumber: "1A", SeatType: "premium", IsAvailable: true},
+		{ID: 202, SeatNumber: "1B", SeatType: "disabled", IsAvailable: false},
+		{ID: 203, SeatNumber: "2B", SeatType: "standard", IsAvailable: true},
+	}
+
+	changes := services.MapSeats(booked, seats)
+	require.Len(t, changes, 5)
+
+	// Same number first
+	require.NotNil(t, changes[0].ToSeatID)
+	assert.Equal(t, 201, *changes[0].ToSeatID)
+	assert.Equal(t, services.SeatMatchNumber, changes[0].Match)
+
+	// Unavailable seats are skipped and 2B goes to its own booking before being shared
+	assert.Equal(t, 203, *changes[3].ToSeatID)
+	assert.Equal(t, services.SeatMatchNumber, changes[3].Match)
+
+	// 2B is free again from stop 2, so it takes the next leg of another booking
+	require.NotNil(t, changes[4].ToSeatID)
+	assert.Equal(t, 203, *changes[4].ToSeatID)
+	assert.Equal(t, services.SeatMatchType, changes[4].Match)
+
+	// No seat is left for the whole route
+	assert.Nil(t, changes[1].ToSeatID)
+	assert.Nil(t, changes[2].ToSeatID)
+	assert.Empty(t, changes[2].Match)
+}
+
+func TestMapSeatsFallsBackToAnySeat(t *testing.T) {
+	booked := []models.BookedSeat{{BookingID: 1, SeatID: 101, SeatNumber: "12A", SeatType: "premium"}}
+	seats := []models.Seat{{ID: 201, SeatNumber: "3D", SeatType: "standard", IsAvailable: true}}
+
+	changes := services.MapSeats(booked, seats)
+
+	require.NotNil(t, changes[0].ToSeatID)
+	assert.Equal(t, "3D", changes[0].ToSeatNumber)
+	assert.Equal(t, services.SeatMatchAny, changes[0].Match)
+}
+
+func TestMapTripSeatsKeepsGroupSeats(t *testing.T) {
+	sequence := func(value int) *int { return &value }
+	booked := []models.BookedSeat{{BookingID: 1, SeatID: 101, SeatNumber: "1A", SeatType: "standard"}}
+	held := []models.HeldSeat{
+		{Kind: models.SeatHoldGroup, ID: 7, SeatID: 102, SeatNumber: "1B", SeatType: "standard", OriginStopSequence: sequence(1), DestinationStopSequence: sequence(3)},
+		{Kind: models.SeatHoldGroup, ID: 8, SeatID: 103, SeatNumber: "1C", SeatType: "standard", OriginStopSequence: sequence(1), DestinationStopSequence: sequence(3)},
+	}
+	seats := []models.Seat{
+		{ID: 201, SeatNumber: "1A", SeatType: "standard", IsAvailable: true},
+		{ID: 202, SeatNumber: "1B", SeatType: "standard", IsAvailable: true},
+	}
+
+	changes, holds := services.MapTripSeats(booked, held, seats)
+	require.Len(t, changes, 1)
+	require.Len(t, holds, 2)
+
+	assert.Equal(t, 201, *changes[0].ToSeatID)
+	require.NotNil(t, holds[0].ToSeatID)
+	assert.Equal(t, 202, *holds[0].ToSeatID)
+	assert.Equal(t, 7, holds[0].ID)
+
+	// Group seats do not take seats already given to bookings
+	assert.Nil(t, holds[1].ToSeatID)
+}
+
+func TestMapTripSeatsMovesSeatBlocksAndOffers(t *testing.T) {
+	sequence := func(value int) *int { return &value }
+	booked := []models.BookedSeat{{BookingID: 1, SeatID: 101, SeatNumber: "1A", SeatType: "standard"}}
+	held := []models.HeldSeat{
+		// A staff seat blocked on the swapped trip, for the whole route
+		{Kind: models.SeatHoldBlock, ID: 3, SeatID: 110, SeatNumber: "10D", SeatType: "standard"},
+		{Kind: models.SeatHoldWaitlist, ID: 9, SeatID: 104, SeatNumber: "2A", SeatType: "premium", OriginStopSequence: sequence(2), DestinationStopSequence: sequence(3)},
+	}
+	seats := []models.Seat{
+		{ID: 201, SeatNumber: "1A", SeatType: "standard", IsAvailable: true},
+		{ID: 202, SeatNumber: "8D", SeatType: "standard", IsAvailable: true},
+		{ID: 203, SeatNumber: "2A", SeatType: "premium", IsAvailable: true},
+	}
+
+	changes, holds := services.MapTripSeats(booked, held, seats)
+	require.Len(t, holds, 2)
+	assert.Equal(t, 201, *changes[0].ToSeatID)
+
+	// The block keeps a seat of the same type off sale on the new vehicle
+	require.NotNil(t, holds[0].ToSeatID)
+	assert.Equal(t, 202, *holds[0].ToSeatID)
+	assert.Equal(t, services.SeatMatchType, holds[0].Match)
+	assert.Equal(t, models.SeatHoldBlock, holds[0].Kind)
+
+	// The waitlist offer follows its seat number
+	require.NotNil(t, holds[1].ToSeatID)
+	assert.Equal(t, 203, *holds[1].ToSeatID)
+	assert.Equal(t, services.SeatMatchNumber, holds[1].Match)
+}
+
+func TestMapTripSeatsReportsBlocksWithoutSeat(t *testing.T) {
+	held := []models.HeldSeat{{Kind: models.SeatHoldBlock, ID: 3, SeatID: 110, SeatNumber: "10D", SeatType: "standard"}}
+	seats := []models.Seat{{ID: 201, SeatNumber: "1A", SeatType: "standard", IsAvailable: false}}
+
+	changes, holds := services.MapTripSeats(nil, held, seats)
+	assert.Empty(t, changes)
+	require.Len(t, holds, 1)
+	assert.Nil(t, holds[0].ToSeatID)
+	assert.Empty(t, holds[0].Match)
+}
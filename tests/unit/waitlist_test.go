@@ -0,0 +1,33 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickWaitlistSeat(t *testing.T) {
+	seats := []models.Seat{
+		{ID: 1, SeatType: "standard"},
+		{ID: 2, SeatType: "premium"},
+		{ID: 3, SeatType: "standard"},
+	}
+	premium := "premium"
+	disabled := "disabled"
+
+	assert.Equal(t, 1, services.PickWaitlistSeat(seats, nil).ID)
+	assert.Equal(t, 2, services.PickWaitlistSeat(seats, &premium).ID)
+	assert.Nil(t, services.PickWaitlistSeat(seats, &disabled))
+	assert.Nil(t, services.PickWaitlistSeat(nil, nil))
+}
+
+func TestWaitlistOfferExpiry(t *testing.T) {
+	offeredAt := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, offeredAt.Add(30*time.Minute), services.WaitlistOfferExpiry(offeredAt, 30*time.Minute, offeredAt.Add(5*time.Hour)))
+	// An offer never outlives the departure
+	assert.Equal(t, offeredAt.Add(10*time.Minute), services.WaitlistOfferExpiry(offeredAt, 30*time.Minute, offeredAt.Add(10*time.Minute)))
+}
@@ -0,0 +1,106 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/models"
+	"github.com/Rodrigoberes/TransportBookingBackend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+
+func yieldRules() []models.YieldRule {
+	return []models.YieldRule{
+		{ID: 1, Name: "Last minute", MinLoadPercent: 0, MaxLoadPercent: 100, MaxDaysBefore: intPtr(2), PricePercent: 140, IsActive: true},
+		{ID: 2, Name: "Nearly full", MinLoadPercent: 80, MaxLoadPercent: 100, PricePercent: 125, IsActive: true},
+		{ID: 3, Name: "Early bird", MinLoadPercent: 0, MaxLoadPercent: 30, MinDaysBefore: intPtr(30), PricePercent: 70, IsActive: true},
+		{ID: 4, Name: "Disabled", MinLoadPercent: 0, MaxLoadPercent: 100, PricePercent: 10, IsActive: false},
+	}
+}
+
+func TestYieldPercent(t *testing.T) {
+	rules := yieldRules()
+
+	percent, rule := services.YieldPercent(rules, 50, 1, nil, nil)
+	assert.Equal(t, 140.0, percent)
+	assert.Equal(t, 1, rule.ID)
+
+	// The first matching rule wins over later ones
+	percent, rule = services.YieldPercent(rules, 90, 1, nil, nil)
+	assert.Equal(t, 140.0, percent)
+	assert.Equal(t, 1, rule.ID)
+
+	percent, rule = services.YieldPercent(rules, 90, 10, nil, nil)
+	assert.Equal(t, 125.0, percent)
+	assert.Equal(t, 2, rule.ID)
+
+	percent, rule = services.YieldPercent(rules, 10, 45, nil, nil)
+	assert.Equal(t, 70.0, percent)
+	assert.Equal(t, 3, rule.ID)
+
+	// No active rule matches: the static fare applies
+	percent, rule = services.YieldPercent(rules, 50, 10, nil, nil)
+	assert.Equal(t, 100.0, percent)
+	assert.Nil(t, rule)
+}
+
+func TestYieldPercentCaps(t *testing.T) {
+	rules := yieldRules()
+
+	percent, _ := services.YieldPercent(rules, 50, 1, nil, floatPtr(120))
+	assert.Equal(t, 120.0, percent)
+	percent, _ = services.YieldPercent(rules, 10, 45, floatPtr(80), nil)
+	assert.Equal(t, 80.0, percent)
+}
+
+func TestLoadPercentAndDaysBefore(t *testing.T) {
+	assert.Equal(t, 37.5, services.LoadPercent(15, 40))
+	assert.Equal(t, 0.0, services.LoadPercent(3, 0))
+
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2, services.DaysBefore(now.Add(71*time.Hour), now))
+	assert.Equal(t, 0, services.DaysBefore(now.Add(-time.Hour), now))
+}
+
+func TestSimulateYield(t *testing.T) {
+	route := &models.Route{BasePrice: 50, MaxYieldPercent: floatPtr(130)}
+	// Given out of order, rules are evaluated by priority
+	rules := []models.YieldRule{
+		{Name: "Nearly full", MinLoadPercent: 80, MaxLoadPercent: 100, PricePercent: 125, Priority: 2, IsActive: true},
+		{Name: "Last minute", MaxLoadPercent: 100, MaxDaysBefore: intPtr(2), PricePercent: 150, Priority: 1, IsActive: true},
+	}
+
+	quotes := services.SimulateYield(route, rules, []float64{50, 90}, []int{1, 10})
+	assert.Len(t, quotes, 4)
+	assert.Equal(t, "Last minute", quotes[0].RuleName)
+	assert.Equal(t, 65.0, quotes[0].Price) // capped at 130%
+	assert.Equal(t, "Last minute", quotes[1].RuleName)
+	assert.Equal(t, 50.0, quotes[2].Price)
+	assert.Equal(t, 62.5, quotes[3].Price)
+
+	assert.Len(t, services.SimulateYield(route, nil, nil, nil), len(services.DefaultSimulationLoads)*len(services.DefaultSimulationDays))
+}
+
+func TestValidateYieldRule(t *testing.T) {
+	valid := models.YieldRule{Name: "Peak", MinLoadPercent: 50, MaxLoadPercent: 100, MinDaysBefore: intPtr(0), MaxDaysBefore: intPtr(7), PricePercent: 120}
+	assert.NoError(t, services.ValidateYieldRule(&valid))
+
+	cases := []func(r *models.YieldRule){
+		func(r *models.YieldRule) { r.Name = "" },
+		func(r *models.YieldRule) { r.MinLoadPercent = 60; r.MaxLoadPercent = 40 },
+		func(r *models.YieldRule) { r.MaxLoadPercent = 110 },
+		func(r *models.YieldRule) { r.MinDaysBefore = intPtr(10) },
+		func(r *models.YieldRule) { r.PricePercent = 0 },
+	}
+	for _, mutate := range cases {
+		rule := valid
+		mutate(&rule)
+		assert.True(t, errors.Is(services.ValidateYieldRule(&rule), services.ErrInvalidYieldRule))
+	}
+
+	assert.NoError(t, services.ValidateYieldCaps(floatPtr(80), floatPtr(150)))
+	assert.Error(t, services.ValidateYieldCaps(floatPtr(150), floatPtr(80)))
+}